@@ -0,0 +1,163 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+var (
+	adoptInventoryFile string
+	adoptState         string
+	adoptApplyTags     bool
+	adoptTags          map[string]string
+	adoptOutput        string
+)
+
+// adoptCmd represents the adopt command.
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <resource stack> <name>",
+	Short: "Bring an AWS resource created outside of aws-builder under management",
+	Long: fmt.Sprintf(`Bring an AWS resource created outside of aws-builder under management by
+writing an inventory for it.  The name is the resource to adopt for the
+resource stack:
+* eks - the EKS cluster name; its VPC, subnets, security group, IAM roles,
+  node groups and OIDC provider are adopted with it
+* rds - the DB instance identifier; its subnet group and security group are
+  adopted with it
+* s3 - the bucket name
+Adopted resources are marked in the inventory and are kept when the resource
+stack is deleted unless "aws-builder delete --include-adopted" is used.  Use
+--apply-tags to add a Name tag with the name, and any --tags, to the adopted
+resources.  An existing inventory is kept in the state history and can be
+restored with "aws-builder inventory restore".
+%s`, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack and name arguments provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+		title := strings.ToUpper(resourceStack.Name())
+
+		if err := validateOutput(adoptOutput); err != nil {
+			return err
+		}
+		if len(adoptTags) > 0 && !adoptApplyTags {
+			return errors.New("--tags may only be provided with --apply-tags")
+		}
+
+		// use state location if provided, otherwise create default inventory
+		// filename if not provided
+		stateLocation := adoptState
+		if stateLocation != "" && adoptInventoryFile != "" {
+			return errors.New("only one of --state and --inventory-file may be provided")
+		}
+		if stateLocation == "" {
+			stateLocation = adoptInventoryFile
+		}
+		if stateLocation == "" {
+			stateLocation = fmt.Sprintf("%s-inventory.json", args[0])
+		}
+
+		// load AWS config
+		awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, "", awsSerialNumber)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+
+		// get backend to store inventory in
+		backend, err := state.New(stateLocation, awsConfig)
+		if err != nil {
+			return err
+		}
+
+		// create resource client - adopting does not send messages
+		resourceClient := newResourceClient(cmd.Context(), awsConfig)
+
+		adopter, ok := resourceStack.New(resourceClient).(stack.Adopter)
+		if !ok {
+			return fmt.Errorf("adopting %s resource stacks is not supported", resourceStack.Name())
+		}
+
+		cmd.SilenceUsage = true
+
+		// lock state before any tags are applied and keep any existing
+		// inventory in its history
+		ctx := cmd.Context()
+		lockInfo, err := state.Acquire(ctx, backend, "adopt")
+		if err != nil {
+			return err
+		}
+		defer state.Release(ctx, backend, lockInfo.Id)
+
+		resourceInventory := resourceStack.NewInventory()
+		report, err := adopter.Adopt(args[1], adoptTags, adoptApplyTags, resourceInventory)
+		if err != nil {
+			return fmt.Errorf("failed to adopt %s resource stack: %w", title, err)
+		}
+		if err := resourceInventory.WriteState(ctx, backend); err != nil {
+			return err
+		}
+
+		if adoptOutput == "json" {
+			err = report.WriteJson(os.Stdout)
+		} else {
+			err = report.WriteText(os.Stdout)
+		}
+		if err != nil {
+			return err
+		}
+
+		if report.HasAmbiguity() {
+			return fmt.Errorf(
+				"%d ambiguous resources in %s resource stack need manual resolution in inventory '%s'",
+				report.Count(discovery.StatusAmbiguous),
+				title,
+				backend,
+			)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().StringVarP(
+		&adoptInventoryFile, "inventory-file", "i", "",
+		"File to write AWS resource inventory to",
+	)
+	adoptCmd.Flags().StringVarP(
+		&adoptState, "state", "", "",
+		"Location to store AWS resource inventory in, e.g. s3://bucket/key or a file path",
+	)
+	adoptCmd.Flags().BoolVar(
+		&adoptApplyTags, "apply-tags", false,
+		"Add a Name tag with the name, and any --tags, to the adopted resources",
+	)
+	adoptCmd.Flags().StringToStringVar(
+		&adoptTags, "tags", nil,
+		"Custom tags to add to the adopted resources with --apply-tags, e.g. team=platform,env=prod",
+	)
+	adoptCmd.Flags().StringVarP(
+		&adoptOutput, "output", "o", "text",
+		"Output format for the adoption report: text or json",
+	)
+}
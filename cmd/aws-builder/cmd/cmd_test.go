@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+// useFakeBackend makes commands call a new fake backend in the region
+// instead of AWS for the rest of the test.
+func useFakeBackend(t *testing.T, region string) *fake.Backend {
+	t.Helper()
+
+	backend := fake.NewBackend(region)
+	configDir := t.TempDir()
+	configFile := filepath.Join(configDir, "config")
+	if err := os.WriteFile(configFile, []byte("[default]\nregion = "+backend.Region+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write AWS config file: %v", err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(configDir, "credentials"))
+
+	newResourceClient = func(ctx context.Context, awsConfig *aws.Config) *client.ResourceClient {
+		resourceClient := client.CreateResourceClientWithContext(ctx, awsConfig)
+		resourceClient.Apis = backend.Apis()
+		return resourceClient
+	}
+	t.Cleanup(func() {
+		newResourceClient = client.CreateResourceClientWithContext
+	})
+
+	return backend
+}
+
+// execute runs the command line with the arguments and returns what was
+// written to stdout.  Flags are reset to their defaults afterwards so they
+// don't carry over to the next command line.
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	defer resetFlags(rootCmd)
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe for stdout: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	var output bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(&output, reader)
+		close(copied)
+	}()
+
+	rootCmd.SetArgs(args)
+	err = rootCmd.ExecuteContext(context.Background())
+
+	os.Stdout = stdout
+	writer.Close()
+	<-copied
+
+	return output.String(), err
+}
+
+// resetFlags sets the flags of the command and its subcommands back to their
+// defaults.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			sliceValue.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, subcommand := range cmd.Commands() {
+		resetFlags(subcommand)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+var configShowEffective bool
+
+// configCmd represents the config command.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect AWS resource stack config",
+	Long:  `Inspect AWS resource stack config.`,
+}
+
+// configShowCmd represents the config show command.
+var configShowCmd = &cobra.Command{
+	Use:   "show <resource stack> <config file>...",
+	Short: "Print an AWS resource stack config",
+	Long: fmt.Sprintf(`Print an AWS resource stack config as YAML.  By default only the values set
+in the config files are printed.  With --effective, the fully-resolved config
+that create would use is printed, with defaults applied for every value not
+set in the config files and the region from AWS config if none is set.
+Secrets such as the RDS database password are redacted.
+%s
+%s`, configFilesHelp, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack and config file arguments provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		var resourceConfig stack.Config
+		if configShowEffective {
+			if resourceConfig, err = resourceStack.LoadConfig(nil, args[1:]...); err != nil {
+				return fmt.Errorf("failed to load %s config file: %w", strings.ToUpper(args[0]), err)
+			}
+
+			// the region in AWS config is used if the config doesn't set
+			// one
+			if resourceConfig.GetRegion() == "" {
+				region := awsRegion
+				if region == "" {
+					awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, "", "", "", "")
+					if err != nil {
+						return fmt.Errorf("failed to load AWS config: %w", err)
+					}
+					region = awsConfig.Region
+				}
+				resourceConfig.SetRegion(region)
+			}
+		} else {
+			resourceConfig = resourceStack.NewConfig()
+			if err := configfile.Load(resourceConfig, args[1:]...); err != nil {
+				return err
+			}
+		}
+		if redacter, ok := resourceConfig.(stack.Redacter); ok {
+			redacter.Redact()
+		}
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(resourceConfig); err != nil {
+			return fmt.Errorf("failed to marshal config to YAML: %w", err)
+		}
+
+		return encoder.Close()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configShowCmd.Flags().BoolVar(
+		&configShowEffective, "effective", false,
+		"Print the config with defaults applied",
+	)
+}
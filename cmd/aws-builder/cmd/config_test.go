@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nukleros/aws-builder/pkg/rds"
+)
+
+func TestConfigShow(t *testing.T) {
+	useFakeBackend(t, "us-west-2")
+	configFile := filepath.Join(t.TempDir(), "rds-config.yaml")
+	content := "engine: postgres\nstorageGb: 50\ndbUserPassword: secret-password\n"
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	// only values set in the file are printed by default
+	output, err := execute(t, "config", "show", "rds", configFile)
+	if err != nil {
+		t.Fatalf("failed to run config show: %v", err)
+	}
+	var shown rds.RdsConfig
+	if err := yaml.Unmarshal([]byte(output), &shown); err != nil {
+		t.Fatalf("failed to unmarshal config: %v\n%s", err, output)
+	}
+	if shown.StorageGb != 50 || shown.DbPort != 0 || shown.Region != "" {
+		t.Errorf("expected only file values, got:\n%s", output)
+	}
+
+	// file values win over defaults and the region comes from AWS config
+	output, err = execute(t, "config", "show", "rds", configFile, "--effective")
+	if err != nil {
+		t.Fatalf("failed to run config show: %v", err)
+	}
+	var effective rds.RdsConfig
+	if err := yaml.Unmarshal([]byte(output), &effective); err != nil {
+		t.Fatalf("failed to unmarshal config: %v\n%s", err, output)
+	}
+	if effective.StorageGb != 50 || effective.Class != rds.DefaultClass || effective.DbPort != 5432 || effective.Region != "us-west-2" {
+		t.Errorf("expected file values with defaults, got:\n%s", output)
+	}
+	if strings.Contains(output, "secret-password") || effective.DbUserPassword != "<redacted>" {
+		t.Errorf("expected password to be redacted, got:\n%s", output)
+	}
+}
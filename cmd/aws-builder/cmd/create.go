@@ -4,41 +4,72 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/spf13/cobra"
 
-	"github.com/nukleros/aws-builder/pkg/client"
 	"github.com/nukleros/aws-builder/pkg/config"
-	"github.com/nukleros/aws-builder/pkg/eks"
-	"github.com/nukleros/aws-builder/pkg/rds"
-	"github.com/nukleros/aws-builder/pkg/s3"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
 )
 
 var (
 	createInventoryFile string
+	createState         string
 	inputInventoryFile  string
+	createOutput        string
 )
 
 // createCmd represents the create command.
 var createCmd = &cobra.Command{
-	Use:   "create <resource stack> <config file>",
+	Use:   "create <resource stack> <config file>...",
 	Short: "Provision an AWS resource stack",
-	Long: fmt.Sprintf(`Provision an AWS resource stack.
-%s`, supportedResourceStacks),
+	Long: fmt.Sprintf(`Provision an AWS resource stack.  Resources in the inventory already
+stored at the state location, e.g. by an interrupted create, are reused.
+%s
+%s`, configFilesHelp, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("creating AWS resource stack...")
-
 		// ensure resource stack argument provided
 		if len(args) < 2 {
 			return fmt.Errorf("missing arguments")
 		}
 
-		// create default inventory filename if not provided
-		if createInventoryFile == "" {
-			createInventoryFile = fmt.Sprintf("%s-inventory.json", args[0])
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+		title := strings.ToUpper(resourceStack.Name())
+
+		if err := validateOutput(createOutput); err != nil {
+			return err
+		}
+
+		// waiter settings from flags override those in the resource config
+		waiters, err := waiterConfigs()
+		if err != nil {
+			return err
+		}
+
+		if createOutput == "text" {
+			fmt.Println("creating AWS resource stack...")
+		}
+
+		// use state location if provided, otherwise create default inventory
+		// filename if not provided
+		stateLocation := createState
+		if stateLocation != "" && createInventoryFile != "" {
+			return errors.New("only one of --state and --inventory-file may be provided")
+		}
+		if stateLocation == "" {
+			stateLocation = createInventoryFile
+		}
+		if stateLocation == "" {
+			stateLocation = fmt.Sprintf("%s-inventory.json", args[0])
 		}
 
 		// load AWS config
@@ -47,119 +78,115 @@ var createCmd = &cobra.Command{
 			return fmt.Errorf("failed to load AWS config: %w", err)
 		}
 
+		// get backend to store inventory in
+		backend, err := state.New(stateLocation, awsConfig)
+		if err != nil {
+			return err
+		}
+
+		// load input inventory if provided - before the state is locked so an
+		// invalid file can't leave the state locked
+		resourceInventory := resourceStack.NewInventory()
+		if inputInventoryFile != "" {
+			if err := resourceInventory.Load(inputInventoryFile); err != nil {
+				return fmt.Errorf("failed to load input inventory: %w", err)
+			}
+		}
+
 		// create resource client
-		resourceClient := client.CreateResourceClient(awsConfig)
+		resourceClient := newResourceClient(cmd.Context(), awsConfig)
+		resourceClient.Waiters = waiters
 
 		// use a wait group to ensure messages and inventory are processed
 		// before quitting
 		var createWait sync.WaitGroup
 
-		// capture messages as resources are created and return to user
-		createWait.Add(1)
-		go func() {
-			defer createWait.Done()
-			for msg := range *resourceClient.MessageChan {
-				fmt.Println(msg)
-			}
-		}()
-
-		// call requested resource stack creation
-		switch args[0] {
-		case "eks":
-			// create client and config for resource creation
-			invChan := make(chan eks.EksInventory)
-			eksClient, eksConfig, err := eks.InitCreate(
-				resourceClient,
-				args[1],
-				createInventoryFile,
-				&invChan,
-				&createWait,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to initialize EKS resource client and config: %w", err)
-			}
-
-			// load input inventory if provided
-			var eksInventory eks.EksInventory
-			if inputInventoryFile != "" {
-				if err := eksInventory.Load(inputInventoryFile); err != nil {
-					return fmt.Errorf("failed to load input inventory: %w", err)
-				}
-			}
-
-			// create resources
-			if err := eksClient.CreateEksResourceStack(eksConfig, &eksInventory); err != nil {
-				return fmt.Errorf("failed to create EKS resource stack: %w", err)
-			}
-			close(invChan)
-		case "rds":
-			// create client and config for resource creation
-			invChan := make(chan rds.RdsInventory)
-			rdsClient, rdsConfig, err := rds.InitCreate(
-				resourceClient,
-				args[1],
-				createInventoryFile,
-				&invChan,
-				&createWait,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to initialize RDS resource client and config: %w", err)
-			}
-
-			// load input inventory if provided
-			var rdsInventory rds.RdsInventory
-			if inputInventoryFile != "" {
-				if err := rdsInventory.Load(inputInventoryFile); err != nil {
-					return fmt.Errorf("failed to load input inventory: %w", err)
-				}
-			}
-
-			// create resources
-			if err := rdsClient.CreateRdsResourceStack(rdsConfig, &rdsInventory); err != nil {
-				return fmt.Errorf("failed to create RDS resource stack: %w", err)
-			}
-			close(invChan)
-		case "s3":
-			// create client and config for resource creation
-			invChan := make(chan s3.S3Inventory)
-			s3Client, s3Config, err := s3.InitCreate(
-				resourceClient,
-				args[1],
-				createInventoryFile,
-				&invChan,
-				&createWait,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to initialize S3 resource client and config: %w", err)
-			}
+		// capture messages or events as resources are created and return to
+		// user
+		closeProgressOutput := startProgressOutput(resourceClient, createOutput, &createWait)
+
+		// create client and config for resource creation
+		stackClient, resourceConfig, closeInventory, err := stack.InitCreate(
+			resourceStack,
+			resourceClient,
+			args[1:],
+			nil,
+			backend,
+			&createWait,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to initialize %s resource client and config: %w", title, err)
+		}
 
-			// create resources
-			if err := s3Client.CreateS3ResourceStack(s3Config); err != nil {
-				return fmt.Errorf("failed to create S3 resource stack: %w", err)
-			}
-			close(invChan)
-		default:
-			return errors.New("unrecognized resource stack")
+		// reuse resources in the inventory stored by an earlier create, then
+		// create resources
+		var createErr error
+		if err := loadStoredInventory(cmd.Context(), backend, resourceInventory); err != nil {
+			createErr = fmt.Errorf("failed to load %s inventory: %w", title, err)
+		} else if err := stackClient.Create(resourceConfig, resourceInventory); err != nil {
+			createErr = fmt.Errorf("failed to create %s resource stack: %w", title, err)
 		}
+		closeInventory()
 
-		close(*resourceClient.MessageChan)
+		closeProgressOutput()
 
-		// wait until all inventory and message goroutines have completed
+		// wait until all inventory and message goroutines have completed so
+		// the latest inventory is written to file even if an error occurred
 		createWait.Wait()
-		fmt.Println("AWS resource stack created")
+		if createErr != nil {
+			return interruptedError(createErr, backend.String())
+		}
+		if createOutput == "text" {
+			fmt.Println("AWS resource stack created")
+		}
 
 		return nil
 	},
 }
 
+// loadStoredInventory loads the inventory stored in the backend by an earlier
+// create, if any, so its resources are reused.  An input inventory file can
+// only be used for a resource stack with no stored inventory since it would
+// replace the stored inventory and drop its resources from state.
+func loadStoredInventory(ctx context.Context, backend state.Backend, inventory stack.Inventory) error {
+	if inputInventoryFile != "" {
+		_, err := backend.Read(ctx)
+		switch {
+		case errors.Is(err, state.ErrNotFound):
+			return nil
+		case err != nil:
+			return err
+		}
+		return fmt.Errorf(
+			"inventory is already stored at '%s' and is reused by create, --input-inventory-file may only be used when there is none",
+			backend,
+		)
+	}
+
+	if err := inventory.LoadState(ctx, backend); err != nil && !errors.Is(err, state.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(createCmd)
 	createCmd.Flags().StringVarP(
 		&createInventoryFile, "inventory-file", "i", "",
 		"File to write AWS resource inventory to",
 	)
+	createCmd.Flags().StringVarP(
+		&createState, "state", "", "",
+		"Location to store AWS resource inventory in, e.g. s3://bucket/key or a file path; the state is locked while resources are created",
+	)
 	createCmd.Flags().StringVarP(
 		&inputInventoryFile, "input-inventory-file", "", "",
-		"File to read existing inventory from; existing inventory will be used as a part of the resource stack",
+		"File to read existing inventory from when no inventory is stored yet; existing inventory will be used as a part of the resource stack",
+	)
+	createCmd.Flags().StringVarP(
+		&createOutput, "output", "o", "text",
+		"Output format for progress: text or json (one event per line)",
 	)
+	addWaiterFlags(createCmd)
 }
@@ -4,33 +4,66 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"sync"
 
 	"github.com/spf13/cobra"
 
-	"github.com/nukleros/aws-builder/pkg/client"
 	"github.com/nukleros/aws-builder/pkg/config"
-	"github.com/nukleros/aws-builder/pkg/eks"
-	"github.com/nukleros/aws-builder/pkg/rds"
-	"github.com/nukleros/aws-builder/pkg/s3"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+var (
+	deleteState          string
+	deleteOutput         string
+	deleteIncludeAdopted bool
 )
 
 // deleteCmd represents the delete command.
 var deleteCmd = &cobra.Command{
-	Use:   "delete <resource stack> <inventory file>",
+	Use:   "delete <resource stack> [inventory file]",
 	Short: "Remove an AWS resource stack",
-	Long: fmt.Sprintf(`Remove an AWS resource stack.
+	Long: fmt.Sprintf(`Remove an AWS resource stack.  Resources brought under management with
+"aws-builder adopt" are kept unless --include-adopted is used.
 %s`, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("deleting AWS resource stack...")
-
-		// ensure resource stack argument provided
-		if len(args) < 2 {
+		// ensure resource stack argument and one of inventory file or state
+		// location provided
+		if len(args) < 1 || (len(args) < 2 && deleteState == "") {
 			return fmt.Errorf("missing arguments")
 		}
+		stateLocation := deleteState
+		if len(args) > 1 {
+			if stateLocation != "" {
+				return errors.New("only one of inventory file and --state may be provided")
+			}
+			stateLocation = args[1]
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+		title := strings.ToUpper(resourceStack.Name())
+
+		if err := validateOutput(deleteOutput); err != nil {
+			return err
+		}
+
+		// waiter settings from flags override those in the resource config
+		waiters, err := waiterConfigs()
+		if err != nil {
+			return err
+		}
+
+		if deleteOutput == "text" {
+			fmt.Println("deleting AWS resource stack...")
+		}
 
 		// load AWS config
 		awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, "", awsSerialNumber)
@@ -38,93 +71,62 @@ var deleteCmd = &cobra.Command{
 			return fmt.Errorf("failed to load AWS config: %w", err)
 		}
 
+		// get backend inventory is stored in
+		backend, err := state.New(stateLocation, awsConfig)
+		if err != nil {
+			return err
+		}
+
 		// create resource client
-		resourceClient := client.CreateResourceClient(awsConfig)
+		resourceClient := newResourceClient(cmd.Context(), awsConfig)
+		resourceClient.Waiters = waiters
+		resourceClient.DeleteAdopted = deleteIncludeAdopted
 
 		// use a wait group to ensure messages and inventory are processed
 		// before quitting
 		var deleteWait sync.WaitGroup
 
-		// capture messages as resources are deleted and return to user
-		deleteWait.Add(1)
-		go func() {
-			defer deleteWait.Done()
-			for msg := range *resourceClient.MessageChan {
-				fmt.Println(msg)
-			}
-		}()
-
-		// call requested resource stack deletion
-		switch args[0] {
-		case "eks":
-			// create client and config for resource deletion
-			invChan := make(chan eks.EksInventory)
-			eksClient, eksInventory, err := eks.InitDelete(
-				resourceClient,
-				args[1],
-				&invChan,
-				&deleteWait,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to initialize EKS resource client and inventory: %w", err)
-			}
-
-			// delete resources
-			if err := eksClient.DeleteEksResourceStack(eksInventory); err != nil {
-				return fmt.Errorf("failed to remove EKS resource stack: %w", err)
-			}
-			close(invChan)
-		case "rds":
-			// create client and config for resource deletion
-			invChan := make(chan rds.RdsInventory)
-			rdsClient, rdsInventory, err := rds.InitDelete(
-				resourceClient,
-				args[1],
-				&invChan,
-				&deleteWait,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to initialize RDS resource client and inventory: %w", err)
-			}
-
-			// delete resources
-			if err := rdsClient.DeleteRdsResourceStack(rdsInventory); err != nil {
-				return fmt.Errorf("failed to remove RDS resource stack: %w", err)
-			}
-			close(invChan)
-		case "s3":
-			// create client and config for resource deletion
-			invChan := make(chan s3.S3Inventory)
-			s3Client, s3Inventory, err := s3.InitDelete(
-				resourceClient,
-				args[1],
-				&invChan,
-				&deleteWait,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to initialize S3 resource client and inventory: %w", err)
-			}
+		// capture messages or events as resources are deleted and return to
+		// user
+		closeProgressOutput := startProgressOutput(resourceClient, deleteOutput, &deleteWait)
+
+		// create client and inventory for resource deletion
+		stackClient, resourceInventory, closeInventory, err := stack.InitDelete(
+			resourceStack,
+			resourceClient,
+			backend,
+			&deleteWait,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to initialize %s resource client and inventory: %w", title, err)
+		}
 
-			// delete resources
-			if err := s3Client.DeleteS3ResourceStack(s3Inventory); err != nil {
-				return fmt.Errorf("failed to remove S3 resource stack: %w", err)
-			}
-			close(invChan)
-		default:
-			return errors.New("unrecognized resource stack")
+		// delete resources
+		var deleteErr error
+		if err := stackClient.Delete(resourceInventory); err != nil {
+			deleteErr = fmt.Errorf("failed to remove %s resource stack: %w", title, err)
 		}
+		closeInventory()
 
-		close(*resourceClient.MessageChan)
+		closeProgressOutput()
 
-		// wait until all inventory and message goroutines have completed
+		// wait until all inventory and message goroutines have completed so
+		// the latest inventory is written to file even if an error occurred
 		deleteWait.Wait()
-		fmt.Println("AWS resources deleted")
+		if deleteErr != nil {
+			return interruptedError(deleteErr, backend.String())
+		}
+		if deleteOutput == "text" {
+			fmt.Println("AWS resources deleted")
+		}
 
-		// remove inventory file from filesystem
-		if err := os.Remove(args[1]); err != nil {
-			return fmt.Errorf("failed to remove eks cluster inventory file: %w", err)
+		// remove inventory from state
+		if err := backend.Delete(context.WithoutCancel(cmd.Context())); err != nil {
+			return fmt.Errorf("failed to remove inventory: %w", err)
+		}
+		if deleteOutput == "text" {
+			fmt.Printf("Inventory '%s' deleted, previous versions are kept in its history\n", backend)
 		}
-		fmt.Printf("Inventory file '%s' deleted\n", args[1])
 
 		return nil
 	},
@@ -132,4 +134,17 @@ var deleteCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(deleteCmd)
+	deleteCmd.Flags().StringVarP(
+		&deleteState, "state", "", "",
+		"Location AWS resource inventory is stored in, e.g. s3://bucket/key, if no inventory file is provided; the state is locked while resources are deleted",
+	)
+	deleteCmd.Flags().StringVarP(
+		&deleteOutput, "output", "o", "text",
+		"Output format for progress: text or json (one event per line)",
+	)
+	deleteCmd.Flags().BoolVar(
+		&deleteIncludeAdopted, "include-adopted", false,
+		"Also delete resources that were adopted rather than created by aws-builder",
+	)
+	addWaiterFlags(deleteCmd)
 }
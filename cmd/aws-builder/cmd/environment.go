@@ -0,0 +1,156 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/environment"
+)
+
+var environmentOutput string
+
+// environmentManifestHelp describes environment manifests.
+const environmentManifestHelp = `An environment manifest lists resource stacks, e.g.:
+
+  stacks:
+  - name: eks
+    configFiles: [eks-config.yaml]
+  - name: db
+    type: rds
+    configFiles: [rds-config.yaml]
+    state: s3://bucket/db-inventory.json
+
+Config files can reference the outputs of other resource stacks in the
+environment, e.g. vpcId: ${eks.vpcId} or subnetIds: ${eks.privateSubnetIds},
+and resource stacks are ordered so each is created after the resource stacks it
+references.  Use dependsOn to add dependencies that are not referenced.  The
+type defaults to the name and inventory is stored in <name>-inventory.json by
+default.  Relative paths are relative to the manifest.`
+
+// environmentCmd represents the environment command.
+var environmentCmd = &cobra.Command{
+	Use:     "environment",
+	Aliases: []string{"env"},
+	Short:   "Manage several AWS resource stacks together",
+	Long: fmt.Sprintf(`Manage several AWS resource stacks together as an environment.
+%s
+%s`, environmentManifestHelp, supportedResourceStacks),
+}
+
+// environmentCreateCmd represents the environment create command.
+var environmentCreateCmd = &cobra.Command{
+	Use:   "create <manifest file>",
+	Short: "Provision the AWS resource stacks in an environment",
+	Long: `Provision the AWS resource stacks in an environment in dependency order.
+Resource stacks that already have inventory reuse the resources in it so an
+interrupted create can be run again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runEnvironment(cmd, args, "create")
+	},
+}
+
+// environmentDeleteCmd represents the environment delete command.
+var environmentDeleteCmd = &cobra.Command{
+	Use:   "delete <manifest file>",
+	Short: "Remove the AWS resource stacks in an environment",
+	Long: `Remove the AWS resource stacks in an environment in the reverse of
+dependency order so resource stacks are removed before the resource stacks
+they reference.  Resource stacks with no inventory are skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runEnvironment(cmd, args, "delete")
+	},
+}
+
+// runEnvironment creates or deletes the resource stacks in an environment.
+func runEnvironment(cmd *cobra.Command, args []string, operation string) error {
+	// ensure manifest argument provided
+	if len(args) < 1 {
+		return fmt.Errorf("missing arguments")
+	}
+
+	if err := validateOutput(environmentOutput); err != nil {
+		return err
+	}
+
+	// waiter settings from flags override those in the resource config
+	waiters, err := waiterConfigs()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := environment.LoadManifest(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to load environment manifest: %w", err)
+	}
+	order, err := manifest.CreateOrder()
+	verb := "creating"
+	if operation == "delete" {
+		order, err = manifest.DeleteOrder()
+		verb = "deleting"
+	}
+	if err != nil {
+		return err
+	}
+	if environmentOutput == "text" {
+		var names []string
+		for _, stackManifest := range order {
+			names = append(names, stackManifest.Name)
+		}
+		fmt.Printf("%s AWS resource stacks in order: %s\n", verb, strings.Join(names, ", "))
+	}
+
+	// load AWS config
+	awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, "", awsSerialNumber)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	// create resource client
+	resourceClient := newResourceClient(cmd.Context(), awsConfig)
+	resourceClient.Waiters = waiters
+
+	// use a wait group to ensure messages are processed before quitting
+	var environmentWait sync.WaitGroup
+
+	// capture messages or events as resources are changed and return to user
+	closeProgressOutput := startProgressOutput(resourceClient, environmentOutput, &environmentWait)
+
+	var environmentErr error
+	if operation == "delete" {
+		environmentErr = environment.Delete(resourceClient, manifest)
+	} else {
+		environmentErr = environment.Create(resourceClient, manifest)
+	}
+
+	closeProgressOutput()
+	environmentWait.Wait()
+	if environmentErr != nil {
+		return interruptedError(environmentErr, "each resource stack's state")
+	}
+	if environmentOutput == "text" && operation == "delete" {
+		fmt.Println("AWS resource stacks deleted")
+	} else if environmentOutput == "text" {
+		fmt.Println("AWS resource stacks created")
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(environmentCmd)
+	environmentCmd.AddCommand(environmentCreateCmd)
+	environmentCmd.AddCommand(environmentDeleteCmd)
+	environmentCmd.PersistentFlags().StringVarP(
+		&environmentOutput, "output", "o", "text",
+		"Output format for progress: text or json (one event per line)",
+	)
+	addWaiterFlags(environmentCreateCmd)
+	addWaiterFlags(environmentDeleteCmd)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/orphan"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+var (
+	gcInventoryFile string
+	gcState         string
+	gcDelete        bool
+	gcReportOnly    bool
+	gcYes           bool
+	gcOutput        string
+)
+
+// gcCmd represents the gc command.
+var gcCmd = &cobra.Command{
+	Use:   "gc <resource stack> <config file>...",
+	Short: "Find and delete resources of an AWS resource stack that are not in its inventory",
+	Long: fmt.Sprintf(`Find and delete resources of an AWS resource stack that are not in its
+inventory, such as elastic IPs and NAT gateways left behind by a failed
+create.  Resources are searched for by the tags, names and IAM path the create
+command would give them with the same config files:
+* eks - EC2 resources by tags, IAM roles by name, IAM policies under the
+  /<cluster name>/ path and the cluster, node groups and OIDC provider by the
+  cluster name
+* s3 - buckets named with the resource stack name and a UUID suffix that
+  have the resource stack's tags
+Each orphan is shown with an estimated monthly cost.  Orphans are deleted once
+confirmed, or without confirmation with --yes.  Use --report-only to only show
+them.  If no inventory is found every resource that matches is reported, so
+these broad scans only report unless --delete is used.  The state is locked
+while searching so resources being created are not mistaken for orphans.
+%s
+%s`, configFilesHelp, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack and config file arguments provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+		title := strings.ToUpper(resourceStack.Name())
+
+		if err := validateOutput(gcOutput); err != nil {
+			return err
+		}
+		if gcDelete && gcReportOnly {
+			return errors.New("only one of --delete and --report-only may be provided")
+		}
+
+		// waiter settings from flags override the defaults
+		waiters, err := waiterConfigs()
+		if err != nil {
+			return err
+		}
+
+		// use state location if provided, otherwise create default inventory
+		// filename if not provided
+		stateLocation := gcState
+		if stateLocation != "" && gcInventoryFile != "" {
+			return errors.New("only one of --state and --inventory-file may be provided")
+		}
+		if stateLocation == "" {
+			stateLocation = gcInventoryFile
+		}
+		if stateLocation == "" {
+			stateLocation = fmt.Sprintf("%s-inventory.json", args[0])
+		}
+
+		// load AWS config
+		awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, "", awsSerialNumber)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+
+		// get backend inventory is stored in
+		backend, err := state.New(stateLocation, awsConfig)
+		if err != nil {
+			return err
+		}
+
+		// create resource client - searching does not send messages
+		resourceClient := newResourceClient(cmd.Context(), awsConfig)
+		resourceClient.Waiters = waiters
+
+		collector, ok := resourceStack.New(resourceClient).(stack.Collector)
+		if !ok {
+			return fmt.Errorf("garbage collection of %s resource stacks is not supported", resourceStack.Name())
+		}
+
+		// load config
+		resourceConfig, err := resourceStack.LoadConfig(nil, args[1:]...)
+		if err != nil {
+			return fmt.Errorf("failed to load %s config file: %w", title, err)
+		}
+
+		cmd.SilenceUsage = true
+
+		// lock state so resources being created are not found as orphans
+		ctx := cmd.Context()
+		lockInfo, err := state.Acquire(ctx, backend, "gc")
+		if err != nil {
+			return err
+		}
+		defer state.Release(ctx, backend, lockInfo.Id)
+
+		// without an inventory every resource found is an orphan
+		resourceInventory := resourceStack.NewInventory()
+		broad := false
+		if err := resourceInventory.LoadState(ctx, backend); err != nil {
+			if !errors.Is(err, state.ErrNotFound) {
+				return fmt.Errorf("failed to load %s inventory: %w", title, err)
+			}
+			broad = true
+		}
+
+		report, err := collector.Orphans(resourceConfig, resourceInventory, broad)
+		if err != nil {
+			return fmt.Errorf("failed to find orphans of %s resource stack: %w", title, err)
+		}
+		if gcOutput == "text" {
+			if err := report.WriteText(os.Stdout); err != nil {
+				return err
+			}
+		}
+
+		orphanCount := report.Count(orphan.StatusOrphaned)
+		switch {
+		case orphanCount == 0, gcReportOnly:
+			return writeJsonReport(report)
+		case broad && !gcDelete:
+			if gcOutput == "text" {
+				fmt.Printf("\nNo inventory was found at '%s' so orphans are only reported, use --delete to delete them\n", backend)
+			}
+			return writeJsonReport(report)
+		case !gcYes && !confirm(fmt.Sprintf("Delete %d orphaned resources?", orphanCount)):
+			if gcOutput == "text" {
+				fmt.Println("No resources deleted")
+			}
+			return writeJsonReport(report)
+		}
+
+		// capture messages as orphans are deleted and return to user - the
+		// JSON report includes the outcome for each orphan
+		var gcWait sync.WaitGroup
+		closeProgressOutput := func() {}
+		if gcOutput == "text" {
+			fmt.Println()
+			closeProgressOutput = startProgressOutput(resourceClient, gcOutput, &gcWait)
+		}
+
+		deleteErr := resourceStack.New(resourceClient).(stack.Collector).DeleteOrphans(report)
+		closeProgressOutput()
+		gcWait.Wait()
+
+		if gcOutput == "text" {
+			fmt.Printf("\n%s\n", report.Summary())
+		} else if err := report.WriteJson(os.Stdout); err != nil {
+			return err
+		}
+		if deleteErr != nil {
+			return fmt.Errorf(
+				"failed to delete %d orphaned resources of %s resource stack: %w",
+				report.Count(orphan.StatusFailed),
+				title,
+				deleteErr,
+			)
+		}
+
+		return nil
+	},
+}
+
+// writeJsonReport writes the orphan report as JSON if JSON output was
+// requested.  Text reports are written before orphans are deleted.
+func writeJsonReport(report *orphan.Report) error {
+	if gcOutput != "json" {
+		return nil
+	}
+
+	return report.WriteJson(os.Stdout)
+}
+
+// confirm prompts on stderr for confirmation and returns true if the answer
+// read from stdin is yes.
+func confirm(prompt string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().StringVarP(
+		&gcInventoryFile, "inventory-file", "i", "",
+		"File AWS resource inventory is stored in",
+	)
+	gcCmd.Flags().StringVarP(
+		&gcState, "state", "", "",
+		"Location AWS resource inventory is stored in, e.g. s3://bucket/key or a file path",
+	)
+	gcCmd.Flags().BoolVar(
+		&gcDelete, "delete", false,
+		"Delete orphans even when no inventory was found",
+	)
+	gcCmd.Flags().BoolVar(
+		&gcReportOnly, "report-only", false,
+		"Only report orphans, never delete them",
+	)
+	gcCmd.Flags().BoolVarP(
+		&gcYes, "yes", "y", false,
+		"Delete orphans without asking for confirmation",
+	)
+	gcCmd.Flags().StringVarP(
+		&gcOutput, "output", "o", "text",
+		"Output format for the orphan report: text or json",
+	)
+	addWaiterFlags(gcCmd)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/s3"
+)
+
+func TestGcWithoutInventory(t *testing.T) {
+	backend := useFakeBackend(t, "us-east-1")
+	configFile := "../../../sample/s3-config.yaml"
+	resourceConfig, err := s3.LoadS3Config(configFile)
+	if err != nil {
+		t.Fatalf("failed to load sample config: %v", err)
+	}
+	s3Client := s3.S3Client{ResourceClient: *backend.ResourceClient()}
+	bucketName, err := s3Client.CreateBucket(
+		s3.CreateS3Tags(resourceConfig.Name, resourceConfig.Tags),
+		resourceConfig.Name,
+		resourceConfig.Region,
+	)
+	if err != nil {
+		t.Fatalf("failed to create leftover bucket: %v", err)
+	}
+	inventoryFile := filepath.Join(t.TempDir(), "s3-inventory.json")
+
+	// without an inventory orphans are only reported, even with --yes
+	output, err := execute(t, "gc", "s3", configFile, "-i", inventoryFile, "--yes")
+	if err != nil {
+		t.Fatalf("failed to run gc: %v", err)
+	}
+	if !strings.Contains(output, bucketName) || !strings.Contains(output, "orphans are only reported") {
+		t.Errorf("expected bucket %s to only be reported, got:\n%s", bucketName, output)
+	}
+	if buckets := backend.Resources()["s3-bucket"]; len(buckets) != 1 {
+		t.Errorf("expected bucket to be kept, found %v", buckets)
+	}
+
+	// --report-only never deletes
+	if _, err := execute(t, "gc", "s3", configFile, "-i", inventoryFile, "--report-only", "-o", "json"); err != nil {
+		t.Fatalf("failed to run gc: %v", err)
+	}
+	if buckets := backend.Resources()["s3-bucket"]; len(buckets) != 1 {
+		t.Errorf("expected bucket to be kept, found %v", buckets)
+	}
+
+	output, err = execute(t, "gc", "s3", configFile, "-i", inventoryFile, "--delete", "--yes", "-o", "json")
+	if err != nil {
+		t.Fatalf("failed to run gc: %v", err)
+	}
+	if !strings.Contains(output, `"status": "deleted"`) {
+		t.Errorf("expected report of the deleted bucket, got:\n%s", output)
+	}
+	if buckets := backend.Resources()["s3-bucket"]; len(buckets) != 0 {
+		t.Errorf("expected bucket to be deleted, found %v", buckets)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+var (
+	importInventoryFile string
+	importState         string
+	importOutput        string
+)
+
+// importCmd represents the import command.
+var importCmd = &cobra.Command{
+	Use:   "import <resource stack> <config file>...",
+	Short: "Rebuild the inventory for an existing AWS resource stack",
+	Long: fmt.Sprintf(`Rebuild the inventory for an existing AWS resource stack, e.g. when the
+inventory file was lost.  Resources are searched for by the tags and names the
+create command would give them with the same config files, and each resource
+is reported as one of:
+* found - exactly one matching resource was found and added to the inventory
+* not-found - no matching resource was found
+* ambiguous - more than one matching resource was found
+Ambiguous resources are left out of the inventory and the command fails so
+they can be resolved manually by adding the correct ID to the inventory.  An
+existing inventory is kept in the state history and can be restored with
+"aws-builder inventory restore".  No resources are created or changed.
+%s
+%s`, configFilesHelp, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack argument provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+		title := strings.ToUpper(resourceStack.Name())
+
+		if err := validateOutput(importOutput); err != nil {
+			return err
+		}
+
+		// use state location if provided, otherwise create default inventory
+		// filename if not provided
+		stateLocation := importState
+		if stateLocation != "" && importInventoryFile != "" {
+			return errors.New("only one of --state and --inventory-file may be provided")
+		}
+		if stateLocation == "" {
+			stateLocation = importInventoryFile
+		}
+		if stateLocation == "" {
+			stateLocation = fmt.Sprintf("%s-inventory.json", args[0])
+		}
+
+		// load AWS config
+		awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, "", awsSerialNumber)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+
+		// get backend to store inventory in
+		backend, err := state.New(stateLocation, awsConfig)
+		if err != nil {
+			return err
+		}
+
+		// create resource client - importing does not send messages
+		resourceClient := newResourceClient(cmd.Context(), awsConfig)
+
+		importer, ok := resourceStack.New(resourceClient).(stack.Importer)
+		if !ok {
+			return fmt.Errorf("importing %s resource stacks is not supported", resourceStack.Name())
+		}
+
+		// load config
+		resourceConfig, err := resourceStack.LoadConfig(nil, args[1:]...)
+		if err != nil {
+			return fmt.Errorf("failed to load %s config file: %w", title, err)
+		}
+
+		cmd.SilenceUsage = true
+
+		resourceInventory := resourceStack.NewInventory()
+		report, err := importer.Import(resourceConfig, resourceInventory)
+		if err != nil {
+			return fmt.Errorf("failed to import %s resource stack: %w", title, err)
+		}
+
+		// lock state and keep any existing inventory in its history
+		ctx := cmd.Context()
+		lockInfo, err := state.Acquire(ctx, backend, "import")
+		if err != nil {
+			return err
+		}
+		defer state.Release(ctx, backend, lockInfo.Id)
+		if err := resourceInventory.WriteState(ctx, backend); err != nil {
+			return err
+		}
+
+		if importOutput == "json" {
+			err = report.WriteJson(os.Stdout)
+		} else {
+			err = report.WriteText(os.Stdout)
+		}
+		if err != nil {
+			return err
+		}
+
+		if report.HasAmbiguity() {
+			return fmt.Errorf(
+				"%d ambiguous resources in %s resource stack need manual resolution in inventory '%s'",
+				report.Count(discovery.StatusAmbiguous),
+				title,
+				backend,
+			)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVarP(
+		&importInventoryFile, "inventory-file", "i", "",
+		"File to write AWS resource inventory to",
+	)
+	importCmd.Flags().StringVarP(
+		&importState, "state", "", "",
+		"Location to store AWS resource inventory in, e.g. s3://bucket/key or a file path",
+	)
+	importCmd.Flags().StringVarP(
+		&importOutput, "output", "o", "text",
+		"Output format for the import report: text or json",
+	)
+}
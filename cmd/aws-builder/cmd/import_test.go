@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/eks"
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+func TestImport(t *testing.T) {
+	configFile := "../../../sample/eks-config.yaml"
+	resourceConfig, err := eks.LoadEksConfig(configFile)
+	if err != nil {
+		t.Fatalf("failed to load sample config: %v", err)
+	}
+	backend := useFakeBackend(t, resourceConfig.Region)
+	eksClient := eks.EksClient{
+		ResourceClient:     *backend.ResourceClient(),
+		OidcThumbprintFunc: fake.OidcThumbprint,
+	}
+	var created eks.EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &created); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	inventoryFile := filepath.Join(t.TempDir(), "eks-inventory.json")
+
+	output, err := execute(t, "import", "eks", configFile, "-i", inventoryFile)
+	if err != nil {
+		t.Fatalf("failed to run import: %v", err)
+	}
+	if !strings.Contains(output, "0 not found, 0 ambiguous") {
+		t.Errorf("expected every resource to be found, got:\n%s", output)
+	}
+	var imported eks.EksInventory
+	if err := imported.Load(inventoryFile); err != nil {
+		t.Fatalf("failed to load imported inventory: %v", err)
+	}
+	// the schema version is set when the inventory is written
+	created.SchemaVersion = eks.InventorySchemaVersion
+	if !reflect.DeepEqual(imported, created) {
+		t.Errorf("expected imported inventory to equal the created inventory\nexpected: %+v\ngot:      %+v", created, imported)
+	}
+
+	// a second VPC with the same tags is left out of the inventory and the
+	// command fails so it can be resolved manually
+	if _, err := backend.Apis().Ec2.CreateVpc(context.Background(), &aws_ec2.CreateVpcInput{
+		CidrBlock: aws.String(resourceConfig.ClusterCidr),
+		TagSpecifications: []ec2_types.TagSpecification{
+			{
+				ResourceType: ec2_types.ResourceTypeVpc,
+				Tags:         *ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags),
+			},
+		},
+	}); err != nil {
+		t.Fatalf("failed to create VPC: %v", err)
+	}
+	_, err = execute(t, "import", "eks", configFile, "-i", inventoryFile, "-o", "json")
+	if err == nil || !strings.Contains(err.Error(), "1 ambiguous resources") {
+		t.Fatalf("expected import to fail with an ambiguous VPC, got %v", err)
+	}
+	imported = eks.EksInventory{}
+	if err := imported.Load(inventoryFile); err != nil {
+		t.Fatalf("failed to load imported inventory: %v", err)
+	}
+	if imported.VpcId != "" || imported.Cluster.ClusterName != created.Cluster.ClusterName {
+		t.Errorf("expected only the ambiguous VPC to be left out of inventory, got %+v", imported)
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/inventory"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+var inventoryHistoryOutput string
+
+// inventoryCmd represents the inventory command.
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Manage AWS resource stack inventory",
+	Long: `Manage AWS resource stack inventory.  Each time create or delete runs, the
+existing inventory is added to the inventory's history before it is changed.
+The most recent snapshots are kept and can be restored to recover from a bad
+run.`,
+}
+
+// inventoryHistoryCmd represents the inventory history command.
+var inventoryHistoryCmd = &cobra.Command{
+	Use:   "history <state location>",
+	Short: "List previous versions of an inventory",
+	Long: `List previous versions of an inventory, newest first.  The state location is
+an inventory file path or s3://bucket/key.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure state location argument provided
+		if len(args) < 1 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		if err := validateOutput(inventoryHistoryOutput); err != nil {
+			return err
+		}
+
+		backend, err := inventoryBackend(args[0])
+		if err != nil {
+			return err
+		}
+		snapshots, err := backend.History(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get inventory history: %w", err)
+		}
+
+		if inventoryHistoryOutput == "json" {
+			if snapshots == nil {
+				snapshots = []state.Snapshot{}
+			}
+			historyJson, err := json.MarshalIndent(snapshots, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal inventory history to JSON: %w", err)
+			}
+			fmt.Println(string(historyJson))
+			return nil
+		}
+
+		if len(snapshots) == 0 {
+			fmt.Printf("No history for inventory '%s'\n", backend)
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tTIME\tSIZE")
+		for _, snapshot := range snapshots {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", snapshot.Id, snapshot.Time.Local().Format(time.RFC3339), snapshot.Size)
+		}
+
+		return tw.Flush()
+	},
+}
+
+// inventoryRestoreCmd represents the inventory restore command.
+var inventoryRestoreCmd = &cobra.Command{
+	Use:   "restore <state location> <snapshot id>",
+	Short: "Restore a previous version of an inventory",
+	Long: `Restore a previous version of an inventory from its history.  The current
+inventory is added to the history first so the restore can be undone.  The
+state is locked while the inventory is restored.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure state location and snapshot ID arguments provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		backend, err := inventoryBackend(args[0])
+		if err != nil {
+			return err
+		}
+
+		lockInfo := state.NewLockInfo("restore")
+		if err := backend.Lock(cmd.Context(), lockInfo); err != nil {
+			return fmt.Errorf("failed to lock state: %w", err)
+		}
+		restoreErr := state.Restore(cmd.Context(), backend, args[1])
+		unlockErr := backend.Unlock(context.WithoutCancel(cmd.Context()), lockInfo.Id)
+		if restoreErr != nil {
+			return fmt.Errorf("failed to restore inventory: %w", restoreErr)
+		}
+		if unlockErr != nil {
+			return fmt.Errorf("failed to unlock state: %w", unlockErr)
+		}
+		fmt.Printf("Inventory '%s' restored from %s\n", backend, args[1])
+
+		return nil
+	},
+}
+
+// inventoryMigrateCmd represents the inventory migrate command.
+var inventoryMigrateCmd = &cobra.Command{
+	Use:   "migrate <resource stack> <state location>...",
+	Short: "Upgrade inventories to the current schema version",
+	Long: fmt.Sprintf(`Upgrade inventories written by older versions of aws-builder to the current
+schema version and write them in place.  Inventories are also upgraded when
+they are loaded by other commands but are only written in the new version
+when they change.  The state is locked while each inventory is migrated and
+the original inventory is kept in its history.  Inventories with fields the
+resource stack's inventories don't have are rejected since they are likely for
+another resource stack.
+%s`, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack and state location arguments provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		for _, location := range args[1:] {
+			backend, err := inventoryBackend(location)
+			if err != nil {
+				return err
+			}
+			if err := migrateInventory(cmd.Context(), resourceStack, backend); err != nil {
+				return fmt.Errorf("failed to migrate inventory '%s': %w", backend, err)
+			}
+		}
+
+		return nil
+	},
+}
+
+// migrateInventory upgrades the inventory for a resource stack in a state
+// backend to the current schema version.
+func migrateInventory(ctx context.Context, resourceStack stack.Stack, backend state.Backend) error {
+	currentVersion := resourceStack.InventorySchemaVersion()
+
+	// lock state before reading the version so it can't change until the
+	// migrated inventory is written
+	lockInfo := state.NewLockInfo("migrate")
+	if err := backend.Lock(ctx, lockInfo); err != nil {
+		return fmt.Errorf("failed to lock state: %w", err)
+	}
+	defer state.Release(ctx, backend, lockInfo.Id)
+
+	inventoryBytes, err := backend.Read(ctx)
+	if err != nil {
+		return err
+	}
+	version, err := inventory.Version(inventoryBytes)
+	if err != nil {
+		return err
+	}
+	if version == currentVersion {
+		fmt.Printf("Inventory '%s' is already at schema version %d\n", backend, version)
+		return nil
+	}
+
+	// migrate the inventory as JSON rather than loading it into an inventory
+	// type so it is written back unchanged apart from the migrations.  An
+	// inventory with a newer schema version fails before it is snapshotted.
+	migratedBytes, err := inventory.Migrate(resourceStack.Name(), inventoryBytes, currentVersion)
+	if err != nil {
+		return err
+	}
+	if err := inventory.CheckFields(resourceStack.Name(), migratedBytes, resourceStack.NewInventory()); err != nil {
+		return err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, migratedBytes, "", "  "); err != nil {
+		return fmt.Errorf("failed to format migrated inventory: %w", err)
+	}
+
+	// keep the original inventory in its history
+	if err := backend.Snapshot(ctx); err != nil {
+		return fmt.Errorf("failed to add inventory to state history: %w", err)
+	}
+	if err := backend.Write(ctx, indented.Bytes()); err != nil {
+		return fmt.Errorf("failed to write migrated inventory: %w", err)
+	}
+	fmt.Printf("Inventory '%s' migrated from schema version %d to %d\n", backend, version, currentVersion)
+
+	return nil
+}
+
+// inventoryBackend returns the state backend for a state location.  AWS
+// config is only loaded for locations in S3.
+func inventoryBackend(location string) (state.Backend, error) {
+	if !strings.HasPrefix(location, state.S3Prefix) {
+		return state.New(location, nil)
+	}
+
+	// load AWS config
+	awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, "", awsSerialNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return state.New(location, awsConfig)
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+	inventoryCmd.AddCommand(inventoryHistoryCmd)
+	inventoryCmd.AddCommand(inventoryRestoreCmd)
+	inventoryCmd.AddCommand(inventoryMigrateCmd)
+	inventoryHistoryCmd.Flags().StringVarP(
+		&inventoryHistoryOutput, "output", "o", "text",
+		"Output format: text or json",
+	)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/eks"
+	"github.com/nukleros/aws-builder/pkg/eks/connection"
+)
+
+var (
+	kubeconfigFile              string
+	kubeconfigContext           string
+	kubeconfigStsEndpoint       string
+	kubeconfigEmbedToken        bool
+	kubeconfigSetCurrentContext bool
+)
+
+// kubeconfigCmd represents the kubeconfig command.
+var kubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig <state location>",
+	Short: "Write a kubeconfig context for an EKS cluster",
+	Long: `Write a kubeconfig context for the EKS cluster in an EKS inventory so kubectl
+can connect to it.  The state location is an inventory file path or
+s3://bucket/key.  The cluster, user and context are merged into the kubeconfig
+file, replacing any with the same name.  Use --kubeconfig - to print the
+kubeconfig instead.
+
+By default the kubeconfig runs "aws-builder token" to get a token each time
+one is needed, using the same AWS config profile, region, role and MFA serial
+number flags as this command.  Use --embed-token to embed a token instead so
+aws-builder isn't needed to use the kubeconfig.  Embedded tokens expire after
+about 15 minutes.  Use --sts-endpoint to presign tokens for an STS endpoint
+other than the regional endpoint, such as a VPC endpoint.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure state location argument provided
+		if len(args) < 1 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		backend, err := inventoryBackend(args[0])
+		if err != nil {
+			return err
+		}
+		var eksInventory eks.EksInventory
+		if err := eksInventory.LoadState(cmd.Context(), backend); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		clusterName := eksInventory.Cluster.ClusterName
+		if clusterName == "" {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("no EKS cluster in inventory %s", args[0])
+		}
+
+		// use the cluster's region unless one is provided
+		region := awsRegion
+		if region == "" {
+			region = eksInventory.Region
+		}
+		awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, region, awsRoleArn, awsExternalId, awsSerialNumber)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+
+		cmd.SilenceUsage = true
+		connectionInfo := connection.EksClusterConnectionInfo{
+			ClusterName: clusterName,
+			StsEndpoint: kubeconfigStsEndpoint,
+		}
+		if err := connectionInfo.Get(awsConfig); err != nil {
+			return fmt.Errorf("failed to get connection info for EKS cluster %s: %w", clusterName, err)
+		}
+
+		contextName := kubeconfigContext
+		if contextName == "" {
+			contextName = clusterName
+		}
+		var exec *clientcmdapi.ExecConfig
+		if !kubeconfigEmbedToken {
+			exec, err = tokenExecConfig(clusterName, region)
+			if err != nil {
+				return err
+			}
+		}
+		kubeconfig := connectionInfo.Kubeconfig(contextName, exec)
+
+		if kubeconfigFile == "-" {
+			kubeconfigYaml, err := clientcmd.Write(*kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to marshal kubeconfig: %w", err)
+			}
+			fmt.Print(string(kubeconfigYaml))
+			return nil
+		}
+		if err := connection.MergeKubeconfig(kubeconfigFile, kubeconfig, kubeconfigSetCurrentContext); err != nil {
+			return err
+		}
+		fmt.Printf("Context '%s' for EKS cluster %s written to %s\n", contextName, clusterName, kubeconfigFile)
+
+		return nil
+	},
+}
+
+// tokenExecConfig returns an exec credential plugin config that runs this
+// executable's token command with the AWS flags this command was run with.
+func tokenExecConfig(clusterName, region string) (*clientcmdapi.ExecConfig, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find aws-builder executable: %w", err)
+	}
+
+	args := []string{
+		"token",
+		"--cluster-name", clusterName,
+		"--aws-config-profile", awsConfigProfile,
+		"--aws-region", region,
+	}
+	if awsRoleArn != "" {
+		args = append(args, "--aws-role-arn", awsRoleArn)
+	}
+	if awsExternalId != "" {
+		args = append(args, "--aws-external-id", awsExternalId)
+	}
+	if awsSerialNumber != "" {
+		args = append(args, "--aws-serial-number", awsSerialNumber)
+	}
+	if kubeconfigStsEndpoint != "" {
+		args = append(args, "--sts-endpoint", kubeconfigStsEndpoint)
+	}
+
+	return connection.TokenExecConfig(executable, args...), nil
+}
+
+// defaultKubeconfigFile returns the first file in KUBECONFIG if it is set or
+// ~/.kube/config.
+func defaultKubeconfigFile() string {
+	for _, file := range filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar)) {
+		if file != "" {
+			return file
+		}
+	}
+
+	return clientcmd.RecommendedHomeFile
+}
+
+func init() {
+	rootCmd.AddCommand(kubeconfigCmd)
+	kubeconfigCmd.Flags().StringVar(
+		&kubeconfigFile, "kubeconfig", defaultKubeconfigFile(),
+		"The kubeconfig file to write to or - to print the kubeconfig",
+	)
+	kubeconfigCmd.Flags().StringVar(
+		&kubeconfigContext, "context", "",
+		"The name of the kubeconfig cluster, user and context (default the EKS cluster name)",
+	)
+	kubeconfigCmd.Flags().StringVar(
+		&kubeconfigStsEndpoint, "sts-endpoint", "",
+		"The STS endpoint URL to presign tokens for (default the regional STS endpoint)",
+	)
+	kubeconfigCmd.Flags().BoolVar(
+		&kubeconfigEmbedToken, "embed-token", false,
+		"Embed a short-lived token instead of running aws-builder to get tokens",
+	)
+	kubeconfigCmd.Flags().BoolVar(
+		&kubeconfigSetCurrentContext, "set-current-context", true,
+		"Make the context the current context",
+	)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+)
+
+// validateOutput returns an error if the output format is not supported.
+func validateOutput(output string) error {
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unsupported output format %s, must be one of: text, json", output)
+	}
+
+	return nil
+}
+
+// startProgressOutput starts a goroutine that prints progress as resources are
+// created or deleted.  For text output each message is printed on its own
+// line.  For json output each event is printed as a line of JSON.  The
+// returned function must be called once all resource operations are complete
+// to stop the goroutine.
+func startProgressOutput(
+	resourceClient *client.ResourceClient,
+	output string,
+	wait *sync.WaitGroup,
+) func() {
+	wait.Add(1)
+
+	if output == "json" {
+		eventChan := make(chan client.Event)
+		resourceClient.EventChan = &eventChan
+		go func() {
+			defer wait.Done()
+			encoder := json.NewEncoder(os.Stdout)
+			for event := range eventChan {
+				if err := encoder.Encode(event); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to write event: %s\n", err)
+				}
+			}
+		}()
+
+		return func() { close(eventChan) }
+	}
+
+	messageChan := make(chan string)
+	resourceClient.MessageChan = &messageChan
+	go func() {
+		defer wait.Done()
+		for msg := range messageChan {
+			fmt.Println(msg)
+		}
+	}()
+
+	return func() { close(messageChan) }
+}
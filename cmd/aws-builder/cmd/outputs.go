@@ -0,0 +1,227 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+var (
+	outputsFormat    string
+	outputsPrefix    string
+	outputsName      string
+	outputsNamespace string
+)
+
+// outputsFormats are the formats outputs can be printed in.
+var outputsFormats = []string{"export", "dotenv", "json", "configmap"}
+
+// dotenvBarePattern matches dotenv values that don't need to be quoted.
+var dotenvBarePattern = regexp.MustCompile(`^[A-Za-z0-9_./:@,+-]*$`)
+
+// outputsCmd represents the outputs command.
+var outputsCmd = &cobra.Command{
+	Use:   "outputs <resource stack> <state location>",
+	Short: "Print the outputs of an AWS resource stack",
+	Long: fmt.Sprintf(`Print the outputs of an AWS resource stack, such as the cluster name or
+database endpoint, read from its inventory.  The state location is an inventory
+file path or s3://bucket/key.  Every output is printed, with an empty value if
+its resource has not been created, and lists are joined with commas except in
+JSON.  Formats:
+* export - shell export lines, e.g. export EKS_CLUSTER_NAME='my-cluster'
+* dotenv - a dotenv file, e.g. EKS_CLUSTER_NAME=my-cluster
+* json - a JSON object of output names to values
+* configmap - a Kubernetes ConfigMap manifest for use with envFrom
+Variable names and ConfigMap keys are the output names in upper snake case
+with a prefix that defaults to the resource stack name, e.g. EKS_.
+%s
+%s`, outputsHelp(), supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack and state location arguments provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		if !slices.Contains(outputsFormats, outputsFormat) {
+			return fmt.Errorf("unsupported output format %s, must be one of: %s", outputsFormat, strings.Join(outputsFormats, ", "))
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		backend, err := inventoryBackend(args[1])
+		if err != nil {
+			return err
+		}
+		resourceInventory := resourceStack.NewInventory()
+		if err := resourceInventory.LoadState(cmd.Context(), backend); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		prefix := strings.ToUpper(resourceStack.Name()) + "_"
+		if cmd.Flags().Changed("prefix") {
+			prefix = outputsPrefix
+		}
+		name := outputsName
+		if name == "" {
+			name = fmt.Sprintf("%s-outputs", resourceStack.Name())
+		}
+
+		return writeOutputs(
+			os.Stdout,
+			outputsFormat,
+			resourceStack.Outputs(),
+			resourceInventory.Outputs(),
+			prefix,
+			name,
+			outputsNamespace,
+		)
+	},
+}
+
+// writeOutputs writes the outputs in the format.  The name and namespace are
+// only used for ConfigMap manifests.
+func writeOutputs(
+	w io.Writer,
+	format string,
+	outputs []stack.Output,
+	values map[string]any,
+	prefix string,
+	name string,
+	namespace string,
+) error {
+	switch format {
+	case "export":
+		for _, output := range outputs {
+			value := outputString(values[output.Name])
+			fmt.Fprintf(w, "export %s='%s'\n", envName(prefix, output.Name), strings.ReplaceAll(value, "'", `'\''`))
+		}
+	case "dotenv":
+		for _, output := range outputs {
+			value := outputString(values[output.Name])
+			if !dotenvBarePattern.MatchString(value) {
+				value = fmt.Sprintf("%q", value)
+			}
+			fmt.Fprintf(w, "%s=%s\n", envName(prefix, output.Name), value)
+		}
+	case "json":
+		outputsJson, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal outputs to JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(outputsJson))
+	case "configmap":
+		data := map[string]string{}
+		for _, output := range outputs {
+			data[envName(prefix, output.Name)] = outputString(values[output.Name])
+		}
+		metadata := map[string]string{"name": name}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		configMap := struct {
+			ApiVersion string            `yaml:"apiVersion"`
+			Kind       string            `yaml:"kind"`
+			Metadata   map[string]string `yaml:"metadata"`
+			Data       map[string]string `yaml:"data"`
+		}{"v1", "ConfigMap", metadata, data}
+
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(configMap); err != nil {
+			return fmt.Errorf("failed to marshal outputs to YAML: %w", err)
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported output format %s, must be one of: %s", format, strings.Join(outputsFormats, ", "))
+	}
+
+	return nil
+}
+
+// outputString returns an output value as a string with lists joined by
+// commas.
+func outputString(value any) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
+
+// envName returns the environment variable name for an output, e.g.
+// EKS_CLUSTER_NAME for clusterName with the prefix EKS_.
+func envName(prefix, outputName string) string {
+	var name strings.Builder
+	name.WriteString(prefix)
+	for i, r := range outputName {
+		if i > 0 && unicode.IsUpper(r) {
+			name.WriteRune('_')
+		}
+		name.WriteRune(unicode.ToUpper(r))
+	}
+
+	return name.String()
+}
+
+// outputsHelp returns the help text listing the outputs of each registered
+// resource stack.
+func outputsHelp() string {
+	var help strings.Builder
+	help.WriteString("\nOutputs by resource stack:\n")
+	tw := tabwriter.NewWriter(&help, 0, 0, 2, ' ', 0)
+	for _, resourceStack := range stack.All() {
+		fmt.Fprintf(tw, "%s:\n", resourceStack.Name())
+		for _, output := range resourceStack.Outputs() {
+			description := output.Description
+			if output.List {
+				description += "  A list."
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", output.Name, description)
+		}
+	}
+	tw.Flush()
+
+	return strings.TrimSuffix(help.String(), "\n")
+}
+
+func init() {
+	rootCmd.AddCommand(outputsCmd)
+	outputsCmd.Flags().StringVarP(
+		&outputsFormat, "output", "o", "export",
+		"Output format: export, dotenv, json or configmap",
+	)
+	outputsCmd.Flags().StringVar(
+		&outputsPrefix, "prefix", "",
+		"Prefix for variable names and ConfigMap keys (default the resource stack name, e.g. EKS_)",
+	)
+	outputsCmd.Flags().StringVar(
+		&outputsName, "name", "",
+		"Name of the ConfigMap (default <resource stack>-outputs)",
+	)
+	outputsCmd.Flags().StringVar(
+		&outputsNamespace, "namespace", "",
+		"Namespace of the ConfigMap",
+	)
+}
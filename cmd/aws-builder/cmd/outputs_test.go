@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nukleros/aws-builder/pkg/eks"
+	"github.com/nukleros/aws-builder/pkg/rds"
+	"github.com/nukleros/aws-builder/pkg/s3"
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+// namedOutput is an output's name, variable name and value as printed in
+// every format but JSON.
+type namedOutput struct {
+	name     string
+	variable string
+	value    string
+}
+
+func TestOutputs(t *testing.T) {
+	testCases := []struct {
+		stack     string
+		inventory interface{ Write(string) error }
+		outputs   []namedOutput
+	}{
+		{
+			stack: "eks",
+			inventory: &eks.EksInventory{
+				Region: "us-east-2",
+				AvailabilityZones: []eks.AvailabilityZoneInventory{
+					{
+						Zone:           "us-east-2a",
+						PublicSubnets:  []eks.SubnetInventory{{SubnetId: "subnet-1"}},
+						PrivateSubnets: []eks.SubnetInventory{{SubnetId: "subnet-2"}},
+					},
+					{
+						Zone:           "us-east-2b",
+						PublicSubnets:  []eks.SubnetInventory{{SubnetId: "subnet-3"}},
+						PrivateSubnets: []eks.SubnetInventory{{SubnetId: "subnet-4"}},
+					},
+				},
+				VpcId:                 "vpc-1",
+				SecurityGroupId:       "sg-1",
+				Cluster:               eks.ClusterInventory{ClusterName: "test-0", ClusterArn: "arn:aws:eks:us-east-2:123456789012:cluster/test-0", OidcProviderUrl: "https://oidc.eks.us-east-2.amazonaws.com/id/1"},
+				OidcProviderArn:       "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/1",
+				ClusterRole:           eks.RoleInventory{RoleArn: "arn:aws:iam::123456789012:role/cluster-role-test-0"},
+				WorkerRole:            eks.RoleInventory{RoleArn: "arn:aws:iam::123456789012:role/worker-role-test-0"},
+				StorageManagementRole: eks.RoleInventory{RoleArn: "arn:aws:iam::123456789012:role/csi-role-test-0"},
+			},
+			outputs: []namedOutput{
+				{"region", "EKS_REGION", "us-east-2"},
+				{"vpcId", "EKS_VPC_ID", "vpc-1"},
+				{"publicSubnetIds", "EKS_PUBLIC_SUBNET_IDS", "subnet-1,subnet-3"},
+				{"privateSubnetIds", "EKS_PRIVATE_SUBNET_IDS", "subnet-2,subnet-4"},
+				{"securityGroupId", "EKS_SECURITY_GROUP_ID", "sg-1"},
+				{"clusterName", "EKS_CLUSTER_NAME", "test-0"},
+				{"clusterArn", "EKS_CLUSTER_ARN", "arn:aws:eks:us-east-2:123456789012:cluster/test-0"},
+				{"oidcProviderUrl", "EKS_OIDC_PROVIDER_URL", "https://oidc.eks.us-east-2.amazonaws.com/id/1"},
+				{"oidcProviderArn", "EKS_OIDC_PROVIDER_ARN", "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/1"},
+				{"clusterRoleArn", "EKS_CLUSTER_ROLE_ARN", "arn:aws:iam::123456789012:role/cluster-role-test-0"},
+				{"workerRoleArn", "EKS_WORKER_ROLE_ARN", "arn:aws:iam::123456789012:role/worker-role-test-0"},
+				{"dnsManagementRoleArn", "EKS_DNS_MANAGEMENT_ROLE_ARN", ""},
+				{"dns01ChallengeRoleArn", "EKS_DNS01_CHALLENGE_ROLE_ARN", ""},
+				{"secretsManagerRoleArn", "EKS_SECRETS_MANAGER_ROLE_ARN", ""},
+				{"clusterAutoscalingRoleArn", "EKS_CLUSTER_AUTOSCALING_ROLE_ARN", ""},
+				{"storageManagementRoleArn", "EKS_STORAGE_MANAGEMENT_ROLE_ARN", "arn:aws:iam::123456789012:role/csi-role-test-0"},
+			},
+		},
+		{
+			stack: "rds",
+			inventory: &rds.RdsInventory{
+				Region:              "us-east-1",
+				RdsInstanceId:       "wordpress-db-0",
+				RdsInstanceEndpoint: "wordpress-db-0.abc.us-east-1.rds.amazonaws.com",
+				SubnetGroupName:     "wordpress-db-0-subnet-group",
+				SecurityGroupId:     "sg-2",
+			},
+			outputs: []namedOutput{
+				{"region", "RDS_REGION", "us-east-1"},
+				{"instanceId", "RDS_INSTANCE_ID", "wordpress-db-0"},
+				{"endpoint", "RDS_ENDPOINT", "wordpress-db-0.abc.us-east-1.rds.amazonaws.com"},
+				{"subnetGroupName", "RDS_SUBNET_GROUP_NAME", "wordpress-db-0-subnet-group"},
+				{"securityGroupId", "RDS_SECURITY_GROUP_ID", "sg-2"},
+			},
+		},
+		{
+			stack: "s3",
+			inventory: &s3.S3Inventory{
+				Region:          "us-east-1",
+				BucketName:      "test-0-5d1b1c0e-8f3e-4d8e-9c55-3a0b1e2f4c6d",
+				AccessPointName: "test-0",
+				PolicyArn:       "arn:aws:iam::123456789012:policy/aws-client-abc",
+				Role:            s3.RoleInventory{RoleName: "aws-client-abc", RoleArn: "arn:aws:iam::123456789012:role/aws-client-abc"},
+			},
+			outputs: []namedOutput{
+				{"region", "S3_REGION", "us-east-1"},
+				{"bucketName", "S3_BUCKET_NAME", "test-0-5d1b1c0e-8f3e-4d8e-9c55-3a0b1e2f4c6d"},
+				{"accessPointName", "S3_ACCESS_POINT_NAME", "test-0"},
+				{"policyArn", "S3_POLICY_ARN", "arn:aws:iam::123456789012:policy/aws-client-abc"},
+				{"roleName", "S3_ROLE_NAME", "aws-client-abc"},
+				{"roleArn", "S3_ROLE_ARN", "arn:aws:iam::123456789012:role/aws-client-abc"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.stack, func(t *testing.T) {
+			inventoryFile := filepath.Join(t.TempDir(), testCase.stack+"-inventory.json")
+			if err := testCase.inventory.Write(inventoryFile); err != nil {
+				t.Fatalf("failed to write inventory: %v", err)
+			}
+
+			var export, dotenv strings.Builder
+			data := map[string]string{}
+			for _, output := range testCase.outputs {
+				fmt.Fprintf(&export, "export %s='%s'\n", output.variable, output.value)
+				fmt.Fprintf(&dotenv, "%s=%s\n", output.variable, output.value)
+				data[output.variable] = output.value
+			}
+			for format, expected := range map[string]string{"export": export.String(), "dotenv": dotenv.String()} {
+				output, err := execute(t, "outputs", testCase.stack, inventoryFile, "-o", format)
+				if err != nil {
+					t.Fatalf("failed to run outputs: %v", err)
+				}
+				if output != expected {
+					t.Errorf("expected %s outputs\n%s\ngot\n%s", format, expected, output)
+				}
+			}
+
+			// lists are only kept as lists in JSON
+			output, err := execute(t, "outputs", testCase.stack, inventoryFile, "-o", "json")
+			if err != nil {
+				t.Fatalf("failed to run outputs: %v", err)
+			}
+			var values map[string]any
+			if err := json.Unmarshal([]byte(output), &values); err != nil {
+				t.Fatalf("failed to unmarshal outputs: %v\n%s", err, output)
+			}
+			if len(values) != len(testCase.outputs) {
+				t.Errorf("expected %d outputs, got %v", len(testCase.outputs), values)
+			}
+			for _, expected := range testCase.outputs {
+				value := values[expected.name]
+				if list, ok := value.([]any); ok {
+					var items []string
+					for _, item := range list {
+						items = append(items, item.(string))
+					}
+					value = strings.Join(items, ",")
+				}
+				if value != expected.value {
+					t.Errorf("expected JSON output %s to be %q, got %v", expected.name, expected.value, values[expected.name])
+				}
+			}
+
+			output, err = execute(t, "outputs", testCase.stack, inventoryFile, "-o", "configmap", "--namespace", "apps")
+			if err != nil {
+				t.Fatalf("failed to run outputs: %v", err)
+			}
+			var configMap struct {
+				ApiVersion string            `yaml:"apiVersion"`
+				Kind       string            `yaml:"kind"`
+				Metadata   map[string]string `yaml:"metadata"`
+				Data       map[string]string `yaml:"data"`
+			}
+			if err := yaml.Unmarshal([]byte(output), &configMap); err != nil {
+				t.Fatalf("failed to unmarshal ConfigMap: %v\n%s", err, output)
+			}
+			metadata := map[string]string{"name": testCase.stack + "-outputs", "namespace": "apps"}
+			if configMap.ApiVersion != "v1" || configMap.Kind != "ConfigMap" || !reflect.DeepEqual(configMap.Metadata, metadata) {
+				t.Errorf("expected ConfigMap %v, got:\n%s", metadata, output)
+			}
+			if !reflect.DeepEqual(configMap.Data, data) {
+				t.Errorf("expected ConfigMap data %v, got %v", data, configMap.Data)
+			}
+		})
+	}
+}
+
+func TestWriteOutputsQuoting(t *testing.T) {
+	outputs := []stack.Output{
+		{Name: "plain"},
+		{Name: "quote"},
+		{Name: "space"},
+		{Name: "shell"},
+		{Name: "list", List: true},
+	}
+	values := map[string]any{
+		"plain": "arn:aws:iam::123456789012:role/test-0",
+		"quote": `it's "quoted"`,
+		"space": "two words",
+		"shell": "$HOME `id`",
+		"list":  []string{"a b", "c"},
+	}
+
+	for _, testCase := range []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "export",
+			expected: `export TEST_PLAIN='arn:aws:iam::123456789012:role/test-0'
+export TEST_QUOTE='it'\''s "quoted"'
+export TEST_SPACE='two words'
+export TEST_SHELL='$HOME ` + "`id`" + `'
+export TEST_LIST='a b,c'
+`,
+		},
+		{
+			format: "dotenv",
+			expected: `TEST_PLAIN=arn:aws:iam::123456789012:role/test-0
+TEST_QUOTE="it's \"quoted\""
+TEST_SPACE="two words"
+TEST_SHELL="$HOME ` + "`id`" + `"
+TEST_LIST="a b,c"
+`,
+		},
+		{
+			format: "configmap",
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-outputs
+data:
+  TEST_LIST: a b,c
+  TEST_PLAIN: arn:aws:iam::123456789012:role/test-0
+  TEST_QUOTE: it's "quoted"
+  TEST_SHELL: $HOME ` + "`id`" + `
+  TEST_SPACE: two words
+`,
+		},
+	} {
+		t.Run(testCase.format, func(t *testing.T) {
+			var output bytes.Buffer
+			if err := writeOutputs(&output, testCase.format, outputs, values, "TEST_", "test-outputs", ""); err != nil {
+				t.Fatalf("failed to write outputs: %v", err)
+			}
+			if output.String() != testCase.expected {
+				t.Errorf("expected\n%s\ngot\n%s", testCase.expected, output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+var (
+	planInputInventoryFile string
+	planOutput             string
+)
+
+// planCmd represents the plan command.
+var planCmd = &cobra.Command{
+	Use:   "plan <resource stack> <config file>...",
+	Short: "Show the changes creating an AWS resource stack would make",
+	Long: fmt.Sprintf(`Show the changes creating an AWS resource stack would make.  Each resource
+is reported as one of:
+* create - the resource does not exist and would be created
+* reuse - the resource was found in the input inventory
+* adopt - an existing resource with matching tags or name was found
+* skip-not-requested - the resource is optional and not requested in config
+No resources are created or changed.
+%s
+%s`, configFilesHelp, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack argument provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+		title := strings.ToUpper(resourceStack.Name())
+
+		if err := validateOutput(planOutput); err != nil {
+			return err
+		}
+
+		// load AWS config
+		awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, "", awsSerialNumber)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+
+		// create resource client - planning does not send messages
+		resourceClient := newResourceClient(cmd.Context(), awsConfig)
+
+		// load config and input inventory if provided
+		resourceConfig, err := resourceStack.LoadConfig(nil, args[1:]...)
+		if err != nil {
+			return fmt.Errorf("failed to load %s config file: %w", title, err)
+		}
+		resourceInventory := resourceStack.NewInventory()
+		if planInputInventoryFile != "" {
+			if err := resourceInventory.Load(planInputInventoryFile); err != nil {
+				return fmt.Errorf("failed to load input inventory: %w", err)
+			}
+		}
+
+		changeSet, err := resourceStack.New(resourceClient).Plan(resourceConfig, resourceInventory)
+		if err != nil {
+			return fmt.Errorf("failed to plan %s resource stack: %w", title, err)
+		}
+
+		if planOutput == "json" {
+			return changeSet.WriteJson(os.Stdout)
+		}
+
+		return changeSet.WriteText(os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.Flags().StringVarP(
+		&planInputInventoryFile, "input-inventory-file", "", "",
+		"File to read existing inventory from; resources in inventory will be reused",
+	)
+	planCmd.Flags().StringVarP(
+		&planOutput, "output", "o", "text",
+		"Output format for the plan: text or json",
+	)
+}
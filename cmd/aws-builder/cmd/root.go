@@ -4,16 +4,22 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/client"
 )
 
-const supportedResourceStacks = `
-Supported resource stacks:
-* rds (Relational Database Service)
-* s3 (Simple Storage Service)`
+const configFilesHelp = `Config files can be YAML or JSON and unknown fields are rejected.  If more
+than one config file is provided, values in later files override values in
+earlier files.  String values can reference environment variables, e.g.
+${DB_PASSWORD}.`
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -24,6 +30,10 @@ needed for particular managed services that serve applications.
 %s`, supportedResourceStacks),
 }
 
+// newResourceClient returns the resource client commands manage resources
+// with.  Tests replace it to call a fake backend instead of AWS.
+var newResourceClient = client.CreateResourceClientWithContext
+
 var (
 	awsConfigProfile string
 	awsRegion        string
@@ -34,13 +44,33 @@ var (
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// SIGINT and SIGTERM cancel the context passed to each command so in-flight
+// operations stop and the latest inventory can be written before exiting.  A
+// second signal terminates immediately.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// interruptedError adds the inventory file the latest inventory was written
+// to when an error was caused by the command being interrupted.
+func interruptedError(err error, inventoryFile string) error {
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("interrupted, latest inventory written to %s: %w", inventoryFile, err)
+	}
+
+	return err
+}
+
 func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	rootCmd.PersistentFlags().StringVarP(&awsConfigProfile, "aws-config-profile", "p", "default",
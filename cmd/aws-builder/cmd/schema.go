@@ -0,0 +1,47 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+// schemaCmd represents the schema command.
+var schemaCmd = &cobra.Command{
+	Use:   "schema <resource stack>",
+	Short: "Print the JSON Schema for an AWS resource stack config",
+	Long: fmt.Sprintf(`Print the JSON Schema for an AWS resource stack config file.  The schema
+includes descriptions, allowed values and defaults and can be used by editors
+and CI to check config files before they are used.
+%s`, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack argument provided
+		if len(args) < 1 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		schemaJson, err := json.MarshalIndent(resourceStack.ConfigSchema(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal schema to JSON: %w", err)
+		}
+		fmt.Println(string(schemaJson))
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/stack"
+
+	// resource stacks register themselves when imported
+	_ "github.com/nukleros/aws-builder/pkg/eks"
+	_ "github.com/nukleros/aws-builder/pkg/rds"
+	_ "github.com/nukleros/aws-builder/pkg/s3"
+)
+
+// supportedResourceStacks lists the registered resource stacks for help text.
+var supportedResourceStacks = supportedResourceStacksHelp()
+
+// supportedResourceStacksHelp returns the help text listing the registered
+// resource stacks.
+func supportedResourceStacksHelp() string {
+	var help strings.Builder
+	help.WriteString("\nSupported resource stacks:")
+	for _, resourceStack := range stack.All() {
+		fmt.Fprintf(&help, "\n* %s (%s)", resourceStack.Name(), resourceStack.Description())
+	}
+
+	return help.String()
+}
+
+// completeResourceStack completes the resource stack argument with the names
+// of the registered resource stacks and later arguments with file names.
+func completeResourceStack(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	var completions []string
+	for _, resourceStack := range stack.All() {
+		completions = append(completions, fmt.Sprintf("%s\t%s", resourceStack.Name(), resourceStack.Description()))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
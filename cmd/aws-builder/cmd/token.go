@@ -0,0 +1,164 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/eks/connection"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// tokenCacheMargin is how long before a cached token expires that a new
+// token is generated so clients don't use a token that expires in flight.
+const tokenCacheMargin = 2 * time.Minute
+
+var (
+	tokenClusterName string
+	tokenStsEndpoint string
+	tokenNoCache     bool
+)
+
+// tokenCmd represents the token command.
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print a token for an EKS cluster as an exec credential",
+	Long: `Print a token for an EKS cluster as a client.authentication.k8s.io/v1
+ExecCredential with the time the token expires.  This command is run by
+kubectl and other Kubernetes clients using a kubeconfig written by
+"aws-builder kubeconfig".
+
+Tokens are cached in the user cache directory, e.g. ~/.cache/aws-builder/tokens,
+until shortly before they expire so repeated kubectl commands are fast and an
+MFA token code is only requested when a new token is needed.  Tokens are cached
+separately for each combination of cluster and AWS flags.  Use --no-cache to
+always generate a new token.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if tokenClusterName == "" {
+			return fmt.Errorf("missing cluster name")
+		}
+		cmd.SilenceUsage = true
+
+		cacheFile := tokenCacheFile()
+		if !tokenNoCache && cacheFile != "" {
+			if execCredentialJson, ok := cachedExecCredential(cacheFile); ok {
+				fmt.Println(string(execCredentialJson))
+				return nil
+			}
+		}
+
+		awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, awsExternalId, awsSerialNumber)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+
+		connectionInfo := connection.EksClusterConnectionInfo{
+			ClusterName: tokenClusterName,
+			StsEndpoint: tokenStsEndpoint,
+		}
+		if err := connectionInfo.GetToken(awsConfig); err != nil {
+			return fmt.Errorf("failed to get token for EKS cluster %s: %w", tokenClusterName, err)
+		}
+		execCredentialJson, err := json.Marshal(connectionInfo.ExecCredential())
+		if err != nil {
+			return fmt.Errorf("failed to marshal exec credential to JSON: %w", err)
+		}
+
+		// a token that can't be cached can still be used
+		if !tokenNoCache && cacheFile != "" {
+			if err := writeTokenCache(cacheFile, execCredentialJson); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to cache token: %s\n", err)
+			}
+		}
+		fmt.Println(string(execCredentialJson))
+
+		return nil
+	},
+}
+
+// tokenCacheFile returns the file tokens for the cluster and AWS flags are
+// cached in, or an empty string if there is no user cache directory.  The
+// file name is a hash of the cluster and flags, and the AWS access key ID in
+// the environment, so tokens for different identities aren't mixed up.
+func tokenCacheFile() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	key, err := json.Marshal([]string{
+		tokenClusterName,
+		tokenStsEndpoint,
+		awsConfigProfile,
+		awsRegion,
+		awsRoleArn,
+		awsExternalId,
+		awsSerialNumber,
+		os.Getenv("AWS_ACCESS_KEY_ID"),
+	})
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(key)
+
+	return filepath.Join(cacheDir, "aws-builder", "tokens", hex.EncodeToString(hash[:])+".json")
+}
+
+// cachedExecCredential returns the exec credential in the cache file if it
+// doesn't expire within tokenCacheMargin.
+func cachedExecCredential(cacheFile string) ([]byte, bool) {
+	execCredentialJson, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+
+	var execCredential clientauthenticationv1.ExecCredential
+	if err := json.Unmarshal(execCredentialJson, &execCredential); err != nil {
+		return nil, false
+	}
+	if execCredential.Status == nil || execCredential.Status.ExpirationTimestamp == nil {
+		return nil, false
+	}
+	if time.Until(execCredential.Status.ExpirationTimestamp.Time) < tokenCacheMargin {
+		return nil, false
+	}
+
+	return execCredentialJson, true
+}
+
+// writeTokenCache writes an exec credential to the cache file so only the
+// user can read it.
+func writeTokenCache(cacheFile string, execCredentialJson []byte) error {
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o700); err != nil {
+		return fmt.Errorf("failed to create token cache directory: %w", err)
+	}
+
+	return util.WriteFileAtomic(cacheFile, execCredentialJson, 0o600)
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.Flags().StringVar(
+		&tokenClusterName, "cluster-name", "",
+		"The name of the EKS cluster to get a token for",
+	)
+	tokenCmd.Flags().StringVar(
+		&tokenStsEndpoint, "sts-endpoint", "",
+		"The STS endpoint URL to presign tokens for (default the regional STS endpoint)",
+	)
+	tokenCmd.Flags().BoolVar(
+		&tokenNoCache, "no-cache", false,
+		"Generate a new token instead of using a cached token",
+	)
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+
+	"github.com/nukleros/aws-builder/pkg/eks/connection"
+)
+
+// useTokenCache makes the token command cache tokens in a new directory and
+// presign tokens with static credentials for the rest of the test.  It
+// returns the cache directory.
+func useTokenCache(t *testing.T) string {
+	t.Helper()
+
+	useFakeBackend(t, "us-east-2")
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+
+	// presigning only needs credentials, not calls to AWS
+	credentialsFile := filepath.Join(cacheDir, "credentials")
+	credentials := "[default]\naws_access_key_id = AKIDTEST\naws_secret_access_key = secret\n"
+	if err := os.WriteFile(credentialsFile, []byte(credentials), 0600); err != nil {
+		t.Fatalf("failed to write AWS credentials file: %v", err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+
+	return cacheDir
+}
+
+// testExecCredential returns an exec credential JSON with the token that
+// expires at the time.
+func testExecCredential(t *testing.T, token string, expiration time.Time) []byte {
+	t.Helper()
+
+	expirationTime := metav1.NewTime(expiration)
+	execCredentialJson, err := json.Marshal(clientauthenticationv1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{APIVersion: connection.ExecCredentialApiVersion, Kind: "ExecCredential"},
+		Status: &clientauthenticationv1.ExecCredentialStatus{
+			Token:               token,
+			ExpirationTimestamp: &expirationTime,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal exec credential: %v", err)
+	}
+
+	return execCredentialJson
+}
+
+// execCredentialToken returns the token in the exec credential JSON.
+func execCredentialToken(t *testing.T, execCredentialJson string) string {
+	t.Helper()
+
+	var execCredential clientauthenticationv1.ExecCredential
+	if err := json.Unmarshal([]byte(execCredentialJson), &execCredential); err != nil {
+		t.Fatalf("failed to unmarshal exec credential: %v\n%s", err, execCredentialJson)
+	}
+	if execCredential.Status == nil {
+		t.Fatalf("expected exec credential status, got %s", execCredentialJson)
+	}
+
+	return execCredential.Status.Token
+}
+
+func TestTokenCacheFile(t *testing.T) {
+	cacheDir := useTokenCache(t)
+	t.Cleanup(func() {
+		tokenClusterName, awsRegion = "", ""
+	})
+
+	tokenClusterName = "test-0"
+	cacheFile := tokenCacheFile()
+	if filepath.Dir(cacheFile) != filepath.Join(cacheDir, "aws-builder", "tokens") {
+		t.Errorf("expected cache file in the user cache directory, got %s", cacheFile)
+	}
+	if again := tokenCacheFile(); again != cacheFile {
+		t.Errorf("expected the same cache file for the same cluster and flags, got %s and %s", cacheFile, again)
+	}
+
+	// tokens for other clusters, flags or identities are cached separately
+	for name, update := range map[string]func(){
+		"cluster":    func() { tokenClusterName = "test-1" },
+		"region":     func() { awsRegion = "us-west-2" },
+		"access key": func() { t.Setenv("AWS_ACCESS_KEY_ID", "AKIDOTHER") },
+	} {
+		tokenClusterName, awsRegion = "test-0", ""
+		t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+		update()
+		if other := tokenCacheFile(); other == cacheFile {
+			t.Errorf("expected a different cache file for another %s, got %s", name, other)
+		}
+	}
+}
+
+func TestCachedExecCredential(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content []byte
+		cached  bool
+	}{
+		{
+			name:    "expires after margin",
+			content: testExecCredential(t, "test-token", time.Now().Add(tokenCacheMargin+time.Minute)),
+			cached:  true,
+		},
+		{
+			name:    "expires within margin",
+			content: testExecCredential(t, "test-token", time.Now().Add(tokenCacheMargin-time.Second)),
+		},
+		{
+			name:    "no expiration",
+			content: []byte(`{"kind": "ExecCredential", "status": {"token": "test-token"}}`),
+		},
+		{
+			name:    "invalid",
+			content: []byte("not json"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cacheFile := filepath.Join(t.TempDir(), "token.json")
+			if err := os.WriteFile(cacheFile, testCase.content, 0600); err != nil {
+				t.Fatalf("failed to write cache file: %v", err)
+			}
+
+			execCredentialJson, cached := cachedExecCredential(cacheFile)
+			if cached != testCase.cached {
+				t.Fatalf("expected cached %t, got %t", testCase.cached, cached)
+			}
+			if cached && string(execCredentialJson) != string(testCase.content) {
+				t.Errorf("expected cached exec credential %s, got %s", testCase.content, execCredentialJson)
+			}
+		})
+	}
+
+	if _, cached := cachedExecCredential(filepath.Join(t.TempDir(), "missing.json")); cached {
+		t.Error("expected no cached exec credential without a cache file")
+	}
+}
+
+func TestToken(t *testing.T) {
+	cacheDir := useTokenCache(t)
+
+	// a new token is cached so only the user can read it
+	output, err := execute(t, "token", "--cluster-name", "test-0")
+	if err != nil {
+		t.Fatalf("failed to run token: %v", err)
+	}
+	token := execCredentialToken(t, output)
+	if !strings.HasPrefix(token, connection.TokenPrefix) {
+		t.Errorf("expected an EKS token, got %s", token)
+	}
+	cacheFiles, err := filepath.Glob(filepath.Join(cacheDir, "aws-builder", "tokens", "*.json"))
+	if err != nil || len(cacheFiles) != 1 {
+		t.Fatalf("expected one cache file, got %v %v", cacheFiles, err)
+	}
+	cacheFile := cacheFiles[0]
+	for file, mode := range map[string]os.FileMode{cacheFile: 0600, filepath.Dir(cacheFile): 0700} {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatalf("failed to stat %s: %v", file, err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("expected %s to have mode %v, got %v", file, mode, info.Mode().Perm())
+		}
+	}
+	cached, err := os.ReadFile(cacheFile)
+	if err != nil {
+		t.Fatalf("failed to read cache file: %v", err)
+	}
+	if strings.TrimSpace(output) != string(cached) {
+		t.Errorf("expected printed exec credential to be cached\nprinted: %s\ncached:  %s", output, cached)
+	}
+
+	// a cached token is printed until it is about to expire
+	if err := os.WriteFile(cacheFile, testExecCredential(t, "cached-token", time.Now().Add(10*time.Minute)), 0600); err != nil {
+		t.Fatalf("failed to write cache file: %v", err)
+	}
+	output, err = execute(t, "token", "--cluster-name", "test-0")
+	if err != nil {
+		t.Fatalf("failed to run token: %v", err)
+	}
+	if token := execCredentialToken(t, output); token != "cached-token" {
+		t.Errorf("expected cached token, got %s", token)
+	}
+
+	// --no-cache neither reads nor writes the cache
+	output, err = execute(t, "token", "--cluster-name", "test-0", "--no-cache")
+	if err != nil {
+		t.Fatalf("failed to run token: %v", err)
+	}
+	if token := execCredentialToken(t, output); token == "cached-token" {
+		t.Error("expected a new token with --no-cache")
+	}
+	if token := execCredentialToken(t, readFile(t, cacheFile)); token != "cached-token" {
+		t.Errorf("expected cache not to be written with --no-cache, got %s", token)
+	}
+
+	// a token about to expire is replaced
+	if err := os.WriteFile(cacheFile, testExecCredential(t, "cached-token", time.Now().Add(time.Minute)), 0600); err != nil {
+		t.Fatalf("failed to write cache file: %v", err)
+	}
+	output, err = execute(t, "token", "--cluster-name", "test-0")
+	if err != nil {
+		t.Fatalf("failed to run token: %v", err)
+	}
+	token = execCredentialToken(t, output)
+	if token == "cached-token" {
+		t.Error("expected a new token to replace a token about to expire")
+	}
+	if cachedToken := execCredentialToken(t, readFile(t, cacheFile)); cachedToken != token {
+		t.Errorf("expected new token to be cached, got %s", cachedToken)
+	}
+}
+
+// readFile returns the contents of the file.
+func readFile(t *testing.T, file string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", file, err)
+	}
+
+	return string(content)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// unlockCmd represents the unlock command.
+var unlockCmd = &cobra.Command{
+	Use:   "unlock <state location>",
+	Short: "Release the lock on AWS resource stack state",
+	Long: `Release the lock on AWS resource stack state, e.g. s3://bucket/key or an
+inventory file path.  A lock is held while a create or delete operation is in
+progress and is left behind if the operation is killed before it finishes.
+Only release a lock when no operation is in progress.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure state location argument provided
+		if len(args) < 1 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		backend, err := inventoryBackend(args[0])
+		if err != nil {
+			return err
+		}
+		if err := backend.Unlock(cmd.Context(), ""); err != nil {
+			return fmt.Errorf("failed to unlock state: %w", err)
+		}
+		fmt.Printf("State '%s' unlocked\n", backend)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unlockCmd)
+}
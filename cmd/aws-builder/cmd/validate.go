@@ -0,0 +1,96 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+var validateOutputFormat string
+
+// validateCmd represents the validate command.
+var validateCmd = &cobra.Command{
+	Use:   "validate <resource stack> <config file>...",
+	Short: "Check an AWS resource stack config for problems",
+	Long: fmt.Sprintf(`Check an AWS resource stack config for values that would cause resource
+creation to fail, such as subnets outside the cluster CIDR, names that exceed
+AWS limits or a database port that doesn't match the engine.  Every problem
+is reported with the path to the field.  No AWS API calls are made.  Exits
+non-zero if any problems are found.  The same checks are made by create.
+%s
+%s`, configFilesHelp, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack and config file arguments provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := validateOutput(validateOutputFormat); err != nil {
+			return err
+		}
+
+		// load and validate requested resource stack config - problems
+		// found loading config files such as unknown fields are reported
+		// with problems found validating the config
+		resourceConfig, validateErr := resourceStack.LoadConfig(nil, args[1:]...)
+		if validateErr == nil {
+			validateErr = resourceConfig.Validate()
+		}
+
+		problems := validation.Errors{}
+		if validateErr != nil && !errors.As(validateErr, &problems) {
+			return fmt.Errorf("failed to load %s config: %w", args[0], validateErr)
+		}
+
+		if validateOutputFormat == "json" {
+			problemsJson, err := json.MarshalIndent(problems, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal problems to JSON: %w", err)
+			}
+			fmt.Println(string(problemsJson))
+		} else if len(problems) == 0 {
+			fmt.Printf("%s config is valid\n", args[0])
+		} else {
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "FIELD\tPROBLEM")
+			for _, problem := range problems {
+				fmt.Fprintf(tw, "%s\t%s\n", problem.Field, problem.Message)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+
+		if len(problems) > 0 {
+			// invalid config is not a usage error
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d problem(s) found in %s config", len(problems), args[0])
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVarP(
+		&validateOutputFormat, "output", "o", "text",
+		"Output format: text or json",
+	)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+func TestValidate(t *testing.T) {
+	configFile := "../../../sample/rds-config.yaml"
+	output, err := execute(t, "validate", "rds", configFile)
+	if err != nil {
+		t.Fatalf("expected sample config to be valid, got %v", err)
+	}
+	if output != "rds config is valid\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	// every problem in the merged config files is reported with its field
+	overlayFile := filepath.Join(t.TempDir(), "overlay.yaml")
+	if err := os.WriteFile(overlayFile, []byte("engine: postgres\nstorageGb: 10\n"), 0600); err != nil {
+		t.Fatalf("failed to write overlay file: %v", err)
+	}
+	output, err = execute(t, "validate", "rds", configFile, overlayFile, "-o", "json")
+	if err == nil || err.Error() != "2 problem(s) found in rds config" {
+		t.Errorf("expected problems to fail the command, got %v", err)
+	}
+	var problems validation.Errors
+	if err := json.Unmarshal([]byte(output), &problems); err != nil {
+		t.Fatalf("failed to unmarshal problems: %v\n%s", err, output)
+	}
+	expected := validation.Errors{
+		{Field: "dbPort", Message: "must be 5432, the port the postgres engine listens on"},
+		{Field: "storageGb", Message: "must be 20 or greater"},
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected problems %v, got %v", expected, problems)
+	}
+}
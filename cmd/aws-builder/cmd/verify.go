@@ -0,0 +1,109 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/config"
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+var (
+	verifyConfigFiles []string
+	verifyOutput      string
+)
+
+// verifyCmd represents the verify command.
+var verifyCmd = &cobra.Command{
+	Use:   "verify <resource stack> <inventory file>",
+	Short: "Check an AWS resource stack for drift from its inventory",
+	Long: fmt.Sprintf(`Check an AWS resource stack for drift from its inventory.  Each resource in
+inventory is described and reported as one of:
+* ok - the resource exists and matches its expected state
+* missing - the resource no longer exists
+* modified - the resource exists but differs from its expected state
+* unexpected - the resource has state the resource stack did not create
+If a config file is provided, configurable values such as versions and sizes
+are also compared.  Exits non-zero if any drift is found.
+%s`, supportedResourceStacks),
+	ValidArgsFunction: completeResourceStack,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ensure resource stack argument provided
+		if len(args) < 2 {
+			return fmt.Errorf("missing arguments")
+		}
+
+		resourceStack, err := stack.Get(args[0])
+		if err != nil {
+			return err
+		}
+		title := strings.ToUpper(resourceStack.Name())
+
+		if err := validateOutput(verifyOutput); err != nil {
+			return err
+		}
+
+		// load AWS config
+		awsConfig, err := config.LoadAWSConfig(false, awsConfigProfile, awsRegion, awsRoleArn, "", awsSerialNumber)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+
+		// create resource client - verification does not send messages
+		resourceClient := newResourceClient(cmd.Context(), awsConfig)
+
+		resourceInventory := resourceStack.NewInventory()
+		if err := resourceInventory.Load(args[1]); err != nil {
+			return fmt.Errorf("failed to load %s inventory: %w", title, err)
+		}
+
+		// load config if provided
+		var resourceConfig stack.Config
+		if len(verifyConfigFiles) > 0 {
+			resourceConfig, err = resourceStack.LoadConfig(nil, verifyConfigFiles...)
+			if err != nil {
+				return fmt.Errorf("failed to load %s config file: %w", title, err)
+			}
+		}
+
+		report, err := resourceStack.New(resourceClient).Verify(resourceInventory, resourceConfig)
+		if err != nil {
+			return fmt.Errorf("failed to verify %s resource stack: %w", title, err)
+		}
+
+		if verifyOutput == "json" {
+			err = report.WriteJson(os.Stdout)
+		} else {
+			err = report.WriteText(os.Stdout)
+		}
+		if err != nil {
+			return err
+		}
+
+		if report.HasDrift() {
+			// drift is not a usage error
+			cmd.SilenceUsage = true
+			return fmt.Errorf("drift detected in %s resource stack: %s", args[0], report.Summary())
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringArrayVarP(
+		&verifyConfigFiles, "config", "", nil,
+		"Config file used to create the resource stack; configurable values will be compared. Repeat for overlay files",
+	)
+	verifyCmd.Flags().StringVarP(
+		&verifyOutput, "output", "o", "text",
+		"Output format for the report: text or json",
+	)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_s3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/nukleros/aws-builder/pkg/s3"
+)
+
+func TestVerify(t *testing.T) {
+	configFile := "../../../sample/s3-config.yaml"
+	resourceConfig, err := s3.LoadS3Config(configFile)
+	if err != nil {
+		t.Fatalf("failed to load sample config: %v", err)
+	}
+	backend := useFakeBackend(t, resourceConfig.Region)
+	s3Client := s3.S3Client{ResourceClient: *backend.ResourceClient()}
+	var inventory s3.S3Inventory
+	if err := s3Client.CreateS3ResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	inventoryFile := filepath.Join(t.TempDir(), "s3-inventory.json")
+	if err := inventory.Write(inventoryFile); err != nil {
+		t.Fatalf("failed to write inventory: %v", err)
+	}
+
+	output, err := execute(t, "verify", "s3", inventoryFile, "--config", configFile)
+	if err != nil {
+		t.Fatalf("expected no drift, got %v:\n%s", err, output)
+	}
+	if !strings.Contains(output, "4 ok, 0 missing, 0 modified, 0 unexpected") {
+		t.Errorf("expected every resource to be ok, got:\n%s", output)
+	}
+
+	// drift fails the command after the report is written
+	if _, err := backend.Apis().S3.PutBucketVersioning(context.Background(), &aws_s3.PutBucketVersioningInput{
+		Bucket: aws.String(inventory.BucketName),
+		VersioningConfiguration: &types.VersioningConfiguration{
+			Status: types.BucketVersioningStatusSuspended,
+		},
+	}); err != nil {
+		t.Fatalf("failed to suspend versioning: %v", err)
+	}
+	output, err = execute(t, "verify", "s3", inventoryFile, "-o", "json")
+	if err == nil || !strings.Contains(err.Error(), "drift detected in s3 resource stack: 3 ok, 0 missing, 1 modified") {
+		t.Errorf("expected drift error, got %v", err)
+	}
+	if !strings.Contains(output, `"details": [`) || !strings.Contains(output, "versioning is not enabled") {
+		t.Errorf("expected JSON report of the modified bucket, got:\n%s", output)
+	}
+}
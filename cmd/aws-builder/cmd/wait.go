@@ -0,0 +1,112 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/waiter"
+)
+
+var (
+	waitTimeouts  map[string]string
+	waitIntervals map[string]string
+	waitBackoffs  map[string]string
+)
+
+// waiterNames are the resources that can be waited on in any resource stack.
+var waiterNames = allWaiterNames()
+
+// allWaiterNames returns the resources that can be waited on in the registered
+// resource stacks.
+func allWaiterNames() []string {
+	var names []string
+	for _, resourceStack := range stack.All() {
+		names = append(names, resourceStack.WaiterNames()...)
+	}
+
+	return names
+}
+
+// addWaiterFlags adds the flags used to configure waiters to a command.
+func addWaiterFlags(cmd *cobra.Command) {
+	names := strings.Join(waiterNames, ", ")
+	cmd.Flags().StringToStringVar(
+		&waitTimeouts, "wait-timeout", nil,
+		fmt.Sprintf("Time to wait for a resource before giving up, e.g. cluster=30m; resources: %s", names),
+	)
+	cmd.Flags().StringToStringVar(
+		&waitIntervals, "wait-interval", nil,
+		"Time between checks while waiting for a resource, e.g. node-group=30s",
+	)
+	cmd.Flags().StringToStringVar(
+		&waitBackoffs, "wait-backoff", nil,
+		"Factor the wait interval is multiplied by after each check, e.g. rds-instance=1.5",
+	)
+}
+
+// waiterConfigs returns the waiter configs set with command flags.  Values
+// set with flags take precedence over those in the resource config.
+func waiterConfigs() (waiter.Configs, error) {
+	configs := waiter.Configs{}
+
+	for name, value := range waitTimeouts {
+		config, err := waiterConfig(configs, name)
+		if err != nil {
+			return nil, err
+		}
+		if config.Timeout, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid wait timeout for %s: %w", name, err)
+		}
+		configs[name] = config
+	}
+
+	for name, value := range waitIntervals {
+		config, err := waiterConfig(configs, name)
+		if err != nil {
+			return nil, err
+		}
+		if config.Interval, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid wait interval for %s: %w", name, err)
+		}
+		configs[name] = config
+	}
+
+	for name, value := range waitBackoffs {
+		config, err := waiterConfig(configs, name)
+		if err != nil {
+			return nil, err
+		}
+		if config.Backoff, err = strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("invalid wait backoff for %s: %w", name, err)
+		}
+		if config.Backoff < 1 {
+			return nil, fmt.Errorf("invalid wait backoff for %s: must be at least 1", name)
+		}
+		configs[name] = config
+	}
+
+	return configs, nil
+}
+
+// waiterConfig returns the config for the named waiter, or an error if there
+// is no waiter with that name.
+func waiterConfig(configs waiter.Configs, name string) (waiter.Config, error) {
+	if !slices.Contains(waiterNames, name) {
+		return waiter.Config{}, fmt.Errorf(
+			"unrecognized waiter %s, must be one of: %s",
+			name,
+			strings.Join(waiterNames, ", "),
+		)
+	}
+
+	return configs[name], nil
+}
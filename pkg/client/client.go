@@ -2,42 +2,183 @@ package client
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+
+	"github.com/nukleros/aws-builder/pkg/waiter"
 )
 
 type Client interface {
 	GetMessageChan() *chan string
 	GetContext() context.Context
 	GetAwsConfig() *aws.Config
+	GetEc2Api() Ec2Api
 }
 
 // ResourceClient contains the elements needed to manage resources.
 type ResourceClient struct {
 	// A channel for messages to be passed to client as resources are created
-	// and deleted.
+	// and deleted.  If set, it must be drained or resource operations will
+	// block.  It is not set by the CreateResourceClient constructors.
 	MessageChan *chan string
 
-	// A context object available for passing data across operations.
+	// A channel for structured progress events to be passed to client as
+	// resources are created and deleted.  Each event is also sent on the
+	// message channel as text.  If set, it must be drained or resource
+	// operations will block.
+	EventChan *chan Event
+
+	// An observer notified of progress events and inventory updates as
+	// resources are created and deleted.  Unlike the channels, an observer
+	// requires no goroutine to drain it.  If nil, no observer is notified.
+	Observer Observer
+
+	// A context object available for passing data across operations.  When
+	// the context is cancelled, in-progress operations return an error.
 	Context context.Context
 
 	// The AWS configuration for default settings and credentials.
 	AwsConfig *aws.Config
+
+	// The settings used to wait for resources to reach a desired condition
+	// by resource name.  Values set here take precedence over those in a
+	// resource stack config.
+	Waiters waiter.Configs
+
+	// Whether resources adopted into a resource stack rather than created by
+	// it are deleted along with the rest of the resource stack.  If false,
+	// adopted resources are left in place.
+	DeleteAdopted bool
+
+	// The AWS service APIs to call.  Fields left nil use the real AWS
+	// services.
+	Apis ServiceApis
 }
 
 // CreateResourceClient configures a resource client and returns it.
 func CreateResourceClient(awsConfig *aws.Config) *ResourceClient {
-	msgChan := make(chan string)
-	ctx := context.Background()
-	resourceClient := ResourceClient{&msgChan, ctx, awsConfig}
+	return CreateResourceClientWithContext(context.Background(), awsConfig)
+}
+
+// CreateResourceClientWithContext configures a resource client that uses the
+// provided context for all operations and returns it.  Cancelling the context
+// cancels in-flight AWS API calls and stops any waits for resources to reach
+// a desired condition.  No channels are created so resource operations never
+// block on progress notifications.  Set an observer or channels on the
+// returned client to receive them.
+func CreateResourceClientWithContext(ctx context.Context, awsConfig *aws.Config) *ResourceClient {
+	resourceClient := ResourceClient{
+		Context:   ctx,
+		AwsConfig: awsConfig,
+	}
+
+	return &resourceClient
+}
+
+// CreateResourceClientWithObserver configures a resource client that uses the
+// provided context for all operations and notifies the observer of progress
+// and inventory updates.  No channels are created so there is nothing to
+// drain.
+func CreateResourceClientWithObserver(
+	ctx context.Context,
+	awsConfig *aws.Config,
+	observer Observer,
+) *ResourceClient {
+	resourceClient := ResourceClient{
+		Context:   ctx,
+		AwsConfig: awsConfig,
+		Observer:  observer,
+	}
 
 	return &resourceClient
 }
 
-// SendMessage sends a message on the resource client's message channel if
-// present.
+// SendMessage sends a message on the resource client's message channel and
+// an event containing only the message on the event channel, if present.
 func (c *ResourceClient) SendMessage(message string) {
-	if c.MessageChan != nil {
-		*c.MessageChan <- message
+	c.SendEvent(Event{Message: message})
+}
+
+// SendEvent notifies the resource client's observer of an event and sends it
+// on the event and message channels, if present.
+func (c *ResourceClient) SendEvent(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	c.observer().OnMessage(event)
+	ChannelObserver[any]{MessageChan: c.MessageChan, EventChan: c.EventChan}.OnMessage(event)
+}
+
+// SendInventory notifies the resource client's observer of an inventory
+// update with a deep copy of the inventory.  Inventory channels are specific
+// to each resource stack client.
+func (c *ResourceClient) SendInventory(inventory any) {
+	if c.Observer == nil {
+		return
+	}
+	c.Observer.OnInventoryUpdate(CopyInventory(inventory))
+}
+
+// observer returns the resource client's observer or a no-op observer if
+// not set.
+func (c *ResourceClient) observer() Observer {
+	if c.Observer == nil {
+		return NopObserver{}
+	}
+	return c.Observer
+}
+
+// GetEc2Api returns the EC2 API for the resource client.
+func (c *ResourceClient) GetEc2Api() Ec2Api {
+	if c.Apis.Ec2 != nil {
+		return c.Apis.Ec2
+	}
+	return ec2.NewFromConfig(*c.AwsConfig)
+}
+
+// GetEksApi returns the EKS API for the resource client.
+func (c *ResourceClient) GetEksApi() EksApi {
+	if c.Apis.Eks != nil {
+		return c.Apis.Eks
+	}
+	return eks.NewFromConfig(*c.AwsConfig)
+}
+
+// GetIamApi returns the IAM API for the resource client.
+func (c *ResourceClient) GetIamApi() IamApi {
+	if c.Apis.Iam != nil {
+		return c.Apis.Iam
+	}
+	return iam.NewFromConfig(*c.AwsConfig)
+}
+
+// GetRdsApi returns the RDS API for the resource client.
+func (c *ResourceClient) GetRdsApi() RdsApi {
+	if c.Apis.Rds != nil {
+		return c.Apis.Rds
+	}
+	return rds.NewFromConfig(*c.AwsConfig)
+}
+
+// GetS3Api returns the S3 API for the resource client.
+func (c *ResourceClient) GetS3Api() S3Api {
+	if c.Apis.S3 != nil {
+		return c.Apis.S3
+	}
+	return s3.NewFromConfig(*c.AwsConfig)
+}
+
+// GetS3ControlApi returns the S3 Control API for the resource client.
+func (c *ResourceClient) GetS3ControlApi() S3ControlApi {
+	if c.Apis.S3Control != nil {
+		return c.Apis.S3Control
 	}
+	return s3control.NewFromConfig(*c.AwsConfig)
 }
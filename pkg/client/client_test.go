@@ -0,0 +1,56 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+type testInventory struct {
+	VpcId     string   `json:"vpcId"`
+	SubnetIds []string `json:"subnetIds"`
+}
+
+func TestCreateResourceClientDoesNotBlock(t *testing.T) {
+	resourceClient := CreateResourceClient(&aws.Config{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resourceClient.SendMessage("creating VPC")
+		resourceClient.SendEvent(Event{Stack: "eks", Kind: "vpc", Phase: PhaseCreated})
+		resourceClient.SendInventory(testInventory{VpcId: "vpc-1"})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sending progress on a default resource client blocked")
+	}
+}
+
+func TestSendInventoryCopiesInventory(t *testing.T) {
+	var received []testInventory
+	resourceClient := CreateResourceClient(&aws.Config{})
+	resourceClient.Observer = ObserverFuncs{
+		OnInventoryUpdateFunc: func(inventory any) {
+			received = append(received, inventory.(testInventory))
+		},
+	}
+
+	inventory := testInventory{VpcId: "vpc-1", SubnetIds: []string{"subnet-1"}}
+	resourceClient.SendInventory(inventory)
+	inventory.SubnetIds[0] = "subnet-2"
+	resourceClient.SendInventory(inventory)
+
+	if len(received) != 2 {
+		t.Fatalf("expected 2 inventory updates, got %d", len(received))
+	}
+	if received[0].SubnetIds[0] != "subnet-1" {
+		t.Errorf("expected first update to be unchanged, got %v", received[0].SubnetIds)
+	}
+	if received[1].SubnetIds[0] != "subnet-2" {
+		t.Errorf("expected second update to contain change, got %v", received[1].SubnetIds)
+	}
+}
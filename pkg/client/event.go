@@ -0,0 +1,63 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Phase is the stage a resource has reached when an event is sent.
+type Phase string
+
+// Phases reported for resources as they are created and deleted.
+const (
+	PhaseCreating         Phase = "creating"
+	PhaseCreated          Phase = "created"
+	PhaseWaiting          Phase = "waiting"
+	PhaseReady            Phase = "ready"
+	PhaseFoundInInventory Phase = "found-in-inventory"
+	PhaseNotRequested     Phase = "not-requested"
+	PhaseKeptAdopted      Phase = "kept-adopted"
+	PhaseDeleting         Phase = "deleting"
+	PhaseDeleted          Phase = "deleted"
+	PhaseSkipped          Phase = "skipped"
+	PhaseFailed           Phase = "failed"
+)
+
+// Event reports progress on the resources in a resource stack as they are
+// created and deleted.  Events sent with SendMessage only have the time and
+// message set.
+type Event struct {
+	// The time the event was sent.
+	Time time.Time `json:"time"`
+
+	// The resource stack the resource belongs to, e.g. "eks".
+	Stack string `json:"stack,omitempty"`
+
+	// The kind of resource, e.g. "vpc" or "eks-cluster".
+	Kind string `json:"kind,omitempty"`
+
+	// The IDs, names or ARNs of the resources the event applies to.
+	Ids []string `json:"ids,omitempty"`
+
+	// The stage the resources have reached.
+	Phase Phase `json:"phase,omitempty"`
+
+	// A human readable description of the event.
+	Message string `json:"message,omitempty"`
+
+	// The error that caused a failed event.
+	Error string `json:"error,omitempty"`
+}
+
+// String returns the text rendering of the event.
+func (e Event) String() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Error != "":
+		return fmt.Sprintf("%s %s: %s", e.Kind, e.Phase, e.Error)
+	default:
+		return fmt.Sprintf("%s %s: %s", e.Kind, e.Phase, strings.Join(e.Ids, ", "))
+	}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Observer is notified of progress as resources are created and deleted.  Its
+// methods are called synchronously from the goroutine managing the resources
+// so they should return quickly and must not block indefinitely.
+type Observer interface {
+	// OnMessage is called with each progress event.
+	OnMessage(event Event)
+
+	// OnInventoryUpdate is called with a deep copy of the latest inventory
+	// each time it changes, so it can be kept after the inventory changes
+	// again.  The inventory is an eks.EksInventory, rds.RdsInventory or
+	// s3.S3Inventory depending on the resource stack.
+	OnInventoryUpdate(inventory any)
+}
+
+// CopyInventory returns a deep copy of an inventory that shares no slices or
+// maps with the original.  Inventories only contain values that can be
+// marshalled to JSON so the copy is made by marshalling and unmarshalling.  If
+// that fails the inventory is returned as is.
+func CopyInventory[I any](inventory I) I {
+	inventoryType := reflect.TypeOf(inventory)
+	if inventoryType == nil {
+		return inventory
+	}
+	inventoryJson, err := json.Marshal(inventory)
+	if err != nil {
+		return inventory
+	}
+	inventoryCopy := reflect.New(inventoryType)
+	if err := json.Unmarshal(inventoryJson, inventoryCopy.Interface()); err != nil {
+		return inventory
+	}
+
+	return inventoryCopy.Elem().Interface().(I)
+}
+
+// NopObserver is an observer that ignores all notifications.
+type NopObserver struct{}
+
+// OnMessage ignores the event.
+func (NopObserver) OnMessage(event Event) {}
+
+// OnInventoryUpdate ignores the inventory.
+func (NopObserver) OnInventoryUpdate(inventory any) {}
+
+// ObserverFuncs is an observer that calls the provided functions.  Either
+// function may be nil in which case that notification is ignored.
+type ObserverFuncs struct {
+	OnMessageFunc         func(event Event)
+	OnInventoryUpdateFunc func(inventory any)
+}
+
+// OnMessage calls OnMessageFunc if set.
+func (o ObserverFuncs) OnMessage(event Event) {
+	if o.OnMessageFunc != nil {
+		o.OnMessageFunc(event)
+	}
+}
+
+// OnInventoryUpdate calls OnInventoryUpdateFunc if set.
+func (o ObserverFuncs) OnInventoryUpdate(inventory any) {
+	if o.OnInventoryUpdateFunc != nil {
+		o.OnInventoryUpdateFunc(inventory)
+	}
+}
+
+// ChannelObserver is an observer that sends notifications on channels.  Each
+// event is sent on the event channel and its text rendering on the message
+// channel.  Failed events are not sent as messages since the error is also
+// returned to the caller.  Inventory of type I is sent on the inventory
+// channel.  Nil channels are skipped.  The channels must be drained by the
+// caller or resource operations will block.
+type ChannelObserver[I any] struct {
+	MessageChan   *chan string
+	EventChan     *chan Event
+	InventoryChan *chan I
+}
+
+// OnMessage sends the event on the event and message channels.
+func (o ChannelObserver[I]) OnMessage(event Event) {
+	if o.EventChan != nil {
+		*o.EventChan <- event
+	}
+	if o.MessageChan != nil && event.Phase != PhaseFailed {
+		*o.MessageChan <- event.String()
+	}
+}
+
+// OnInventoryUpdate sends the inventory on the inventory channel if it is of
+// type I.
+func (o ChannelObserver[I]) OnInventoryUpdate(inventory any) {
+	if o.InventoryChan == nil {
+		return
+	}
+	if inv, ok := inventory.(I); ok {
+		*o.InventoryChan <- inv
+	}
+}
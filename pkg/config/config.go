@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -22,7 +23,8 @@ func LoadAWSConfig(
 	externalId,
 	serialNumber string,
 ) (*aws.Config, error) {
-	configOptions := []func(*config.LoadOptions) error{}
+	// retry throttling and eventual consistency errors on all AWS API calls
+	configOptions := retryOptions()
 
 	// load shared config profile if provided
 	if configProfile != "" {
@@ -45,7 +47,7 @@ func LoadAWSConfig(
 		configOptions,
 		config.WithAssumeRoleCredentialOptions(
 			func(o *stscreds.AssumeRoleOptions) {
-				o.TokenProvider = stscreds.StdinTokenProvider
+				o.TokenProvider = stderrTokenProvider
 				if externalId != "" {
 					o.ExternalID = aws.String(externalId)
 				}
@@ -67,7 +69,7 @@ func LoadAWSConfig(
 		stsClient := sts.NewFromConfig(*awsConfig)
 
 		// get MFA token from user
-		tokenCode, err := stscreds.StdinTokenProvider()
+		tokenCode, err := stderrTokenProvider()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get token code: %w", err)
 		}
@@ -118,6 +120,16 @@ func LoadAWSConfig(
 	return awsConfig, err
 }
 
+// stderrTokenProvider prompts for an MFA token code on stderr so the prompt
+// isn't mixed with output, such as an exec credential, written to stdout.
+func stderrTokenProvider() (string, error) {
+	var tokenCode string
+	fmt.Fprint(os.Stderr, "MFA token code: ")
+	_, err := fmt.Scanln(&tokenCode)
+
+	return tokenCode, err
+}
+
 // LoadAWSConfigFromAPIKeys returns an AWS config from static API keys and
 // overrides the default region if provided.  The token parameter can be an
 // empty string.
@@ -130,7 +142,8 @@ func LoadAWSConfigFromAPIKeys(
 	roleSessionName,
 	externalId string,
 ) (*aws.Config, error) {
-	configOptions := []func(*config.LoadOptions) error{}
+	// retry throttling and eventual consistency errors on all AWS API calls
+	configOptions := retryOptions()
 
 	// use specified region if provided
 	if region != "" {
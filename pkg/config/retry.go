@@ -0,0 +1,241 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const (
+	// RetryMaxAttempts is the maximum number of attempts made for an AWS API
+	// call.  With RetryMaxBackoff this allows roughly a minute for IAM
+	// changes to propagate.
+	RetryMaxAttempts = 10
+
+	// RetryMaxBackoff is the longest delay between attempts.  Delays grow
+	// exponentially with random jitter up to this value.
+	RetryMaxBackoff = 20 * time.Second
+)
+
+// RetryableError describes an error returned by an AWS API call that is
+// retried in addition to the SDK's default retryable and throttling errors.
+type RetryableError struct {
+	// The service ID, e.g. "EKS" or "IAM".
+	Service string
+
+	// The operations the error is retried for.  If empty the error is retried
+	// for all operations in the service.
+	Operations []string
+
+	// The API error codes that are retried.
+	Codes []string
+
+	// A case-insensitive substring the error message must contain.  If
+	// empty any message matches.
+	MessageContains string
+
+	// How long after an API call's first attempt the error is retried.  If
+	// zero the error is retried for all attempts.
+	Window time.Duration
+}
+
+// RetryableErrors are the errors retried by the retry policy, most of which
+// are caused by eventual consistency between a resource being created and it
+// being usable by another resource.
+var RetryableErrors = []RetryableError{
+	{
+		// a newly created IAM role may not yet be assumable by EKS or have
+		// its attached policies visible to EKS
+		Service:         "EKS",
+		Operations:      []string{"CreateCluster", "CreateNodegroup", "CreateAddon"},
+		Codes:           []string{"InvalidParameterException"},
+		MessageContains: "role",
+	},
+	{
+		Service: "IAM",
+		Codes:   []string{"ConcurrentModification", "EntityTemporarilyUnmodifiable"},
+	},
+	{
+		// newly created EC2 resources may not yet be visible to other calls
+		Service: "EC2",
+		Operations: []string{
+			"CreateSubnet",
+			"CreateRouteTable",
+			"CreateRoute",
+			"AssociateRouteTable",
+			"AttachInternetGateway",
+			"CreateNatGateway",
+			"CreateSecurityGroup",
+			"AuthorizeSecurityGroupIngress",
+			"AuthorizeSecurityGroupEgress",
+			"ModifySubnetAttribute",
+			"ModifyVpcAttribute",
+		},
+		Codes: []string{
+			"InvalidVpcID.NotFound",
+			"InvalidSubnetID.NotFound",
+			"InvalidRouteTableID.NotFound",
+			"InvalidInternetGatewayID.NotFound",
+			"InvalidAllocationID.NotFound",
+			"InvalidNatGatewayID.NotFound",
+			"InvalidGroup.NotFound",
+		},
+	},
+	{
+		// removing a bucket's public access block takes time to propagate
+		// before public policies and ACLs are accepted; later denials are
+		// genuine permission errors
+		Service:    "S3",
+		Operations: []string{"PutBucketPolicy", "PutBucketAcl"},
+		Codes:      []string{"AccessDenied"},
+		Window:     30 * time.Second,
+	},
+}
+
+// Matches returns true if the error returned by the service operation should
+// be retried.
+func (r RetryableError) Matches(service, operation string, err error) bool {
+	if service != r.Service {
+		return false
+	}
+	if len(r.Operations) > 0 && !slices.Contains(r.Operations, operation) {
+		return false
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || !slices.Contains(r.Codes, apiErr.ErrorCode()) {
+		return false
+	}
+
+	return r.MessageContains == "" ||
+		strings.Contains(strings.ToLower(apiErr.ErrorMessage()), strings.ToLower(r.MessageContains))
+}
+
+// IsRetryable returns true if an error returned by the service operation
+// matches any of the RetryableErrors.  Errors with a retry window are
+// considered to be returned by the call's first attempt.
+func IsRetryable(service, operation string, err error) bool {
+	return isRetryable(service, operation, 0, err)
+}
+
+// isRetryable returns true if an error returned by the service operation
+// the given time after its first attempt matches any of the RetryableErrors
+// within their retry window.
+func isRetryable(service, operation string, elapsed time.Duration, err error) bool {
+	for _, retryableError := range RetryableErrors {
+		if retryableError.Window > 0 && elapsed > retryableError.Window {
+			continue
+		}
+		if retryableError.Matches(service, operation, err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewRetryer returns the retryer used for all AWS API calls.  It retries the
+// SDK's default retryable and throttling errors as well as errors marked
+// retryable by the classifier added with AddRetryClassifier, using jittered
+// exponential backoff.  Retries are bounded by RetryMaxAttempts per call and
+// the SDK's retry token bucket across calls.
+func NewRetryer() aws.Retryer {
+	return retry.NewStandard(func(o *retry.StandardOptions) {
+		o.MaxAttempts = RetryMaxAttempts
+		o.MaxBackoff = RetryMaxBackoff
+	})
+}
+
+// AddRetryClassifier adds middleware to an AWS API call that marks errors
+// matching RetryableErrors as retryable.  It runs on each attempt so the
+// service and operation are known when the error is classified, and records
+// when the call started so retry windows are measured from the first attempt.
+func AddRetryClassifier(stack *middleware.Stack) error {
+	if err := stack.Initialize.Add(retryStart{}, middleware.After); err != nil {
+		return err
+	}
+
+	return stack.Finalize.Add(retryClassifier{}, middleware.After)
+}
+
+// retryStartKey is the stack value key for the time an API call started.
+type retryStartKey struct{}
+
+// retryStart is the middleware added by AddRetryClassifier that records when
+// an API call started, before any attempts are made.
+type retryStart struct{}
+
+func (retryStart) ID() string {
+	return "RetryStart"
+}
+
+func (retryStart) HandleInitialize(
+	ctx context.Context,
+	in middleware.InitializeInput,
+	next middleware.InitializeHandler,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	return next.HandleInitialize(middleware.WithStackValue(ctx, retryStartKey{}, time.Now()), in)
+}
+
+// retryClassifier is the middleware added by AddRetryClassifier.
+type retryClassifier struct{}
+
+func (retryClassifier) ID() string {
+	return "RetryClassifier"
+}
+
+func (retryClassifier) HandleFinalize(
+	ctx context.Context,
+	in middleware.FinalizeInput,
+	next middleware.FinalizeHandler,
+) (middleware.FinalizeOutput, middleware.Metadata, error) {
+	out, metadata, err := next.HandleFinalize(ctx, in)
+	if err == nil {
+		return out, metadata, err
+	}
+
+	var elapsed time.Duration
+	if start, ok := middleware.GetStackValue(ctx, retryStartKey{}).(time.Time); ok {
+		elapsed = time.Since(start)
+	}
+	if isRetryable(awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx), elapsed, err) {
+		err = &retryableError{err: err}
+	}
+
+	return out, metadata, err
+}
+
+// retryableError wraps an error to mark it retryable for the SDK's retryer.
+// The wrapped error's message and type are preserved for callers.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+func (e *retryableError) RetryableError() bool {
+	return true
+}
+
+// retryOptions returns the config load options that apply the retry policy.
+func retryOptions() []func(*config.LoadOptions) error {
+	return []func(*config.LoadOptions) error{
+		config.WithRetryer(NewRetryer),
+		config.WithAPIOptions([]func(*middleware.Stack) error{AddRetryClassifier}),
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestIsRetryable(t *testing.T) {
+	accessDenied := &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+	cases := []struct {
+		name      string
+		service   string
+		operation string
+		elapsed   time.Duration
+		err       error
+		retryable bool
+	}{
+		{"IAM role not assumable", "EKS", "CreateCluster", time.Minute,
+			&smithy.GenericAPIError{Code: "InvalidParameterException", Message: "Role is not authorized"}, true},
+		{"other EKS parameter error", "EKS", "CreateCluster", 0,
+			&smithy.GenericAPIError{Code: "InvalidParameterException", Message: "invalid version"}, false},
+		{"S3 access denied in window", "S3", "PutBucketPolicy", 10 * time.Second, accessDenied, true},
+		{"S3 access denied after window", "S3", "PutBucketPolicy", time.Minute, accessDenied, false},
+		{"S3 access denied for other operation", "S3", "PutObject", 0, accessDenied, false},
+		{"non-API error", "S3", "PutBucketAcl", 0, errors.New("connection reset"), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if retryable := isRetryable(c.service, c.operation, c.elapsed, c.err); retryable != c.retryable {
+				t.Errorf("expected retryable to be %t, got %t", c.retryable, retryable)
+			}
+		})
+	}
+}
+
+func TestRetryClassifier(t *testing.T) {
+	stack := middleware.NewStack("PutBucketPolicy", func() any { return nil })
+	if err := stack.Initialize.Add(&awsmiddleware.RegisterServiceMetadata{
+		ServiceID:     "S3",
+		OperationName: "PutBucketPolicy",
+	}, middleware.Before); err != nil {
+		t.Fatalf("failed to add service metadata: %v", err)
+	}
+	if err := AddRetryClassifier(stack); err != nil {
+		t.Fatalf("failed to add retry classifier: %v", err)
+	}
+
+	accessDenied := &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(
+		func(ctx context.Context, in any) (any, middleware.Metadata, error) {
+			return nil, middleware.Metadata{}, accessDenied
+		},
+	), stack)
+
+	_, _, err := handler.Handle(context.Background(), nil)
+	var retryable interface{ RetryableError() bool }
+	if !errors.As(err, &retryable) || !retryable.RetryableError() {
+		t.Errorf("expected error on first attempt to be retryable, got %v", err)
+	}
+	if !errors.Is(err, accessDenied) {
+		t.Errorf("expected API error to be wrapped, got %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+// Package configfile loads resource stack configs from YAML or JSON files.
+// Unknown fields are rejected, ${ENV_VAR} references are replaced with the
+// value of the environment variable, ${stack.output} references are replaced
+// with the outputs of other resource stacks and multiple files can be layered
+// so values in later files override values in earlier files.
+package configfile
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+// referencePattern matches environment variable references, e.g.
+// ${DB_PASSWORD}, and resource stack output references, e.g. ${eks.vpcId}.
+// References can be escaped by doubling the dollar sign, e.g. $${NOT_EXPANDED}.
+var referencePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*|[A-Za-z][A-Za-z0-9_-]*\.[A-Za-z][A-Za-z0-9_]*)\}`)
+
+// Outputs contains the outputs of resource stacks by resource stack name and
+// output name.  Each output is a string or a []string.
+type Outputs map[string]map[string]any
+
+// Load reads each config file in order and unmarshals them into config, which
+// must be a pointer to a struct.  Values already set in config are kept unless
+// set in a file, so config can contain defaults.  Mappings in later files are
+// merged with mappings in earlier files.  Any other value in a later file
+// replaces the value in earlier files, including lists.
+//
+// Files can be YAML or JSON.  String values can contain ${ENV_VAR}
+// references which are replaced with the value of the environment variable.
+// If any file contains fields that config does not have or references
+// environment variables that are not set, a validation.Errors containing every
+// problem is returned.
+func Load(config any, configFiles ...string) error {
+	return LoadWithOutputs(config, nil, configFiles...)
+}
+
+// LoadWithOutputs loads config files like Load and also replaces
+// ${stack.output} references with the outputs of other resource stacks.  A
+// value that is only a reference to a list output, e.g. ${eks.privateSubnetIds},
+// is replaced with the list.  A problem is added for each reference to an
+// output that is not in outputs.
+func LoadWithOutputs(config any, outputs Outputs, configFiles ...string) error {
+	if len(configFiles) == 0 {
+		return fmt.Errorf("no config files provided")
+	}
+	configType := reflect.TypeOf(config)
+	if configType.Kind() != reflect.Pointer || configType.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a pointer to a struct, got %s", configType)
+	}
+
+	var errs validation.Errors
+	var merged *yaml.Node
+	for _, configFile := range configFiles {
+		configBytes, err := os.ReadFile(configFile)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		var document yaml.Node
+		if err := yaml.Unmarshal(configBytes, &document); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", configFile, err)
+		}
+
+		// an empty file sets no values
+		if len(document.Content) == 0 {
+			continue
+		}
+		root := document.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return fmt.Errorf("config file %s must contain a mapping of field names to values", configFile)
+		}
+
+		expand(&errs, configFile, "", root, outputs)
+		checkFields(&errs, configFile, "", root, configType.Elem())
+		merged = merge(merged, root)
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+	if merged == nil {
+		return nil
+	}
+
+	if err := merged.Decode(config); err != nil {
+		return fmt.Errorf("failed to unmarshal config files %s: %w", strings.Join(configFiles, ", "), err)
+	}
+
+	return nil
+}
+
+// location returns a description of where a node is in a config file for use
+// in problem messages.
+func location(configFile string, node *yaml.Node) string {
+	return fmt.Sprintf("%s line %d", configFile, node.Line)
+}
+
+// expand replaces environment variable and output references in every string
+// value in a node.  A problem is added for each reference to a variable that
+// is not set or an output that is not available.
+func expand(errs *validation.Errors, configFile, field string, node *yaml.Node, outputs Outputs) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			expand(errs, configFile, childField(field, node.Content[i].Value), node.Content[i+1], outputs)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			expand(errs, configFile, validation.Index(field, i), item, outputs)
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "${") {
+			return
+		}
+
+		// a value that is only a reference to a list output is replaced
+		// with the list
+		if match := referencePattern.FindStringSubmatch(node.Value); match != nil && match[0] == node.Value {
+			if list, ok := lookupOutput(outputs, match[1]).([]string); ok {
+				node.Kind = yaml.SequenceNode
+				node.Tag = "!!seq"
+				node.Value = ""
+				node.Content = nil
+				for _, item := range list {
+					node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+				}
+				return
+			}
+		}
+
+		node.Value = referencePattern.ReplaceAllStringFunc(node.Value, func(reference string) string {
+			if strings.HasPrefix(reference, "$$") {
+				return reference[1:]
+			}
+			name := referencePattern.FindStringSubmatch(reference)[1]
+			if strings.Contains(name, ".") {
+				switch value := lookupOutput(outputs, name).(type) {
+				case string:
+					return value
+				case []string:
+					errs.Add(field, "output %s is a list and must be the whole value (%s)", name, location(configFile, node))
+				default:
+					errs.Add(field, "output %s is not available (%s)", name, location(configFile, node))
+				}
+				return ""
+			}
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				errs.Add(field, "environment variable %s is not set (%s)", name, location(configFile, node))
+			}
+			return value
+		})
+
+		// unquoted values without an explicit tag are resolved again so that
+		// references can be used for numbers and booleans.  A value that
+		// resolves to null, e.g. "~" or an empty value, is kept as a string
+		// so a reference cannot clear a field.
+		if node.Style&(yaml.TaggedStyle|yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+			if node.ShortTag() == "!!null" {
+				node.Tag = "!!str"
+			}
+		}
+	}
+}
+
+// lookupOutput returns the output for a stack.output reference or nil if it
+// is not in outputs or is empty.
+func lookupOutput(outputs Outputs, reference string) any {
+	stackName, outputName, ok := strings.Cut(reference, ".")
+	if !ok {
+		return nil
+	}
+
+	switch value := outputs[stackName][outputName].(type) {
+	case string:
+		if value != "" {
+			return value
+		}
+	case []string:
+		if len(value) > 0 {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// OutputReferences returns the names of the resource stacks whose outputs are
+// referenced in the config files, in sorted order.
+func OutputReferences(configFiles ...string) ([]string, error) {
+	stackNames := map[string]bool{}
+	for _, configFile := range configFiles {
+		configBytes, err := os.ReadFile(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		var document yaml.Node
+		if err := yaml.Unmarshal(configBytes, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", configFile, err)
+		}
+		addOutputReferences(stackNames, &document)
+	}
+
+	return slices.Sorted(maps.Keys(stackNames)), nil
+}
+
+// addOutputReferences adds the names of the resource stacks whose outputs are
+// referenced in the string values in a node.
+func addOutputReferences(stackNames map[string]bool, node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		for _, match := range referencePattern.FindAllStringSubmatch(node.Value, -1) {
+			if strings.HasPrefix(match[0], "$$") {
+				continue
+			}
+			if stackName, _, ok := strings.Cut(match[1], "."); ok {
+				stackNames[stackName] = true
+			}
+		}
+	}
+	for _, child := range node.Content {
+		addOutputReferences(stackNames, child)
+	}
+}
+
+// checkFields adds a problem for each field in a mapping node that is not in
+// the struct type the node will be unmarshalled into.  Field names are
+// case-sensitive.
+func checkFields(errs *validation.Errors, configFile, field string, node *yaml.Node, configType reflect.Type) {
+	for configType.Kind() == reflect.Pointer {
+		configType = configType.Elem()
+	}
+
+	switch {
+	case node.Kind == yaml.MappingNode && configType.Kind() == reflect.Struct:
+		fields := yamlFields(configType)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				message := "unknown field"
+				if similar := similarField(key.Value, fields); similar != "" {
+					message = fmt.Sprintf("unknown field, did you mean %q?", similar)
+				}
+				errs.Add(childField(field, key.Value), "%s (%s)", message, location(configFile, key))
+				continue
+			}
+			checkFields(errs, configFile, childField(field, key.Value), node.Content[i+1], fieldType)
+		}
+	case node.Kind == yaml.MappingNode && configType.Kind() == reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkFields(errs, configFile, childField(field, node.Content[i].Value), node.Content[i+1], configType.Elem())
+		}
+	case node.Kind == yaml.SequenceNode && configType.Kind() == reflect.Slice:
+		for i, item := range node.Content {
+			checkFields(errs, configFile, validation.Index(field, i), item, configType.Elem())
+		}
+	}
+}
+
+// yamlFields returns the types of the fields of a struct type by their YAML
+// field names.
+func yamlFields(structType reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for _, structField := range reflect.VisibleFields(structType) {
+		if name := FieldName(structField); name != "" {
+			fields[name] = structField.Type
+		}
+	}
+
+	return fields
+}
+
+// FieldName returns the YAML field name for a struct field or "" if the
+// field can't be set in config files.
+func FieldName(structField reflect.StructField) string {
+	if !structField.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(structField.Tag.Get("yaml"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return strings.ToLower(structField.Name)
+	}
+
+	return name
+}
+
+// similarField returns the name of a field that differs from name only in case
+// or by a plural "s", or "" if there is none.
+func similarField(name string, fields map[string]reflect.Type) string {
+	for _, fieldName := range slices.Sorted(maps.Keys(fields)) {
+		if strings.EqualFold(name, fieldName) ||
+			strings.EqualFold(name+"s", fieldName) ||
+			strings.EqualFold(name, fieldName+"s") {
+			return fieldName
+		}
+	}
+
+	return ""
+}
+
+// childField returns the path to a field in a mapping.
+func childField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return validation.Key(parent, name)
+}
+
+// merge returns the result of layering overlay on top of base.  Mappings are
+// merged key by key and any other value in overlay replaces the value in
+// base.
+func merge(base, overlay *yaml.Node) *yaml.Node {
+	if base == nil || base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(base.Content); j += 2 {
+			if base.Content[j].Value == key.Value {
+				base.Content[j+1] = merge(base.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+
+	return base
+}
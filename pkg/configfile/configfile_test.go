@@ -0,0 +1,75 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testConfig struct {
+	Name    string `yaml:"name"`
+	Port    int    `yaml:"port"`
+	Enabled bool   `yaml:"enabled"`
+	Tagged  string `yaml:"tagged"`
+}
+
+// writeConfig writes a config file to a temporary directory and returns its
+// path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	return configFile
+}
+
+func TestLoadExpandsScalars(t *testing.T) {
+	t.Setenv("TEST_PORT", "3306")
+	t.Setenv("TEST_ENABLED", "true")
+	t.Setenv("TEST_NUMBER", "0123")
+	configFile := writeConfig(t, `
+name: ${TEST_NUMBER}
+port: ${TEST_PORT}
+enabled: ${TEST_ENABLED}
+tagged: !!str ${TEST_PORT}
+`)
+
+	var config testConfig
+	if err := Load(&config, configFile); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	expected := testConfig{Name: "0123", Port: 3306, Enabled: true, Tagged: "3306"}
+	if config != expected {
+		t.Errorf("expected %+v, got %+v", expected, config)
+	}
+}
+
+func TestLoadKeepsNullExpansionsAsStrings(t *testing.T) {
+	for _, value := range []string{"null", "~", "Null"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("TEST_NAME", value)
+			configFile := writeConfig(t, "name: ${TEST_NAME}\n")
+
+			config := testConfig{Name: "default"}
+			if err := Load(&config, configFile); err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			if config.Name != value {
+				t.Errorf("expected name %q, got %q", value, config.Name)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsNullExpansionForNumbers(t *testing.T) {
+	t.Setenv("TEST_PORT", "~")
+	configFile := writeConfig(t, "port: ${TEST_PORT}\n")
+
+	config := testConfig{Port: 5432}
+	if err := Load(&config, configFile); err == nil {
+		t.Errorf("expected error for null reference in number field, got port %d", config.Port)
+	}
+}
@@ -0,0 +1,154 @@
+// Package discovery contains the report returned when searching for the
+// existing resources of a resource stack to rebuild its inventory or adopt
+// them.
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Status is the result of searching for a single resource.
+type Status string
+
+const (
+	// StatusFound indicates exactly one matching resource was found and it
+	// was added to the inventory.
+	StatusFound Status = "found"
+
+	// StatusNotFound indicates no matching resource was found.
+	StatusNotFound Status = "not-found"
+
+	// StatusAmbiguous indicates more than one matching resource was found.
+	// None of them are added to the inventory so the correct one must be
+	// added manually.
+	StatusAmbiguous Status = "ambiguous"
+)
+
+// Result is the outcome of searching for a single resource.
+type Result struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name,omitempty"`
+	Id         string   `json:"id,omitempty"`
+	Status     Status   `json:"status"`
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// Report contains the results of searching for every resource in a resource
+// stack.
+type Report struct {
+	Stack   string    `json:"stack"`
+	Region  string    `json:"region"`
+	Results []*Result `json:"results"`
+}
+
+// NewReport returns an empty report for a resource stack.
+func NewReport(stack, region string) *Report {
+	return &Report{
+		Stack:   stack,
+		Region:  region,
+		Results: []*Result{},
+	}
+}
+
+// Add adds the result of searching for a resource given the IDs of the
+// matching resources.  The ID is returned if exactly one resource matched,
+// otherwise an empty string is returned.
+func (r *Report) Add(kind, name string, ids ...string) string {
+	result := Result{
+		Kind: kind,
+		Name: name,
+	}
+	switch len(ids) {
+	case 0:
+		result.Status = StatusNotFound
+	case 1:
+		result.Status = StatusFound
+		result.Id = ids[0]
+	default:
+		result.Status = StatusAmbiguous
+		result.Candidates = ids
+	}
+	r.Results = append(r.Results, &result)
+
+	return result.Id
+}
+
+// Count returns the number of resources with the given status.
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// HasAmbiguity returns true if any resource needs manual resolution.
+func (r *Report) HasAmbiguity() bool {
+	return r.Count(StatusAmbiguous) != 0
+}
+
+// Summary returns a one line summary of the number of resources per status.
+func (r *Report) Summary() string {
+	return fmt.Sprintf(
+		"%d found, %d not found, %d ambiguous",
+		r.Count(StatusFound),
+		r.Count(StatusNotFound),
+		r.Count(StatusAmbiguous),
+	)
+}
+
+// WriteText writes the report as a human readable table.  The candidates for
+// ambiguous resources are listed in the ID column.
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Resources of %s resource stack in region %s:\n\n", r.Stack, r.Region)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tKIND\tNAME\tID")
+	for _, result := range r.Results {
+		id := result.Id
+		if result.Status == StatusAmbiguous {
+			id = strings.Join(result.Candidates, ", ")
+		}
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\n",
+			result.Status,
+			result.Kind,
+			valueOrDash(result.Name),
+			valueOrDash(id),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write discovery report: %w", err)
+	}
+	fmt.Fprintf(w, "\n%s\n", r.Summary())
+
+	return nil
+}
+
+// WriteJson writes the report as indented JSON.
+func (r *Report) WriteJson(w io.Writer) error {
+	reportJson, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal discovery report to JSON: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(reportJson)); err != nil {
+		return fmt.Errorf("failed to write discovery report: %w", err)
+	}
+
+	return nil
+}
+
+// valueOrDash returns a dash for empty values so table columns stay aligned.
+func valueOrDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,158 @@
+// Package drift contains the report returned when verifying that the resources
+// recorded in a resource stack inventory still exist and match their expected
+// state.
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Status is the result of verifying a single resource.
+type Status string
+
+const (
+	// StatusOk indicates the resource exists and matches its expected state.
+	StatusOk Status = "ok"
+
+	// StatusMissing indicates the resource in inventory no longer exists.
+	StatusMissing Status = "missing"
+
+	// StatusModified indicates the resource exists but some of its state
+	// differs from what the resource stack expects.
+	StatusModified Status = "modified"
+
+	// StatusUnexpected indicates the resource exists as expected but has
+	// additional state that was not created with the resource stack, such as
+	// an extra policy attached to a role.
+	StatusUnexpected Status = "unexpected"
+)
+
+// Result is the outcome of verifying a single resource in inventory.
+type Result struct {
+	Kind    string   `json:"kind"`
+	Id      string   `json:"id"`
+	Status  Status   `json:"status"`
+	Details []string `json:"details,omitempty"`
+}
+
+// Modified records a difference between the resource and its expected state.
+func (r *Result) Modified(format string, args ...any) {
+	if r.Status != StatusMissing {
+		r.Status = StatusModified
+	}
+	r.Details = append(r.Details, fmt.Sprintf(format, args...))
+}
+
+// Unexpected records state on the resource that the resource stack did not
+// create.  It does not override a missing or modified status.
+func (r *Result) Unexpected(format string, args ...any) {
+	if r.Status == StatusOk {
+		r.Status = StatusUnexpected
+	}
+	r.Details = append(r.Details, fmt.Sprintf(format, args...))
+}
+
+// Report contains the results of verifying every resource in a resource stack
+// inventory.
+type Report struct {
+	Stack   string    `json:"stack"`
+	Region  string    `json:"region"`
+	Results []*Result `json:"results"`
+}
+
+// NewReport returns an empty report for a resource stack.
+func NewReport(stack, region string) *Report {
+	return &Report{
+		Stack:   stack,
+		Region:  region,
+		Results: []*Result{},
+	}
+}
+
+// Found adds a result for a resource that exists and returns it so any
+// differences from its expected state can be recorded.
+func (r *Report) Found(kind, id string) *Result {
+	result := Result{
+		Kind:   kind,
+		Id:     id,
+		Status: StatusOk,
+	}
+	r.Results = append(r.Results, &result)
+
+	return &result
+}
+
+// Missing adds a result for a resource that no longer exists.
+func (r *Report) Missing(kind, id string) *Result {
+	result := r.Found(kind, id)
+	result.Status = StatusMissing
+
+	return result
+}
+
+// Count returns the number of resources with the given status.
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// HasDrift returns true if any resource is not in its expected state.
+func (r *Report) HasDrift() bool {
+	return r.Count(StatusOk) != len(r.Results)
+}
+
+// Summary returns a one line summary of the number of resources per status.
+func (r *Report) Summary() string {
+	return fmt.Sprintf(
+		"%d ok, %d missing, %d modified, %d unexpected",
+		r.Count(StatusOk),
+		r.Count(StatusMissing),
+		r.Count(StatusModified),
+		r.Count(StatusUnexpected),
+	)
+}
+
+// WriteText writes the report as a human readable table.
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Verification of %s resource stack in region %s:\n\n", r.Stack, r.Region)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tKIND\tID\tDETAILS")
+	for _, result := range r.Results {
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\n",
+			result.Status,
+			result.Kind,
+			result.Id,
+			strings.Join(result.Details, "; "),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write drift report: %w", err)
+	}
+	fmt.Fprintf(w, "\n%s\n", r.Summary())
+
+	return nil
+}
+
+// WriteJson writes the report as indented JSON.
+func (r *Report) WriteJson(w io.Writer) error {
+	reportJson, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal drift report to JSON: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(reportJson)); err != nil {
+		return fmt.Errorf("failed to write drift report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package drift
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestResultStatus(t *testing.T) {
+	report := NewReport("eks", "us-east-1")
+
+	// a modification overrides unexpected state but not a missing resource
+	modified := report.Found("iam-role", "role-1")
+	modified.Unexpected("policy %s attached outside of resource stack", "policy-1")
+	modified.Modified("policy %s not attached", "policy-2")
+	missing := report.Missing("vpc", "vpc-1")
+	missing.Modified("CIDR block is %s", "10.0.0.0/16")
+	unexpected := report.Found("iam-role", "role-2")
+	unexpected.Unexpected("policy %s attached outside of resource stack", "policy-3")
+
+	for _, expected := range []struct {
+		result  *Result
+		status  Status
+		details int
+	}{
+		{modified, StatusModified, 2},
+		{missing, StatusMissing, 1},
+		{unexpected, StatusUnexpected, 1},
+	} {
+		if expected.result.Status != expected.status || len(expected.result.Details) != expected.details {
+			t.Errorf("expected %s %s to be %s with %d details, got %+v",
+				expected.result.Kind, expected.result.Id, expected.status, expected.details, expected.result)
+		}
+	}
+}
+
+func TestReportHasDrift(t *testing.T) {
+	report := NewReport("s3", "us-east-1")
+	report.Found("s3-bucket", "bucket-1")
+	if report.HasDrift() {
+		t.Errorf("expected no drift, got %s", report.Summary())
+	}
+
+	report.Missing("s3-access-point", "access-point-1")
+	if !report.HasDrift() {
+		t.Errorf("expected drift, got %s", report.Summary())
+	}
+	if summary := report.Summary(); summary != "1 ok, 1 missing, 0 modified, 0 unexpected" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+
+	var output bytes.Buffer
+	if err := report.WriteText(&output); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	if !strings.Contains(output.String(), "missing  s3-access-point  access-point-1") {
+		t.Errorf("expected missing access point in report, got:\n%s", output.String())
+	}
+}
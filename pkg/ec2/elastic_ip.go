@@ -28,7 +28,7 @@ func CheckUniqueTagsForElasticIp(
 		filters = append(filters, filter)
 	}
 
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeElasticIpInput := ec2.DescribeAddressesInput{
 		Filters: filters,
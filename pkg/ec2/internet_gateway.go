@@ -28,7 +28,7 @@ func CheckUniqueTagsForInternetGateway(
 		filters = append(filters, filter)
 	}
 
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeInternetGatewayInput := ec2.DescribeInternetGatewaysInput{
 		Filters: filters,
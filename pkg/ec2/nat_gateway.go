@@ -36,7 +36,7 @@ func CheckUniqueTagsForNatGateway(
 	}
 	filters = append(filters, subnetFilter)
 
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeNatGatewayInput := ec2.DescribeNatGatewaysInput{
 		Filter: filters,
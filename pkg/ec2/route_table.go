@@ -27,7 +27,7 @@ func CheckUniqueTagsForRouteTables(
 		filters = append(filters, filter)
 	}
 
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeRouteTableInput := ec2.DescribeRouteTablesInput{
 		Filters: filters,
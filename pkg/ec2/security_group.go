@@ -36,7 +36,7 @@ func CheckUniqueTagsForSecurityGroup(
 		filters = append(filters, filter)
 	}
 
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeSecurityGroupInput := ec2.DescribeSecurityGroupsInput{
 		Filters: filters,
@@ -36,7 +36,7 @@ func CheckUniqueTagsForSubnet(
 	}
 	filters = append(filters, cidrFilter)
 
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeSubnetInput := ec2.DescribeSubnetsInput{
 		Filters: filters,
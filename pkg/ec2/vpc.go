@@ -28,7 +28,7 @@ func CheckUniqueTagsForVpc(
 		filters = append(filters, filter)
 	}
 
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeVpcInput := ec2.DescribeVpcsInput{
 		Filters: filters,
@@ -54,7 +54,7 @@ func CheckDnsResolutionForVpc(
 	client client.Client,
 	vpcId string,
 ) (bool, error) {
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeAttributeInput := ec2.DescribeVpcAttributeInput{
 		VpcId:     &vpcId,
@@ -78,7 +78,7 @@ func CheckDnsHostnamesForVpc(
 	client client.Client,
 	vpcId string,
 ) (bool, error) {
-	svc := ec2.NewFromConfig(*client.GetAwsConfig())
+	svc := client.GetEc2Api()
 
 	describeAttributeInput := ec2.DescribeVpcAttributeInput{
 		VpcId:     &vpcId,
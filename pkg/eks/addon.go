@@ -5,7 +5,10 @@ import (
 	"fmt"
 
 	aws_eks "github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 const EbsStorageAddonName = "aws-ebs-csi-driver"
@@ -17,7 +20,7 @@ func (c *EksClient) CreateEbsStorageAddon(
 	clusterName string,
 	storageManagementRoleArn string,
 ) (string, error) {
-	svc := aws_eks.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEksApi()
 
 	ebsAddonName := EbsStorageAddonName
 
@@ -41,3 +44,25 @@ func (c *EksClient) CreateEbsStorageAddon(
 
 	return *resp.Addon.AddonName, nil
 }
+
+// getAddon retrieves an addon installed on an EKS cluster.  If the addon or
+// cluster is not found util.ErrResourceNotFound is returned.
+func (c *EksClient) getAddon(clusterName, addonName string) (*types.Addon, error) {
+	svc := c.GetEksApi()
+
+	describeAddonInput := aws_eks.DescribeAddonInput{
+		ClusterName: &clusterName,
+		AddonName:   &addonName,
+	}
+	resp, err := svc.DescribeAddon(c.Context, &describeAddonInput)
+	if err != nil {
+		var notFoundErr *types.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return nil, util.ErrResourceNotFound
+		} else {
+			return nil, fmt.Errorf("failed to describe addon %s: %w", addonName, err)
+		}
+	}
+
+	return resp.Addon, nil
+}
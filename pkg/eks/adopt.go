@@ -0,0 +1,360 @@
+package eks
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	aws_eks "github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// AdoptEksResourceStack records an existing EKS cluster that was not created
+// by aws-builder, and the resources it depends on, in the inventory as
+// adopted.  The VPC, subnets and security group are taken from the cluster's
+// VPC config and subnets are public if their route table routes to an
+// internet gateway.  The cluster's IAM role, node groups, the node role they
+// share, the OIDC provider and the EBS CSI addon are recorded if they exist.
+// If applyTags is true, the Name tag and custom tags are added to the
+// cluster, its node groups, VPC, subnets and security group.
+func (c *EksClient) AdoptEksResourceStack(
+	clusterName string,
+	tags map[string]string,
+	applyTags bool,
+	inventory *EksInventory,
+) (*discovery.Report, error) {
+	inventory.Region = c.AwsConfig.Region
+	report := discovery.NewReport("eks", inventory.Region)
+
+	// EKS Cluster
+	cluster, err := c.getCluster(clusterName)
+	if errors.Is(err, util.ErrResourceNotFound) {
+		return nil, fmt.Errorf("EKS cluster %s not found in region %s", clusterName, inventory.Region)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Add("eks-cluster", clusterName, *cluster.Arn)
+	inventory.Cluster.ClusterName = *cluster.Name
+	inventory.Cluster.ClusterArn = *cluster.Arn
+	inventory.Adopted = append(inventory.Adopted, *cluster.Name)
+	var oidcProviderUrl string
+	if cluster.Identity != nil && cluster.Identity.Oidc != nil && cluster.Identity.Oidc.Issuer != nil {
+		oidcProviderUrl = *cluster.Identity.Oidc.Issuer
+	}
+	if cluster.Status == types.ClusterStatusActive {
+		inventory.Cluster.OidcProviderUrl = oidcProviderUrl
+	}
+
+	// VPC, Subnets and Security Group
+	if cluster.ResourcesVpcConfig != nil {
+		vpcConfig := cluster.ResourcesVpcConfig
+		vpcId := aws.ToString(vpcConfig.VpcId)
+		if vpcId != "" {
+			inventory.VpcId = report.Add("vpc", clusterName, vpcId)
+			inventory.Adopted = append(inventory.Adopted, vpcId)
+		} else {
+			report.Add("vpc", clusterName)
+		}
+
+		azInventory, err := c.adoptSubnets(report, vpcId, vpcConfig.SubnetIds)
+		if err != nil {
+			return nil, err
+		}
+		inventory.AvailabilityZones = azInventory
+		inventory.Adopted = append(inventory.Adopted, vpcConfig.SubnetIds...)
+
+		securityGroupId := aws.ToString(vpcConfig.ClusterSecurityGroupId)
+		if securityGroupId != "" {
+			inventory.SecurityGroupId = report.Add("security-group", clusterName, securityGroupId)
+			inventory.Adopted = append(inventory.Adopted, securityGroupId)
+		} else {
+			report.Add("security-group", clusterName)
+		}
+	}
+
+	// IAM Role for cluster
+	clusterRole, err := c.importRole(report, roleNameFromArn(aws.ToString(cluster.RoleArn)))
+	if err != nil {
+		return nil, err
+	}
+	inventory.ClusterRole = clusterRole
+	if clusterRole.RoleName != "" {
+		inventory.Adopted = append(inventory.Adopted, clusterRole.RoleName)
+	}
+
+	// Node Groups and the IAM Role for worker nodes
+	var nodeGroupArns []string
+	var nodeRoleArns []string
+	paginator := aws_eks.NewListNodegroupsPaginator(c.GetEksApi(), &aws_eks.ListNodegroupsInput{
+		ClusterName: &clusterName,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(c.Context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list node groups for cluster %s: %w", clusterName, err)
+		}
+		for _, name := range page.Nodegroups {
+			nodeGroup, err := c.getNodeGroup(clusterName, name)
+			switch {
+			case errors.Is(err, util.ErrResourceNotFound):
+				continue
+			case err != nil:
+				return nil, err
+			}
+			report.Add("eks-node-group", name, name)
+			inventory.NodeGroupNames = append(inventory.NodeGroupNames, name)
+			inventory.Adopted = append(inventory.Adopted, name)
+			nodeGroupArns = append(nodeGroupArns, aws.ToString(nodeGroup.NodegroupArn))
+			if nodeRoleArn := aws.ToString(nodeGroup.NodeRole); !slices.Contains(nodeRoleArns, nodeRoleArn) {
+				nodeRoleArns = append(nodeRoleArns, nodeRoleArn)
+			}
+		}
+	}
+	switch len(nodeRoleArns) {
+	case 0:
+	case 1:
+		workerRole, err := c.importRole(report, roleNameFromArn(nodeRoleArns[0]))
+		if err != nil {
+			return nil, err
+		}
+		inventory.WorkerRole = workerRole
+		if workerRole.RoleName != "" {
+			inventory.Adopted = append(inventory.Adopted, workerRole.RoleName)
+		}
+	default:
+		// the inventory has a single worker role
+		report.Add("iam-role", "node role", nodeRoleArns...)
+	}
+
+	// OIDC Provider
+	if oidcProviderUrl != "" {
+		var oidcProviderArns []string
+		oidcProviderArn, err := c.getOidcProviderArn(oidcProviderUrl)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			oidcProviderArns = append(oidcProviderArns, oidcProviderArn)
+		}
+		inventory.OidcProviderArn = report.Add("iam-oidc-provider", oidcProviderUrl, oidcProviderArns...)
+		if inventory.OidcProviderArn != "" {
+			inventory.Adopted = append(inventory.Adopted, inventory.OidcProviderArn)
+		}
+	}
+
+	// EBS CSI Addon - deleted along with the cluster
+	_, err = c.getAddon(clusterName, EbsStorageAddonName)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		report.Add("eks-addon", EbsStorageAddonName)
+	case err != nil:
+		return nil, err
+	default:
+		report.Add("eks-addon", EbsStorageAddonName, EbsStorageAddonName)
+		inventory.ClusterAddon = true
+	}
+
+	if applyTags {
+		if err := c.tagAdoptedResources(inventory, clusterName, tags, nodeGroupArns); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// adoptSubnets describes the subnets of an adopted cluster and returns them
+// grouped by availability zone.  A subnet is public if its route table, or
+// the VPC's main route table if it has none, routes to an internet gateway.
+func (c *EksClient) adoptSubnets(
+	report *discovery.Report,
+	vpcId string,
+	subnetIds []string,
+) ([]AvailabilityZoneInventory, error) {
+	if len(subnetIds) == 0 {
+		return nil, nil
+	}
+
+	svc := c.GetEc2Api()
+
+	subnetResp, err := svc.DescribeSubnets(c.Context, &aws_ec2.DescribeSubnetsInput{
+		SubnetIds: subnetIds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe subnets for adopted cluster: %w", err)
+	}
+	routeTableResp, err := svc.DescribeRouteTables(c.Context, &aws_ec2.DescribeRouteTablesInput{
+		Filters: []ec2_types.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: []string{vpcId},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe route tables in VPC %s: %w", vpcId, err)
+	}
+	publicSubnets := make(map[string]bool)
+	mainRouteTablePublic := false
+	for _, routeTable := range routeTableResp.RouteTables {
+		public := routesToInternetGateway(&routeTable)
+		for _, association := range routeTable.Associations {
+			if aws.ToBool(association.Main) {
+				mainRouteTablePublic = public
+			}
+			if association.SubnetId != nil {
+				publicSubnets[*association.SubnetId] = public
+			}
+		}
+	}
+
+	azInventories := make(map[string]*AvailabilityZoneInventory)
+	for _, subnet := range subnetResp.Subnets {
+		zone := aws.ToString(subnet.AvailabilityZone)
+		if azInventories[zone] == nil {
+			azInventories[zone] = &AvailabilityZoneInventory{Zone: zone}
+		}
+		azInventory := azInventories[zone]
+		subnetInventory := SubnetInventory{
+			SubnetId:   *subnet.SubnetId,
+			SubnetCidr: aws.ToString(subnet.CidrBlock),
+		}
+		subnetName := fmt.Sprintf("%s %s", zone, subnetInventory.SubnetCidr)
+		public, ok := publicSubnets[*subnet.SubnetId]
+		if !ok {
+			public = mainRouteTablePublic
+		}
+		if public {
+			report.Add("public-subnet", subnetName, subnetInventory.SubnetId)
+			azInventory.PublicSubnets = append(azInventory.PublicSubnets, subnetInventory)
+		} else {
+			report.Add("private-subnet", subnetName, subnetInventory.SubnetId)
+			azInventory.PrivateSubnets = append(azInventory.PrivateSubnets, subnetInventory)
+		}
+	}
+
+	zones := make([]string, 0, len(azInventories))
+	for zone := range azInventories {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	var azInventory []AvailabilityZoneInventory
+	for _, zone := range zones {
+		azInventory = append(azInventory, *azInventories[zone])
+	}
+
+	return azInventory, nil
+}
+
+// tagAdoptedResources adds the Name tag and custom tags to an adopted
+// cluster, its node groups and the VPC, subnets and security group in the
+// inventory.
+func (c *EksClient) tagAdoptedResources(
+	inventory *EksInventory,
+	name string,
+	tags map[string]string,
+	nodeGroupArns []string,
+) error {
+	mapTags := util.CreateMapTags(name, tags)
+	ec2Tags := ec2.CreateEc2Tags(name, tags)
+
+	for _, resourceArn := range append([]string{inventory.Cluster.ClusterArn}, nodeGroupArns...) {
+		if _, err := c.GetEksApi().TagResource(c.Context, &aws_eks.TagResourceInput{
+			ResourceArn: aws.String(resourceArn),
+			Tags:        mapTags,
+		}); err != nil {
+			return fmt.Errorf("failed to tag EKS resource %s: %w", resourceArn, err)
+		}
+	}
+
+	var resourceIds []string
+	if inventory.VpcId != "" {
+		resourceIds = append(resourceIds, inventory.VpcId)
+	}
+	for _, az := range inventory.AvailabilityZones {
+		for _, subnet := range slices.Concat(az.PublicSubnets, az.PrivateSubnets) {
+			resourceIds = append(resourceIds, subnet.SubnetId)
+		}
+	}
+	if inventory.SecurityGroupId != "" {
+		resourceIds = append(resourceIds, inventory.SecurityGroupId)
+	}
+	if len(resourceIds) == 0 {
+		return nil
+	}
+	if _, err := c.GetEc2Api().CreateTags(c.Context, &aws_ec2.CreateTagsInput{
+		Resources: resourceIds,
+		Tags:      *ec2Tags,
+	}); err != nil {
+		return fmt.Errorf("failed to tag resources %s: %w", resourceIds, err)
+	}
+
+	return nil
+}
+
+// keepAdopted returns true if the resource was adopted and adopted resources
+// are not being deleted, in which case an event is sent reporting the
+// resource is kept.
+func (c *EksClient) keepAdopted(inventory *EksInventory, kind string, id string) bool {
+	if c.DeleteAdopted || id == "" || !slices.Contains(inventory.Adopted, id) {
+		return false
+	}
+	c.sendEvent(kind, client.PhaseKeptAdopted, fmt.Sprintf("%s %s was adopted and is kept", kind, id), id)
+
+	return true
+}
+
+// withoutAdoptedSubnets returns a copy of the availability zone inventory
+// without the subnets that are kept because they were adopted.
+func (c *EksClient) withoutAdoptedSubnets(inventory *EksInventory) []AvailabilityZoneInventory {
+	var azInventory []AvailabilityZoneInventory
+	for _, az := range inventory.AvailabilityZones {
+		deletableAz := AvailabilityZoneInventory{
+			Zone:         az.Zone,
+			NatGatewayId: az.NatGatewayId,
+		}
+		for _, subnet := range az.PublicSubnets {
+			if !c.keepAdopted(inventory, "subnet", subnet.SubnetId) {
+				deletableAz.PublicSubnets = append(deletableAz.PublicSubnets, subnet)
+			}
+		}
+		for _, subnet := range az.PrivateSubnets {
+			if !c.keepAdopted(inventory, "subnet", subnet.SubnetId) {
+				deletableAz.PrivateSubnets = append(deletableAz.PrivateSubnets, subnet)
+			}
+		}
+		azInventory = append(azInventory, deletableAz)
+	}
+
+	return azInventory
+}
+
+// routesToInternetGateway returns true if the route table has a route to an
+// internet gateway.
+func routesToInternetGateway(routeTable *ec2_types.RouteTable) bool {
+	for _, route := range routeTable.Routes {
+		if strings.HasPrefix(aws.ToString(route.GatewayId), "igw-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// roleNameFromArn returns the name of an IAM role from its ARN, which may
+// include a path.
+func roleNameFromArn(roleArn string) string {
+	return roleArn[strings.LastIndex(roleArn, "/")+1:]
+}
@@ -0,0 +1,163 @@
+package eks
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+// resultIds returns the IDs of the found resources of a kind in the report.
+func resultIds(report *discovery.Report, kind string) []string {
+	var ids []string
+	for _, result := range report.Results {
+		if result.Kind == kind && result.Status == discovery.StatusFound {
+			ids = append(ids, result.Id)
+		}
+	}
+
+	return ids
+}
+
+func TestAdoptEksResourceStack(t *testing.T) {
+	backend := fake.NewBackend(fake.DefaultRegion)
+	network, err := backend.CreateNetwork("10.1.0.0/16", "10.1.0.0/20", "10.1.16.0/20")
+	if err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	cluster, err := backend.CreateCluster("adopted-0", network, "workers-a", "workers-b")
+	if err != nil {
+		t.Fatalf("failed to create cluster: %v", err)
+	}
+	eksClient := testClient(backend)
+
+	var inventory EksInventory
+	report, err := eksClient.AdoptEksResourceStack(cluster.Name, map[string]string{"Tier": "test"}, true, &inventory)
+	if err != nil {
+		t.Fatalf("failed to adopt resource stack: %v", err)
+	}
+
+	for kind, expected := range map[string][]string{
+		"vpc":            {network.VpcId},
+		"private-subnet": network.SubnetIds,
+		"iam-role": {
+			"arn:aws:iam::" + fake.DefaultAccountId + ":role/" + cluster.RoleName,
+			"arn:aws:iam::" + fake.DefaultAccountId + ":role/" + cluster.NodeRoleName,
+		},
+		"eks-node-group":    cluster.NodeGroupNames,
+		"iam-oidc-provider": {cluster.OidcProviderArn},
+	} {
+		if ids := resultIds(report, kind); !reflect.DeepEqual(ids, expected) {
+			t.Errorf("expected %s %v in report, got %v", kind, expected, ids)
+		}
+	}
+	if report.HasAmbiguity() {
+		t.Errorf("expected no ambiguous resources, got %s", report.Summary())
+	}
+	if inventory.Cluster.ClusterName != cluster.Name || inventory.VpcId != network.VpcId {
+		t.Errorf("expected cluster %s in VPC %s in inventory, got %+v", cluster.Name, network.VpcId, inventory)
+	}
+	if !reflect.DeepEqual(inventory.NodeGroupNames, cluster.NodeGroupNames) {
+		t.Errorf("expected node groups %v in inventory, got %v", cluster.NodeGroupNames, inventory.NodeGroupNames)
+	}
+	if subnetIds := subnetIds(inventory.AvailabilityZones); !reflect.DeepEqual(subnetIds, network.SubnetIds) {
+		t.Errorf("expected subnets %v in inventory, got %v", network.SubnetIds, subnetIds)
+	}
+	if inventory.OidcProviderArn != cluster.OidcProviderArn {
+		t.Errorf("expected OIDC provider %s in inventory, got %q", cluster.OidcProviderArn, inventory.OidcProviderArn)
+	}
+	for _, id := range slices.Concat(
+		[]string{cluster.Name, network.VpcId, cluster.RoleName, cluster.NodeRoleName, cluster.OidcProviderArn, inventory.SecurityGroupId},
+		network.SubnetIds,
+		cluster.NodeGroupNames,
+	) {
+		if !slices.Contains(inventory.Adopted, id) {
+			t.Errorf("expected %s to be adopted, got %v", id, inventory.Adopted)
+		}
+	}
+}
+
+func TestAdoptEksResourceStackWithoutVpc(t *testing.T) {
+	backend := fake.NewBackend(fake.DefaultRegion)
+	cluster, err := backend.CreateCluster("adopted-0", &fake.Network{})
+	if err != nil {
+		t.Fatalf("failed to create cluster: %v", err)
+	}
+	eksClient := testClient(backend)
+
+	var inventory EksInventory
+	report, err := eksClient.AdoptEksResourceStack(cluster.Name, nil, false, &inventory)
+	if err != nil {
+		t.Fatalf("failed to adopt resource stack: %v", err)
+	}
+	for _, result := range report.Results {
+		if result.Kind == "vpc" && result.Status != discovery.StatusNotFound {
+			t.Errorf("expected VPC to be reported as not found, got %+v", result)
+		}
+	}
+	if inventory.VpcId != "" {
+		t.Errorf("expected no VPC in inventory, got %q", inventory.VpcId)
+	}
+	if slices.Contains(inventory.Adopted, "") {
+		t.Errorf("expected no empty IDs to be adopted, got %v", inventory.Adopted)
+	}
+}
+
+func TestDeleteEksResourceStackKeepsAdopted(t *testing.T) {
+	testCases := []struct {
+		name          string
+		deleteAdopted bool
+	}{
+		{name: "adopted resources kept", deleteAdopted: false},
+		{name: "adopted resources deleted", deleteAdopted: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			backend := fake.NewBackend(fake.DefaultRegion)
+			network, err := backend.CreateNetwork("10.1.0.0/16", "10.1.0.0/20", "10.1.16.0/20")
+			if err != nil {
+				t.Fatalf("failed to create network: %v", err)
+			}
+			cluster, err := backend.CreateCluster("adopted-0", network, "workers-a", "workers-b")
+			if err != nil {
+				t.Fatalf("failed to create cluster: %v", err)
+			}
+			eksClient := testClient(backend)
+
+			var inventory EksInventory
+			if _, err := eksClient.AdoptEksResourceStack(cluster.Name, nil, false, &inventory); err != nil {
+				t.Fatalf("failed to adopt resource stack: %v", err)
+			}
+			adopted := backend.Resources()
+			adoptedInventory, err := inventory.Marshal()
+			if err != nil {
+				t.Fatalf("failed to marshal inventory: %v", err)
+			}
+
+			eksClient.DeleteAdopted = testCase.deleteAdopted
+			if err := eksClient.DeleteEksResourceStack(&inventory); err != nil {
+				t.Fatalf("failed to delete resource stack: %v", err)
+			}
+
+			if testCase.deleteAdopted {
+				if resources := backend.Resources(); len(resources) != 0 {
+					t.Errorf("expected adopted resources to be deleted, found %v", resources)
+				}
+				return
+			}
+			if resources := backend.Resources(); !reflect.DeepEqual(resources, adopted) {
+				t.Errorf("expected adopted resources %v to be kept, found %v", adopted, resources)
+			}
+			remainingInventory, err := inventory.Marshal()
+			if err != nil {
+				t.Fatalf("failed to marshal inventory: %v", err)
+			}
+			if string(remainingInventory) != string(adoptedInventory) {
+				t.Errorf("expected adopted resources to stay in inventory\nexpected: %s\ngot:      %s", adoptedInventory, remainingInventory)
+			}
+		})
+	}
+}
@@ -63,7 +63,7 @@ func (c *EksClient) SetAvailabilityZones(
 	// default to 2 availability zones if not specified
 	var desiredAZs int32
 	if desiredAzCount == 0 {
-		desiredAZs = 2
+		desiredAZs = DefaultAzCount
 	} else {
 		desiredAZs = desiredAzCount
 	}
@@ -88,7 +88,7 @@ func (c *EksClient) SetupAvailabilityZoneForRegion(
 	desiredAZs int32,
 	cidrBlocks []string,
 ) (*[]AvailabilityZoneInventory, error) {
-	svc := ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 	var availabilityZones []AvailabilityZoneInventory
 
 	filterName := "region-name"
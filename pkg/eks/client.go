@@ -15,6 +15,10 @@ type EksClient struct {
 	// A channel for latest version of resource inventory to be passed to client
 	// as resources are created and deleted.
 	InventoryChan *chan EksInventory
+
+	// A function used to retrieve the certificate thumbprint for the cluster's
+	// OIDC provider.  If nil, GetOidcThumbprint is used.
+	OidcThumbprintFunc func(providerUrl string) (string, error)
 }
 
 func (c *EksClient) GetMessageChan() *chan string {
@@ -28,3 +32,37 @@ func (c *EksClient) GetContext() context.Context {
 func (c *EksClient) GetAwsConfig() *aws.Config {
 	return c.AwsConfig
 }
+
+// sendEvent sends a progress event for resources in the EKS resource stack.
+// The message is the text rendering of the event.
+func (c *EksClient) sendEvent(kind string, phase client.Phase, message string, ids ...string) {
+	c.SendEvent(client.Event{
+		Stack:   "eks",
+		Kind:    kind,
+		Ids:     ids,
+		Phase:   phase,
+		Message: message,
+	})
+}
+
+// sendFailed sends a failed event for a resource in the EKS resource stack
+// and returns the error.
+func (c *EksClient) sendFailed(kind string, err error) error {
+	c.SendEvent(client.Event{
+		Stack: "eks",
+		Kind:  kind,
+		Phase: client.PhaseFailed,
+		Error: err.Error(),
+	})
+
+	return err
+}
+
+// sendInventory notifies the observer and sends on the inventory channel, if
+// present, the latest inventory.
+func (c *EksClient) sendInventory(inventory *EksInventory) {
+	c.SendInventory(*inventory)
+	if c.InventoryChan != nil {
+		*c.InventoryChan <- client.CopyInventory(*inventory)
+	}
+}
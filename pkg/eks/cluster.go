@@ -10,6 +10,7 @@ import (
 	"github.com/aws/smithy-go"
 
 	"github.com/nukleros/aws-builder/pkg/util"
+	"github.com/nukleros/aws-builder/pkg/waiter"
 )
 
 type ClusterCondition string
@@ -17,10 +18,17 @@ type ClusterCondition string
 const (
 	ClusterConditionCreated = "ClusterCreated"
 	ClusterConditionDeleted = "ClusterDeleted"
-	ClusterCheckInterval    = 15 //check cluster status every 15 seconds
-	ClusterCheckMaxCount    = 60 // check 60 times before giving up (15 minutes)
+	ClusterWaiter           = "cluster"
 )
 
+// DefaultClusterWaiter checks cluster status every 15 seconds for up to 15
+// minutes.
+var DefaultClusterWaiter = waiter.Config{
+	Timeout:  15 * time.Minute,
+	Interval: 15 * time.Second,
+	Backoff:  1,
+}
+
 // CreateCluster creates a new EKS Cluster.
 func (c *EksClient) CreateCluster(
 	tags *map[string]string,
@@ -29,7 +37,7 @@ func (c *EksClient) CreateCluster(
 	roleArn string,
 	azInventory *[]AvailabilityZoneInventory,
 ) (*types.Cluster, error) {
-	svc := aws_eks.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEksApi()
 
 	// collect subnet IDs
 	var subnetIds []string
@@ -87,7 +95,7 @@ func (c *EksClient) DeleteCluster(clusterName string) error {
 		return nil
 	}
 
-	svc := aws_eks.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEksApi()
 
 	deleteClusterInput := aws_eks.DeleteClusterInput{Name: &clusterName}
 	_, err := svc.DeleteCluster(c.Context, &deleteClusterInput)
@@ -117,38 +125,37 @@ func (c *EksClient) WaitForCluster(
 		return oicdIssuer, nil
 	}
 
-	clusterCheckCount := 0
-	for {
-		clusterCheckCount += 1
-		if clusterCheckCount > ClusterCheckMaxCount {
-			return oicdIssuer, errors.New("cluster condition check timed out")
-		}
-
-		cluster, err := c.getCluster(clusterName)
-		if err != nil {
-			if errors.Is(err, util.ErrResourceNotFound) && clusterCondition == ClusterConditionDeleted {
-				// resource was not found and we're waiting for it to be
-				// deleted so condition is met
-				break
-			} else {
-				return oicdIssuer, fmt.Errorf("failed to get cluster status while waiting for %s: %w", clusterName, err)
+	err := waiter.Wait(
+		c.Context,
+		c.Waiters.Get(ClusterWaiter, DefaultClusterWaiter),
+		fmt.Sprintf("cluster %s", clusterName),
+		func() (bool, string, error) {
+			cluster, err := c.getCluster(clusterName)
+			if err != nil {
+				if errors.Is(err, util.ErrResourceNotFound) && clusterCondition == ClusterConditionDeleted {
+					// resource was not found and we're waiting for it to be
+					// deleted so condition is met
+					return true, "", nil
+				}
+				return false, "", fmt.Errorf("failed to get cluster status while waiting for %s: %w", clusterName, err)
 			}
-		}
-		if cluster.Status == types.ClusterStatusActive && clusterCondition == ClusterConditionCreated {
-			// resource is available and we're waiting for it to be created
-			// so condition is met
-			oicdIssuer = *cluster.Identity.Oidc.Issuer
-			break
-		}
-		time.Sleep(time.Second * 15)
-	}
+			if cluster.Status == types.ClusterStatusActive && clusterCondition == ClusterConditionCreated {
+				// resource is available and we're waiting for it to be
+				// created so condition is met
+				oicdIssuer = *cluster.Identity.Oidc.Issuer
+				return true, "", nil
+			}
+
+			return false, string(cluster.Status), nil
+		},
+	)
 
-	return oicdIssuer, nil
+	return oicdIssuer, err
 }
 
 // getCluster retrieves the cluster for a given cluster name.
 func (c *EksClient) getCluster(clusterName string) (*types.Cluster, error) {
-	svc := aws_eks.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEksApi()
 
 	describeClusterInput := aws_eks.DescribeClusterInput{
 		Name: &clusterName,
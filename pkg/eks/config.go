@@ -1,13 +1,25 @@
 package eks
 
 import (
-	"fmt"
-	"io/ioutil"
-
-	"gopkg.in/yaml.v2"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/waiter"
 )
 
-const DefaultKubernetesVersion = "1.32"
+const (
+	DefaultKubernetesVersion = "1.32"
+
+	// DefaultAzCount is the number of availability zones used when none are
+	// provided in config.
+	DefaultAzCount = int32(2)
+
+	// DefaultStorageManagementServiceAccountName is the name of the service
+	// account used by the EBS CSI driver controller.
+	DefaultStorageManagementServiceAccountName = "ebs-csi-controller-sa"
+
+	// DefaultStorageManagementServiceAccountNamespace is the namespace the
+	// EBS CSI driver controller runs in.
+	DefaultStorageManagementServiceAccountNamespace = "kube-system"
+)
 
 // EksConfig contains the configuration options for an EKS cluster.
 type EksConfig struct {
@@ -33,6 +45,7 @@ type EksConfig struct {
 	ClusterAutoscalingServiceAccount ServiceAccountConfig     `yaml:"clusterAutoscalingServiceAccount"`
 	KeyPair                          string                   `yaml:"keyPair"`
 	Tags                             map[string]string        `yaml:"tags"`
+	Waiters                          waiter.Configs           `yaml:"waiters"`
 }
 
 // AvailabilityZone contains configuration options for an EKS cluster
@@ -51,19 +64,25 @@ type ServiceAccountConfig struct {
 	Namespace string `yaml:"namespace"`
 }
 
-// LoadEksConfig loads an EKS config from a config file and returns the
-// EksConfig object.
-func LoadEksConfig(configFile string) (*EksConfig, error) {
-	configYaml, err := ioutil.ReadFile(configFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-	var eksConfig EksConfig
-	if err := yaml.Unmarshal(configYaml, &eksConfig); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal yaml from config file: %w", err)
+// LoadEksConfig loads an EKS config from one or more YAML or JSON config
+// files and returns the EksConfig object.  Values in later files override
+// values in earlier files.  Values not set in any file are set to the defaults
+// from NewEksConfig and SetDefaults.  See configfile.Load for details.
+func LoadEksConfig(configFiles ...string) (*EksConfig, error) {
+	return LoadEksConfigWithOutputs(nil, configFiles...)
+}
+
+// LoadEksConfigWithOutputs loads an EKS config like LoadEksConfig and also
+// replaces ${stack.output} references with the outputs of other resource
+// stacks.  See configfile.LoadWithOutputs for details.
+func LoadEksConfigWithOutputs(outputs configfile.Outputs, configFiles ...string) (*EksConfig, error) {
+	eksConfig := NewEksConfig()
+	if err := configfile.LoadWithOutputs(eksConfig, outputs, configFiles...); err != nil {
+		return nil, err
 	}
+	eksConfig.SetDefaults()
 
-	return &eksConfig, nil
+	return eksConfig, nil
 }
 
 // NewEksConfig returns an EksConfig with default values set.
@@ -75,5 +94,46 @@ func NewEksConfig() *EksConfig {
 		InstanceTypes:     []string{"t3.micro"},
 		MinNodes:          int32(2),
 		MaxNodes:          int32(4),
+		StorageManagementServiceAccount: ServiceAccountConfig{
+			Name:      DefaultStorageManagementServiceAccountName,
+			Namespace: DefaultStorageManagementServiceAccountNamespace,
+		},
+	}
+}
+
+// SetDefaults sets defaults that depend on other values in the config:
+//   - initialNodes defaults to minNodes
+//   - desiredAzCount defaults to DefaultAzCount if no availability zones are
+//     provided
+//   - waiters default to the default waiter for each resource
+//
+// Values that are already set are not changed.
+func (c *EksConfig) SetDefaults() {
+	if c.InitialNodes == 0 {
+		c.InitialNodes = c.MinNodes
+	}
+	if c.DesiredAzCount == 0 && len(c.AvailabilityZones) == 0 {
+		c.DesiredAzCount = DefaultAzCount
+	}
+	c.Waiters = c.Waiters.Merge(defaultWaiters())
+}
+
+// GetRegion returns the region resources are created in.
+func (c *EksConfig) GetRegion() string {
+	return c.Region
+}
+
+// SetRegion sets the region resources are created in.
+func (c *EksConfig) SetRegion(region string) {
+	c.Region = region
+}
+
+// defaultWaiters returns the default waiter for each resource in an EKS
+// resource stack.
+func defaultWaiters() waiter.Configs {
+	return waiter.Configs{
+		ClusterWaiter:    DefaultClusterWaiter,
+		NatGatewayWaiter: DefaultNatGatewayWaiter,
+		NodeGroupWaiter:  DefaultNodeGroupWaiter,
 	}
 }
@@ -0,0 +1,99 @@
+package eks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nukleros/aws-builder/pkg/waiter"
+)
+
+func TestLoadEksConfigDefaults(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected func(resourceConfig *EksConfig)
+	}{
+		{
+			name:    "defaults",
+			content: "name: test-0\n",
+			expected: func(resourceConfig *EksConfig) {
+				resourceConfig.Name = "test-0"
+				resourceConfig.InitialNodes = 2
+				resourceConfig.DesiredAzCount = DefaultAzCount
+				resourceConfig.Waiters = defaultWaiters()
+			},
+		},
+		{
+			name: "file values",
+			content: `
+name: test-0
+kubernetesVersion: "1.30"
+clusterCidr: 10.1.0.0/16
+instanceTypes:
+  - m5.large
+minNodes: 0
+maxNodes: 8
+availabilityZones:
+  - zone: us-east-2a
+    privateSubnetCidr: 10.1.0.0/22
+    publicSubnetCidr: 10.1.4.0/22
+storageManagementServiceAccount:
+  name: ebs-csi
+waiters:
+  cluster:
+    timeout: 30m
+`,
+			expected: func(resourceConfig *EksConfig) {
+				resourceConfig.Name = "test-0"
+				resourceConfig.KubernetesVersion = "1.30"
+				resourceConfig.ClusterCidr = "10.1.0.0/16"
+				resourceConfig.InstanceTypes = []string{"m5.large"}
+				// zero values set in a file override defaults too
+				resourceConfig.MinNodes = 0
+				resourceConfig.MaxNodes = 8
+				resourceConfig.AvailabilityZones = []AvailabilityZoneConfig{
+					{Zone: "us-east-2a", PrivateSubnetCidr: "10.1.0.0/22", PublicSubnetCidr: "10.1.4.0/22"},
+				}
+				resourceConfig.StorageManagementServiceAccount.Name = "ebs-csi"
+				resourceConfig.Waiters = defaultWaiters()
+				resourceConfig.Waiters[ClusterWaiter] = waiter.Config{
+					Timeout:  30 * time.Minute,
+					Interval: DefaultClusterWaiter.Interval,
+					Backoff:  DefaultClusterWaiter.Backoff,
+				}
+			},
+		},
+		{
+			name:    "initial nodes",
+			content: "minNodes: 3\ninitialNodes: 4\ndesiredAzCount: 1\n",
+			expected: func(resourceConfig *EksConfig) {
+				resourceConfig.MinNodes = 3
+				resourceConfig.InitialNodes = 4
+				resourceConfig.DesiredAzCount = 1
+				resourceConfig.Waiters = defaultWaiters()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "eks-config.yaml")
+			if err := os.WriteFile(configFile, []byte(testCase.content), 0644); err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+
+			resourceConfig, err := LoadEksConfig(configFile)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			expected := NewEksConfig()
+			testCase.expected(expected)
+			if !reflect.DeepEqual(resourceConfig, expected) {
+				t.Errorf("expected config\n%+v\ngot\n%+v", expected, resourceConfig)
+			}
+		})
+	}
+}
@@ -8,17 +8,14 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
-	aws_v1 "github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/sts"
-	"sigs.k8s.io/aws-iam-authenticator/pkg/token"
 )
 
 // EksClusterConnectionInfo contains the information needed to connect to an EKS
-// cluster.
+// cluster.  If StsEndpoint is set, tokens are presigned for it instead of the
+// regional STS endpoint.
 type EksClusterConnectionInfo struct {
 	ClusterName     string
+	StsEndpoint     string
 	APIEndpoint     string
 	CACertificate   string
 	Token           string
@@ -38,42 +35,6 @@ func (c *EksClusterConnectionInfo) Get(awsConfig *aws.Config) error {
 		return fmt.Errorf("failed to describe EKS cluster: %w", err)
 	}
 
-	// construct a config object for the earlier v1 version of AWS SDK
-	creds, err := awsConfig.Credentials.Retrieve(context.Background())
-	if err != nil {
-		return fmt.Errorf("failed to retrieve credentials from AWS config: %w", err)
-	}
-	v1Config := aws_v1.Config{
-		Region: aws_v1.String(awsConfig.Region),
-		Credentials: credentials.NewStaticCredentials(
-			creds.AccessKeyID,
-			creds.SecretAccessKey,
-			creds.SessionToken,
-		),
-	}
-
-	// create a new session using the v1 SDK which is used by
-	// sigs.k8s.io/aws-iam-authenticator/pkg/token to get a token
-	sessionOpts := session.Options{
-		Config:            v1Config,
-		SharedConfigState: session.SharedConfigEnable,
-	}
-	awsSession, err := session.NewSessionWithOptions(sessionOpts)
-	if err != nil {
-		return fmt.Errorf("failed to create new AWS session for generating EKS cluster token: %w", err)
-	}
-	stsApi := sts.New(awsSession)
-
-	// create a new token generator
-	gen, err := token.NewGenerator(true, false)
-	if err != nil {
-		return fmt.Errorf("failed to generate new token: %w", err)
-	}
-	// get EKS cluster token
-	tkn, err := gen.GetWithSTS(c.ClusterName, stsApi)
-	if err != nil {
-		return fmt.Errorf("failed to get token with options: %w", err)
-	}
 	ca, err := base64.StdEncoding.DecodeString(*eksCluster.Cluster.CertificateAuthority.Data)
 	if err != nil {
 		return fmt.Errorf("failed to decode CA data: %w", err)
@@ -82,8 +43,20 @@ func (c *EksClusterConnectionInfo) Get(awsConfig *aws.Config) error {
 	// update EKSClusterConnectionInfo object
 	c.APIEndpoint = *eksCluster.Cluster.Endpoint
 	c.CACertificate = string(ca)
-	c.Token = tkn.Token
-	c.TokenExpiration = tkn.Expiration.UTC()
+
+	return c.GetToken(awsConfig)
+}
+
+// GetToken retrieves a token for a given EKS cluster by name without
+// describing the cluster.  Only the token and its expiration are set.
+func (c *EksClusterConnectionInfo) GetToken(awsConfig *aws.Config) error {
+	generator := NewTokenGenerator(awsConfig, c.StsEndpoint)
+	token, expiration, err := generator.Token(context.Background(), c.ClusterName)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	c.Token = token
+	c.TokenExpiration = expiration.UTC()
 
 	return nil
 }
@@ -0,0 +1,112 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ExecCredentialApiVersion is the client authentication API version used by
+// exec credential plugins.
+const ExecCredentialApiVersion = "client.authentication.k8s.io/v1"
+
+// Kubeconfig returns a kubeconfig with a cluster, user and context named
+// contextName for the EKS cluster and sets it as the current context.  If
+// exec is nil the token is embedded in the kubeconfig and it can only be used
+// until the token expires.  Otherwise the exec credential plugin is used to
+// get a token each time one is needed.
+func (c *EksClusterConnectionInfo) Kubeconfig(
+	contextName string,
+	exec *clientcmdapi.ExecConfig,
+) *clientcmdapi.Config {
+	kubeconfig := clientcmdapi.NewConfig()
+
+	cluster := clientcmdapi.NewCluster()
+	cluster.Server = c.APIEndpoint
+	cluster.CertificateAuthorityData = []byte(c.CACertificate)
+	kubeconfig.Clusters[contextName] = cluster
+
+	user := clientcmdapi.NewAuthInfo()
+	if exec != nil {
+		user.Exec = exec
+	} else {
+		user.Token = c.Token
+	}
+	kubeconfig.AuthInfos[contextName] = user
+
+	kubeContext := clientcmdapi.NewContext()
+	kubeContext.Cluster = contextName
+	kubeContext.AuthInfo = contextName
+	kubeconfig.Contexts[contextName] = kubeContext
+	kubeconfig.CurrentContext = contextName
+
+	return kubeconfig
+}
+
+// ExecCredential returns an ExecCredential with the token and its expiration
+// for an exec credential plugin to print.
+func (c *EksClusterConnectionInfo) ExecCredential() *clientauthenticationv1.ExecCredential {
+	expiration := metav1.NewTime(c.TokenExpiration)
+
+	return &clientauthenticationv1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: ExecCredentialApiVersion,
+			Kind:       "ExecCredential",
+		},
+		Status: &clientauthenticationv1.ExecCredentialStatus{
+			Token:               c.Token,
+			ExpirationTimestamp: &expiration,
+		},
+	}
+}
+
+// TokenExecConfig returns an exec credential plugin config that runs command
+// with args to get a token for an EKS cluster.  The command must print an
+// ExecCredential with the token, e.g. "aws-builder token".  The plugin may
+// prompt for input, such as an MFA token code, when kubectl is run in a
+// terminal.
+func TokenExecConfig(command string, args ...string) *clientcmdapi.ExecConfig {
+	return &clientcmdapi.ExecConfig{
+		APIVersion:      ExecCredentialApiVersion,
+		Command:         command,
+		Args:            args,
+		InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+	}
+}
+
+// MergeKubeconfig merges a kubeconfig into the kubeconfig file, which is
+// created if it doesn't exist.  Clusters, users and contexts with the same
+// names as those in kubeconfig are replaced and others are kept.  The current
+// context is only changed if setCurrentContext is true.
+func MergeKubeconfig(kubeconfigFile string, kubeconfig *clientcmdapi.Config, setCurrentContext bool) error {
+	existing, err := clientcmd.LoadFromFile(kubeconfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		existing = clientcmdapi.NewConfig()
+	} else if err != nil {
+		return fmt.Errorf("failed to load kubeconfig %s: %w", kubeconfigFile, err)
+	}
+
+	for name, cluster := range kubeconfig.Clusters {
+		existing.Clusters[name] = cluster
+	}
+	for name, user := range kubeconfig.AuthInfos {
+		existing.AuthInfos[name] = user
+	}
+	for name, kubeContext := range kubeconfig.Contexts {
+		existing.Contexts[name] = kubeContext
+	}
+	if setCurrentContext || existing.CurrentContext == "" {
+		existing.CurrentContext = kubeconfig.CurrentContext
+	}
+
+	if err := clientcmd.WriteToFile(*existing, kubeconfigFile); err != nil {
+		return fmt.Errorf("failed to write kubeconfig %s: %w", kubeconfigFile, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package connection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// testConnectionInfo returns connection info for a cluster with the API
+// endpoint.
+func testConnectionInfo(apiEndpoint string) *EksClusterConnectionInfo {
+	return &EksClusterConnectionInfo{
+		ClusterName:   "test-0",
+		APIEndpoint:   apiEndpoint,
+		CACertificate: "test-ca",
+		Token:         TokenPrefix + "test-token",
+	}
+}
+
+func TestKubeconfig(t *testing.T) {
+	connectionInfo := testConnectionInfo("https://test-0.eks.amazonaws.com")
+
+	kubeconfig := connectionInfo.Kubeconfig("test-context", nil)
+	if kubeconfig.CurrentContext != "test-context" {
+		t.Errorf("expected current context test-context, got %q", kubeconfig.CurrentContext)
+	}
+	kubeContext := kubeconfig.Contexts["test-context"]
+	if kubeContext == nil || kubeContext.Cluster != "test-context" || kubeContext.AuthInfo != "test-context" {
+		t.Fatalf("expected context for test-context cluster and user, got %+v", kubeContext)
+	}
+	cluster := kubeconfig.Clusters["test-context"]
+	if cluster.Server != connectionInfo.APIEndpoint || string(cluster.CertificateAuthorityData) != connectionInfo.CACertificate {
+		t.Errorf("expected cluster for %s, got %+v", connectionInfo.APIEndpoint, cluster)
+	}
+	if user := kubeconfig.AuthInfos["test-context"]; user.Token != connectionInfo.Token || user.Exec != nil {
+		t.Errorf("expected embedded token, got %+v", user)
+	}
+
+	exec := TokenExecConfig("aws-builder", "token", "--cluster-name", "test-0")
+	kubeconfig = connectionInfo.Kubeconfig("test-context", exec)
+	if user := kubeconfig.AuthInfos["test-context"]; user.Token != "" || user.Exec != exec {
+		t.Errorf("expected exec credential plugin without a token, got %+v", user)
+	}
+}
+
+func TestMergeKubeconfig(t *testing.T) {
+	kubeconfigFile := filepath.Join(t.TempDir(), "config")
+
+	// an existing kubeconfig with a context for another cluster
+	other := clientcmdapi.NewConfig()
+	other.Clusters["other"] = &clientcmdapi.Cluster{Server: "https://other.example.com"}
+	other.AuthInfos["other"] = &clientcmdapi.AuthInfo{Token: "other-token"}
+	other.Contexts["other"] = &clientcmdapi.Context{Cluster: "other", AuthInfo: "other", Namespace: "apps"}
+	other.CurrentContext = "other"
+	if err := clientcmd.WriteToFile(*other, kubeconfigFile); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	testCases := []struct {
+		name              string
+		apiEndpoint       string
+		setCurrentContext bool
+		currentContext    string
+	}{
+		{
+			name:           "context added",
+			apiEndpoint:    "https://test-0.eks.amazonaws.com",
+			currentContext: "other",
+		},
+		{
+			name:              "context replaced",
+			apiEndpoint:       "https://test-0-new.eks.amazonaws.com",
+			setCurrentContext: true,
+			currentContext:    "test-0",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			kubeconfig := testConnectionInfo(testCase.apiEndpoint).Kubeconfig("test-0", nil)
+			if err := MergeKubeconfig(kubeconfigFile, kubeconfig, testCase.setCurrentContext); err != nil {
+				t.Fatalf("failed to merge kubeconfig: %v", err)
+			}
+
+			merged, err := clientcmd.LoadFromFile(kubeconfigFile)
+			if err != nil {
+				t.Fatalf("failed to load merged kubeconfig: %v", err)
+			}
+			if merged.CurrentContext != testCase.currentContext {
+				t.Errorf("expected current context %s, got %s", testCase.currentContext, merged.CurrentContext)
+			}
+			if len(merged.Contexts) != 2 || len(merged.Clusters) != 2 || len(merged.AuthInfos) != 2 {
+				t.Errorf("expected the other and test-0 contexts, got contexts %v", merged.Contexts)
+			}
+			if server := merged.Clusters["test-0"].Server; server != testCase.apiEndpoint {
+				t.Errorf("expected test-0 server %s, got %s", testCase.apiEndpoint, server)
+			}
+
+			// the other cluster, user and context are kept unchanged
+			if server := merged.Clusters["other"].Server; server != other.Clusters["other"].Server {
+				t.Errorf("expected other cluster to be kept, got server %s", server)
+			}
+			if token := merged.AuthInfos["other"].Token; token != other.AuthInfos["other"].Token {
+				t.Errorf("expected other user to be kept, got token %s", token)
+			}
+			kubeContext := merged.Contexts["other"]
+			if kubeContext.Cluster != "other" || kubeContext.AuthInfo != "other" || kubeContext.Namespace != "apps" {
+				t.Errorf("expected other context to be kept, got %+v", kubeContext)
+			}
+		})
+	}
+}
+
+func TestMergeKubeconfigNewFile(t *testing.T) {
+	kubeconfigFile := filepath.Join(t.TempDir(), ".kube", "config")
+
+	kubeconfig := testConnectionInfo("https://test-0.eks.amazonaws.com").Kubeconfig("test-0", nil)
+	if err := MergeKubeconfig(kubeconfigFile, kubeconfig, false); err != nil {
+		t.Fatalf("failed to merge kubeconfig: %v", err)
+	}
+
+	merged, err := clientcmd.LoadFromFile(kubeconfigFile)
+	if err != nil {
+		t.Fatalf("failed to load merged kubeconfig: %v", err)
+	}
+	// the only context is current even if not requested
+	if merged.CurrentContext != "test-0" {
+		t.Errorf("expected current context test-0, got %q", merged.CurrentContext)
+	}
+	if contexts := merged.Contexts; len(contexts) != 1 || contexts["test-0"] == nil {
+		t.Errorf("expected only the test-0 context, got %v", contexts)
+	}
+	info, err := os.Stat(kubeconfigFile)
+	if err != nil {
+		t.Fatalf("failed to stat kubeconfig: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected kubeconfig to only be readable by its owner, got %v", mode)
+	}
+}
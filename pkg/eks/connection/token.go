@@ -0,0 +1,126 @@
+package connection
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	// TokenPrefix is the prefix of EKS cluster tokens.  The rest of the token
+	// is a presigned STS GetCallerIdentity URL encoded as unpadded URL-safe
+	// base64.
+	TokenPrefix = "k8s-aws-v1."
+
+	// clusterIdHeader is the signed header that binds a token to a cluster.
+	clusterIdHeader = "x-k8s-aws-id"
+
+	// presignedUrlExpiration is how long STS accepts a presigned
+	// GetCallerIdentity URL for after it is signed, regardless of
+	// X-Amz-Expires.
+	presignedUrlExpiration = 15 * time.Minute
+
+	// presignedUrlExpires is the X-Amz-Expires value in seconds.  It is
+	// ignored by STS but authenticators 0.3.0 and older require a value
+	// between 0 and 60.
+	presignedUrlExpires = "60"
+
+	// amzDateFormat is the format of the X-Amz-Date query parameter.
+	amzDateFormat = "20060102T150405Z"
+)
+
+// Presigner presigns STS GetCallerIdentity requests.  It is implemented by
+// sts.PresignClient and can be replaced with a fake.
+type Presigner interface {
+	PresignGetCallerIdentity(
+		ctx context.Context,
+		params *sts.GetCallerIdentityInput,
+		optFns ...func(*sts.PresignOptions),
+	) (*v4.PresignedHTTPRequest, error)
+}
+
+// TokenGenerator generates tokens for EKS clusters from presigned STS
+// GetCallerIdentity requests.  The EKS cluster's authenticator calls the
+// presigned URL to find the IAM identity the token belongs to.
+type TokenGenerator struct {
+	Presigner Presigner
+}
+
+// NewTokenGenerator returns a token generator that presigns requests with the
+// credentials in the AWS config, which are refreshed as needed.  Requests are
+// presigned for the regional STS endpoint unless stsEndpoint is provided,
+// e.g. https://sts.us-east-2.amazonaws.com or a VPC endpoint URL.
+func NewTokenGenerator(awsConfig *aws.Config, stsEndpoint string) *TokenGenerator {
+	stsClient := sts.NewFromConfig(*awsConfig, func(o *sts.Options) {
+		if stsEndpoint != "" {
+			o.BaseEndpoint = aws.String(stsEndpoint)
+		}
+	})
+
+	return &TokenGenerator{Presigner: sts.NewPresignClient(stsClient)}
+}
+
+// Token returns a token for the EKS cluster and the time it expires.  Tokens
+// expire a minute before STS stops accepting the presigned URL.
+func (g *TokenGenerator) Token(ctx context.Context, clusterName string) (string, time.Time, error) {
+	presigned, err := g.Presigner.PresignGetCallerIdentity(
+		ctx,
+		&sts.GetCallerIdentityInput{},
+		func(o *sts.PresignOptions) {
+			o.ClientOptions = append(o.ClientOptions, func(o *sts.Options) {
+				o.APIOptions = append(o.APIOptions, addTokenRequestParams(clusterName))
+			})
+		},
+	)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to presign STS GetCallerIdentity request: %w", err)
+	}
+
+	presignedUrl, err := url.Parse(presigned.URL)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse presigned URL: %w", err)
+	}
+	signingTime, err := time.Parse(amzDateFormat, presignedUrl.Query().Get("X-Amz-Date"))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse X-Amz-Date of presigned URL: %w", err)
+	}
+
+	token := TokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(presigned.URL))
+	expiration := signingTime.Add(presignedUrlExpiration - time.Minute)
+
+	return token, expiration, nil
+}
+
+// addTokenRequestParams returns an API option that adds the cluster ID header
+// and X-Amz-Expires parameter to the request before it is presigned.
+func addTokenRequestParams(clusterName string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Build.Add(middleware.BuildMiddlewareFunc(
+			"AddEksTokenRequestParams",
+			func(
+				ctx context.Context,
+				in middleware.BuildInput,
+				next middleware.BuildHandler,
+			) (middleware.BuildOutput, middleware.Metadata, error) {
+				request, ok := in.Request.(*smithyhttp.Request)
+				if !ok {
+					return middleware.BuildOutput{}, middleware.Metadata{}, fmt.Errorf("unexpected request type %T", in.Request)
+				}
+				request.Header.Set(clusterIdHeader, clusterName)
+				query := request.URL.Query()
+				query.Set("X-Amz-Expires", presignedUrlExpires)
+				request.URL.RawQuery = query.Encode()
+
+				return next.HandleBuild(ctx, in)
+			},
+		), middleware.After)
+	}
+}
@@ -0,0 +1,145 @@
+package connection
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// fakePresigner returns a fixed presigned URL or error.
+type fakePresigner struct {
+	url string
+	err error
+}
+
+func (p *fakePresigner) PresignGetCallerIdentity(
+	ctx context.Context,
+	params *sts.GetCallerIdentityInput,
+	optFns ...func(*sts.PresignOptions),
+) (*v4.PresignedHTTPRequest, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return &v4.PresignedHTTPRequest{URL: p.url, Method: "GET"}, nil
+}
+
+// decodeToken returns the presigned URL in a token.
+func decodeToken(t *testing.T, token string) string {
+	t.Helper()
+
+	encoded, ok := strings.CutPrefix(token, TokenPrefix)
+	if !ok {
+		t.Fatalf("expected token to start with %s, got %s", TokenPrefix, token)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("expected token to be unpadded URL-safe base64: %v", err)
+	}
+
+	return string(decoded)
+}
+
+func TestToken(t *testing.T) {
+	// the URL is encoded with "+" and padding by standard base64
+	presignedUrl := "https://sts.us-east-2.amazonaws.com/?Action=GetCallerIdentity&Version=2011-06-15" +
+		"&X-Amz-Date=20240102T030405Z&X-Amz-Expires=60&X-Amz-Signature=c0ffee~~?"
+	generator := TokenGenerator{Presigner: &fakePresigner{url: presignedUrl}}
+
+	token, expiration, err := generator.Token(context.Background(), "test-cluster")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("expected URL-safe unpadded token, got %s", token)
+	}
+	if decoded := decodeToken(t, token); decoded != presignedUrl {
+		t.Errorf("expected token to contain presigned URL %s, got %s", presignedUrl, decoded)
+	}
+	expected := time.Date(2024, 1, 2, 3, 18, 5, 0, time.UTC)
+	if !expiration.Equal(expected) {
+		t.Errorf("expected expiration %s, 14 minutes after X-Amz-Date, got %s", expected, expiration)
+	}
+}
+
+func TestTokenErrors(t *testing.T) {
+	presignErr := errors.New("no credentials")
+	cases := map[string]Presigner{
+		"presign error":    &fakePresigner{err: presignErr},
+		"invalid URL":      &fakePresigner{url: "https://sts.amazonaws.com/%zz"},
+		"missing date":     &fakePresigner{url: "https://sts.amazonaws.com/?Action=GetCallerIdentity"},
+		"malformed date":   &fakePresigner{url: "https://sts.amazonaws.com/?X-Amz-Date=2024-01-02"},
+		"date not in UTC":  &fakePresigner{url: "https://sts.amazonaws.com/?X-Amz-Date=20240102T030405"},
+		"empty presigning": &fakePresigner{url: ""},
+	}
+	for name, presigner := range cases {
+		t.Run(name, func(t *testing.T) {
+			generator := TokenGenerator{Presigner: presigner}
+			token, _, err := generator.Token(context.Background(), "test-cluster")
+			if err == nil {
+				t.Fatalf("expected error, got token %s", token)
+			}
+			if token != "" {
+				t.Errorf("expected no token on error, got %s", token)
+			}
+		})
+	}
+
+	generator := TokenGenerator{Presigner: &fakePresigner{err: presignErr}}
+	if _, _, err := generator.Token(context.Background(), "test-cluster"); !errors.Is(err, presignErr) {
+		t.Errorf("expected presign error to be wrapped, got %v", err)
+	}
+}
+
+func TestNewTokenGenerator(t *testing.T) {
+	awsConfig := aws.Config{
+		Region:      "us-east-2",
+		Credentials: credentials.NewStaticCredentialsProvider("AKIAEXAMPLE", "secret", ""),
+	}
+	generator := NewTokenGenerator(&awsConfig, "")
+
+	before := time.Now().UTC().Truncate(time.Second)
+	token, expiration, err := generator.Token(context.Background(), "test-cluster")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	presignedUrl, err := url.Parse(decodeToken(t, token))
+	if err != nil {
+		t.Fatalf("failed to parse presigned URL: %v", err)
+	}
+	if presignedUrl.Host != "sts.us-east-2.amazonaws.com" {
+		t.Errorf("expected regional STS endpoint, got %s", presignedUrl.Host)
+	}
+	query := presignedUrl.Query()
+	if query.Get("Action") != "GetCallerIdentity" {
+		t.Errorf("expected GetCallerIdentity action, got %s", query.Get("Action"))
+	}
+	if query.Get("X-Amz-Expires") != presignedUrlExpires {
+		t.Errorf("expected X-Amz-Expires %s, got %s", presignedUrlExpires, query.Get("X-Amz-Expires"))
+	}
+	if !strings.Contains(query.Get("X-Amz-SignedHeaders"), clusterIdHeader) {
+		t.Errorf("expected %s to be signed, got %s", clusterIdHeader, query.Get("X-Amz-SignedHeaders"))
+	}
+	if expiration.Before(before.Add(14*time.Minute)) || expiration.After(time.Now().Add(14*time.Minute)) {
+		t.Errorf("expected expiration 14 minutes from now, got %s", expiration)
+	}
+
+	generator = NewTokenGenerator(&awsConfig, "https://vpce-example.sts.us-east-2.vpce.amazonaws.com")
+	token, _, err = generator.Token(context.Background(), "test-cluster")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if decoded := decodeToken(t, token); !strings.HasPrefix(decoded, "https://vpce-example.sts.us-east-2.vpce.amazonaws.com/") {
+		t.Errorf("expected presigned URL for STS endpoint, got %s", decoded)
+	}
+}
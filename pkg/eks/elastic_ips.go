@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	"github.com/aws/smithy-go"
 
 	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreateElasticIps allocates elastic IP addresses for use by NAT gateways.
@@ -17,24 +18,16 @@ func (c *EksClient) CreateElasticIps(
 	tags *[]types.Tag,
 	azInventory *[]AvailabilityZoneInventory,
 ) ([]string, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	var elasticIpIds []string
 
 	// in order to uniquely reference EIPs created for an EKS cluster, we
 	// add an ElasticIpRef tag with auto-incrementing IDs
-	eipRefTagKey := "ElasticIpRef"
 	eipRefTagValue := 1
 	for _, az := range *azInventory {
 		for _, _ = range az.PublicSubnets {
-			eipTags := *tags
-			tk := eipRefTagKey
-			tv := strconv.Itoa(eipRefTagValue)
-			eipRefTag := types.Tag{
-				Key:   &tk,
-				Value: &tv,
-			}
-			eipTags = append(eipTags, eipRefTag)
+			eipTags := elasticIpTags(tags, eipRefTagValue)
 			eipRefTagValue++
 
 			// because elastic IPs don't have unique names we have to check for
@@ -69,33 +62,40 @@ func (c *EksClient) CreateElasticIps(
 }
 
 // DeleteElasticIps releases elastic IP addresses.  If no IDs are supplied, or
-// if the address IDs are not found it exits without error.
+// if the address IDs are not found it exits without error.  Every address is
+// attempted and an error joining each failure is returned.
 func (c *EksClient) DeleteElasticIps(elasticIpIds []string) error {
-	// if elasticIpiDs are empty, there's nothing to delete
-	if len(elasticIpIds) == 0 {
-		return nil
-	}
+	_, err := c.deleteElasticIps(elasticIpIds)
+
+	return err
+}
 
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+// deleteElasticIps releases elastic IP addresses and returns the IDs of those
+// released or not found.  Every address is attempted and an error joining
+// each failure is returned.
+func (c *EksClient) deleteElasticIps(elasticIpIds []string) ([]string, error) {
+	svc := c.GetEc2Api()
 
+	var deletedElasticIpIds []string
+	var errs []error
 	for _, elasticIpId := range elasticIpIds {
 		deleteElasticIpInput := aws_ec2.ReleaseAddressInput{AllocationId: &elasticIpId}
 		_, err := svc.ReleaseAddress(c.Context, &deleteElasticIpInput)
-		if err != nil {
-			var ae smithy.APIError
-			if errors.As(err, &ae) {
-				if ae.ErrorCode() == "InvalidAllocationID.NotFound" {
-					// attempting to delete a elastic IP that doesn't exist so return
-					// without error
-					return nil
-				} else {
-					return fmt.Errorf("failed to delete elastic IP with ID %s: %w", elasticIpId, err)
-				}
-			} else {
-				return fmt.Errorf("failed to delete elastic IP with ID %s: %w", elasticIpId, err)
-			}
+		if err != nil && !util.HasErrorCode(err, "InvalidAllocationID.NotFound") {
+			errs = append(errs, fmt.Errorf("failed to delete elastic IP with ID %s: %w", elasticIpId, err))
+			continue
 		}
+		deletedElasticIpIds = append(deletedElasticIpIds, elasticIpId)
 	}
 
-	return nil
+	return deletedElasticIpIds, errors.Join(errs...)
+}
+
+// elasticIpTags returns a copy of the resource stack tags with an ElasticIpRef
+// tag added so each elastic IP can be uniquely referenced.
+func elasticIpTags(tags *[]types.Tag, ref int) []types.Tag {
+	return append(append([]types.Tag{}, *tags...), types.Tag{
+		Key:   aws.String("ElasticIpRef"),
+		Value: aws.String(strconv.Itoa(ref)),
+	})
 }
@@ -0,0 +1,409 @@
+package eks
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	aws_eks "github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iam_types "github.com/aws/aws-sdk-go-v2/service/iam/types"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/orphan"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// OrphansEksResourceStack searches for resources of the EKS resource stack
+// that are not in the inventory, such as elastic IPs and NAT gateways left
+// behind by a failed create.  EC2 resources are found by the resource stack's
+// tags, IAM roles by name, IAM policies by the cluster's IAM path and the
+// cluster, its node groups and OIDC provider by the cluster name.  The
+// security group and addon of an orphaned cluster are deleted with it so are
+// not reported.  Only read-only calls are made.
+func (c *EksClient) OrphansEksResourceStack(
+	resourceConfig *EksConfig,
+	inventory *EksInventory,
+	broad bool,
+) (*orphan.Report, error) {
+	// resource config region takes precedence
+	// if not set, use the region defined in AWS config
+	region := resourceConfig.Region
+	if region != "" {
+		c.AwsConfig.Region = region
+	} else {
+		region = c.AwsConfig.Region
+	}
+
+	report := orphan.NewReport("eks", region, broad)
+
+	if err := c.orphanNetwork(report, inventory, resourceConfig); err != nil {
+		return nil, err
+	}
+	if err := c.orphanIam(report, inventory, resourceConfig); err != nil {
+		return nil, err
+	}
+	if err := c.orphanCluster(report, inventory, resourceConfig); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// orphanNetwork searches for VPCs, internet gateways, subnets, elastic IPs,
+// NAT gateways and route tables with the resource stack's tags.
+func (c *EksClient) orphanNetwork(
+	report *orphan.Report,
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) error {
+	svc := c.GetEc2Api()
+	filters := tagFilters(*ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags))
+
+	// VPCs
+	vpcResp, err := svc.DescribeVpcs(c.Context, &aws_ec2.DescribeVpcsInput{Filters: filters})
+	if err != nil {
+		return fmt.Errorf("failed to describe VPCs to find orphans: %w", err)
+	}
+	for _, vpc := range vpcResp.Vpcs {
+		if *vpc.VpcId != inventory.VpcId {
+			report.Add("vpc", *vpc.VpcId, "")
+		}
+	}
+
+	// Internet Gateways - the attached VPC is needed to detach them
+	igwResp, err := svc.DescribeInternetGateways(c.Context, &aws_ec2.DescribeInternetGatewaysInput{Filters: filters})
+	if err != nil {
+		return fmt.Errorf("failed to describe internet gateways to find orphans: %w", err)
+	}
+	for _, igw := range igwResp.InternetGateways {
+		if *igw.InternetGatewayId == inventory.InternetGatewayId {
+			continue
+		}
+		var vpcId string
+		if len(igw.Attachments) > 0 {
+			vpcId = aws.ToString(igw.Attachments[0].VpcId)
+		}
+		report.Add("internet-gateway", *igw.InternetGatewayId, vpcId)
+	}
+
+	// Subnets
+	var subnetIds []string
+	var natGatewayIds []string
+	for _, az := range inventory.AvailabilityZones {
+		for _, subnet := range slices.Concat(az.PublicSubnets, az.PrivateSubnets) {
+			subnetIds = append(subnetIds, subnet.SubnetId)
+		}
+		natGatewayIds = append(natGatewayIds, az.NatGatewayId)
+	}
+	subnetResp, err := svc.DescribeSubnets(c.Context, &aws_ec2.DescribeSubnetsInput{Filters: filters})
+	if err != nil {
+		return fmt.Errorf("failed to describe subnets to find orphans: %w", err)
+	}
+	for _, subnet := range subnetResp.Subnets {
+		if !slices.Contains(subnetIds, *subnet.SubnetId) {
+			report.Add("subnet", *subnet.SubnetId, aws.ToString(subnet.VpcId))
+		}
+	}
+
+	// Elastic IPs
+	eipResp, err := svc.DescribeAddresses(c.Context, &aws_ec2.DescribeAddressesInput{Filters: filters})
+	if err != nil {
+		return fmt.Errorf("failed to describe elastic IPs to find orphans: %w", err)
+	}
+	for _, address := range eipResp.Addresses {
+		if !slices.Contains(inventory.ElasticIpIds, *address.AllocationId) {
+			report.Add("elastic-ip", *address.AllocationId, "")
+		}
+	}
+
+	// NAT Gateways - those already deleted are still described for a while
+	natResp, err := svc.DescribeNatGateways(c.Context, &aws_ec2.DescribeNatGatewaysInput{Filter: filters})
+	if err != nil {
+		return fmt.Errorf("failed to describe NAT gateways to find orphans: %w", err)
+	}
+	for _, natGateway := range natResp.NatGateways {
+		if natGateway.State != ec2_types.NatGatewayStatePending &&
+			natGateway.State != ec2_types.NatGatewayStateAvailable {
+			continue
+		}
+		if !slices.Contains(natGatewayIds, *natGateway.NatGatewayId) {
+			report.Add("nat-gateway", *natGateway.NatGatewayId, aws.ToString(natGateway.VpcId))
+		}
+	}
+
+	// Route Tables
+	routeTableIds := append([]string{inventory.PublicRouteTableId}, inventory.PrivateRouteTableIds...)
+	rtResp, err := svc.DescribeRouteTables(c.Context, &aws_ec2.DescribeRouteTablesInput{Filters: filters})
+	if err != nil {
+		return fmt.Errorf("failed to describe route tables to find orphans: %w", err)
+	}
+	for _, routeTable := range rtResp.RouteTables {
+		if !slices.Contains(routeTableIds, *routeTable.RouteTableId) {
+			report.Add("route-table", *routeTable.RouteTableId, aws.ToString(routeTable.VpcId))
+		}
+	}
+
+	return nil
+}
+
+// orphanIam searches for the IAM roles the resource stack creates by name
+// and for IAM policies under the cluster's IAM path.
+func (c *EksClient) orphanIam(
+	report *orphan.Report,
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) error {
+	// IAM Roles
+	var inventoryRoleNames []string
+	for _, role := range []RoleInventory{
+		inventory.ClusterRole,
+		inventory.WorkerRole,
+		inventory.DnsManagementRole,
+		inventory.Dns01ChallengeRole,
+		inventory.SecretsManagerRole,
+		inventory.ClusterAutoscalingRole,
+		inventory.StorageManagementRole,
+	} {
+		inventoryRoleNames = append(inventoryRoleNames, role.RoleName)
+	}
+	for _, rolePrefix := range []string{
+		ClusterRoleName,
+		WorkerRoleName,
+		DnsManagementRoleName,
+		Dns01ChallengeRoleName,
+		SecretsManagerRoleName,
+		ClusterAutoscalingRoleName,
+		StorageManagementRoleName,
+	} {
+		roleName := fmt.Sprintf("%s-%s", rolePrefix, resourceConfig.Name)
+		_, err := c.getRole(roleName)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+			continue
+		case err != nil:
+			return err
+		}
+		if !slices.Contains(inventoryRoleNames, roleName) {
+			report.Add("iam-role", roleName, "")
+		}
+	}
+
+	// IAM Policies
+	policyPath := fmt.Sprintf("/%s/", resourceConfig.Name)
+	resp, err := c.GetIamApi().ListPolicies(c.Context, &iam.ListPoliciesInput{
+		PathPrefix: &policyPath,
+		Scope:      iam_types.PolicyScopeTypeLocal,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list policies under path %s to find orphans: %w", policyPath, err)
+	}
+	for _, policy := range resp.Policies {
+		if !slices.Contains(inventory.PolicyArns, *policy.Arn) {
+			report.Add("iam-policy", *policy.Arn, "")
+		}
+	}
+
+	return nil
+}
+
+// orphanCluster searches for the EKS cluster by name, its node groups and its
+// OIDC provider.
+func (c *EksClient) orphanCluster(
+	report *orphan.Report,
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) error {
+	clusterName := resourceConfig.Name
+
+	// EKS Cluster
+	cluster, err := c.getCluster(clusterName)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	if inventory.Cluster.ClusterName != clusterName {
+		report.Add("eks-cluster", clusterName, "")
+	}
+
+	// Node Groups
+	paginator := aws_eks.NewListNodegroupsPaginator(c.GetEksApi(), &aws_eks.ListNodegroupsInput{
+		ClusterName: &clusterName,
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(c.Context)
+		if err != nil {
+			return fmt.Errorf("failed to list node groups for cluster %s to find orphans: %w", clusterName, err)
+		}
+		for _, nodeGroupName := range resp.Nodegroups {
+			if !slices.Contains(inventory.NodeGroupNames, nodeGroupName) {
+				report.Add("eks-node-group", nodeGroupName, clusterName)
+			}
+		}
+	}
+
+	// OIDC Provider
+	if cluster.Identity == nil || cluster.Identity.Oidc == nil || cluster.Identity.Oidc.Issuer == nil {
+		return nil
+	}
+	oidcProviderArn, err := c.getOidcProviderArn(*cluster.Identity.Oidc.Issuer)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	if oidcProviderArn != inventory.OidcProviderArn {
+		report.Add("iam-oidc-provider", oidcProviderArn, clusterName)
+	}
+
+	return nil
+}
+
+// DeleteEksOrphans deletes the orphans in the report in the same order the
+// EKS resource stack is deleted, waiting for node groups, clusters and NAT
+// gateways to be deleted before the resources they depend on.  Every orphan
+// is attempted even if others fail, although an orphan is likely to fail if
+// one it depends on could not be deleted.
+func (c *EksClient) DeleteEksOrphans(report *orphan.Report) error {
+	c.AwsConfig.Region = report.Region
+
+	// OIDC Providers
+	for _, o := range report.Kind("iam-oidc-provider") {
+		c.deleteOrphan(o, c.DeleteOidcProvider(o.Id))
+	}
+
+	// Node Groups - grouped by cluster to wait for them to be deleted
+	deletingNodeGroups := map[string][]*orphan.Orphan{}
+	for _, o := range report.Kind("eks-node-group") {
+		if err := c.DeleteNodeGroups(o.Parent, []string{o.Id}); err != nil {
+			c.deleteOrphan(o, err)
+			continue
+		}
+		deletingNodeGroups[o.Parent] = append(deletingNodeGroups[o.Parent], o)
+	}
+	for _, clusterName := range slices.Sorted(maps.Keys(deletingNodeGroups)) {
+		var nodeGroupNames []string
+		for _, o := range deletingNodeGroups[clusterName] {
+			nodeGroupNames = append(nodeGroupNames, o.Id)
+		}
+		err := c.WaitForNodeGroups(clusterName, nodeGroupNames, NodeGroupConditionDeleted)
+		for _, o := range deletingNodeGroups[clusterName] {
+			c.deleteOrphan(o, err)
+		}
+	}
+
+	// EKS Clusters
+	for _, o := range report.Kind("eks-cluster") {
+		if err := c.DeleteCluster(o.Id); err != nil {
+			c.deleteOrphan(o, err)
+			continue
+		}
+		_, err := c.WaitForCluster(o.Id, ClusterConditionDeleted)
+		c.deleteOrphan(o, err)
+	}
+
+	// IAM Roles - attached policies must be detached first
+	for _, o := range report.Kind("iam-role") {
+		resp, err := c.GetIamApi().ListAttachedRolePolicies(c.Context, &iam.ListAttachedRolePoliciesInput{
+			RoleName: &o.Id,
+		})
+		if err != nil {
+			c.deleteOrphan(o, fmt.Errorf("failed to list policies for role %s: %w", o.Id, err))
+			continue
+		}
+		role := RoleInventory{RoleName: o.Id}
+		for _, policy := range resp.AttachedPolicies {
+			role.RolePolicyArns = append(role.RolePolicyArns, *policy.PolicyArn)
+		}
+		c.deleteOrphan(o, c.DeleteRoles(&[]RoleInventory{role}))
+	}
+
+	// IAM Policies
+	for _, o := range report.Kind("iam-policy") {
+		_, err := c.DeletePolicies([]string{o.Id})
+		c.deleteOrphan(o, err)
+	}
+
+	// NAT Gateways - elastic IPs and subnets can't be deleted until the NAT
+	// gateways using them are
+	var deletingNatGateways []*orphan.Orphan
+	var natGatewayIds []string
+	for _, o := range report.Kind("nat-gateway") {
+		if _, err := c.DeleteNatGateways(&[]AvailabilityZoneInventory{{NatGatewayId: o.Id}}); err != nil {
+			c.deleteOrphan(o, err)
+			continue
+		}
+		deletingNatGateways = append(deletingNatGateways, o)
+		natGatewayIds = append(natGatewayIds, o.Id)
+	}
+	err := c.waitForNatGatewaysDeleted(natGatewayIds)
+	for _, o := range deletingNatGateways {
+		c.deleteOrphan(o, err)
+	}
+
+	// Internet Gateways
+	for _, o := range report.Kind("internet-gateway") {
+		c.deleteOrphan(o, c.deleteOrphanInternetGateway(o.Id, o.Parent))
+	}
+
+	// Elastic IPs
+	for _, o := range report.Kind("elastic-ip") {
+		c.deleteOrphan(o, c.DeleteElasticIps([]string{o.Id}))
+	}
+
+	// Subnets
+	for _, o := range report.Kind("subnet") {
+		_, _, err := c.DeleteSubnets(&[]AvailabilityZoneInventory{
+			{PrivateSubnets: []SubnetInventory{{SubnetId: o.Id}}},
+		})
+		c.deleteOrphan(o, err)
+	}
+
+	// Route Tables
+	for _, o := range report.Kind("route-table") {
+		c.deleteOrphan(o, c.DeleteRouteTables([]string{o.Id}, ""))
+	}
+
+	// VPCs
+	for _, o := range report.Kind("vpc") {
+		c.deleteOrphan(o, c.DeleteVpc(o.Id))
+	}
+
+	return report.Err()
+}
+
+// deleteOrphan records the result of deleting an orphan in the report and
+// sends an event for it.
+func (c *EksClient) deleteOrphan(o *orphan.Orphan, err error) {
+	if err != nil {
+		c.sendFailed(o.Kind, o.Failed(err))
+		return
+	}
+	o.Deleted()
+	c.sendEvent(o.Kind, client.PhaseDeleted, fmt.Sprintf("orphaned %s %s deleted", o.Kind, o.Id), o.Id)
+}
+
+// deleteOrphanInternetGateway deletes an internet gateway, detaching it from
+// the VPC first if it is attached to one.
+func (c *EksClient) deleteOrphanInternetGateway(internetGatewayId, vpcId string) error {
+	if vpcId != "" {
+		return c.DeleteInternetGateway(internetGatewayId, vpcId)
+	}
+
+	_, err := c.GetEc2Api().DeleteInternetGateway(c.Context, &aws_ec2.DeleteInternetGatewayInput{
+		InternetGatewayId: &internetGatewayId,
+	})
+	if err != nil && !util.HasErrorCode(err, "InvalidInternetGatewayID.NotFound") {
+		return fmt.Errorf("failed to delete internet gateway with ID %s: %w", internetGatewayId, err)
+	}
+
+	return nil
+}
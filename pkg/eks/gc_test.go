@@ -0,0 +1,163 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/fake"
+	"github.com/nukleros/aws-builder/pkg/orphan"
+)
+
+func TestOrphansEksResourceStack(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+	apis := backend.Apis()
+	ctx := context.Background()
+
+	var inventory EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+
+	// an elastic IP, NAT gateway and IAM policy left behind by a failed
+	// create have the resource stack's tags and IAM path
+	ec2Tags := *ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags)
+	address, err := apis.Ec2.AllocateAddress(ctx, &aws_ec2.AllocateAddressInput{
+		Domain: ec2_types.DomainTypeVpc,
+		TagSpecifications: []ec2_types.TagSpecification{
+			{ResourceType: ec2_types.ResourceTypeElasticIp, Tags: ec2Tags},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to allocate elastic IP: %v", err)
+	}
+	natGateway, err := apis.Ec2.CreateNatGateway(ctx, &aws_ec2.CreateNatGatewayInput{
+		AllocationId: address.AllocationId,
+		SubnetId:     aws.String(inventory.AvailabilityZones[0].PublicSubnets[0].SubnetId),
+		TagSpecifications: []ec2_types.TagSpecification{
+			{ResourceType: ec2_types.ResourceTypeNatgateway, Tags: ec2Tags},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create NAT gateway: %v", err)
+	}
+	policy, err := apis.Iam.CreatePolicy(ctx, &iam.CreatePolicyInput{
+		PolicyName:     aws.String("leftover-policy"),
+		Path:           aws.String(fmt.Sprintf("/%s/", resourceConfig.Name)),
+		PolicyDocument: aws.String(`{"Version": "2012-10-17", "Statement": []}`),
+	})
+	if err != nil {
+		t.Fatalf("failed to create policy: %v", err)
+	}
+	before := backend.Resources()
+
+	report, err := eksClient.OrphansEksResourceStack(resourceConfig, &inventory, false)
+	if err != nil {
+		t.Fatalf("failed to find orphans: %v", err)
+	}
+	expected := map[string]struct {
+		id          string
+		monthlyCost float64
+	}{
+		"elastic-ip":  {*address.AllocationId, 3.65},
+		"nat-gateway": {*natGateway.NatGateway.NatGatewayId, 32.85},
+		"iam-policy":  {*policy.Policy.Arn, 0},
+	}
+	if len(report.Orphans) != len(expected) {
+		t.Fatalf("expected only the leftover resources to be orphans, got %+v", report.Orphans)
+	}
+	for _, o := range report.Orphans {
+		if o.Id != expected[o.Kind].id {
+			t.Errorf("expected %s orphan %s, got %s", o.Kind, expected[o.Kind].id, o.Id)
+		}
+		if math.Abs(o.MonthlyCost-expected[o.Kind].monthlyCost) > 0.001 {
+			t.Errorf("expected %s orphan to cost %.2f a month, got %.2f", o.Kind, expected[o.Kind].monthlyCost, o.MonthlyCost)
+		}
+	}
+	if resources := backend.Resources(); !reflect.DeepEqual(resources, before) {
+		t.Errorf("expected searching for orphans to change nothing, found %v", resources)
+	}
+
+	if err := eksClient.DeleteEksOrphans(report); err != nil {
+		t.Fatalf("failed to delete orphans: %v", err)
+	}
+	if count := report.Count(orphan.StatusDeleted); count != len(expected) {
+		t.Errorf("expected %d orphans to be deleted, got %d", len(expected), count)
+	}
+	resources := backend.Resources()
+	for kind, orphan := range expected {
+		for _, id := range resources[kind] {
+			if id == orphan.id {
+				t.Errorf("expected %s %s to be deleted", kind, id)
+			}
+		}
+	}
+	if len(resources["nat-gateway"]) != len(inventory.AvailabilityZones) || len(resources["eks-cluster"]) != 1 {
+		t.Errorf("expected inventory resources to remain, found %v", resources)
+	}
+}
+
+func TestDeleteEksOrphans(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+
+	var inventory EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+
+	// without an inventory every resource of the stack is an orphan
+	report, err := eksClient.OrphansEksResourceStack(resourceConfig, &EksInventory{}, true)
+	if err != nil {
+		t.Fatalf("failed to find orphans: %v", err)
+	}
+	for _, kind := range []string{"vpc", "subnet", "nat-gateway", "elastic-ip", "eks-cluster", "eks-node-group", "iam-role", "iam-oidc-provider"} {
+		if len(report.Kind(kind)) == 0 {
+			t.Errorf("expected %s orphans to be reported", kind)
+		}
+	}
+	remainingPolicyArn := report.Kind("iam-policy")[0].Id
+	remainingElasticIpId := report.Kind("elastic-ip")[0].Id
+	backend.FailNext("DeletePolicy", &smithy.GenericAPIError{Code: "ServiceFailure", Message: "policy failure"})
+	backend.FailNext("ReleaseAddress", &smithy.GenericAPIError{Code: "InternalError", Message: "address failure"})
+
+	// the cluster, NAT gateways and everything else in the VPC must be
+	// deleted before the resources they depend on for the VPC to be deleted
+	err = eksClient.DeleteEksOrphans(report)
+	if err == nil {
+		t.Fatal("expected deleting orphans to fail")
+	}
+	for _, message := range []string{
+		"iam-policy " + remainingPolicyArn,
+		"policy failure",
+		"elastic-ip " + remainingElasticIpId,
+		"address failure",
+	} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("expected error to contain %q, got %v", message, err)
+		}
+	}
+	if count := report.Count(orphan.StatusFailed); count != 2 {
+		t.Errorf("expected 2 orphans to fail, got %d", count)
+	}
+	expected := map[string][]string{
+		"iam-policy": {remainingPolicyArn},
+		"elastic-ip": {remainingElasticIpId},
+	}
+	if resources := backend.Resources(); !reflect.DeepEqual(resources, expected) {
+		t.Errorf("expected only the failed orphans to remain, found %v", resources)
+	}
+}
@@ -0,0 +1,492 @@
+package eks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	aws_eks "github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// ImportEksResourceStack rebuilds an EKS inventory from existing resources.
+// Each resource is searched for by the tags or name CreateEksResourceStack
+// would adopt it by given the same config, and is added to the inventory if
+// exactly one match is found.  Resources with more than one match are
+// reported as ambiguous and left out of the inventory so they can be resolved
+// manually.  Only read-only calls are made.
+func (c *EksClient) ImportEksResourceStack(
+	resourceConfig *EksConfig,
+	inventory *EksInventory,
+) (*discovery.Report, error) {
+	// resource config region takes precedence
+	// if not set, use the region defined in AWS config
+	region := resourceConfig.Region
+	if region != "" {
+		c.AwsConfig.Region = region
+	} else {
+		region = c.AwsConfig.Region
+	}
+	inventory.Region = region
+
+	report := discovery.NewReport("eks", region)
+
+	if err := c.importNetwork(report, inventory, resourceConfig); err != nil {
+		return nil, err
+	}
+	if err := c.importIam(report, inventory, resourceConfig); err != nil {
+		return nil, err
+	}
+	if err := c.importCluster(report, inventory, resourceConfig); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// importNetwork searches for the VPC, internet gateway, subnets, elastic IPs,
+// NAT gateways and route tables.
+func (c *EksClient) importNetwork(
+	report *discovery.Report,
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) error {
+	svc := c.GetEc2Api()
+
+	// Tags
+	ec2Tags := ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags)
+
+	// Availability Zones - subnets are searched for in the zones and CIDR
+	// blocks the config would use
+	azInventory, err := c.SetAvailabilityZones(
+		c.AwsConfig.Region,
+		resourceConfig.DesiredAzCount,
+		&resourceConfig.AvailabilityZones,
+	)
+	if err != nil {
+		return err
+	}
+	inventory.AvailabilityZones = *azInventory
+
+	// VPC
+	vpcResp, err := svc.DescribeVpcs(c.Context, &aws_ec2.DescribeVpcsInput{
+		Filters: tagFilters(*ec2Tags),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe VPCs to import: %w", err)
+	}
+	var vpcIds []string
+	for _, vpc := range vpcResp.Vpcs {
+		vpcIds = append(vpcIds, *vpc.VpcId)
+	}
+	inventory.VpcId = report.Add("vpc", resourceConfig.Name, vpcIds...)
+
+	// Internet Gateway
+	igwResp, err := svc.DescribeInternetGateways(c.Context, &aws_ec2.DescribeInternetGatewaysInput{
+		Filters: tagFilters(*ec2Tags),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe internet gateways to import: %w", err)
+	}
+	var igwIds []string
+	for _, igw := range igwResp.InternetGateways {
+		igwIds = append(igwIds, *igw.InternetGatewayId)
+	}
+	inventory.InternetGatewayId = report.Add("internet-gateway", resourceConfig.Name, igwIds...)
+
+	// Subnets
+	publicTags := publicSubnetTags(ec2Tags, resourceConfig.Name)
+	privateTags := privateSubnetTags(ec2Tags, resourceConfig.Name)
+	publicSubnetCount := 0
+	for azIdx, az := range inventory.AvailabilityZones {
+		for subnetIdx, subnet := range az.PublicSubnets {
+			subnetId, err := c.importSubnet(report, "public-subnet", publicTags, az.Zone, subnet.SubnetCidr)
+			if err != nil {
+				return err
+			}
+			inventory.AvailabilityZones[azIdx].PublicSubnets[subnetIdx].SubnetId = subnetId
+			publicSubnetCount++
+		}
+		for subnetIdx, subnet := range az.PrivateSubnets {
+			subnetId, err := c.importSubnet(report, "private-subnet", privateTags, az.Zone, subnet.SubnetCidr)
+			if err != nil {
+				return err
+			}
+			inventory.AvailabilityZones[azIdx].PrivateSubnets[subnetIdx].SubnetId = subnetId
+		}
+	}
+
+	// Elastic IPs - one for each public subnet with an ElasticIpRef tag
+	for eipRefTagValue := 1; eipRefTagValue <= publicSubnetCount; eipRefTagValue++ {
+		eipTags := elasticIpTags(ec2Tags, eipRefTagValue)
+		resp, err := svc.DescribeAddresses(c.Context, &aws_ec2.DescribeAddressesInput{
+			Filters: tagFilters(eipTags),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to describe elastic IPs to import: %w", err)
+		}
+		var elasticIpIds []string
+		for _, address := range resp.Addresses {
+			elasticIpIds = append(elasticIpIds, *address.AllocationId)
+		}
+		eipName := fmt.Sprintf("ElasticIpRef=%d", eipRefTagValue)
+		if elasticIpId := report.Add("elastic-ip", eipName, elasticIpIds...); elasticIpId != "" {
+			inventory.ElasticIpIds = append(inventory.ElasticIpIds, elasticIpId)
+		}
+	}
+
+	// NAT Gateways - one in the public subnet of each availability zone
+	for azIdx, az := range inventory.AvailabilityZones {
+		var natGatewayIds []string
+		for _, subnet := range az.PublicSubnets {
+			if subnet.SubnetId == "" {
+				continue
+			}
+			resp, err := svc.DescribeNatGateways(c.Context, &aws_ec2.DescribeNatGatewaysInput{
+				Filter: append(tagFilters(*ec2Tags), ec2_types.Filter{
+					Name:   aws.String("subnet-id"),
+					Values: []string{subnet.SubnetId},
+				}),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to describe NAT gateways to import: %w", err)
+			}
+			for _, natGateway := range resp.NatGateways {
+				if natGateway.State == ec2_types.NatGatewayStatePending ||
+					natGateway.State == ec2_types.NatGatewayStateAvailable {
+					natGatewayIds = append(natGatewayIds, *natGateway.NatGatewayId)
+				}
+			}
+		}
+		inventory.AvailabilityZones[azIdx].NatGatewayId = report.Add("nat-gateway", az.Zone, natGatewayIds...)
+	}
+
+	// Public Route Table
+	publicRtTags := publicRouteTableTags(ec2Tags)
+	publicRouteTableId, err := c.importRouteTable(report, "public-route-table", "PublicRouteTableRef=1", publicRtTags)
+	if err != nil {
+		return err
+	}
+	inventory.PublicRouteTableId = publicRouteTableId
+
+	// Private Route Tables - one for each private subnet with a
+	// PrivateRouteTableRef tag
+	privateRtRefTagValue := 1
+	for _, az := range inventory.AvailabilityZones {
+		for range az.PrivateSubnets {
+			privateRtTags := privateRouteTableTags(ec2Tags, privateRtRefTagValue)
+			routeTableName := fmt.Sprintf("PrivateRouteTableRef=%d", privateRtRefTagValue)
+			privateRtRefTagValue++
+			routeTableId, err := c.importRouteTable(report, "private-route-table", routeTableName, privateRtTags)
+			if err != nil {
+				return err
+			}
+			if routeTableId != "" {
+				inventory.PrivateRouteTableIds = append(inventory.PrivateRouteTableIds, routeTableId)
+			}
+		}
+	}
+
+	return nil
+}
+
+// importSubnet searches for a subnet with the tags and CIDR block in the
+// availability zone and returns its ID if exactly one is found.
+func (c *EksClient) importSubnet(
+	report *discovery.Report,
+	kind string,
+	tags []ec2_types.Tag,
+	zone string,
+	cidrBlock string,
+) (string, error) {
+	svc := c.GetEc2Api()
+
+	resp, err := svc.DescribeSubnets(c.Context, &aws_ec2.DescribeSubnetsInput{
+		Filters: append(tagFilters(tags),
+			ec2_types.Filter{
+				Name:   aws.String("availability-zone"),
+				Values: []string{zone},
+			},
+			ec2_types.Filter{
+				Name:   aws.String("cidr-block"),
+				Values: []string{cidrBlock},
+			},
+		),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe subnets to import: %w", err)
+	}
+	var subnetIds []string
+	for _, subnet := range resp.Subnets {
+		subnetIds = append(subnetIds, *subnet.SubnetId)
+	}
+
+	return report.Add(kind, fmt.Sprintf("%s %s", zone, cidrBlock), subnetIds...), nil
+}
+
+// importRouteTable searches for a route table with the tags and returns its
+// ID if exactly one is found.
+func (c *EksClient) importRouteTable(
+	report *discovery.Report,
+	kind string,
+	name string,
+	tags []ec2_types.Tag,
+) (string, error) {
+	routeTables, _, err := ec2.CheckUniqueTagsForRouteTables(c, &tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for unique tags on route tables: %w", err)
+	}
+	var routeTableIds []string
+	if routeTables != nil {
+		for _, routeTable := range *routeTables {
+			routeTableIds = append(routeTableIds, *routeTable.RouteTableId)
+		}
+	}
+
+	return report.Add(kind, name, routeTableIds...), nil
+}
+
+// importIam searches for the IAM roles for the cluster, worker nodes and
+// storage management, and the IAM policies and roles for requested optional
+// features.  Roles are found by name and policies by name under the cluster
+// path.
+func (c *EksClient) importIam(
+	report *discovery.Report,
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) error {
+	// IAM Roles for cluster and worker nodes
+	clusterRole, err := c.importRole(report, fmt.Sprintf("%s-%s", ClusterRoleName, resourceConfig.Name))
+	if err != nil {
+		return err
+	}
+	inventory.ClusterRole = clusterRole
+	workerRole, err := c.importRole(report, fmt.Sprintf("%s-%s", WorkerRoleName, resourceConfig.Name))
+	if err != nil {
+		return err
+	}
+	inventory.WorkerRole = workerRole
+
+	// IAM Policies and Roles for optional features
+	optionalRoles := []struct {
+		requested     bool
+		policyName    string
+		roleName      string
+		roleInventory *RoleInventory
+	}{
+		{resourceConfig.DnsManagement, DnsPolicyName, DnsManagementRoleName, &inventory.DnsManagementRole},
+		{resourceConfig.Dns01Challenge, Dns01ChallengePolicyName, Dns01ChallengeRoleName, &inventory.Dns01ChallengeRole},
+		{resourceConfig.SecretsManager, SecretsManagerPolicyName, SecretsManagerRoleName, &inventory.SecretsManagerRole},
+		{resourceConfig.ClusterAutoscaling, AutoscalingPolicyName, ClusterAutoscalingRoleName, &inventory.ClusterAutoscalingRole},
+	}
+	policyPath := fmt.Sprintf("/%s/", resourceConfig.Name)
+	for _, optionalRole := range optionalRoles {
+		if !optionalRole.requested {
+			continue
+		}
+
+		policyName := fmt.Sprintf("%s-%s", optionalRole.policyName, resourceConfig.Name)
+		var policyArns []string
+		policy, err := c.getPolicy(policyName, policyPath)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+		case err != nil:
+			return err
+		default:
+			policyArns = append(policyArns, *policy.Arn)
+		}
+		policyArn := report.Add("iam-policy", policyName, policyArns...)
+
+		roleInventory, err := c.importRole(report, fmt.Sprintf("%s-%s", optionalRole.roleName, resourceConfig.Name))
+		if err != nil {
+			return err
+		}
+		// a policy without its role is recorded the same way create records
+		// it before the role is created
+		if policyArn != "" {
+			inventory.PolicyArns = append(inventory.PolicyArns, policyArn)
+			if roleInventory.RoleName == "" {
+				roleInventory.RolePolicyArns = []string{policyArn}
+			}
+		}
+		*optionalRole.roleInventory = roleInventory
+	}
+
+	// IAM Role for Storage Management
+	storageManagementRole, err := c.importRole(report, fmt.Sprintf("%s-%s", StorageManagementRoleName, resourceConfig.Name))
+	if err != nil {
+		return err
+	}
+	inventory.StorageManagementRole = storageManagementRole
+
+	return nil
+}
+
+// importRole searches for an IAM role by name and returns its inventory with
+// the policies attached to it if found.
+func (c *EksClient) importRole(report *discovery.Report, roleName string) (RoleInventory, error) {
+	svc := c.GetIamApi()
+
+	role, err := c.getRole(roleName)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		report.Add("iam-role", roleName)
+		return RoleInventory{}, nil
+	case err != nil:
+		return RoleInventory{}, err
+	}
+	report.Add("iam-role", roleName, *role.Arn)
+
+	resp, err := svc.ListAttachedRolePolicies(c.Context, &iam.ListAttachedRolePoliciesInput{
+		RoleName: &roleName,
+	})
+	if err != nil {
+		return RoleInventory{}, fmt.Errorf("failed to list policies for role %s: %w", roleName, err)
+	}
+	var policyArns []string
+	for _, policy := range resp.AttachedPolicies {
+		policyArns = append(policyArns, *policy.PolicyArn)
+	}
+
+	return RoleInventory{
+		RoleName:       *role.RoleName,
+		RoleArn:        *role.Arn,
+		RolePolicyArns: policyArns,
+	}, nil
+}
+
+// importCluster searches for the EKS cluster by name and, if it exists, its
+// security group, node groups, OIDC provider and addon.
+func (c *EksClient) importCluster(
+	report *discovery.Report,
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) error {
+	clusterName := resourceConfig.Name
+	nodeGroupName := fmt.Sprintf("%s-private-node-group", clusterName)
+
+	// EKS Cluster
+	cluster, err := c.getCluster(clusterName)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		report.Add("eks-cluster", clusterName)
+		report.Add("security-group", clusterName)
+		report.Add("eks-node-group", nodeGroupName)
+		report.Add("iam-oidc-provider", "")
+		report.Add("eks-addon", EbsStorageAddonName)
+		return nil
+	case err != nil:
+		return err
+	}
+	report.Add("eks-cluster", clusterName, *cluster.Arn)
+	inventory.Cluster.ClusterName = *cluster.Name
+	inventory.Cluster.ClusterArn = *cluster.Arn
+
+	// the OIDC provider URL is only recorded once the cluster is active so
+	// create waits for a cluster that is still being created
+	var oidcProviderUrl string
+	if cluster.Identity != nil && cluster.Identity.Oidc != nil && cluster.Identity.Oidc.Issuer != nil {
+		oidcProviderUrl = *cluster.Identity.Oidc.Issuer
+	}
+	if cluster.Status == types.ClusterStatusActive {
+		inventory.Cluster.OidcProviderUrl = oidcProviderUrl
+	}
+
+	// EKS Cluster Security Group - created by AWS along with the cluster
+	sgResp, err := c.GetEc2Api().DescribeSecurityGroups(c.Context, &aws_ec2.DescribeSecurityGroupsInput{
+		Filters: []ec2_types.Filter{
+			{
+				Name:   aws.String("tag:aws:eks:cluster-name"),
+				Values: []string{clusterName},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe security groups filtered by cluster name %s: %w", clusterName, err)
+	}
+	var securityGroupIds []string
+	for _, securityGroup := range sgResp.SecurityGroups {
+		securityGroupIds = append(securityGroupIds, *securityGroup.GroupId)
+	}
+	inventory.SecurityGroupId = report.Add("security-group", clusterName, securityGroupIds...)
+
+	// Node Groups - every node group in the cluster with the resource stack's
+	// Name tag
+	var nodeGroupNames []string
+	paginator := aws_eks.NewListNodegroupsPaginator(c.GetEksApi(), &aws_eks.ListNodegroupsInput{
+		ClusterName: &clusterName,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(c.Context)
+		if err != nil {
+			return fmt.Errorf("failed to list node groups for cluster %s: %w", clusterName, err)
+		}
+		for _, name := range page.Nodegroups {
+			nodeGroup, err := c.getNodeGroup(clusterName, name)
+			switch {
+			case errors.Is(err, util.ErrResourceNotFound):
+				continue
+			case err != nil:
+				return err
+			}
+			if nodeGroup.Tags["Name"] == resourceConfig.Name {
+				nodeGroupNames = append(nodeGroupNames, name)
+			}
+		}
+	}
+	if len(nodeGroupNames) == 0 {
+		report.Add("eks-node-group", nodeGroupName)
+	}
+	for _, name := range nodeGroupNames {
+		report.Add("eks-node-group", name, name)
+	}
+	inventory.NodeGroupNames = nodeGroupNames
+
+	// OIDC Provider
+	var oidcProviderArns []string
+	if oidcProviderUrl != "" {
+		oidcProviderArn, err := c.getOidcProviderArn(oidcProviderUrl)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+		case err != nil:
+			return err
+		default:
+			oidcProviderArns = append(oidcProviderArns, oidcProviderArn)
+		}
+	}
+	inventory.OidcProviderArn = report.Add("iam-oidc-provider", oidcProviderUrl, oidcProviderArns...)
+
+	// EBS CSI Addon
+	_, err = c.getAddon(clusterName, EbsStorageAddonName)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		report.Add("eks-addon", EbsStorageAddonName)
+	case err != nil:
+		return err
+	default:
+		report.Add("eks-addon", EbsStorageAddonName, EbsStorageAddonName)
+		inventory.ClusterAddon = true
+	}
+
+	return nil
+}
+
+// tagFilters returns EC2 filters that match resources with all the tags.
+func tagFilters(tags []ec2_types.Tag) []ec2_types.Filter {
+	var filters []ec2_types.Filter
+	for _, tag := range tags {
+		filters = append(filters, ec2_types.Filter{
+			Name:   aws.String(fmt.Sprintf("tag:%s", *tag.Key)),
+			Values: []string{*tag.Value},
+		})
+	}
+
+	return filters
+}
@@ -0,0 +1,130 @@
+package eks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+func TestImportEksResourceStack(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+
+	var created EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &created); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	createCalls := backend.CallCount("CreateVpc")
+
+	var imported EksInventory
+	report, err := eksClient.ImportEksResourceStack(resourceConfig, &imported)
+	if err != nil {
+		t.Fatalf("failed to import resource stack: %v", err)
+	}
+	if count := report.Count(discovery.StatusFound); count == 0 || report.HasAmbiguity() {
+		t.Errorf("expected every resource to be found, got %s", report.Summary())
+	}
+	if !reflect.DeepEqual(imported, created) {
+		t.Errorf("expected imported inventory to equal the created inventory\nexpected: %+v\ngot:      %+v", created, imported)
+	}
+	if count := backend.CallCount("CreateVpc"); count != createCalls {
+		t.Errorf("expected import to create nothing, CreateVpc called %d times", count-createCalls)
+	}
+}
+
+func TestImportEksResourceStackAmbiguousVpc(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+
+	var created EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &created); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	vpc, err := backend.Apis().Ec2.CreateVpc(context.Background(), &aws_ec2.CreateVpcInput{
+		CidrBlock: aws.String(resourceConfig.ClusterCidr),
+		TagSpecifications: []ec2_types.TagSpecification{
+			{
+				ResourceType: ec2_types.ResourceTypeVpc,
+				Tags:         *ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create VPC: %v", err)
+	}
+
+	var imported EksInventory
+	report, err := eksClient.ImportEksResourceStack(resourceConfig, &imported)
+	if err != nil {
+		t.Fatalf("failed to import resource stack: %v", err)
+	}
+	for _, result := range report.Results {
+		if result.Kind != "vpc" {
+			continue
+		}
+		expected := []string{created.VpcId, *vpc.Vpc.VpcId}
+		if result.Status != discovery.StatusAmbiguous || !reflect.DeepEqual(result.Candidates, expected) {
+			t.Errorf("expected VPC to be ambiguous between %v, got %+v", expected, result)
+		}
+	}
+	if count := report.Count(discovery.StatusAmbiguous); count != 1 {
+		t.Errorf("expected only the VPC to be ambiguous, got %s", report.Summary())
+	}
+	if imported.VpcId != "" {
+		t.Errorf("expected ambiguous VPC to be left out of inventory, got %s", imported.VpcId)
+	}
+	if imported.Cluster.ClusterName != created.Cluster.ClusterName {
+		t.Errorf("expected other resources to be imported, got %+v", imported)
+	}
+}
+
+func TestImportEksResourceStackSubnets(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	resourceConfig.AvailabilityZones = []AvailabilityZoneConfig{
+		{Zone: "us-east-2a", PublicSubnetCidr: "10.0.0.0/22", PrivateSubnetCidr: "10.0.4.0/22"},
+		{Zone: "us-east-2b", PublicSubnetCidr: "10.0.8.0/22", PrivateSubnetCidr: "10.0.12.0/22"},
+	}
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+
+	var created EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &created); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+
+	// each subnet is imported into the zone and CIDR block it was created
+	// with
+	var imported EksInventory
+	if _, err := eksClient.ImportEksResourceStack(resourceConfig, &imported); err != nil {
+		t.Fatalf("failed to import resource stack: %v", err)
+	}
+	if !reflect.DeepEqual(imported.AvailabilityZones, created.AvailabilityZones) {
+		t.Errorf("expected subnets to be imported by zone and CIDR block\nexpected: %+v\ngot:      %+v", created.AvailabilityZones, imported.AvailabilityZones)
+	}
+
+	// subnets with the same CIDR blocks in other zones are not the config's
+	resourceConfig.AvailabilityZones[0].Zone, resourceConfig.AvailabilityZones[1].Zone = "us-east-2b", "us-east-2a"
+	imported = EksInventory{}
+	report, err := eksClient.ImportEksResourceStack(resourceConfig, &imported)
+	if err != nil {
+		t.Fatalf("failed to import resource stack: %v", err)
+	}
+	for _, result := range report.Results {
+		if (result.Kind == "public-subnet" || result.Kind == "private-subnet") && result.Status != discovery.StatusNotFound {
+			t.Errorf("expected subnet %s not to be found, got %+v", result.Name, result)
+		}
+	}
+	if subnetIds := subnetIds(imported.AvailabilityZones); len(subnetIds) != 0 {
+		t.Errorf("expected no subnets in inventory, got %v", subnetIds)
+	}
+}
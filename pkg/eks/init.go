@@ -1,74 +1,89 @@
 package eks
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/state"
 )
 
-// InitCreate initializes EKS resource creation by creating an inventory
-// channel, starting a goroutine to write inventory to file, creating the EKS
-// client and loading the EKS configuration.
+// InitCreate initializes EKS resource creation by loading and validating
+// the EKS configuration, locking the state, adding the current inventory to
+// the state history, creating an inventory channel, starting a goroutine to
+// write inventory to state and creating the EKS client.  The state is
+// unlocked once the inventory channel is closed and the last inventory has
+// been written.
 func InitCreate(
 	resourceClient *client.ResourceClient,
-	configFile string,
-	inventoryFile string,
+	configFiles []string,
+	backend state.Backend,
 	inventoryChan *chan EksInventory,
 	createWait *sync.WaitGroup,
 ) (*EksClient, *EksConfig, error) {
-	// capture inventory and write to file as resources are created
-	createWait.Add(1)
-	go func() {
-		defer createWait.Done()
-		for inventory := range *inventoryChan {
-			if err := inventory.Write(inventoryFile); err != nil {
-				fmt.Printf("failed to write inventory file: %s", err)
-			}
-		}
-	}()
+	// load config
+	eksConfig, err := LoadEksConfig(configFiles...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load EKS config file: %w", err)
+	}
 
-	// create client and load config
-	eksClient := EksClient{
-		*resourceClient,
-		inventoryChan,
+	// validate config before any resources are changed
+	if err := eksConfig.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid EKS config: %w", err)
 	}
-	eksConfig, err := LoadEksConfig(configFile)
+
+	// lock state so no other operation can change the resource stack
+	lockInfo, err := state.Acquire(resourceClient.Context, backend, "create")
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load EKS config file: %w", err)
+		return nil, nil, err
+	}
+
+	// capture inventory and write to state as resources are created
+	state.StartWriter(resourceClient.Context, backend, lockInfo, *inventoryChan, createWait)
+
+	// create client
+	eksClient := EksClient{
+		ResourceClient: *resourceClient,
+		InventoryChan:  inventoryChan,
 	}
 
 	return &eksClient, eksConfig, nil
 }
 
-// InitDelete initializes EKS resource deletion by creating an inventory
-// channel, starting a goroutine to write inventory updates to file, creating
-// the EKS client and loading the inventory to be deleted.
+// InitDelete initializes EKS resource deletion by locking the state, adding the
+// current inventory to the state history, loading the inventory to be deleted,
+// creating an inventory channel, starting a goroutine to write inventory
+// updates to state and creating the EKS client.  The state is unlocked once
+// the inventory channel is closed and the last inventory has been written.
 func InitDelete(
 	resourceClient *client.ResourceClient,
-	inventoryFile string,
+	backend state.Backend,
 	inventoryChan *chan EksInventory,
 	deleteWait *sync.WaitGroup,
 ) (*EksClient, *EksInventory, error) {
-	// capture inventory and write to file as resources are deleted
-	deleteWait.Add(1)
-	go func() {
-		defer deleteWait.Done()
-		for inventory := range *inventoryChan {
-			if err := inventory.Write(inventoryFile); err != nil {
-				fmt.Printf("failed to write inventory file: %s", err)
-			}
+	// lock state so no other operation can change the resource stack
+	lockInfo, err := state.Acquire(resourceClient.Context, backend, "delete")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// load inventory to delete
+	var eksInventory EksInventory
+	if err := eksInventory.LoadState(resourceClient.Context, backend); err != nil {
+		if unlockErr := backend.Unlock(resourceClient.Context, lockInfo.Id); unlockErr != nil {
+			return nil, nil, fmt.Errorf("failed to load EKS inventory and unlock state: %w", errors.Join(err, unlockErr))
 		}
-	}()
+		return nil, nil, fmt.Errorf("failed to load EKS inventory: %w", err)
+	}
+
+	// capture inventory and write to state as resources are deleted
+	state.StartWriter(resourceClient.Context, backend, lockInfo, *inventoryChan, deleteWait)
 
-	// create client and load inventory to delete
+	// create client
 	eksClient := EksClient{
-		*resourceClient,
-		inventoryChan,
-	}
-	var eksInventory EksInventory
-	if err := eksInventory.Load(inventoryFile); err != nil {
-		return nil, nil, fmt.Errorf("failed to load EKS inventory file: %w", err)
+		ResourceClient: *resourceClient,
+		InventoryChan:  inventoryChan,
 	}
 
 	return &eksClient, &eksInventory, nil
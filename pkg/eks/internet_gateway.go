@@ -9,6 +9,7 @@ import (
 	"github.com/aws/smithy-go"
 
 	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreateInternetGateway creates an internet gateway for the VPC in which an EKS
@@ -18,7 +19,7 @@ func (c *EksClient) CreateInternetGateway(
 	vpcId string,
 	clusterName string,
 ) (*types.InternetGateway, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	// because internet gateways don't have unique names we have to check for
 	// an existing gateway with matching tags up front
@@ -68,34 +69,32 @@ func (c *EksClient) CreateInternetGateway(
 	return &createdIgw, nil
 }
 
-// DeleteInternetGateway deletes an internet gateway.  If an empty ID is
-// supplied, or if the internet gateway is not found, it returns without error.
+// DeleteInternetGateway detaches an internet gateway from the VPC and deletes
+// it.  If an empty ID is supplied, or if the internet gateway is not found, it
+// returns without error.  An internet gateway that is already detached, e.g.
+// by an earlier delete that failed, is deleted.
 func (c *EksClient) DeleteInternetGateway(internetGatewayId, vpcId string) error {
 	// if internetGatewayId is empty, there's nothing to delete
 	if internetGatewayId == "" {
 		return nil
 	}
 
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	detachInternetGatewayInput := aws_ec2.DetachInternetGatewayInput{
 		InternetGatewayId: &internetGatewayId,
 		VpcId:             &vpcId,
 	}
 	_, err := svc.DetachInternetGateway(c.Context, &detachInternetGatewayInput)
-	if err != nil {
-		var ae smithy.APIError
-		if errors.As(err, &ae) {
-			if ae.ErrorCode() == "InvalidInternetGatewayID.NotFound" {
-				// attempting to detach a internet gateway that doesn't exist so return
-				// without error
-				return nil
-			} else {
-				return fmt.Errorf("failed to detach internet gateway with ID %s: %w", internetGatewayId, err)
-			}
-		} else {
-			return fmt.Errorf("failed to detach internet gateway with ID %s: %w", internetGatewayId, err)
-		}
+	switch {
+	case err == nil, util.HasErrorCode(err, "Gateway.NotAttached"):
+		// detached so continue with deleting it
+	case util.HasErrorCode(err, "InvalidInternetGatewayID.NotFound"):
+		// attempting to detach a internet gateway that doesn't exist so return
+		// without error
+		return nil
+	default:
+		return fmt.Errorf("failed to detach internet gateway with ID %s: %w", internetGatewayId, err)
 	}
 
 	deleteInternetGatewayInput := aws_ec2.DeleteInternetGatewayInput{InternetGatewayId: &internetGatewayId}
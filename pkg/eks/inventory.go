@@ -1,15 +1,41 @@
 package eks
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"os"
+
+	"github.com/nukleros/aws-builder/pkg/inventory"
+	"github.com/nukleros/aws-builder/pkg/state"
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
+// InventorySchemaVersion is the schema version of EKS inventories written by
+// this version of aws-builder.  When the inventory changes in a way that
+// older inventories need to be upgraded for, increment the version and
+// register a migration from the previous version.
+const InventorySchemaVersion = 3
+
+func init() {
+	inventory.Register(inventory.Migration{
+		Stack:       "eks",
+		FromVersion: inventory.UnversionedSchemaVersion,
+		Description: "add schema version to inventories written before versioning",
+	})
+	inventory.Register(inventory.Migration{
+		Stack:       "eks",
+		FromVersion: 2,
+		Description: "add adopted resources that older versions would delete",
+	})
+}
+
 // EksInventory contains a record of all resources created so they can be
-// referenced and cleaned up.
+// referenced and cleaned up.  Adopted contains the IDs and names of resources
+// that were adopted rather than created, which are kept when the resource
+// stack is deleted unless adopted resources are deleted too.
 type EksInventory struct {
+	SchemaVersion          int                         `json:"schemaVersion"`
 	Region                 string                      `json:"region"`
 	AvailabilityZones      []AvailabilityZoneInventory `json:"availabilityZones"`
 	VpcId                  string                      `json:"vpcId"`
@@ -30,6 +56,7 @@ type EksInventory struct {
 	NodeGroupNames         []string                    `json:"nodeGroupNames"`
 	OidcProviderArn        string                      `json:"oidcProviderArn"`
 	SecurityGroupId        string                      `json:"securityGroupId"`
+	Adopted                []string                    `json:"adopted"`
 }
 
 // AvailabilityZoneInventory
@@ -60,21 +87,15 @@ type ClusterInventory struct {
 	OidcProviderUrl string `json:"oidcProviderUrl"`
 }
 
-// send sends the EKS inventory on the inventory channel.
-func (i *EksInventory) send(inventoryChan *chan EksInventory) {
-	if inventoryChan != nil {
-		*inventoryChan <- *i
-	}
-}
-
-// Write writes EKS inventory to file.
+// Write writes EKS inventory to file.  The file is replaced atomically so a
+// crash cannot leave a partially written inventory.
 func (i *EksInventory) Write(inventoryFile string) error {
 	invJson, err := i.Marshal()
 	if err != nil {
 		return fmt.Errorf("failed to marshal EKS inventory to JSON: %w", err)
 	}
 
-	if err := os.WriteFile(inventoryFile, invJson, 0644); err != nil {
+	if err := util.WriteFileAtomic(inventoryFile, invJson, 0644); err != nil {
 		return fmt.Errorf("failed to write EKS inventory to file: %w", err)
 	}
 
@@ -93,9 +114,37 @@ func (i *EksInventory) Load(inventoryFile string) error {
 	return i.Unmarshal(inventoryBytes)
 }
 
-// Marshal returns the JSON EKS inventory from an EksInventory object.
+// WriteState writes EKS inventory to a state backend.
+func (i *EksInventory) WriteState(ctx context.Context, backend state.Backend) error {
+	invJson, err := i.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal EKS inventory to JSON: %w", err)
+	}
+
+	if err := backend.Write(ctx, invJson); err != nil {
+		return fmt.Errorf("failed to write EKS inventory to %s: %w", backend, err)
+	}
+
+	return nil
+}
+
+// LoadState loads the EKS inventory from a state backend.
+func (i *EksInventory) LoadState(ctx context.Context, backend state.Backend) error {
+	inventoryBytes, err := backend.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read EKS inventory from %s: %w", backend, err)
+	}
+
+	// unmarshal JSON inventory
+	return i.Unmarshal(inventoryBytes)
+}
+
+// Marshal returns the JSON EKS inventory from an EksInventory object.  The
+// JSON has the current schema version without changing the object.
 func (i *EksInventory) Marshal() ([]byte, error) {
-	invJson, err := json.MarshalIndent(i, "", "  ")
+	versioned := *i
+	versioned.SchemaVersion = InventorySchemaVersion
+	invJson, err := json.MarshalIndent(&versioned, "", "  ")
 	if err != nil {
 		return []byte{}, err
 	}
@@ -104,7 +153,14 @@ func (i *EksInventory) Marshal() ([]byte, error) {
 }
 
 // Unmarshal populates an EksInventory object from the JSON EKS inventory.
+// Inventories with an older schema version are migrated to the current
+// version and an error is returned for inventories with a newer version.
 func (i *EksInventory) Unmarshal(inventoryBytes []byte) error {
+	inventoryBytes, err := inventory.Migrate("eks", inventoryBytes, InventorySchemaVersion)
+	if err != nil {
+		return err
+	}
+
 	if err := json.Unmarshal(inventoryBytes, i); err != nil {
 		return err
 	}
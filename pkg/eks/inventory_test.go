@@ -0,0 +1,26 @@
+package eks
+
+import (
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/inventory"
+)
+
+func TestMarshalSetsSchemaVersion(t *testing.T) {
+	eksInventory := EksInventory{SchemaVersion: 1, VpcId: "vpc-1"}
+
+	inventoryBytes, err := eksInventory.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal inventory: %v", err)
+	}
+	version, err := inventory.Version(inventoryBytes)
+	if err != nil {
+		t.Fatalf("failed to get schema version: %v", err)
+	}
+	if version != InventorySchemaVersion {
+		t.Errorf("expected marshalled schema version %d, got %d", InventorySchemaVersion, version)
+	}
+	if eksInventory.SchemaVersion != 1 {
+		t.Errorf("expected inventory schema version to be unchanged, got %d", eksInventory.SchemaVersion)
+	}
+}
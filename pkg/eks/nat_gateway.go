@@ -7,9 +7,10 @@ import (
 
 	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	"github.com/aws/smithy-go"
 
 	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
+	"github.com/nukleros/aws-builder/pkg/waiter"
 )
 
 type NatGatewayCondition string
@@ -17,10 +18,17 @@ type NatGatewayCondition string
 const (
 	NatGatewayConditionCreated = "NatGatewayCreated"
 	NatGatewayConditionDeleted = "NatGatewayDeleted"
-	NatGatewayCheckInterval    = 15 //check cluster status every 15 seconds
-	NatGatewayCheckMaxCount    = 20 // check 20 times before giving up (5 minutes)
+	NatGatewayWaiter           = "nat-gateway"
 )
 
+// DefaultNatGatewayWaiter checks NAT gateway status every 15 seconds for up
+// to 5 minutes.
+var DefaultNatGatewayWaiter = waiter.Config{
+	Timeout:  5 * time.Minute,
+	Interval: 15 * time.Second,
+	Backoff:  1,
+}
+
 // CreateNatGateways creates a NAT gateway for each private subnet so that it
 // may reach the public internet.
 func (c *EksClient) CreateNatGateways(
@@ -28,7 +36,7 @@ func (c *EksClient) CreateNatGateways(
 	azInventory *[]AvailabilityZoneInventory,
 	elasticIpIds []string,
 ) error {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	for i, az := range *azInventory {
 		eip := elasticIpIds[i]
@@ -63,43 +71,79 @@ func (c *EksClient) CreateNatGateways(
 	return nil
 }
 
-// DeleteNatGateways deletes the NAT gateway for each availability zone.
+// DeleteNatGateways deletes the NAT gateway for each availability zone and
+// returns the IDs of the NAT gateways.  Every NAT gateway is attempted and an
+// error joining each failure is returned.
 func (c *EksClient) DeleteNatGateways(azInventory *[]AvailabilityZoneInventory) ([]string, error) {
-	// collect NAT gateway inventory
 	var natGatewayIds []string
 	for _, azInv := range *azInventory {
 		if azInv.NatGatewayId != "" {
 			natGatewayIds = append(natGatewayIds, azInv.NatGatewayId)
 		}
 	}
+	_, err := c.deleteNatGateways(azInventory)
 
-	// if there are no subnet IDs there is nothing to do
-	if len(natGatewayIds) == 0 {
-		return natGatewayIds, nil
-	}
+	return natGatewayIds, err
+}
 
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+// deleteNatGateways initiates the deletion of the NAT gateway for each
+// availability zone and returns the IDs of those being deleted or not found.
+// Every NAT gateway is attempted and an error joining each failure is
+// returned.
+func (c *EksClient) deleteNatGateways(azInventory *[]AvailabilityZoneInventory) ([]string, error) {
+	svc := c.GetEc2Api()
 
-	for _, natGatewayId := range natGatewayIds {
+	var deletedNatGatewayIds []string
+	var errs []error
+	for _, azInv := range *azInventory {
+		natGatewayId := azInv.NatGatewayId
+		if natGatewayId == "" {
+			continue
+		}
 		deleteNatGatewayInput := aws_ec2.DeleteNatGatewayInput{NatGatewayId: &natGatewayId}
 		_, err := svc.DeleteNatGateway(c.Context, &deleteNatGatewayInput)
-		if err != nil {
-			var ae smithy.APIError
-			if errors.As(err, &ae) {
-				if ae.ErrorCode() == "InvalidNATGatewayID.NotFound" {
-					// attempting to delete a NAT gateway that doesn't exist so
-					// continue to the next
-					continue
-				} else {
-					return natGatewayIds, fmt.Errorf("failed to delete NAT gateway with ID %s: %w", natGatewayId, err)
-				}
-			} else {
-				return natGatewayIds, fmt.Errorf("failed to delete NAT gateway with ID %s: %w", natGatewayId, err)
-			}
+		if err != nil && !util.HasErrorCode(err, "InvalidNATGatewayID.NotFound") {
+			errs = append(errs, fmt.Errorf("failed to delete NAT gateway with ID %s: %w", natGatewayId, err))
+			continue
 		}
+		deletedNatGatewayIds = append(deletedNatGatewayIds, natGatewayId)
 	}
 
-	return natGatewayIds, nil
+	return deletedNatGatewayIds, errors.Join(errs...)
+}
+
+// waitForNatGatewaysDeleted waits until the NAT gateways are deleted.  NAT
+// gateways that are no longer found are deleted.
+func (c *EksClient) waitForNatGatewaysDeleted(natGatewayIds []string) error {
+	if len(natGatewayIds) == 0 {
+		return nil
+	}
+
+	return waiter.Wait(
+		c.Context,
+		c.Waiters.Get(NatGatewayWaiter, DefaultNatGatewayWaiter),
+		fmt.Sprintf("NAT gateways %s", natGatewayIds),
+		func() (bool, string, error) {
+			for _, natGatewayId := range natGatewayIds {
+				resp, err := c.GetEc2Api().DescribeNatGateways(c.Context, &aws_ec2.DescribeNatGatewaysInput{
+					NatGatewayIds: []string{natGatewayId},
+				})
+				if err != nil {
+					if util.HasErrorCode(err, "NatGatewayNotFound") {
+						continue
+					}
+					return false, "", fmt.Errorf("failed to describe NAT gateway %s: %w", natGatewayId, err)
+				}
+				for _, natGateway := range resp.NatGateways {
+					if natGateway.State != types.NatGatewayStateDeleted {
+						return false, fmt.Sprintf("NAT gateway %s state %s", natGatewayId, natGateway.State), nil
+					}
+				}
+			}
+
+			return true, "", nil
+		},
+	)
 }
 
 // WaitForNatGateways waits for a NAT gateway to reach a given condition.  One of:
@@ -110,50 +154,44 @@ func (c *EksClient) WaitForNatGateways(
 	azInventory *[]AvailabilityZoneInventory,
 	natGatewayCondition NatGatewayCondition,
 ) (*[]AvailabilityZoneInventory, []string, error) {
-	natGatewayCheckCount := 0
-
 	var updatedAzInventory []AvailabilityZoneInventory
 	var natGatewayIds []string
 
-	for {
-		natGatewayCheckCount += 1
-		if natGatewayCheckCount > NatGatewayCheckMaxCount {
-			return nil, natGatewayIds, errors.New("NAT gateway condition check timed out")
-		}
-
-		natGatewayStates, updatedAzInv, err := c.getNatGatewayStatuses(vpcId, azInventory)
-		if err != nil {
-			return nil, natGatewayIds, fmt.Errorf("failed to get NAT gateway statuses for VPC with ID %s: %w", vpcId, err)
-		}
+	err := waiter.Wait(
+		c.Context,
+		c.Waiters.Get(NatGatewayWaiter, DefaultNatGatewayWaiter),
+		fmt.Sprintf("NAT gateways in VPC with ID %s", vpcId),
+		func() (bool, string, error) {
+			natGatewayStates, updatedAzInv, err := c.getNatGatewayStatuses(vpcId, azInventory)
+			if err != nil {
+				return false, "", fmt.Errorf("failed to get NAT gateway statuses for VPC with ID %s: %w", vpcId, err)
+			}
 
-		if len(*natGatewayStates) == 0 && natGatewayCondition == NatGatewayConditionDeleted {
-			// no NAT gateway resources found for this VPC while waiting for
-			// deletion so condition is met
-			updatedAzInventory = *updatedAzInv
-			break
-		}
+			if len(*natGatewayStates) == 0 && natGatewayCondition == NatGatewayConditionDeleted {
+				// no NAT gateway resources found for this VPC while waiting for
+				// deletion so condition is met
+				updatedAzInventory = *updatedAzInv
+				return true, "", nil
+			}
 
-		allConditionsMet := true
-		for _, state := range *natGatewayStates {
-			if state != types.NatGatewayStateAvailable && natGatewayCondition == NatGatewayConditionCreated {
-				// resource is not available but we're waiting for it to be
-				// created so condition is not met
-				allConditionsMet = false
-				break
-			} else if state != types.NatGatewayStateDeleted && natGatewayCondition == NatGatewayConditionDeleted {
-				// resource is not in deleted state but we're waiting for it to
-				// be deleted so condition is not met
-				allConditionsMet = false
-				break
+			for _, state := range *natGatewayStates {
+				if state != types.NatGatewayStateAvailable && natGatewayCondition == NatGatewayConditionCreated {
+					// resource is not available but we're waiting for it to be
+					// created so condition is not met
+					return false, fmt.Sprintf("NAT gateway states %s", *natGatewayStates), nil
+				} else if state != types.NatGatewayStateDeleted && natGatewayCondition == NatGatewayConditionDeleted {
+					// resource is not in deleted state but we're waiting for it to
+					// be deleted so condition is not met
+					return false, fmt.Sprintf("NAT gateway states %s", *natGatewayStates), nil
+				}
 			}
-		}
 
-		if allConditionsMet {
 			updatedAzInventory = *updatedAzInv
-			break
-		}
-
-		time.Sleep(time.Second * 15)
+			return true, "", nil
+		},
+	)
+	if err != nil {
+		return nil, natGatewayIds, err
 	}
 
 	for _, az := range updatedAzInventory {
@@ -173,7 +211,7 @@ func (c *EksClient) getNatGatewayStatuses(
 	vpcId string,
 	availabilityZoneInventory *[]AvailabilityZoneInventory,
 ) (*[]types.NatGatewayState, *[]AvailabilityZoneInventory, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	updatedAzInventory := *availabilityZoneInventory
 	var natGatewayStates []types.NatGatewayState
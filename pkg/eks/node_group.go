@@ -10,6 +10,7 @@ import (
 	"github.com/aws/smithy-go"
 
 	"github.com/nukleros/aws-builder/pkg/util"
+	"github.com/nukleros/aws-builder/pkg/waiter"
 )
 
 type NodeGroupCondition string
@@ -17,10 +18,17 @@ type NodeGroupCondition string
 const (
 	NodeGroupConditionCreated = "NodeGroupCreated"
 	NodeGroupConditionDeleted = "NodeGroupDeleted"
-	NodeGroupCheckInterval    = 15  //check cluster status every 15 seconds
-	NodeGroupCheckMaxCount    = 240 // check 60 times before giving up (60 minutes)
+	NodeGroupWaiter           = "node-group"
 )
 
+// DefaultNodeGroupWaiter checks node group status every 15 seconds for up to
+// 60 minutes.
+var DefaultNodeGroupWaiter = waiter.Config{
+	Timeout:  60 * time.Minute,
+	Interval: 15 * time.Second,
+	Backoff:  1,
+}
+
 // CreateNodeGroups creates a private node group for an EKS cluster.
 func (c *EksClient) CreateNodeGroups(
 	tags *map[string]string,
@@ -34,7 +42,7 @@ func (c *EksClient) CreateNodeGroups(
 	maxNodes int32,
 	keyPair string,
 ) (*[]types.Nodegroup, error) {
-	svc := aws_eks.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEksApi()
 
 	var nodeGroups []types.Nodegroup
 
@@ -113,15 +121,28 @@ func (c *EksClient) CreateNodeGroups(
 
 // DeleteNodeGroups deletes the EKS cluster node groups.  If an empty cluster
 // name or node group name is supplied, or if it does not find a node group
-// matching the given name it returns without error.
+// matching the given name it returns without error.  Every node group is
+// attempted and an error joining each failure is returned.
 func (c *EksClient) DeleteNodeGroups(clusterName string, nodeGroupNames []string) error {
-	// if clusterName or nodeGroupName are empty, there's nothing to delete
-	if clusterName == "" || len(nodeGroupNames) == 0 {
-		return nil
+	_, err := c.deleteNodeGroups(clusterName, nodeGroupNames)
+
+	return err
+}
+
+// deleteNodeGroups initiates the deletion of the EKS cluster node groups and
+// returns the names of those being deleted or not found.  Every node group is
+// attempted and an error joining each failure is returned.  If an empty
+// cluster name is supplied there are no node groups and all are returned.
+func (c *EksClient) deleteNodeGroups(clusterName string, nodeGroupNames []string) ([]string, error) {
+	// if clusterName is empty, there's nothing to delete
+	if clusterName == "" {
+		return nodeGroupNames, nil
 	}
 
-	svc := aws_eks.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEksApi()
 
+	var deletedNodeGroupNames []string
+	var errs []error
 	for _, nodeGroupName := range nodeGroupNames {
 		deleteNodeGroupInput := aws_eks.DeleteNodegroupInput{
 			ClusterName:   &clusterName,
@@ -130,15 +151,15 @@ func (c *EksClient) DeleteNodeGroups(clusterName string, nodeGroupNames []string
 		_, err := svc.DeleteNodegroup(c.Context, &deleteNodeGroupInput)
 		if err != nil {
 			var notFoundErr *types.ResourceNotFoundException
-			if errors.As(err, &notFoundErr) {
-				return nil
-			} else {
-				return fmt.Errorf("failed to delete node group %s: %w", nodeGroupName, err)
+			if !errors.As(err, &notFoundErr) {
+				errs = append(errs, fmt.Errorf("failed to delete node group %s: %w", nodeGroupName, err))
+				continue
 			}
 		}
+		deletedNodeGroupNames = append(deletedNodeGroupNames, nodeGroupName)
 	}
 
-	return nil
+	return deletedNodeGroupNames, errors.Join(errs...)
 }
 
 // WaitForNodeGroups waits for the provided node groups to reach a given
@@ -155,55 +176,50 @@ func (c *EksClient) WaitForNodeGroups(
 		return nil
 	}
 
-	var nodeGroupHealth types.NodegroupHealth
-	nodeGroupCheckCount := 0
-	for {
-		nodeGroupCheckCount += 1
-		if nodeGroupCheckCount > NodeGroupCheckMaxCount {
-			issueErr := errors.New(fmt.Sprintf("issues with node group: %s", getHealthIssues(nodeGroupHealth)))
-			return fmt.Errorf("node group condition check timed out: %w", issueErr)
-		}
+	return waiter.Wait(
+		c.Context,
+		c.Waiters.Get(NodeGroupWaiter, DefaultNodeGroupWaiter),
+		fmt.Sprintf("node groups %s", nodeGroupNames),
+		func() (bool, string, error) {
+			for _, nodeGroupName := range nodeGroupNames {
+				nodeGroup, err := c.getNodeGroup(clusterName, nodeGroupName)
+				if err != nil {
+					if errors.Is(err, util.ErrResourceNotFound) && nodeGroupCondition == NodeGroupConditionDeleted {
+						// resource was not found and we're waiting for it to be
+						// deleted so condition is met
+						continue
+					}
+					return false, "", fmt.Errorf("failed to get node group status while waiting for %s: %w", nodeGroupName, err)
+				}
 
-		allConditionsMet := true
-		for _, nodeGroupName := range nodeGroupNames {
-			nodeGroup, err := c.getNodeGroup(clusterName, nodeGroupName)
-			if nodeGroup != nil && nodeGroup.Health != nil {
-				nodeGroupHealth = *nodeGroup.Health
-			}
-			if err != nil {
-				if errors.Is(err, util.ErrResourceNotFound) && nodeGroupCondition == NodeGroupConditionDeleted {
-					// resource was not found and we're waiting for it to be
-					// deleted so condition is met
+				var healthIssues []string
+				if nodeGroup.Health != nil {
+					healthIssues = getHealthIssues(*nodeGroup.Health)
+				}
+				if nodeGroup.Status == types.NodegroupStatusActive && nodeGroupCondition == NodeGroupConditionCreated {
+					// resource is available and we're waiting for it to be
+					// created so condition is met
 					continue
-				} else {
-					return fmt.Errorf("failed to get node group status while waiting for %s: %w", nodeGroupName, err)
 				}
-			}
+				if nodeGroup.Status == types.NodegroupStatusCreateFailed {
+					return false, "", fmt.Errorf("failed to create node group %s. Issues with node group: %s", nodeGroupName, healthIssues)
+				}
 
-			if nodeGroup.Status == types.NodegroupStatusActive && nodeGroupCondition == NodeGroupConditionCreated {
-				// resource is available and we're waiting for it to be created
-				// so condition is met
-				continue
-			}
-			if nodeGroup.Status == types.NodegroupStatusCreateFailed {
-				return fmt.Errorf("failed to create node group %s. Issues with node group: %s", nodeGroupName, getHealthIssues(nodeGroupHealth))
+				status := fmt.Sprintf("node group %s is %s", nodeGroupName, nodeGroup.Status)
+				if len(healthIssues) > 0 {
+					status = fmt.Sprintf("%s with issues: %s", status, healthIssues)
+				}
+				return false, status, nil
 			}
-			allConditionsMet = false
-			break
-		}
-
-		if allConditionsMet {
-			break
-		}
-		time.Sleep(time.Second * NodeGroupCheckInterval)
-	}
 
-	return nil
+			return true, "", nil
+		},
+	)
 }
 
 // getNodeGroup retrieves a node group by cluster name and node group name.
 func (c *EksClient) getNodeGroup(clusterName, nodeGroupName string) (*types.Nodegroup, error) {
-	svc := aws_eks.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEksApi()
 
 	describeNodeGroupInput := aws_eks.DescribeNodegroupInput{
 		ClusterName:   &clusterName,
@@ -12,6 +12,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreateOidcProvider creates a new identity provider in IAM for the EKS cluster.
@@ -20,25 +22,22 @@ func (c *EksClient) CreateOidcProvider(
 	tags *[]types.Tag,
 	providerUrl string,
 ) (string, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	var oidcProviderArn string
-	// get the OIDC provider server certificate thumbprint
 	parsedUrl, err := url.Parse(providerUrl)
 	if err != nil {
 		return oidcProviderArn, fmt.Errorf("failed to parse OIDC provider URL: %w", err)
 	}
-	conn, err := tls.Dial("tcp", fmt.Sprintf("%s:%d", parsedUrl.Hostname(), 443), &tls.Config{})
-	if err != nil {
-		return oidcProviderArn, fmt.Errorf("failed to connect to OIDC provider: %w", err)
+
+	// get the OIDC provider server certificate thumbprint
+	getThumbprint := GetOidcThumbprint
+	if c.OidcThumbprintFunc != nil {
+		getThumbprint = c.OidcThumbprintFunc
 	}
-	cert := conn.ConnectionState().PeerCertificates[len(conn.ConnectionState().PeerCertificates)-1]
-	thumbprint := sha1.Sum(cert.Raw)
-	var thumbprintString string
-	for _, t := range thumbprint {
-		var buf bytes.Buffer
-		fmt.Fprintf(&buf, "%02X", t)
-		thumbprintString = thumbprintString + strings.ToLower(buf.String())
+	thumbprintString, err := getThumbprint(providerUrl)
+	if err != nil {
+		return oidcProviderArn, err
 	}
 
 	createOidcProviderInput := iam.CreateOpenIDConnectProviderInput{
@@ -86,7 +85,7 @@ func (c *EksClient) DeleteOidcProvider(oidcProviderArn string) error {
 		return nil
 	}
 
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	deleteOidcProviderInput := iam.DeleteOpenIDConnectProviderInput{
 		OpenIDConnectProviderArn: &oidcProviderArn,
@@ -103,3 +102,47 @@ func (c *EksClient) DeleteOidcProvider(oidcProviderArn string) error {
 
 	return nil
 }
+
+// getOidcProviderArn finds the IAM identity provider for an OIDC provider URL.
+// If no provider is found util.ErrResourceNotFound is returned.
+func (c *EksClient) getOidcProviderArn(providerUrl string) (string, error) {
+	svc := c.GetIamApi()
+
+	// provider ARNs end with the provider URL without the scheme
+	providerResource := fmt.Sprintf("oidc-provider/%s", strings.TrimPrefix(providerUrl, "https://"))
+	listOidcProvidersOutput, err := svc.ListOpenIDConnectProviders(c.Context, &iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list OIDC providers: %w", err)
+	}
+	for _, provider := range listOidcProvidersOutput.OpenIDConnectProviderList {
+		if strings.HasSuffix(*provider.Arn, providerResource) {
+			return *provider.Arn, nil
+		}
+	}
+
+	return "", util.ErrResourceNotFound
+}
+
+// GetOidcThumbprint connects to an OIDC provider and returns the SHA1
+// thumbprint of the root certificate in its chain as a lower-case hex string.
+func GetOidcThumbprint(providerUrl string) (string, error) {
+	parsedUrl, err := url.Parse(providerUrl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse OIDC provider URL: %w", err)
+	}
+	conn, err := tls.Dial("tcp", fmt.Sprintf("%s:%d", parsedUrl.Hostname(), 443), &tls.Config{})
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to OIDC provider: %w", err)
+	}
+	defer conn.Close()
+	cert := conn.ConnectionState().PeerCertificates[len(conn.ConnectionState().PeerCertificates)-1]
+	thumbprint := sha1.Sum(cert.Raw)
+	var thumbprintString string
+	for _, t := range thumbprint {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%02X", t)
+		thumbprintString = thumbprintString + strings.ToLower(buf.String())
+	}
+
+	return thumbprintString, nil
+}
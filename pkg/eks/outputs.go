@@ -0,0 +1,97 @@
+package eks
+
+import (
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+// inventoryOutputs are the outputs of the EKS resource stack.  Outputs are
+// used by other resource stacks and scripts so existing names must not be
+// changed.
+var inventoryOutputs = []stack.InventoryOutput[*EksInventory]{
+	{
+		Output: stack.Output{Name: "region", Description: "The AWS region the resources are in."},
+		Value:  func(i *EksInventory) any { return i.Region },
+	},
+	{
+		Output: stack.Output{Name: "vpcId", Description: "The ID of the cluster's VPC."},
+		Value:  func(i *EksInventory) any { return i.VpcId },
+	},
+	{
+		Output: stack.Output{Name: "publicSubnetIds", Description: "The IDs of the public subnets.", List: true},
+		Value: func(i *EksInventory) any {
+			var subnetIds []string
+			for _, az := range i.AvailabilityZones {
+				for _, subnet := range az.PublicSubnets {
+					subnetIds = append(subnetIds, subnet.SubnetId)
+				}
+			}
+			return subnetIds
+		},
+	},
+	{
+		Output: stack.Output{Name: "privateSubnetIds", Description: "The IDs of the private subnets nodes run in.", List: true},
+		Value: func(i *EksInventory) any {
+			var subnetIds []string
+			for _, az := range i.AvailabilityZones {
+				for _, subnet := range az.PrivateSubnets {
+					subnetIds = append(subnetIds, subnet.SubnetId)
+				}
+			}
+			return subnetIds
+		},
+	},
+	{
+		Output: stack.Output{Name: "securityGroupId", Description: "The ID of the cluster security group attached to nodes."},
+		Value:  func(i *EksInventory) any { return i.SecurityGroupId },
+	},
+	{
+		Output: stack.Output{Name: "clusterName", Description: "The name of the EKS cluster."},
+		Value:  func(i *EksInventory) any { return i.Cluster.ClusterName },
+	},
+	{
+		Output: stack.Output{Name: "clusterArn", Description: "The ARN of the EKS cluster."},
+		Value:  func(i *EksInventory) any { return i.Cluster.ClusterArn },
+	},
+	{
+		Output: stack.Output{Name: "oidcProviderUrl", Description: "The https:// URL of the cluster's OIDC provider."},
+		Value:  func(i *EksInventory) any { return i.Cluster.OidcProviderUrl },
+	},
+	{
+		Output: stack.Output{Name: "oidcProviderArn", Description: "The ARN of the cluster's IAM OIDC provider."},
+		Value:  func(i *EksInventory) any { return i.OidcProviderArn },
+	},
+	{
+		Output: stack.Output{Name: "clusterRoleArn", Description: "The ARN of the IAM role for the cluster."},
+		Value:  func(i *EksInventory) any { return i.ClusterRole.RoleArn },
+	},
+	{
+		Output: stack.Output{Name: "workerRoleArn", Description: "The ARN of the IAM role for nodes."},
+		Value:  func(i *EksInventory) any { return i.WorkerRole.RoleArn },
+	},
+	{
+		Output: stack.Output{Name: "dnsManagementRoleArn", Description: "The ARN of the IAM role for DNS management, if enabled."},
+		Value:  func(i *EksInventory) any { return i.DnsManagementRole.RoleArn },
+	},
+	{
+		Output: stack.Output{Name: "dns01ChallengeRoleArn", Description: "The ARN of the IAM role for DNS01 challenges, if enabled."},
+		Value:  func(i *EksInventory) any { return i.Dns01ChallengeRole.RoleArn },
+	},
+	{
+		Output: stack.Output{Name: "secretsManagerRoleArn", Description: "The ARN of the IAM role for AWS Secrets Manager, if enabled."},
+		Value:  func(i *EksInventory) any { return i.SecretsManagerRole.RoleArn },
+	},
+	{
+		Output: stack.Output{Name: "clusterAutoscalingRoleArn", Description: "The ARN of the IAM role for the cluster autoscaler, if enabled."},
+		Value:  func(i *EksInventory) any { return i.ClusterAutoscalingRole.RoleArn },
+	},
+	{
+		Output: stack.Output{Name: "storageManagementRoleArn", Description: "The ARN of the IAM role for the EBS CSI driver."},
+		Value:  func(i *EksInventory) any { return i.StorageManagementRole.RoleArn },
+	},
+}
+
+// Outputs returns the value of each output of the EKS resource stack, e.g.
+// ${eks.vpcId}, by output name.
+func (i *EksInventory) Outputs() map[string]any {
+	return stack.OutputValues(inventoryOutputs, i)
+}
@@ -0,0 +1,448 @@
+package eks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/plan"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// PlanEksResourceStack returns the actions CreateEksResourceStack would take
+// for each resource given the same config and inventory.  Only read-only calls
+// are made so no resources are created or changed.
+func (c *EksClient) PlanEksResourceStack(
+	resourceConfig *EksConfig,
+	inventory *EksInventory,
+) (*plan.ChangeSet, error) {
+	// resource config region takes precedence
+	// if not set, use the region defined in AWS config
+	region := resourceConfig.Region
+	if region != "" {
+		c.AwsConfig.Region = region
+	} else {
+		region = c.AwsConfig.Region
+	}
+
+	if inventory == nil {
+		inventory = &EksInventory{}
+	}
+	if inventory.Region != "" && inventory.Region != region {
+		return nil, fmt.Errorf(
+			"config region %s and inventory region %s do not match",
+			region,
+			inventory.Region,
+		)
+	}
+
+	changeSet := plan.NewChangeSet("eks", region)
+
+	// Tags
+	ec2Tags := ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags)
+
+	// Availability Zones
+	azInventory := inventory.AvailabilityZones
+	if len(azInventory) == 0 {
+		azs, err := c.SetAvailabilityZones(
+			region,
+			resourceConfig.DesiredAzCount,
+			&resourceConfig.AvailabilityZones,
+		)
+		if err != nil {
+			return nil, err
+		}
+		azInventory = *azs
+	}
+
+	// VPC
+	vpcId := inventory.VpcId
+	if vpcId != "" {
+		changeSet.Add("vpc", resourceConfig.Name, vpcId, plan.ActionReuse)
+	} else {
+		vpc, uniqueTagsExist, err := ec2.CheckUniqueTagsForVpc(c, ec2Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for unique tags on VPC: %w", err)
+		}
+		if uniqueTagsExist {
+			vpcId = *vpc.VpcId
+			changeSet.Add("vpc", resourceConfig.Name, vpcId, plan.ActionAdopt)
+		} else {
+			changeSet.Add("vpc", resourceConfig.Name, "", plan.ActionCreate)
+		}
+	}
+
+	// Internet Gateway
+	if inventory.InternetGatewayId != "" {
+		changeSet.Add("internet-gateway", resourceConfig.Name, inventory.InternetGatewayId, plan.ActionReuse)
+	} else {
+		igw, uniqueTagsExist, err := ec2.CheckUniqueTagsForInternetGateway(c, ec2Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for unique tags on internet gateway: %w", err)
+		}
+		if uniqueTagsExist {
+			changeSet.Add("internet-gateway", resourceConfig.Name, *igw.InternetGatewayId, plan.ActionAdopt)
+		} else {
+			changeSet.Add("internet-gateway", resourceConfig.Name, "", plan.ActionCreate)
+		}
+	}
+
+	// Public Subnets
+	publicTags := publicSubnetTags(ec2Tags, resourceConfig.Name)
+	publicSubnetIds := make([][]string, len(azInventory))
+	for azIdx, az := range azInventory {
+		for _, subnet := range az.PublicSubnets {
+			subnetId, err := c.planSubnet(changeSet, "public-subnet", &publicTags, az.Zone, subnet)
+			if err != nil {
+				return nil, err
+			}
+			publicSubnetIds[azIdx] = append(publicSubnetIds[azIdx], subnetId)
+		}
+	}
+
+	// Private Subnets
+	privateTags := privateSubnetTags(ec2Tags, resourceConfig.Name)
+	for _, az := range azInventory {
+		for _, subnet := range az.PrivateSubnets {
+			if _, err := c.planSubnet(changeSet, "private-subnet", &privateTags, az.Zone, subnet); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Elastic IPs
+	if len(inventory.ElasticIpIds) != 0 {
+		for _, elasticIpId := range inventory.ElasticIpIds {
+			changeSet.Add("elastic-ip", "", elasticIpId, plan.ActionReuse)
+		}
+	} else {
+		eipRefTagValue := 1
+		for _, az := range azInventory {
+			for range az.PublicSubnets {
+				eipTags := elasticIpTags(ec2Tags, eipRefTagValue)
+				eipName := fmt.Sprintf("ElasticIpRef=%d", eipRefTagValue)
+				eipRefTagValue++
+				eip, uniqueTagsExist, err := ec2.CheckUniqueTagsForElasticIp(c, &eipTags)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check for unique tags on elastic IP: %w", err)
+				}
+				if uniqueTagsExist {
+					changeSet.Add("elastic-ip", eipName, *eip.AllocationId, plan.ActionAdopt)
+				} else {
+					changeSet.Add("elastic-ip", eipName, "", plan.ActionCreate)
+				}
+			}
+		}
+	}
+
+	// NAT Gateways
+	for azIdx, az := range azInventory {
+		if az.NatGatewayId != "" {
+			changeSet.Add("nat-gateway", az.Zone, az.NatGatewayId, plan.ActionReuse)
+			continue
+		}
+		for _, publicSubnetId := range publicSubnetIds[azIdx] {
+			// a NAT gateway can only exist in a subnet that exists
+			if publicSubnetId == "" {
+				changeSet.Add("nat-gateway", az.Zone, "", plan.ActionCreate)
+				continue
+			}
+			natGateway, uniqueTagsExist, err := ec2.CheckUniqueTagsForNatGateway(c, ec2Tags, publicSubnetId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check for unique tags on NAT gateway: %w", err)
+			}
+			if uniqueTagsExist {
+				changeSet.Add("nat-gateway", az.Zone, *natGateway.NatGatewayId, plan.ActionAdopt)
+			} else {
+				changeSet.Add("nat-gateway", az.Zone, "", plan.ActionCreate)
+			}
+		}
+	}
+
+	// Public Route Table
+	if inventory.PublicRouteTableId != "" {
+		changeSet.Add("public-route-table", "", inventory.PublicRouteTableId, plan.ActionReuse)
+	} else {
+		publicRtTags := publicRouteTableTags(ec2Tags)
+		if err := c.planRouteTable(changeSet, "public-route-table", "PublicRouteTableRef=1", &publicRtTags); err != nil {
+			return nil, err
+		}
+	}
+
+	// Private Route Tables
+	if len(inventory.PrivateRouteTableIds) != 0 {
+		for _, routeTableId := range inventory.PrivateRouteTableIds {
+			changeSet.Add("private-route-table", "", routeTableId, plan.ActionReuse)
+		}
+	} else {
+		privateRtRefTagValue := 1
+		for _, az := range azInventory {
+			for range az.PrivateSubnets {
+				privateRtTags := privateRouteTableTags(ec2Tags, privateRtRefTagValue)
+				routeTableName := fmt.Sprintf("PrivateRouteTableRef=%d", privateRtRefTagValue)
+				privateRtRefTagValue++
+				if err := c.planRouteTable(changeSet, "private-route-table", routeTableName, &privateRtTags); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// IAM Roles for cluster and worker nodes
+	if err := c.planRole(
+		changeSet,
+		inventory.ClusterRole,
+		fmt.Sprintf("%s-%s", ClusterRoleName, resourceConfig.Name),
+	); err != nil {
+		return nil, err
+	}
+	if err := c.planRole(
+		changeSet,
+		inventory.WorkerRole,
+		fmt.Sprintf("%s-%s", WorkerRoleName, resourceConfig.Name),
+	); err != nil {
+		return nil, err
+	}
+
+	// EKS Cluster
+	clusterName := inventory.Cluster.ClusterName
+	oidcProviderUrl := inventory.Cluster.OidcProviderUrl
+	clusterExists := true
+	if clusterName != "" {
+		changeSet.Add("eks-cluster", clusterName, inventory.Cluster.ClusterArn, plan.ActionReuse)
+	} else {
+		clusterName = resourceConfig.Name
+		cluster, err := c.getCluster(clusterName)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+			clusterExists = false
+			changeSet.Add("eks-cluster", clusterName, "", plan.ActionCreate)
+		case err != nil:
+			return nil, err
+		default:
+			if cluster.Identity != nil && cluster.Identity.Oidc != nil && cluster.Identity.Oidc.Issuer != nil {
+				oidcProviderUrl = *cluster.Identity.Oidc.Issuer
+			}
+			changeSet.Add("eks-cluster", clusterName, *cluster.Arn, plan.ActionAdopt)
+		}
+	}
+
+	// EKS Cluster Security Group - created by AWS along with the cluster
+	switch {
+	case inventory.SecurityGroupId != "":
+		changeSet.Add("security-group", clusterName, inventory.SecurityGroupId, plan.ActionReuse)
+	case clusterExists:
+		securityGroupId, err := c.GetClusterSecurityGroup(clusterName)
+		if err != nil {
+			return nil, err
+		}
+		changeSet.Add("security-group", clusterName, securityGroupId, plan.ActionAdopt)
+	default:
+		changeSet.Add("security-group", clusterName, "", plan.ActionCreate)
+	}
+
+	// Node Groups
+	if len(inventory.NodeGroupNames) != 0 {
+		for _, nodeGroupName := range inventory.NodeGroupNames {
+			changeSet.Add("eks-node-group", nodeGroupName, "", plan.ActionReuse)
+		}
+	} else {
+		nodeGroupName := fmt.Sprintf("%s-private-node-group", clusterName)
+		action := plan.ActionCreate
+		if clusterExists {
+			_, err := c.getNodeGroup(clusterName, nodeGroupName)
+			switch {
+			case err == nil:
+				action = plan.ActionAdopt
+			case !errors.Is(err, util.ErrResourceNotFound):
+				return nil, err
+			}
+		}
+		changeSet.Add("eks-node-group", nodeGroupName, "", action)
+	}
+
+	// OIDC Provider
+	switch {
+	case inventory.OidcProviderArn != "":
+		changeSet.Add("iam-oidc-provider", oidcProviderUrl, inventory.OidcProviderArn, plan.ActionReuse)
+	case oidcProviderUrl != "":
+		oidcProviderArn, err := c.getOidcProviderArn(oidcProviderUrl)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+			changeSet.Add("iam-oidc-provider", oidcProviderUrl, "", plan.ActionCreate)
+		case err != nil:
+			return nil, err
+		default:
+			changeSet.Add("iam-oidc-provider", oidcProviderUrl, oidcProviderArn, plan.ActionAdopt)
+		}
+	default:
+		changeSet.Add("iam-oidc-provider", "", "", plan.ActionCreate)
+	}
+
+	// IAM Policies and Roles for optional features
+	optionalRoles := []struct {
+		requested     bool
+		policyName    string
+		roleName      string
+		roleInventory RoleInventory
+	}{
+		{resourceConfig.DnsManagement, DnsPolicyName, DnsManagementRoleName, inventory.DnsManagementRole},
+		{resourceConfig.Dns01Challenge, Dns01ChallengePolicyName, Dns01ChallengeRoleName, inventory.Dns01ChallengeRole},
+		{resourceConfig.SecretsManager, SecretsManagerPolicyName, SecretsManagerRoleName, inventory.SecretsManagerRole},
+		{resourceConfig.ClusterAutoscaling, AutoscalingPolicyName, ClusterAutoscalingRoleName, inventory.ClusterAutoscalingRole},
+	}
+	for _, optionalRole := range optionalRoles {
+		policyName := fmt.Sprintf("%s-%s", optionalRole.policyName, resourceConfig.Name)
+		roleName := fmt.Sprintf("%s-%s", optionalRole.roleName, resourceConfig.Name)
+		if !optionalRole.requested {
+			changeSet.Add("iam-policy", policyName, "", plan.ActionSkip)
+			changeSet.Add("iam-role", roleName, "", plan.ActionSkip)
+			continue
+		}
+		if err := c.planPolicy(
+			changeSet,
+			optionalRole.roleInventory.RolePolicyArns,
+			policyName,
+			fmt.Sprintf("/%s/", resourceConfig.Name),
+		); err != nil {
+			return nil, err
+		}
+		if err := c.planRole(changeSet, optionalRole.roleInventory, roleName); err != nil {
+			return nil, err
+		}
+	}
+
+	// IAM Role for Storage Management
+	if err := c.planRole(
+		changeSet,
+		inventory.StorageManagementRole,
+		fmt.Sprintf("%s-%s", StorageManagementRoleName, resourceConfig.Name),
+	); err != nil {
+		return nil, err
+	}
+
+	// EBS CSI Addon
+	switch {
+	case inventory.ClusterAddon:
+		changeSet.Add("eks-addon", EbsStorageAddonName, "", plan.ActionReuse)
+	case clusterExists:
+		_, err := c.getAddon(clusterName, EbsStorageAddonName)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+			changeSet.Add("eks-addon", EbsStorageAddonName, "", plan.ActionCreate)
+		case err != nil:
+			return nil, err
+		default:
+			changeSet.Add("eks-addon", EbsStorageAddonName, "", plan.ActionAdopt)
+		}
+	default:
+		changeSet.Add("eks-addon", EbsStorageAddonName, "", plan.ActionCreate)
+	}
+
+	return changeSet, nil
+}
+
+// planSubnet adds the planned action for a subnet to the change set and
+// returns the subnet ID if the subnet already exists.
+func (c *EksClient) planSubnet(
+	changeSet *plan.ChangeSet,
+	kind string,
+	tags *[]types.Tag,
+	zone string,
+	subnet SubnetInventory,
+) (string, error) {
+	subnetName := fmt.Sprintf("%s %s", zone, subnet.SubnetCidr)
+	if subnet.SubnetId != "" {
+		changeSet.Add(kind, subnetName, subnet.SubnetId, plan.ActionReuse)
+		return subnet.SubnetId, nil
+	}
+
+	existingSubnet, uniqueTagsExist, err := ec2.CheckUniqueTagsForSubnet(c, tags, subnet.SubnetCidr)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for unique tags on subnet: %w", err)
+	}
+	if uniqueTagsExist {
+		changeSet.Add(kind, subnetName, *existingSubnet.SubnetId, plan.ActionAdopt)
+		return *existingSubnet.SubnetId, nil
+	}
+	changeSet.Add(kind, subnetName, "", plan.ActionCreate)
+
+	return "", nil
+}
+
+// planRouteTable adds the planned action for a route table that is not in
+// inventory to the change set.
+func (c *EksClient) planRouteTable(
+	changeSet *plan.ChangeSet,
+	kind string,
+	name string,
+	tags *[]types.Tag,
+) error {
+	routeTables, uniqueTagsExist, err := ec2.CheckUniqueTagsForRouteTables(c, tags)
+	if err != nil {
+		return fmt.Errorf("failed to check for unique tags on route tables: %w", err)
+	}
+	if routeTables != nil && len(*routeTables) > 1 {
+		return errors.New("multiple route tables with matching tags found")
+	}
+	if uniqueTagsExist {
+		changeSet.Add(kind, name, *(*routeTables)[0].RouteTableId, plan.ActionAdopt)
+	} else {
+		changeSet.Add(kind, name, "", plan.ActionCreate)
+	}
+
+	return nil
+}
+
+// planRole adds the planned action for an IAM role to the change set.
+func (c *EksClient) planRole(
+	changeSet *plan.ChangeSet,
+	roleInventory RoleInventory,
+	roleName string,
+) error {
+	if roleInventory.RoleName != "" {
+		changeSet.Add("iam-role", roleInventory.RoleName, roleInventory.RoleArn, plan.ActionReuse)
+		return nil
+	}
+
+	role, err := c.getRole(roleName)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		changeSet.Add("iam-role", roleName, "", plan.ActionCreate)
+	case err != nil:
+		return err
+	default:
+		changeSet.Add("iam-role", roleName, *role.Arn, plan.ActionAdopt)
+	}
+
+	return nil
+}
+
+// planPolicy adds the planned action for an IAM policy to the change set.
+func (c *EksClient) planPolicy(
+	changeSet *plan.ChangeSet,
+	policyArns []string,
+	policyName string,
+	policyPath string,
+) error {
+	if len(policyArns) != 0 {
+		for _, policyArn := range policyArns {
+			changeSet.Add("iam-policy", policyName, policyArn, plan.ActionReuse)
+		}
+		return nil
+	}
+
+	policy, err := c.getPolicy(policyName, policyPath)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		changeSet.Add("iam-policy", policyName, "", plan.ActionCreate)
+	case err != nil:
+		return err
+	default:
+		changeSet.Add("iam-policy", policyName, *policy.Arn, plan.ActionAdopt)
+	}
+
+	return nil
+}
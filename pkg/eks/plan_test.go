@@ -0,0 +1,73 @@
+package eks
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+	"github.com/nukleros/aws-builder/pkg/plan"
+)
+
+// checkActions checks every change in the change set has the action, other
+// than the IAM roles and policies for optional features not requested in the
+// config, and that the change for the VPC has the ID.
+func checkActions(t *testing.T, changeSet *plan.ChangeSet, action plan.Action, vpcId string) {
+	t.Helper()
+
+	if len(changeSet.Changes) == 0 {
+		t.Fatal("expected changes in change set")
+	}
+	for _, change := range changeSet.Changes {
+		switch {
+		case change.Action == plan.ActionSkip && (change.Kind == "iam-role" || change.Kind == "iam-policy"):
+		case change.Action != action:
+			t.Errorf("expected %s for %s %s, got %s", action, change.Kind, change.Name, change.Action)
+		case change.Kind == "vpc" && change.Id != vpcId:
+			t.Errorf("expected VPC %q in change set, got %q", vpcId, change.Id)
+		}
+	}
+}
+
+func TestPlanEksResourceStack(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+
+	// nothing exists so every resource is created
+	changeSet, err := eksClient.PlanEksResourceStack(resourceConfig, nil)
+	if err != nil {
+		t.Fatalf("failed to plan resource stack: %v", err)
+	}
+	checkActions(t, changeSet, plan.ActionCreate, "")
+	if resources := backend.Resources(); len(resources) != 0 {
+		t.Errorf("expected planning to create nothing, found %v", resources)
+	}
+
+	var inventory EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	created := backend.Resources()
+
+	// resources in the inventory are reused
+	changeSet, err = eksClient.PlanEksResourceStack(resourceConfig, &inventory)
+	if err != nil {
+		t.Fatalf("failed to plan resource stack: %v", err)
+	}
+	checkActions(t, changeSet, plan.ActionReuse, inventory.VpcId)
+
+	// resources with the resource stack's tags or names that are not in the
+	// inventory are adopted
+	changeSet, err = eksClient.PlanEksResourceStack(resourceConfig, &EksInventory{})
+	if err != nil {
+		t.Fatalf("failed to plan resource stack: %v", err)
+	}
+	checkActions(t, changeSet, plan.ActionAdopt, inventory.VpcId)
+	if resources := backend.Resources(); !reflect.DeepEqual(resources, created) {
+		t.Errorf("expected planning to change nothing, found %v", resources)
+	}
+
+	if _, err := eksClient.PlanEksResourceStack(resourceConfig, &EksInventory{Region: "us-west-2"}); err == nil {
+		t.Error("expected an error for an inventory in another region")
+	}
+}
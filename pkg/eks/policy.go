@@ -7,6 +7,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 const (
@@ -27,7 +29,7 @@ func (c *EksClient) CreateDnsManagementPolicy(
 	tags *[]types.Tag,
 	clusterName string,
 ) (*types.Policy, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	dnsPolicyName := fmt.Sprintf("%s-%s", DnsPolicyName, clusterName)
 	dnsPolicyPath := fmt.Sprintf("/%s/", clusterName)
@@ -96,7 +98,7 @@ func (c *EksClient) CreateDns01ChallengePolicy(
 	tags *[]types.Tag,
 	clusterName string,
 ) (*types.Policy, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	dnsPolicyName := fmt.Sprintf("%s-%s", Dns01ChallengePolicyName, clusterName)
 	dnsPolicyPath := fmt.Sprintf("/%s/", clusterName)
@@ -172,7 +174,7 @@ func (c *EksClient) CreateSecretsManagerPolicy(
 	tags *[]types.Tag,
 	clusterName string,
 ) (*types.Policy, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	secretsManagerPolicyName := fmt.Sprintf("%s-%s", SecretsManagerPolicyName, clusterName)
 	secretsManagerPolicyPath := fmt.Sprintf("/%s/", clusterName)
@@ -237,7 +239,7 @@ func (c *EksClient) CreateClusterAutoscalingPolicy(
 	tags *[]types.Tag,
 	clusterName string,
 ) (*types.Policy, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	autoscalingPolicyName := fmt.Sprintf("%s-%s", AutoscalingPolicyName, clusterName)
 	autoscalingPolicyPath := fmt.Sprintf("/%s/", clusterName)
@@ -307,29 +309,58 @@ func (c *EksClient) CreateClusterAutoscalingPolicy(
 }
 
 // DeletePolicies deletes the IAM policies.  If the policyArns slice is empty it
-// returns without error.
+// returns without error.  Every policy is attempted and an error joining each
+// failure is returned.
 func (c *EksClient) DeletePolicies(policyArns []string) ([]string, error) {
-	// if roleArn is empty, there's nothing to delete
-	if len(policyArns) == 0 {
-		return policyArns, nil
-	}
+	_, err := c.deletePolicies(policyArns)
 
-	for _, policyArn := range policyArns {
-		svc := iam.NewFromConfig(*c.AwsConfig)
+	return policyArns, err
+}
 
+// deletePolicies deletes the IAM policies and returns the ARNs of those
+// deleted or not found.  Every policy is attempted and an error joining each
+// failure is returned.
+func (c *EksClient) deletePolicies(policyArns []string) ([]string, error) {
+	svc := c.GetIamApi()
+
+	var deletedPolicyArns []string
+	var errs []error
+	for _, policyArn := range policyArns {
 		deletePolicyInput := iam.DeletePolicyInput{
 			PolicyArn: &policyArn,
 		}
 		_, err := svc.DeletePolicy(c.Context, &deletePolicyInput)
 		if err != nil {
 			var noSuchEntityErr *types.NoSuchEntityException
-			if errors.As(err, &noSuchEntityErr) {
+			if !errors.As(err, &noSuchEntityErr) {
+				errs = append(errs, fmt.Errorf("failed to delete policy %s: %w", policyArn, err))
 				continue
-			} else {
-				return policyArns, fmt.Errorf("failed to delete policy %s: %w", policyArn, err)
 			}
 		}
+		deletedPolicyArns = append(deletedPolicyArns, policyArn)
+	}
+
+	return deletedPolicyArns, errors.Join(errs...)
+}
+
+// getPolicy retrieves a customer managed IAM policy by name and path.  If the
+// policy is not found util.ErrResourceNotFound is returned.
+func (c *EksClient) getPolicy(policyName, policyPath string) (*types.Policy, error) {
+	svc := c.GetIamApi()
+
+	listPoliciesInput := iam.ListPoliciesInput{
+		PathPrefix: &policyPath,
+		Scope:      types.PolicyScopeTypeLocal,
+	}
+	listPoliciesOutput, err := svc.ListPolicies(c.Context, &listPoliciesInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies to find %s policy: %w", policyName, err)
+	}
+	for _, policy := range listPoliciesOutput.Policies {
+		if *policy.PolicyName == policyName {
+			return &policy, nil
+		}
 	}
 
-	return policyArns, nil
+	return nil, util.ErrResourceNotFound
 }
@@ -1,8 +1,11 @@
 package eks
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/nukleros/aws-builder/pkg/client"
 	"github.com/nukleros/aws-builder/pkg/ec2"
 	"github.com/nukleros/aws-builder/pkg/iam"
 	"github.com/nukleros/aws-builder/pkg/util"
@@ -43,6 +46,13 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 	}
 
+	// set defaults that depend on other config values for configs not loaded
+	// from file
+	resourceConfig.SetDefaults()
+
+	// waiter settings on the client take precedence over resource config
+	c.Waiters = c.Waiters.Merge(resourceConfig.Waiters)
+
 	// Tags
 	ec2Tags := ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags)
 	iamTags := iam.CreateIamTags(resourceConfig.Name, resourceConfig.Tags)
@@ -57,14 +67,14 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 		if azInventory != nil {
 			inventory.AvailabilityZones = *azInventory
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("availability-zones", err)
 		}
-		c.SendMessage("Availability zones set up")
+		c.sendEvent("availability-zones", client.PhaseCreated, "Availability zones set up")
 	} else {
-		c.SendMessage("Availability zones found in inventory")
+		c.sendEvent("availability-zones", client.PhaseFoundInInventory, "Availability zones found in inventory")
 	}
 
 	// VPC
@@ -76,14 +86,14 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 		if vpc != nil {
 			inventory.VpcId = *vpc.VpcId
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("vpc", err)
 		}
-		c.SendMessage(fmt.Sprintf("VPC created: %s", *vpc.VpcId))
+		c.sendEvent("vpc", client.PhaseCreated, fmt.Sprintf("VPC created: %s", *vpc.VpcId), *vpc.VpcId)
 	} else {
-		c.SendMessage(fmt.Sprintf("VPC found in inventory: %s", inventory.VpcId))
+		c.sendEvent("vpc", client.PhaseFoundInInventory, fmt.Sprintf("VPC found in inventory: %s", inventory.VpcId), inventory.VpcId)
 	}
 
 	// Internet Gateway
@@ -95,14 +105,14 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 		if igw != nil {
 			inventory.InternetGatewayId = *igw.InternetGatewayId
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("internet-gateway", err)
 		}
-		c.SendMessage(fmt.Sprintf("Internet gateway created: %s", *igw.InternetGatewayId))
+		c.sendEvent("internet-gateway", client.PhaseCreated, fmt.Sprintf("Internet gateway created: %s", *igw.InternetGatewayId), *igw.InternetGatewayId)
 	} else {
-		c.SendMessage(fmt.Sprintf("Internet gateway found in inventory: %s", inventory.InternetGatewayId))
+		c.sendEvent("internet-gateway", client.PhaseFoundInInventory, fmt.Sprintf("Internet gateway found in inventory: %s", inventory.InternetGatewayId), inventory.InternetGatewayId)
 	}
 
 	// Public Subnets
@@ -126,14 +136,14 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 		if azInventory != nil {
 			inventory.AvailabilityZones = *azInventory
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("public-subnet", err)
 		}
-		c.SendMessage(fmt.Sprintf("Public subnets created: %s", publicSubnetIds))
+		c.sendEvent("public-subnet", client.PhaseCreated, fmt.Sprintf("Public subnets created: %s", publicSubnetIds), publicSubnetIds...)
 	} else {
-		c.SendMessage(fmt.Sprintf("Public subnets found in inventory: %s", inventoryPublicSubnetIds))
+		c.sendEvent("public-subnet", client.PhaseFoundInInventory, fmt.Sprintf("Public subnets found in inventory: %s", inventoryPublicSubnetIds), inventoryPublicSubnetIds...)
 	}
 
 	// Private Subnets
@@ -158,14 +168,14 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 		if azInventory != nil {
 			inventory.AvailabilityZones = *azInventory
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("private-subnet", err)
 		}
-		c.SendMessage(fmt.Sprintf("Private subnets created: %s", privateSubnetIds))
+		c.sendEvent("private-subnet", client.PhaseCreated, fmt.Sprintf("Private subnets created: %s", privateSubnetIds), privateSubnetIds...)
 	} else {
-		c.SendMessage(fmt.Sprintf("Private subnets found in inventory: %s", inventoryPrivateSubnetIds))
+		c.sendEvent("private-subnet", client.PhaseFoundInInventory, fmt.Sprintf("Private subnets found in inventory: %s", inventoryPrivateSubnetIds), inventoryPrivateSubnetIds...)
 	}
 
 	// Elastic IPs
@@ -175,13 +185,13 @@ func (c *EksClient) CreateEksResourceStack(
 			&inventory.AvailabilityZones,
 		)
 		inventory.ElasticIpIds = elasticIpIds
-		inventory.send(c.InventoryChan)
+		c.sendInventory(inventory)
 		if err != nil {
-			return err
+			return c.sendFailed("elastic-ip", err)
 		}
-		c.SendMessage(fmt.Sprintf("Elastic IPs created: %s", elasticIpIds))
+		c.sendEvent("elastic-ip", client.PhaseCreated, fmt.Sprintf("Elastic IPs created: %s", elasticIpIds), elasticIpIds...)
 	} else {
-		c.SendMessage(fmt.Sprintf("Elastic IPs found in inventory: %s", inventory.ElasticIpIds))
+		c.sendEvent("elastic-ip", client.PhaseFoundInInventory, fmt.Sprintf("Elastic IPs found in inventory: %s", inventory.ElasticIpIds), inventory.ElasticIpIds...)
 	}
 
 	// NAT Gateways
@@ -200,10 +210,10 @@ func (c *EksClient) CreateEksResourceStack(
 			&inventory.AvailabilityZones,
 			inventory.ElasticIpIds,
 		); err != nil {
-			return err
+			return c.sendFailed("nat-gateway", err)
 		}
-		c.SendMessage("NAT gateways created")
-		c.SendMessage("Waiting for NAT gateways to become active")
+		c.sendEvent("nat-gateway", client.PhaseCreated, "NAT gateways created")
+		c.sendEvent("nat-gateway", client.PhaseWaiting, "Waiting for NAT gateways to become active")
 		updatedAzInventory, natGatewayIds, err := c.WaitForNatGateways(
 			inventory.VpcId,
 			&inventory.AvailabilityZones,
@@ -211,14 +221,14 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 		if updatedAzInventory != nil {
 			inventory.AvailabilityZones = *updatedAzInventory
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("nat-gateway", err)
 		}
-		c.SendMessage(fmt.Sprintf("NAT gateways ready: %s", natGatewayIds))
+		c.sendEvent("nat-gateway", client.PhaseReady, fmt.Sprintf("NAT gateways ready: %s", natGatewayIds), natGatewayIds...)
 	} else {
-		c.SendMessage(fmt.Sprintf("NAT gateways found in inventory: %s", inventoryNatGatewayIds))
+		c.sendEvent("nat-gateway", client.PhaseFoundInInventory, fmt.Sprintf("NAT gateways found in inventory: %s", inventoryNatGatewayIds), inventoryNatGatewayIds...)
 	}
 
 	// Public Route Table
@@ -231,14 +241,14 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 		if publicRouteTable != nil {
 			inventory.PublicRouteTableId = *publicRouteTable.RouteTableId
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("public-route-table", err)
 		}
-		c.SendMessage(fmt.Sprintf("Public route table created: %s", *publicRouteTable.RouteTableId))
+		c.sendEvent("public-route-table", client.PhaseCreated, fmt.Sprintf("Public route table created: %s", *publicRouteTable.RouteTableId), *publicRouteTable.RouteTableId)
 	} else {
-		c.SendMessage(fmt.Sprintf("Public route table found in inventory: %s", inventory.PublicRouteTableId))
+		c.sendEvent("public-route-table", client.PhaseFoundInInventory, fmt.Sprintf("Public route table found in inventory: %s", inventory.PublicRouteTableId), inventory.PublicRouteTableId)
 	}
 
 	// Private Route Tables
@@ -254,14 +264,14 @@ func (c *EksClient) CreateEksResourceStack(
 				privateRouteTableIds = append(privateRouteTableIds, *rt.RouteTableId)
 			}
 			inventory.PrivateRouteTableIds = privateRouteTableIds
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("private-route-table", err)
 		}
-		c.SendMessage(fmt.Sprintf("Private route tables created: %s", privateRouteTableIds))
+		c.sendEvent("private-route-table", client.PhaseCreated, fmt.Sprintf("Private route tables created: %s", privateRouteTableIds), privateRouteTableIds...)
 	} else {
-		c.SendMessage(fmt.Sprintf("Private route tables found in inventory: %s", inventory.PrivateRouteTableIds))
+		c.sendEvent("private-route-table", client.PhaseFoundInInventory, fmt.Sprintf("Private route tables found in inventory: %s", inventory.PrivateRouteTableIds), inventory.PrivateRouteTableIds...)
 	}
 
 	// IAM Role for cluster
@@ -276,14 +286,14 @@ func (c *EksClient) CreateEksResourceStack(
 				RoleArn:        *clusterRole.Arn,
 				RolePolicyArns: []string{ClusterPolicyArn},
 			}
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("iam-role", err)
 		}
-		c.SendMessage(fmt.Sprintf("IAM role for cluster created: %s", *clusterRole.RoleName))
+		c.sendEvent("iam-role", client.PhaseCreated, fmt.Sprintf("IAM role for cluster created: %s", *clusterRole.RoleName), *clusterRole.RoleName)
 	} else {
-		c.SendMessage(fmt.Sprintf("IAM role for cluster found in inventory: %s", inventory.ClusterRole.RoleName))
+		c.sendEvent("iam-role", client.PhaseFoundInInventory, fmt.Sprintf("IAM role for cluster found in inventory: %s", inventory.ClusterRole.RoleName), inventory.ClusterRole.RoleName)
 	}
 
 	// IAM Role for worker nodes
@@ -298,14 +308,14 @@ func (c *EksClient) CreateEksResourceStack(
 				RoleArn:        *nodeRole.Arn,
 				RolePolicyArns: getWorkerPolicyArns(),
 			}
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("iam-role", err)
 		}
-		c.SendMessage(fmt.Sprintf("IAM role for worker nodes created: %s", *nodeRole.RoleName))
+		c.sendEvent("iam-role", client.PhaseCreated, fmt.Sprintf("IAM role for worker nodes created: %s", *nodeRole.RoleName), *nodeRole.RoleName)
 	} else {
-		c.SendMessage(fmt.Sprintf("IAM role for worker nodes found in inventory: %s", inventory.WorkerRole.RoleName))
+		c.sendEvent("iam-role", client.PhaseFoundInInventory, fmt.Sprintf("IAM role for worker nodes found in inventory: %s", inventory.WorkerRole.RoleName), inventory.WorkerRole.RoleName)
 	}
 
 	// EKS Cluster
@@ -320,31 +330,31 @@ func (c *EksClient) CreateEksResourceStack(
 		if cluster != nil {
 			inventory.Cluster.ClusterName = *cluster.Name
 			inventory.Cluster.ClusterArn = *cluster.Arn
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("eks-cluster", err)
 		}
-		c.SendMessage(fmt.Sprintf("EKS cluster created: %s", *cluster.Name))
+		c.sendEvent("eks-cluster", client.PhaseCreated, fmt.Sprintf("EKS cluster created: %s", *cluster.Name), *cluster.Name)
 	} else {
-		c.SendMessage(fmt.Sprintf("EKS cluster found in inventory: %s", inventory.Cluster.ClusterName))
+		c.sendEvent("eks-cluster", client.PhaseFoundInInventory, fmt.Sprintf("EKS cluster found in inventory: %s", inventory.Cluster.ClusterName), inventory.Cluster.ClusterName)
 	}
 	if inventory.Cluster.OidcProviderUrl == "" {
-		c.SendMessage(fmt.Sprintf("Waiting for EKS cluster to become active: %s", inventory.Cluster.ClusterName))
+		c.sendEvent("eks-cluster", client.PhaseWaiting, fmt.Sprintf("Waiting for EKS cluster to become active: %s", inventory.Cluster.ClusterName), inventory.Cluster.ClusterName)
 		oidcIssuer, err := c.WaitForCluster(
 			inventory.Cluster.ClusterName,
 			ClusterConditionCreated,
 		)
 		if oidcIssuer != "" {
 			inventory.Cluster.OidcProviderUrl = oidcIssuer
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("eks-cluster", err)
 		}
-		c.SendMessage(fmt.Sprintf("EKS cluster ready: %s", inventory.Cluster.ClusterName))
+		c.sendEvent("eks-cluster", client.PhaseReady, fmt.Sprintf("EKS cluster ready: %s", inventory.Cluster.ClusterName), inventory.Cluster.ClusterName)
 	} else {
-		c.SendMessage(fmt.Sprintf("EKS cluster found in inventory is ready: %s", inventory.Cluster.ClusterName))
+		c.sendEvent("eks-cluster", client.PhaseReady, fmt.Sprintf("EKS cluster found in inventory is ready: %s", inventory.Cluster.ClusterName), inventory.Cluster.ClusterName)
 	}
 
 	// EKS Cluster Security Group
@@ -352,14 +362,14 @@ func (c *EksClient) CreateEksResourceStack(
 		securityGroupId, err := c.GetClusterSecurityGroup(resourceConfig.Name)
 		if securityGroupId != "" {
 			inventory.SecurityGroupId = securityGroupId
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("security-group", err)
 		}
-		c.SendMessage(fmt.Sprintf("EKS cluster security group ID %s retrieved", securityGroupId))
+		c.sendEvent("security-group", client.PhaseCreated, fmt.Sprintf("EKS cluster security group ID %s retrieved", securityGroupId), securityGroupId)
 	} else {
-		c.SendMessage(fmt.Sprintf("EKS cluster security group ID %s found in inventory", inventory.SecurityGroupId))
+		c.sendEvent("security-group", client.PhaseFoundInInventory, fmt.Sprintf("EKS cluster security group ID %s found in inventory", inventory.SecurityGroupId), inventory.SecurityGroupId)
 	}
 
 	// Node Groups
@@ -382,23 +392,23 @@ func (c *EksClient) CreateEksResourceStack(
 				nodeGroupNames = append(nodeGroupNames, *nodeGroup.NodegroupName)
 			}
 			inventory.NodeGroupNames = nodeGroupNames
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("eks-node-group", err)
 		}
-		c.SendMessage(fmt.Sprintf("EKS node groups created: %s", nodeGroupNames))
-		c.SendMessage(fmt.Sprintf("Waiting for EKS node groups to become active: %s", nodeGroupNames))
+		c.sendEvent("eks-node-group", client.PhaseCreated, fmt.Sprintf("EKS node groups created: %s", nodeGroupNames), nodeGroupNames...)
+		c.sendEvent("eks-node-group", client.PhaseWaiting, fmt.Sprintf("Waiting for EKS node groups to become active: %s", nodeGroupNames), nodeGroupNames...)
 		if err := c.WaitForNodeGroups(
 			inventory.Cluster.ClusterName,
 			nodeGroupNames,
 			NodeGroupConditionCreated,
 		); err != nil {
-			return err
+			return c.sendFailed("eks-node-group", err)
 		}
-		c.SendMessage(fmt.Sprintf("EKS node groups ready: %s", nodeGroupNames))
+		c.sendEvent("eks-node-group", client.PhaseReady, fmt.Sprintf("EKS node groups ready: %s", nodeGroupNames), nodeGroupNames...)
 	} else {
-		c.SendMessage(fmt.Sprintf("EKS node groups found in inventory: %s", inventory.NodeGroupNames))
+		c.sendEvent("eks-node-group", client.PhaseFoundInInventory, fmt.Sprintf("EKS node groups found in inventory: %s", inventory.NodeGroupNames), inventory.NodeGroupNames...)
 	}
 
 	// OIDC Provider
@@ -409,14 +419,14 @@ func (c *EksClient) CreateEksResourceStack(
 		)
 		if oidcProviderArn != "" {
 			inventory.OidcProviderArn = oidcProviderArn
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("iam-oidc-provider", err)
 		}
-		c.SendMessage(fmt.Sprintf("OIDC provider created: %s", oidcProviderArn))
+		c.sendEvent("iam-oidc-provider", client.PhaseCreated, fmt.Sprintf("OIDC provider created: %s", oidcProviderArn), oidcProviderArn)
 	} else {
-		c.SendMessage(fmt.Sprintf("OIDC provider found in inventory: %s", inventory.OidcProviderArn))
+		c.sendEvent("iam-oidc-provider", client.PhaseFoundInInventory, fmt.Sprintf("OIDC provider found in inventory: %s", inventory.OidcProviderArn), inventory.OidcProviderArn)
 	}
 
 	// IAM Policy for DNS Management
@@ -431,17 +441,17 @@ func (c *EksClient) CreateEksResourceStack(
 				inventory.DnsManagementRole = RoleInventory{
 					RolePolicyArns: []string{*dnsPolicy.Arn},
 				}
-				inventory.send(c.InventoryChan)
+				c.sendInventory(inventory)
 			}
 			if err != nil {
-				return err
+				return c.sendFailed("iam-policy", err)
 			}
-			c.SendMessage(fmt.Sprintf("IAM policy created: %s", *dnsPolicy.PolicyName))
+			c.sendEvent("iam-policy", client.PhaseCreated, fmt.Sprintf("IAM policy created: %s", *dnsPolicy.PolicyName), *dnsPolicy.PolicyName)
 		} else {
-			c.SendMessage(fmt.Sprintf("IAM policy found in inventory: %s", inventory.DnsManagementRole.RolePolicyArns))
+			c.sendEvent("iam-policy", client.PhaseFoundInInventory, fmt.Sprintf("IAM policy found in inventory: %s", inventory.DnsManagementRole.RolePolicyArns), inventory.DnsManagementRole.RolePolicyArns...)
 		}
 	} else {
-		c.SendMessage("IAM policy for DNS management not requested")
+		c.sendEvent("iam-policy", client.PhaseNotRequested, "IAM policy for DNS management not requested")
 	}
 
 	// IAM Role for DNS Management
@@ -461,17 +471,17 @@ func (c *EksClient) CreateEksResourceStack(
 			if dnsManagementRole != nil {
 				inventory.DnsManagementRole.RoleName = *dnsManagementRole.RoleName
 				inventory.DnsManagementRole.RoleArn = *dnsManagementRole.Arn
-				inventory.send(c.InventoryChan)
+				c.sendInventory(inventory)
 			}
 			if err != nil {
-				return err
+				return c.sendFailed("iam-role", err)
 			}
-			c.SendMessage(fmt.Sprintf("IAM role for DNS management created: %s", *dnsManagementRole.RoleName))
+			c.sendEvent("iam-role", client.PhaseCreated, fmt.Sprintf("IAM role for DNS management created: %s", *dnsManagementRole.RoleName), *dnsManagementRole.RoleName)
 		} else {
-			c.SendMessage(fmt.Sprintf("IAM role for DNS management found in inventory: %s", inventory.DnsManagementRole.RoleName))
+			c.sendEvent("iam-role", client.PhaseFoundInInventory, fmt.Sprintf("IAM role for DNS management found in inventory: %s", inventory.DnsManagementRole.RoleName), inventory.DnsManagementRole.RoleName)
 		}
 	} else {
-		c.SendMessage("IAM role for DNS management not requested")
+		c.sendEvent("iam-role", client.PhaseNotRequested, "IAM role for DNS management not requested")
 	}
 
 	// IAM Policy for DNS01 Challenge
@@ -486,17 +496,17 @@ func (c *EksClient) CreateEksResourceStack(
 				inventory.Dns01ChallengeRole = RoleInventory{
 					RolePolicyArns: []string{*dns01ChallengePolicy.Arn},
 				}
-				inventory.send(c.InventoryChan)
+				c.sendInventory(inventory)
 			}
 			if err != nil {
-				return err
+				return c.sendFailed("iam-policy", err)
 			}
-			c.SendMessage(fmt.Sprintf("IAM policy created: %s", *dns01ChallengePolicy.PolicyName))
+			c.sendEvent("iam-policy", client.PhaseCreated, fmt.Sprintf("IAM policy created: %s", *dns01ChallengePolicy.PolicyName), *dns01ChallengePolicy.PolicyName)
 		} else {
-			c.SendMessage(fmt.Sprintf("IAM policy found in inventory: %s", inventory.Dns01ChallengeRole.RolePolicyArns))
+			c.sendEvent("iam-policy", client.PhaseFoundInInventory, fmt.Sprintf("IAM policy found in inventory: %s", inventory.Dns01ChallengeRole.RolePolicyArns), inventory.Dns01ChallengeRole.RolePolicyArns...)
 		}
 	} else {
-		c.SendMessage("IAM policy for DNS01 challenge not requested")
+		c.sendEvent("iam-policy", client.PhaseNotRequested, "IAM policy for DNS01 challenge not requested")
 	}
 
 	// IAM Role for DNS01 Challenges
@@ -516,17 +526,17 @@ func (c *EksClient) CreateEksResourceStack(
 			if dns01ChallengeRole != nil {
 				inventory.Dns01ChallengeRole.RoleName = *dns01ChallengeRole.RoleName
 				inventory.Dns01ChallengeRole.RoleArn = *dns01ChallengeRole.Arn
-				inventory.send(c.InventoryChan)
+				c.sendInventory(inventory)
 			}
 			if err != nil {
-				return err
+				return c.sendFailed("iam-role", err)
 			}
-			c.SendMessage(fmt.Sprintf("IAM role for DNS01 challenges created: %s", *dns01ChallengeRole.RoleName))
+			c.sendEvent("iam-role", client.PhaseCreated, fmt.Sprintf("IAM role for DNS01 challenges created: %s", *dns01ChallengeRole.RoleName), *dns01ChallengeRole.RoleName)
 		} else {
-			c.SendMessage(fmt.Sprintf("IAM role for DNS01 challenges found in inventory: %s", inventory.Dns01ChallengeRole.RolePolicyArns))
+			c.sendEvent("iam-role", client.PhaseFoundInInventory, fmt.Sprintf("IAM role for DNS01 challenges found in inventory: %s", inventory.Dns01ChallengeRole.RoleName), inventory.Dns01ChallengeRole.RoleName)
 		}
 	} else {
-		c.SendMessage("IAM role for DNS01 challenge not requested")
+		c.sendEvent("iam-role", client.PhaseNotRequested, "IAM role for DNS01 challenge not requested")
 	}
 
 	// IAM Policy for SecretsManager
@@ -541,17 +551,17 @@ func (c *EksClient) CreateEksResourceStack(
 				inventory.SecretsManagerRole = RoleInventory{
 					RolePolicyArns: []string{*secretsManagerPolicy.Arn},
 				}
-				inventory.send(c.InventoryChan)
+				c.sendInventory(inventory)
 			}
 			if err != nil {
-				return err
+				return c.sendFailed("iam-policy", err)
 			}
-			c.SendMessage(fmt.Sprintf("IAM policy created: %s", *secretsManagerPolicy.PolicyName))
+			c.sendEvent("iam-policy", client.PhaseCreated, fmt.Sprintf("IAM policy created: %s", *secretsManagerPolicy.PolicyName), *secretsManagerPolicy.PolicyName)
 		} else {
-			c.SendMessage(fmt.Sprintf("IAM policy found in inventory: %s", inventory.SecretsManagerRole.RolePolicyArns))
+			c.sendEvent("iam-policy", client.PhaseFoundInInventory, fmt.Sprintf("IAM policy found in inventory: %s", inventory.SecretsManagerRole.RolePolicyArns), inventory.SecretsManagerRole.RolePolicyArns...)
 		}
 	} else {
-		c.SendMessage("IAM policy for secrets manager not requested")
+		c.sendEvent("iam-policy", client.PhaseNotRequested, "IAM policy for secrets manager not requested")
 	}
 
 	// IAM Role for SecretsManager
@@ -571,17 +581,17 @@ func (c *EksClient) CreateEksResourceStack(
 			if secretsManagerRole != nil {
 				inventory.SecretsManagerRole.RoleName = *secretsManagerRole.RoleName
 				inventory.SecretsManagerRole.RoleArn = *secretsManagerRole.Arn
-				inventory.send(c.InventoryChan)
+				c.sendInventory(inventory)
 			}
 			if err != nil {
-				return err
+				return c.sendFailed("iam-role", err)
 			}
-			c.SendMessage(fmt.Sprintf("IAM role for secrets manager created: %s", *secretsManagerRole.RoleName))
+			c.sendEvent("iam-role", client.PhaseCreated, fmt.Sprintf("IAM role for secrets manager created: %s", *secretsManagerRole.RoleName), *secretsManagerRole.RoleName)
 		} else {
-			c.SendMessage(fmt.Sprintf("IAM role for secrets manager found in inventory: %s", inventory.SecretsManagerRole.RolePolicyArns))
+			c.sendEvent("iam-role", client.PhaseFoundInInventory, fmt.Sprintf("IAM role for secrets manager found in inventory: %s", inventory.SecretsManagerRole.RoleName), inventory.SecretsManagerRole.RoleName)
 		}
 	} else {
-		c.SendMessage("IAM role for secrets manager not requested")
+		c.sendEvent("iam-role", client.PhaseNotRequested, "IAM role for secrets manager not requested")
 	}
 
 	// IAM Policy for Cluster Autoscaling
@@ -596,17 +606,17 @@ func (c *EksClient) CreateEksResourceStack(
 				inventory.ClusterAutoscalingRole = RoleInventory{
 					RolePolicyArns: []string{*clusterAutoscalingPolicy.Arn},
 				}
-				inventory.send(c.InventoryChan)
+				c.sendInventory(inventory)
 			}
 			if err != nil {
-				return err
+				return c.sendFailed("iam-policy", err)
 			}
-			c.SendMessage(fmt.Sprintf("IAM policy created: %s", *clusterAutoscalingPolicy.PolicyName))
+			c.sendEvent("iam-policy", client.PhaseCreated, fmt.Sprintf("IAM policy created: %s", *clusterAutoscalingPolicy.PolicyName), *clusterAutoscalingPolicy.PolicyName)
 		} else {
-			c.SendMessage(fmt.Sprintf("IAM policy found in inventory: %s", inventory.ClusterAutoscalingRole.RolePolicyArns))
+			c.sendEvent("iam-policy", client.PhaseFoundInInventory, fmt.Sprintf("IAM policy found in inventory: %s", inventory.ClusterAutoscalingRole.RolePolicyArns), inventory.ClusterAutoscalingRole.RolePolicyArns...)
 		}
 	} else {
-		c.SendMessage("IAM policy for cluster autoscaling not requested")
+		c.sendEvent("iam-policy", client.PhaseNotRequested, "IAM policy for cluster autoscaling not requested")
 	}
 
 	// IAM Role for Cluster Autoscaling
@@ -626,17 +636,17 @@ func (c *EksClient) CreateEksResourceStack(
 			if clusterAutoscalingRole != nil {
 				inventory.ClusterAutoscalingRole.RoleName = *clusterAutoscalingRole.RoleName
 				inventory.ClusterAutoscalingRole.RoleArn = *clusterAutoscalingRole.Arn
-				inventory.send(c.InventoryChan)
+				c.sendInventory(inventory)
 			}
 			if err != nil {
-				return err
+				return c.sendFailed("iam-role", err)
 			}
-			c.SendMessage(fmt.Sprintf("IAM role for cluster autoscaling created: %s", *clusterAutoscalingRole.RoleName))
+			c.sendEvent("iam-role", client.PhaseCreated, fmt.Sprintf("IAM role for cluster autoscaling created: %s", *clusterAutoscalingRole.RoleName), *clusterAutoscalingRole.RoleName)
 		} else {
-			c.SendMessage(fmt.Sprintf("IAM role for cluster autoscaling found in inventory: %s", inventory.ClusterAutoscalingRole.RoleName))
+			c.sendEvent("iam-role", client.PhaseFoundInInventory, fmt.Sprintf("IAM role for cluster autoscaling found in inventory: %s", inventory.ClusterAutoscalingRole.RoleName), inventory.ClusterAutoscalingRole.RoleName)
 		}
 	} else {
-		c.SendMessage("IAM role for cluster autoscaling not requested")
+		c.sendEvent("iam-role", client.PhaseNotRequested, "IAM role for cluster autoscaling not requested")
 	}
 
 	// IAM Role for Storage Management
@@ -654,14 +664,14 @@ func (c *EksClient) CreateEksResourceStack(
 				RoleArn:        *storageManagementRole.Arn,
 				RolePolicyArns: []string{CsiDriverPolicyArn},
 			}
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("iam-role", err)
 		}
-		c.SendMessage(fmt.Sprintf("IAM role for storage management created: %s", *storageManagementRole.RoleName))
+		c.sendEvent("iam-role", client.PhaseCreated, fmt.Sprintf("IAM role for storage management created: %s", *storageManagementRole.RoleName), *storageManagementRole.RoleName)
 	} else {
-		c.SendMessage(fmt.Sprintf("IAM role for storage management found in inventory: %s", inventory.StorageManagementRole.RoleName))
+		c.sendEvent("iam-role", client.PhaseFoundInInventory, fmt.Sprintf("IAM role for storage management found in inventory: %s", inventory.StorageManagementRole.RoleName), inventory.StorageManagementRole.RoleName)
 	}
 
 	// EBS CSI Addon
@@ -671,11 +681,11 @@ func (c *EksClient) CreateEksResourceStack(
 		inventory.StorageManagementRole.RoleArn,
 	)
 	if err != nil {
-		return err
+		return c.sendFailed("eks-addon", err)
 	}
 	inventory.ClusterAddon = true
-	inventory.send(c.InventoryChan)
-	c.SendMessage(fmt.Sprintf("EBS storage addon created: %s", ebsStorageAddonName))
+	c.sendInventory(inventory)
+	c.sendEvent("eks-addon", client.PhaseCreated, fmt.Sprintf("EBS storage addon created: %s", ebsStorageAddonName), ebsStorageAddonName)
 
 	c.SendMessage(fmt.Sprintf("EKS cluster creation complete: %s", inventory.Cluster.ClusterName))
 
@@ -683,143 +693,328 @@ func (c *EksClient) CreateEksResourceStack(
 }
 
 // DeleteResourceStack deletes all the resources in the resource inventory.
+// Adopted resources are kept, and left in the inventory, unless the client is
+// set to delete adopted resources.  Each resource is attempted even if others
+// fail, unless a resource it depends on still exists, in which case it is
+// skipped.  Resources that are not found are treated as deleted, so the
+// inventory is left with only the resources that still exist and deleting
+// again finishes the job.  An error joining every failure and skipped
+// resource is returned.
 func (c *EksClient) DeleteEksResourceStack(inventory *EksInventory) error {
 	c.AwsConfig.Region = inventory.Region
 
+	var errs []error
+	fail := func(kind string, err error) {
+		errs = append(errs, c.sendFailed(kind, err))
+	}
+	skip := func(kind string, message string, ids ...string) {
+		c.sendEvent(kind, client.PhaseSkipped, message, ids...)
+		errs = append(errs, errors.New(message))
+	}
+
 	// OIDC Provider
-	if err := c.DeleteOidcProvider(inventory.OidcProviderArn); err != nil {
-		return err
+	if inventory.OidcProviderArn != "" && !c.keepAdopted(inventory, "iam-oidc-provider", inventory.OidcProviderArn) {
+		if err := c.DeleteOidcProvider(inventory.OidcProviderArn); err != nil {
+			fail("iam-oidc-provider", err)
+		} else {
+			c.sendEvent("iam-oidc-provider", client.PhaseDeleted, fmt.Sprintf("OIDC provider deleted: %s", inventory.OidcProviderArn), inventory.OidcProviderArn)
+			inventory.OidcProviderArn = ""
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("OIDC provider deleted: %s", inventory.OidcProviderArn))
-	inventory.OidcProviderArn = ""
-	inventory.send(c.InventoryChan)
 
-	// Node Groups
-	if err := c.DeleteNodeGroups(inventory.Cluster.ClusterName, inventory.NodeGroupNames); err != nil {
-		return err
+	// Node Groups - adopted node groups are kept in inventory
+	var nodeGroupNames []string
+	for _, nodeGroupName := range inventory.NodeGroupNames {
+		if !c.keepAdopted(inventory, "eks-node-group", nodeGroupName) {
+			nodeGroupNames = append(nodeGroupNames, nodeGroupName)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("Node groups deletion initiated: %s", inventory.NodeGroupNames))
-	c.SendMessage(fmt.Sprintf("Waiting for node groups to be deleted: %s", inventory.NodeGroupNames))
-	if err := c.WaitForNodeGroups(inventory.Cluster.ClusterName, inventory.NodeGroupNames, NodeGroupConditionDeleted); err != nil {
-		return err
+	if len(nodeGroupNames) > 0 {
+		deletedNodeGroupNames, err := c.deleteNodeGroups(inventory.Cluster.ClusterName, nodeGroupNames)
+		if err != nil {
+			fail("eks-node-group", err)
+		}
+		if len(deletedNodeGroupNames) > 0 {
+			c.sendEvent("eks-node-group", client.PhaseDeleting, fmt.Sprintf("Node groups deletion initiated: %s", deletedNodeGroupNames), deletedNodeGroupNames...)
+			c.sendEvent("eks-node-group", client.PhaseWaiting, fmt.Sprintf("Waiting for node groups to be deleted: %s", deletedNodeGroupNames), deletedNodeGroupNames...)
+			if err := c.WaitForNodeGroups(inventory.Cluster.ClusterName, deletedNodeGroupNames, NodeGroupConditionDeleted); err != nil {
+				fail("eks-node-group", err)
+			} else {
+				c.sendEvent("eks-node-group", client.PhaseDeleted, fmt.Sprintf("Node groups deletion complete: %s", deletedNodeGroupNames), deletedNodeGroupNames...)
+				inventory.NodeGroupNames = without(inventory.NodeGroupNames, deletedNodeGroupNames)
+				c.sendInventory(inventory)
+			}
+		}
 	}
-	c.SendMessage(fmt.Sprintf("Node groups deletion complete: %s", inventory.NodeGroupNames))
-	inventory.NodeGroupNames = []string{}
-	inventory.send(c.InventoryChan)
 
-	// EKS Cluster
-	if err := c.DeleteCluster(inventory.Cluster.ClusterName); err != nil {
-		return err
+	// EKS Cluster - node groups must be deleted first
+	clusterName := inventory.Cluster.ClusterName
+	switch {
+	case clusterName == "" || c.keepAdopted(inventory, "eks-cluster", clusterName):
+	case len(inventory.NodeGroupNames) > 0:
+		skip("eks-cluster", fmt.Sprintf("EKS cluster %s not deleted because node groups still exist: %s", clusterName, inventory.NodeGroupNames), clusterName)
+	default:
+		if err := c.deleteCluster(clusterName); err != nil {
+			fail("eks-cluster", err)
+		} else {
+			c.sendEvent("eks-cluster", client.PhaseDeleted, fmt.Sprintf("EKS cluster deletion complete: %s", clusterName), clusterName)
+			// the addon and cluster security group are deleted with the cluster
+			inventory.Cluster = ClusterInventory{}
+			inventory.ClusterAddon = false
+			inventory.SecurityGroupId = ""
+			c.sendInventory(inventory)
+		}
+	}
+
+	// IAM Roles - the cluster and node groups must be deleted before the roles
+	// they use, adopted roles are kept in inventory
+	roleInventories := []*RoleInventory{
+		&inventory.ClusterRole,
+		&inventory.WorkerRole,
+		&inventory.DnsManagementRole,
+		&inventory.Dns01ChallengeRole,
+		&inventory.SecretsManagerRole,
+		&inventory.ClusterAutoscalingRole,
+		&inventory.StorageManagementRole,
+	}
+	var iamRoles []RoleInventory
+	for _, role := range roleInventories {
+		switch {
+		case role.RoleName == "" || c.keepAdopted(inventory, "iam-role", role.RoleName):
+		case role == &inventory.ClusterRole && inventory.Cluster.ClusterName != "":
+			skip("iam-role", fmt.Sprintf("IAM role %s not deleted because EKS cluster still exists: %s", role.RoleName, inventory.Cluster.ClusterName), role.RoleName)
+		case role == &inventory.WorkerRole && len(inventory.NodeGroupNames) > 0:
+			skip("iam-role", fmt.Sprintf("IAM role %s not deleted because node groups still exist: %s", role.RoleName, inventory.NodeGroupNames), role.RoleName)
+		default:
+			iamRoles = append(iamRoles, *role)
+		}
+	}
+	deletedRoleNames, err := c.deleteRoles(&iamRoles)
+	if err != nil {
+		fail("iam-role", err)
 	}
-	c.SendMessage(fmt.Sprintf("EKS cluster deletion initiated: %s", inventory.Cluster.ClusterName))
-	c.SendMessage(fmt.Sprintf("Waiting for EKS cluster to be deleted: %s", inventory.Cluster.ClusterName))
-	if _, err := c.WaitForCluster(inventory.Cluster.ClusterName, ClusterConditionDeleted); err != nil {
-		return err
+	if len(deletedRoleNames) > 0 {
+		c.sendEvent("iam-role", client.PhaseDeleted, fmt.Sprintf("IAM roles deleted: %s", deletedRoleNames), deletedRoleNames...)
+		for _, role := range roleInventories {
+			if slices.Contains(deletedRoleNames, role.RoleName) {
+				*role = RoleInventory{}
+			}
+		}
+		c.sendInventory(inventory)
 	}
-	c.SendMessage(fmt.Sprintf("EKS cluster deletion complete: %s", inventory.Cluster.ClusterName))
-	inventory.Cluster = ClusterInventory{}
-	inventory.send(c.InventoryChan)
-
-	// IAM Roles
-	iamRoles := []RoleInventory{
-		inventory.ClusterRole,
-		inventory.WorkerRole,
-		inventory.DnsManagementRole,
-		inventory.Dns01ChallengeRole,
-		inventory.SecretsManagerRole,
-		inventory.ClusterAutoscalingRole,
-		inventory.StorageManagementRole,
-	}
-	if err := c.DeleteRoles(&iamRoles); err != nil {
-		return err
+
+	// IAM Policies - policies can't be deleted while attached to roles
+	var attachedPolicyArns []string
+	for _, role := range roleInventories {
+		attachedPolicyArns = append(attachedPolicyArns, role.RolePolicyArns...)
 	}
-	c.SendMessage(fmt.Sprintf("IAM roles deleted: %s", iamRoles))
-	inventory.ClusterRole = RoleInventory{}
-	inventory.WorkerRole = RoleInventory{}
-	inventory.DnsManagementRole = RoleInventory{}
-	inventory.Dns01ChallengeRole = RoleInventory{}
-	inventory.ClusterAutoscalingRole = RoleInventory{}
-	inventory.StorageManagementRole = RoleInventory{}
-	inventory.send(c.InventoryChan)
-
-	// IAM Policies
-	policyArns, err := c.DeletePolicies(inventory.PolicyArns)
+	var policyArns []string
+	for _, policyArn := range inventory.PolicyArns {
+		if slices.Contains(attachedPolicyArns, policyArn) {
+			skip("iam-policy", fmt.Sprintf("IAM policy %s not deleted because it is attached to a role that still exists", policyArn), policyArn)
+			continue
+		}
+		policyArns = append(policyArns, policyArn)
+	}
+	deletedPolicyArns, err := c.deletePolicies(policyArns)
 	if err != nil {
-		return err
+		fail("iam-policy", err)
+	}
+	if len(deletedPolicyArns) > 0 {
+		c.sendEvent("iam-policy", client.PhaseDeleted, fmt.Sprintf("IAM policies deleted: %s", deletedPolicyArns), deletedPolicyArns...)
+		inventory.PolicyArns = without(inventory.PolicyArns, deletedPolicyArns)
+		c.sendInventory(inventory)
 	}
-	c.SendMessage(fmt.Sprintf("IAM policies deleted: %s", policyArns))
-	inventory.PolicyArns = []string{}
-	inventory.send(c.InventoryChan)
 
 	// NAT Gateways
-	natGatewayIds, err := c.DeleteNatGateways(&inventory.AvailabilityZones)
+	deletedNatGatewayIds, err := c.deleteNatGateways(&inventory.AvailabilityZones)
 	if err != nil {
-		return err
+		fail("nat-gateway", err)
 	}
-	c.SendMessage(fmt.Sprintf("NAT gateway deletion initiated: %s", natGatewayIds))
-	c.SendMessage("Waiting for NAT gateways to be deleted")
-	updatedAzInventory, natGatewayIds, err := c.WaitForNatGateways(
-		inventory.VpcId,
-		&inventory.AvailabilityZones,
-		NatGatewayConditionDeleted,
-	)
+	if len(deletedNatGatewayIds) > 0 {
+		c.sendEvent("nat-gateway", client.PhaseDeleting, fmt.Sprintf("NAT gateway deletion initiated: %s", deletedNatGatewayIds), deletedNatGatewayIds...)
+		c.sendEvent("nat-gateway", client.PhaseWaiting, "Waiting for NAT gateways to be deleted")
+		if err := c.waitForNatGatewaysDeleted(deletedNatGatewayIds); err != nil {
+			fail("nat-gateway", err)
+		} else {
+			c.sendEvent("nat-gateway", client.PhaseDeleted, fmt.Sprintf("NAT gateway deletion complete: %s", deletedNatGatewayIds), deletedNatGatewayIds...)
+			for azIdx, az := range inventory.AvailabilityZones {
+				if slices.Contains(deletedNatGatewayIds, az.NatGatewayId) {
+					inventory.AvailabilityZones[azIdx].NatGatewayId = ""
+				}
+			}
+			c.sendInventory(inventory)
+		}
+	}
+	natGatewayIds := natGatewayIds(inventory.AvailabilityZones)
+
+	// Internet Gateway - can't be detached while NAT gateways and nodes have
+	// public IPs in the VPC
+	switch {
+	case inventory.InternetGatewayId == "":
+	case len(natGatewayIds) > 0:
+		skip("internet-gateway", fmt.Sprintf("internet gateway %s not deleted because NAT gateways still exist: %s", inventory.InternetGatewayId, natGatewayIds), inventory.InternetGatewayId)
+	case len(inventory.NodeGroupNames) > 0:
+		skip("internet-gateway", fmt.Sprintf("internet gateway %s not deleted because node groups still exist: %s", inventory.InternetGatewayId, inventory.NodeGroupNames), inventory.InternetGatewayId)
+	default:
+		if err := c.DeleteInternetGateway(inventory.InternetGatewayId, inventory.VpcId); err != nil {
+			fail("internet-gateway", err)
+		} else {
+			c.sendEvent("internet-gateway", client.PhaseDeleted, fmt.Sprintf("Internet gateway deleted: %s", inventory.InternetGatewayId), inventory.InternetGatewayId)
+			inventory.InternetGatewayId = ""
+			c.sendInventory(inventory)
+		}
+	}
+
+	// Elastic IPs - can't be released while associated with NAT gateways
+	switch {
+	case len(inventory.ElasticIpIds) == 0:
+	case len(natGatewayIds) > 0:
+		skip("elastic-ip", fmt.Sprintf("elastic IPs not deleted because NAT gateways still exist: %s", natGatewayIds), inventory.ElasticIpIds...)
+	default:
+		deletedElasticIpIds, err := c.deleteElasticIps(inventory.ElasticIpIds)
+		if err != nil {
+			fail("elastic-ip", err)
+		}
+		if len(deletedElasticIpIds) > 0 {
+			c.sendEvent("elastic-ip", client.PhaseDeleted, fmt.Sprintf("Elastic IPs deleted: %s", deletedElasticIpIds), deletedElasticIpIds...)
+			inventory.ElasticIpIds = without(inventory.ElasticIpIds, deletedElasticIpIds)
+			c.sendInventory(inventory)
+		}
+	}
+
+	// Subnets - the cluster, node groups and NAT gateways in them must be
+	// deleted first, adopted subnets are kept in inventory
+	deletableAzInventory := c.withoutAdoptedSubnets(inventory)
+	for azIdx, az := range deletableAzInventory {
+		azSubnetIds := subnetIds([]AvailabilityZoneInventory{az})
+		switch {
+		case len(azSubnetIds) == 0:
+			continue
+		case inventory.Cluster.ClusterName != "":
+			skip("subnet", fmt.Sprintf("subnets not deleted because EKS cluster still exists: %s", azSubnetIds), azSubnetIds...)
+		case len(inventory.NodeGroupNames) > 0:
+			skip("subnet", fmt.Sprintf("subnets not deleted because node groups still exist: %s", azSubnetIds), azSubnetIds...)
+		case az.NatGatewayId != "":
+			skip("subnet", fmt.Sprintf("subnets not deleted because NAT gateway still exists: %s", az.NatGatewayId), azSubnetIds...)
+		default:
+			continue
+		}
+		deletableAzInventory[azIdx].PublicSubnets = nil
+		deletableAzInventory[azIdx].PrivateSubnets = nil
+	}
+	deletedSubnetIds, err := c.deleteSubnets(&deletableAzInventory)
 	if err != nil {
-		return err
+		fail("subnet", err)
+	}
+	if len(deletedSubnetIds) > 0 {
+		c.sendEvent("subnet", client.PhaseDeleted, fmt.Sprintf("Subnets deleted: %s", deletedSubnetIds), deletedSubnetIds...)
+		for azIdx, az := range inventory.AvailabilityZones {
+			for subnetIdx, subnet := range az.PublicSubnets {
+				if slices.Contains(deletedSubnetIds, subnet.SubnetId) {
+					inventory.AvailabilityZones[azIdx].PublicSubnets[subnetIdx].SubnetId = ""
+				}
+			}
+			for subnetIdx, subnet := range az.PrivateSubnets {
+				if slices.Contains(deletedSubnetIds, subnet.SubnetId) {
+					inventory.AvailabilityZones[azIdx].PrivateSubnets[subnetIdx].SubnetId = ""
+				}
+			}
+		}
+		c.sendInventory(inventory)
 	}
-	c.SendMessage(fmt.Sprintf("NAT gateway deletion complete: %s", natGatewayIds))
-	inventory.AvailabilityZones = *updatedAzInventory
-	inventory.send(c.InventoryChan)
+	remainingSubnetIds := subnetIds(inventory.AvailabilityZones)
 
-	// Internet Gateway
-	if err := c.DeleteInternetGateway(inventory.InternetGatewayId, inventory.VpcId); err != nil {
-		return err
+	// Route Tables - can't be deleted while associated with subnets
+	routeTableIds := slices.Concat(inventory.PrivateRouteTableIds, []string{inventory.PublicRouteTableId})
+	routeTableIds = slices.DeleteFunc(routeTableIds, func(id string) bool { return id == "" })
+	switch {
+	case len(routeTableIds) == 0:
+	case len(remainingSubnetIds) > 0:
+		skip("route-table", fmt.Sprintf("route tables not deleted because subnets still exist: %s", remainingSubnetIds), routeTableIds...)
+	default:
+		deletedRouteTableIds, err := c.deleteRouteTables(inventory.PrivateRouteTableIds, inventory.PublicRouteTableId)
+		if err != nil {
+			fail("route-table", err)
+		}
+		if len(deletedRouteTableIds) > 0 {
+			c.sendEvent("route-table", client.PhaseDeleted, fmt.Sprintf("Route tables deleted: %s", deletedRouteTableIds), deletedRouteTableIds...)
+			inventory.PrivateRouteTableIds = without(inventory.PrivateRouteTableIds, deletedRouteTableIds)
+			if slices.Contains(deletedRouteTableIds, inventory.PublicRouteTableId) {
+				inventory.PublicRouteTableId = ""
+			}
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("Internet gateway deleted: %s", inventory.InternetGatewayId))
-	inventory.InternetGatewayId = ""
-	inventory.send(c.InventoryChan)
 
-	// Elastic IPs
-	if err := c.DeleteElasticIps(inventory.ElasticIpIds); err != nil {
-		return err
+	// VPC - every resource in it must be deleted first
+	switch {
+	case inventory.VpcId == "" || c.keepAdopted(inventory, "vpc", inventory.VpcId):
+	case inventory.InternetGatewayId != "" || len(remainingSubnetIds) > 0 ||
+		len(inventory.PrivateRouteTableIds) > 0 || inventory.PublicRouteTableId != "":
+		skip("vpc", fmt.Sprintf("VPC %s not deleted because resources in it still exist", inventory.VpcId), inventory.VpcId)
+	default:
+		if err := c.DeleteVpc(inventory.VpcId); err != nil {
+			fail("vpc", err)
+		} else {
+			c.sendEvent("vpc", client.PhaseDeleted, fmt.Sprintf("VPC deleted: %s", inventory.VpcId), inventory.VpcId)
+			inventory.VpcId = ""
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("Elastic IPs deleted: %s", inventory.ElasticIpIds))
-	inventory.ElasticIpIds = []string{}
-	inventory.send(c.InventoryChan)
 
-	// Subnets
-	updatedAzInventory, subnetIds, err := c.DeleteSubnets(&inventory.AvailabilityZones)
-	if err != nil {
+	return errors.Join(errs...)
+}
+
+// deleteCluster initiates the deletion of an EKS cluster and waits for it to
+// be deleted.
+func (c *EksClient) deleteCluster(clusterName string) error {
+	if err := c.DeleteCluster(clusterName); err != nil {
 		return err
 	}
-	c.SendMessage(fmt.Sprintf("Subnets deleted: %s", subnetIds))
-	if updatedAzInventory != nil {
-		inventory.AvailabilityZones = *updatedAzInventory
+	c.sendEvent("eks-cluster", client.PhaseDeleting, fmt.Sprintf("EKS cluster deletion initiated: %s", clusterName), clusterName)
+	c.sendEvent("eks-cluster", client.PhaseWaiting, fmt.Sprintf("Waiting for EKS cluster to be deleted: %s", clusterName), clusterName)
+	_, err := c.WaitForCluster(clusterName, ClusterConditionDeleted)
+
+	return err
+}
+
+// without returns the IDs that are not in removedIds.
+func without(ids []string, removedIds []string) []string {
+	remaining := []string{}
+	for _, id := range ids {
+		if !slices.Contains(removedIds, id) {
+			remaining = append(remaining, id)
+		}
 	}
-	inventory.send(c.InventoryChan)
 
-	// Route Tables
-	if err := c.DeleteRouteTables(
-		inventory.PrivateRouteTableIds,
-		inventory.PublicRouteTableId,
-	); err != nil {
-		return err
+	return remaining
+}
+
+// subnetIds returns the IDs of the public and private subnets in the
+// availability zones.
+func subnetIds(azInventory []AvailabilityZoneInventory) []string {
+	var ids []string
+	for _, az := range azInventory {
+		for _, subnet := range slices.Concat(az.PublicSubnets, az.PrivateSubnets) {
+			if subnet.SubnetId != "" {
+				ids = append(ids, subnet.SubnetId)
+			}
+		}
 	}
-	c.SendMessage(
-		fmt.Sprintf("Route tables deleted: [%s %s]",
-			inventory.PrivateRouteTableIds, inventory.PublicRouteTableId,
-		),
-	)
-	inventory.PrivateRouteTableIds = []string{}
-	inventory.PublicRouteTableId = ""
-	inventory.send(c.InventoryChan)
 
-	// VPC
-	if err := c.DeleteVpc(inventory.VpcId); err != nil {
-		return err
+	return ids
+}
+
+// natGatewayIds returns the IDs of the NAT gateways in the availability zones.
+func natGatewayIds(azInventory []AvailabilityZoneInventory) []string {
+	var ids []string
+	for _, az := range azInventory {
+		if az.NatGatewayId != "" {
+			ids = append(ids, az.NatGatewayId)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("VPC deleted: %s", inventory.VpcId))
-	inventory.VpcId = ""
-	inventory.send(c.InventoryChan)
 
-	return nil
+	return ids
 }
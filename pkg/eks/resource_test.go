@@ -0,0 +1,243 @@
+package eks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+// testClient returns an EKS client that calls the fake backend.
+func testClient(backend *fake.Backend) *EksClient {
+	return &EksClient{
+		ResourceClient:     *backend.ResourceClient(),
+		OidcThumbprintFunc: fake.OidcThumbprint,
+	}
+}
+
+// sampleConfig returns the sample EKS config.
+func sampleConfig(t *testing.T) *EksConfig {
+	t.Helper()
+
+	resourceConfig, err := LoadEksConfig("../../sample/eks-config.yaml")
+	if err != nil {
+		t.Fatalf("failed to load sample config: %v", err)
+	}
+
+	return resourceConfig
+}
+
+func TestCreateEksResourceStackAvailabilityZones(t *testing.T) {
+	testCases := []struct {
+		name              string
+		desiredAzCount    int32
+		availabilityZones []AvailabilityZoneConfig
+		expected          []AvailabilityZoneConfig
+	}{
+		{
+			name:           "default count",
+			desiredAzCount: 0,
+			expected: []AvailabilityZoneConfig{
+				{Zone: "us-east-2a", PublicSubnetCidr: "10.0.0.0/22", PrivateSubnetCidr: "10.0.4.0/22"},
+				{Zone: "us-east-2b", PublicSubnetCidr: "10.0.8.0/22", PrivateSubnetCidr: "10.0.12.0/22"},
+			},
+		},
+		{
+			name:           "desired count above the maximum",
+			desiredAzCount: 5,
+			expected: []AvailabilityZoneConfig{
+				{Zone: "us-east-2a", PublicSubnetCidr: "10.0.0.0/22", PrivateSubnetCidr: "10.0.4.0/22"},
+				{Zone: "us-east-2b", PublicSubnetCidr: "10.0.8.0/22", PrivateSubnetCidr: "10.0.12.0/22"},
+				{Zone: "us-east-2c", PublicSubnetCidr: "10.0.16.0/22", PrivateSubnetCidr: "10.0.20.0/22"},
+			},
+		},
+		{
+			name: "explicit zones",
+			availabilityZones: []AvailabilityZoneConfig{
+				{Zone: "us-east-2d", PublicSubnetCidr: "10.0.64.0/20", PrivateSubnetCidr: "10.0.128.0/18"},
+				{Zone: "us-east-2b", PublicSubnetCidr: "10.0.80.0/20", PrivateSubnetCidr: "10.0.192.0/18"},
+			},
+			expected: []AvailabilityZoneConfig{
+				{Zone: "us-east-2d", PublicSubnetCidr: "10.0.64.0/20", PrivateSubnetCidr: "10.0.128.0/18"},
+				{Zone: "us-east-2b", PublicSubnetCidr: "10.0.80.0/20", PrivateSubnetCidr: "10.0.192.0/18"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resourceConfig := sampleConfig(t)
+			resourceConfig.DesiredAzCount = testCase.desiredAzCount
+			resourceConfig.AvailabilityZones = testCase.availabilityZones
+			backend := fake.NewBackend(resourceConfig.Region)
+			eksClient := testClient(backend)
+
+			var inventory EksInventory
+			if err := eksClient.CreateEksResourceStack(resourceConfig, &inventory); err != nil {
+				t.Fatalf("failed to create resource stack: %v", err)
+			}
+
+			if len(inventory.AvailabilityZones) != len(testCase.expected) {
+				t.Fatalf("expected %d availability zones, got %+v", len(testCase.expected), inventory.AvailabilityZones)
+			}
+			subnets, err := backend.Apis().Ec2.DescribeSubnets(context.Background(), &ec2.DescribeSubnetsInput{})
+			if err != nil {
+				t.Fatalf("failed to describe subnets: %v", err)
+			}
+			subnetZones := make(map[string]string)
+			subnetCidrs := make(map[string]string)
+			for _, subnet := range subnets.Subnets {
+				subnetZones[*subnet.SubnetId] = *subnet.AvailabilityZone
+				subnetCidrs[*subnet.SubnetId] = *subnet.CidrBlock
+			}
+			natGatewayIds := make(map[string]bool)
+			for i, az := range inventory.AvailabilityZones {
+				expected := testCase.expected[i]
+				if az.Zone != expected.Zone {
+					t.Errorf("expected zone %s, got %s", expected.Zone, az.Zone)
+				}
+				if len(az.PublicSubnets) != 1 || len(az.PrivateSubnets) != 1 {
+					t.Fatalf("expected a public and a private subnet in %s, got %+v", az.Zone, az)
+				}
+				for _, subnet := range []struct {
+					inventory SubnetInventory
+					cidr      string
+				}{
+					{az.PublicSubnets[0], expected.PublicSubnetCidr},
+					{az.PrivateSubnets[0], expected.PrivateSubnetCidr},
+				} {
+					if subnet.inventory.SubnetCidr != subnet.cidr {
+						t.Errorf("expected subnet CIDR %s in %s, got %s", subnet.cidr, az.Zone, subnet.inventory.SubnetCidr)
+					}
+					if zone := subnetZones[subnet.inventory.SubnetId]; zone != expected.Zone {
+						t.Errorf("expected subnet %s to be created in %s, got %q", subnet.inventory.SubnetId, expected.Zone, zone)
+					}
+					if cidr := subnetCidrs[subnet.inventory.SubnetId]; cidr != subnet.cidr {
+						t.Errorf("expected subnet %s to be created with %s, got %q", subnet.inventory.SubnetId, subnet.cidr, cidr)
+					}
+				}
+				if az.NatGatewayId == "" {
+					t.Errorf("expected NAT gateway in %s", az.Zone)
+				}
+				natGatewayIds[az.NatGatewayId] = true
+			}
+			if len(natGatewayIds) != len(testCase.expected) {
+				t.Errorf("expected a NAT gateway for each zone, got %v", natGatewayIds)
+			}
+			if len(inventory.ElasticIpIds) != len(testCase.expected) {
+				t.Errorf("expected an elastic IP for each NAT gateway, got %v", inventory.ElasticIpIds)
+			}
+			if len(inventory.PrivateRouteTableIds) != len(testCase.expected) {
+				t.Errorf("expected a private route table for each zone, got %v", inventory.PrivateRouteTableIds)
+			}
+			if count := len(backend.Resources()["subnet"]); count != 2*len(testCase.expected) {
+				t.Errorf("expected %d subnets, found %d", 2*len(testCase.expected), count)
+			}
+
+			if err := eksClient.DeleteEksResourceStack(&inventory); err != nil {
+				t.Fatalf("failed to delete resource stack: %v", err)
+			}
+			if resources := backend.Resources(); len(resources) != 0 {
+				t.Errorf("expected all resources to be deleted, found %v", resources)
+			}
+		})
+	}
+}
+
+func TestCreateEksResourceStackResumesAfterFailure(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+	backend.FailNext("CreateCluster", &smithy.GenericAPIError{
+		Code:    "ResourceLimitExceeded",
+		Message: "cluster limit exceeded",
+	})
+
+	var inventory EksInventory
+	err := eksClient.CreateEksResourceStack(resourceConfig, &inventory)
+	if err == nil || !strings.Contains(err.Error(), "cluster limit exceeded") {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if inventory.Cluster.ClusterName != "" {
+		t.Errorf("expected no cluster in inventory, got %s", inventory.Cluster.ClusterName)
+	}
+	subnetCount := len(subnetIds(inventory.AvailabilityZones))
+	if subnetCount != 4 {
+		t.Errorf("expected subnets created before the failure to be in inventory, got %+v", inventory.AvailabilityZones)
+	}
+
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to resume create: %v", err)
+	}
+	for operation, expected := range map[string]int{
+		"CreateVpc":        1,
+		"CreateSubnet":     subnetCount,
+		"AllocateAddress":  2,
+		"CreateNatGateway": 2,
+		"CreateCluster":    2,
+	} {
+		if count := backend.CallCount(operation); count != expected {
+			t.Errorf("expected %s to be called %d times, called %d times", operation, expected, count)
+		}
+	}
+	if inventory.Cluster.ClusterName != resourceConfig.Name {
+		t.Errorf("expected cluster %s in inventory, got %q", resourceConfig.Name, inventory.Cluster.ClusterName)
+	}
+	if inventory.OidcProviderArn == "" {
+		t.Error("expected OIDC provider in inventory")
+	}
+}
+
+func TestDeleteEksResourceStackSkipsDependents(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+
+	var inventory EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	injected := &smithy.GenericAPIError{Code: "ServiceFailure", Message: "injected failure"}
+	backend.FailNext("DeleteNodegroup", injected)
+
+	err := eksClient.DeleteEksResourceStack(&inventory)
+	if !errors.Is(err, injected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	for _, message := range []string{
+		"EKS cluster sample-cluster-0 not deleted because node groups still exist",
+		"not deleted because EKS cluster still exists",
+		"subnets not deleted because EKS cluster still exists",
+		"not deleted because resources in it still exist",
+	} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("expected error to contain %q, got %v", message, err)
+		}
+	}
+	resources := backend.Resources()
+	for _, kind := range []string{"eks-node-group", "eks-cluster", "subnet", "vpc"} {
+		if len(resources[kind]) == 0 {
+			t.Errorf("expected %s to remain while node groups exist", kind)
+		}
+	}
+	for _, kind := range []string{"iam-oidc-provider", "nat-gateway", "elastic-ip"} {
+		if len(resources[kind]) != 0 {
+			t.Errorf("expected %s to be deleted despite the failure, found %v", kind, resources[kind])
+		}
+	}
+	if len(inventory.NodeGroupNames) == 0 || inventory.Cluster.ClusterName == "" || inventory.VpcId == "" {
+		t.Errorf("expected remaining resources to stay in inventory, got %+v", inventory)
+	}
+
+	if err := eksClient.DeleteEksResourceStack(&inventory); err != nil {
+		t.Fatalf("failed to resume delete: %v", err)
+	}
+	if resources := backend.Resources(); len(resources) != 0 {
+		t.Errorf("expected all resources to be deleted, found %v", resources)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 const (
@@ -26,7 +28,7 @@ func (c *EksClient) CreateClusterRole(
 	tags *[]types.Tag,
 	clusterName string,
 ) (*types.Role, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	clusterRoleName := fmt.Sprintf("%s-%s", ClusterRoleName, clusterName)
 	if err := CheckRoleName(clusterRoleName); err != nil {
@@ -109,7 +111,7 @@ func (c *EksClient) CreateNodeRole(
 	tags *[]types.Tag,
 	clusterName string,
 ) (*types.Role, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	workerRoleName := fmt.Sprintf("%s-%s", WorkerRoleName, clusterName)
 	if err := CheckRoleName(workerRoleName); err != nil {
@@ -211,7 +213,7 @@ func (c *EksClient) CreateDnsManagementRole(
 	serviceAccount *ServiceAccountConfig,
 	clusterName string,
 ) (*types.Role, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	oidcProviderBare := strings.Trim(oidcProvider, "https://")
 	dnsManagementRoleName := fmt.Sprintf("%s-%s", DnsManagementRoleName, clusterName)
@@ -307,7 +309,7 @@ func (c *EksClient) CreateDns01ChallengeRole(
 	serviceAccount *ServiceAccountConfig,
 	clusterName string,
 ) (*types.Role, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	oidcProviderBare := strings.Trim(oidcProvider, "https://")
 	dns01ChallengeRoleName := fmt.Sprintf("%s-%s", Dns01ChallengeRoleName, clusterName)
@@ -401,7 +403,7 @@ func (c *EksClient) CreateSecretsManagerRole(
 	serviceAccount *ServiceAccountConfig,
 	clusterName string,
 ) (*types.Role, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	oidcProviderBare := strings.Trim(oidcProvider, "https://")
 	secretsManagerRoleName := fmt.Sprintf("%s-%s", SecretsManagerRoleName, clusterName)
@@ -495,7 +497,7 @@ func (c *EksClient) CreateClusterAutoscalingRole(
 	serviceAccount *ServiceAccountConfig,
 	clusterName string,
 ) (*types.Role, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	oidcProviderBare := strings.Trim(oidcProvider, "https://")
 	clusterAutoscalingRoleName := fmt.Sprintf("%s-%s", ClusterAutoscalingRoleName, clusterName)
@@ -588,7 +590,7 @@ func (c *EksClient) CreateStorageManagementRole(
 	serviceAccount *ServiceAccountConfig,
 	clusterName string,
 ) (*types.Role, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	oidcProviderBare := strings.Trim(oidcProvider, "https://")
 	storageManagementRoleName := fmt.Sprintf("%s-%s", StorageManagementRoleName, clusterName)
@@ -672,46 +674,62 @@ func (c *EksClient) CreateStorageManagementRole(
 }
 
 // DeleteRoles deletes the IAM roles used by EKS.  If empty role names are
-// provided, or if the roles are not found it returns without error.
+// provided, or if the roles are not found it returns without error.  Every
+// role is attempted and an error joining each failure is returned.
 func (c *EksClient) DeleteRoles(roles *[]RoleInventory) error {
-	// if roles are empty, there's nothing to delete
-	if len(*roles) == 0 {
-		return nil
-	}
+	_, err := c.deleteRoles(roles)
 
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	return err
+}
 
+// deleteRoles deletes the IAM roles after detaching their policies and
+// returns the names of the roles deleted or not found.  Every role is
+// attempted and an error joining each failure is returned.  Roles with empty
+// names are skipped.
+func (c *EksClient) deleteRoles(roles *[]RoleInventory) ([]string, error) {
+	var deletedRoleNames []string
+	var errs []error
 	for _, role := range *roles {
 		if role.RoleName == "" {
 			// role is empty - skip
 			continue
 		}
-		for _, policyArn := range role.RolePolicyArns {
-			detachRolePolicyInput := iam.DetachRolePolicyInput{
-				PolicyArn: &policyArn,
-				RoleName:  &role.RoleName,
-			}
-			_, err := svc.DetachRolePolicy(c.Context, &detachRolePolicyInput)
-			if err != nil {
-				var noSuchEntityErr *types.NoSuchEntityException
-				if errors.As(err, &noSuchEntityErr) {
-					return nil
-				} else {
-					return fmt.Errorf("failed to detach policy %s from role %s: %w", policyArn, role.RoleName, err)
-				}
-			}
+		if err := c.deleteRole(role); err != nil {
+			errs = append(errs, err)
+			continue
 		}
-		deleteRoleInput := iam.DeleteRoleInput{RoleName: &role.RoleName}
-		_, err := svc.DeleteRole(c.Context, &deleteRoleInput)
+		deletedRoleNames = append(deletedRoleNames, role.RoleName)
+	}
+
+	return deletedRoleNames, errors.Join(errs...)
+}
+
+// deleteRole detaches the policies from an IAM role and deletes it.  Policies
+// that are not attached and a role that is not found are ignored.
+func (c *EksClient) deleteRole(role RoleInventory) error {
+	svc := c.GetIamApi()
+
+	for _, policyArn := range role.RolePolicyArns {
+		detachRolePolicyInput := iam.DetachRolePolicyInput{
+			PolicyArn: &policyArn,
+			RoleName:  &role.RoleName,
+		}
+		_, err := svc.DetachRolePolicy(c.Context, &detachRolePolicyInput)
 		if err != nil {
 			var noSuchEntityErr *types.NoSuchEntityException
-			if errors.As(err, &noSuchEntityErr) {
-				return nil
-			} else {
-				return fmt.Errorf("failed to delete role %s: %w", role.RoleName, err)
+			if !errors.As(err, &noSuchEntityErr) {
+				return fmt.Errorf("failed to detach policy %s from role %s: %w", policyArn, role.RoleName, err)
 			}
 		}
 	}
+	deleteRoleInput := iam.DeleteRoleInput{RoleName: &role.RoleName}
+	_, err := svc.DeleteRole(c.Context, &deleteRoleInput)
+	if err != nil {
+		var noSuchEntityErr *types.NoSuchEntityException
+		if !errors.As(err, &noSuchEntityErr) {
+			return fmt.Errorf("failed to delete role %s: %w", role.RoleName, err)
+		}
+	}
 
 	return nil
 }
@@ -720,7 +738,7 @@ func (c *EksClient) DeleteRoles(roles *[]RoleInventory) error {
 // policy to the role.
 func (c *EksClient) attachPolicyToRole(roleName string, policyArn string) error {
 
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	attachRolePolicyInput := iam.AttachRolePolicyInput{
 		RoleName:  &roleName,
@@ -734,6 +752,25 @@ func (c *EksClient) attachPolicyToRole(roleName string, policyArn string) error
 	return nil
 }
 
+// getRole retrieves an IAM role by name.  If the role is not found
+// util.ErrResourceNotFound is returned.
+func (c *EksClient) getRole(roleName string) (*types.Role, error) {
+	svc := c.GetIamApi()
+
+	getRoleInput := iam.GetRoleInput{RoleName: &roleName}
+	resp, err := svc.GetRole(c.Context, &getRoleInput)
+	if err != nil {
+		var noSuchEntityErr *types.NoSuchEntityException
+		if errors.As(err, &noSuchEntityErr) {
+			return nil, util.ErrResourceNotFound
+		} else {
+			return nil, fmt.Errorf("failed to get role %s: %w", roleName, err)
+		}
+	}
+
+	return resp.Role, nil
+}
+
 // getWorkerPolicyArns returns the IAM policy ARNs needed for clusters and node
 // groups.
 func getWorkerPolicyArns() []string {
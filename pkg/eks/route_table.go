@@ -3,13 +3,15 @@ package eks
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	"github.com/aws/smithy-go"
 
 	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreatePublicRouteTable creates the route tables for the subnets used by the EKS
@@ -20,7 +22,7 @@ func (c *EksClient) CreatePublicRouteTable(
 	internetGatewayId string,
 	azInventory *[]AvailabilityZoneInventory,
 ) (*types.RouteTable, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	destinationCidr := "0.0.0.0/0"
 
@@ -36,13 +38,7 @@ func (c *EksClient) CreatePublicRouteTable(
 
 	// in order to uniquely reference route tables created for an EKS cluster, we
 	// add a PublicRouteTableRef tag
-	publicRtRefTagKey := "PublicRouteTableRef"
-	publicRtRefTagValue := "1"
-	publicRtRefTag := types.Tag{
-		Key:   &publicRtRefTagKey,
-		Value: &publicRtRefTagValue,
-	}
-	publicRtTags := append(*tags, publicRtRefTag)
+	publicRtTags := publicRouteTableTags(tags)
 
 	// because route tables don't have unique names we have to check for
 	// existing route tables with matching tags up front
@@ -140,28 +136,20 @@ func (c *EksClient) CreatePrivateRouteTables(
 	vpcId string,
 	azInventory *[]AvailabilityZoneInventory,
 ) (*[]types.RouteTable, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	destinationCidr := "0.0.0.0/0"
 
 	var privateRouteTables []types.RouteTable
 
 	// in order to uniquely reference route tables created for an EKS cluster, we
-	// add a PublicRouteTableRef tag
-	privateRtRefTagKey := "PrivateRouteTableRef"
+	// add a PrivateRouteTableRef tag
 	privateRtRefTagValue := 1
 
 	// create a route table for each private subnet
 	for _, az := range *azInventory {
 		for _, privateSubnet := range az.PrivateSubnets {
-			privateRtTags := *tags
-			tk := privateRtRefTagKey
-			tv := strconv.Itoa(privateRtRefTagValue)
-			privateRtRefTag := types.Tag{
-				Key:   &tk,
-				Value: &tv,
-			}
-			privateRtTags = append(privateRtTags, privateRtRefTag)
+			privateRtTags := privateRouteTableTags(tags, privateRtRefTagValue)
 			privateRtRefTagValue++
 
 			// because route tables don't have unique names we have to check for
@@ -256,43 +244,39 @@ func (c *EksClient) CreatePrivateRouteTables(
 }
 
 // DeleteRouteTables deletes the route tables for the public and private subnets
-// that are used by EKS.
+// that are used by EKS.  Every route table is attempted and an error joining
+// each failure is returned.
 func (c *EksClient) DeleteRouteTables(privateRouteTableIds []string, publicRouteTable string) error {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
-
-	var allRouteTableIds []string
-	switch {
-	case len(privateRouteTableIds) == 0 && publicRouteTable == "":
-		// if route table IDs are empty, there's nothing to delete
-		return nil
-	case publicRouteTable == "":
-		// don't want to add an empty string to slice of route table IDs
-		allRouteTableIds = privateRouteTableIds
-	default:
-		// there are private and public route table IDs to delete
-		allRouteTableIds = append(privateRouteTableIds, publicRouteTable)
+	_, err := c.deleteRouteTables(privateRouteTableIds, publicRouteTable)
+
+	return err
+}
+
+// deleteRouteTables deletes the private and public route tables and returns
+// the IDs of those deleted or not found.  Every route table is attempted and
+// an error joining each failure is returned.
+func (c *EksClient) deleteRouteTables(privateRouteTableIds []string, publicRouteTable string) ([]string, error) {
+	svc := c.GetEc2Api()
+
+	// don't want to add an empty string to slice of route table IDs
+	allRouteTableIds := slices.Clone(privateRouteTableIds)
+	if publicRouteTable != "" {
+		allRouteTableIds = append(allRouteTableIds, publicRouteTable)
 	}
 
+	var deletedRouteTableIds []string
+	var errs []error
 	for _, routeTableId := range allRouteTableIds {
 		deleteRouteTableInput := aws_ec2.DeleteRouteTableInput{RouteTableId: &routeTableId}
 		_, err := svc.DeleteRouteTable(c.Context, &deleteRouteTableInput)
-		if err != nil {
-			var ae smithy.APIError
-			if errors.As(err, &ae) {
-				if ae.ErrorCode() == "InvalidRouteTableID.NotFound" {
-					// attempting to delete a route table that doesn't exist so return
-					// without error
-					return nil
-				} else {
-					return fmt.Errorf("failed to delete route table with ID %s: %w", routeTableId, err)
-				}
-			} else {
-				return fmt.Errorf("failed to delete route table with ID %s: %w", routeTableId, err)
-			}
+		if err != nil && !util.HasErrorCode(err, "InvalidRouteTableID.NotFound") {
+			errs = append(errs, fmt.Errorf("failed to delete route table with ID %s: %w", routeTableId, err))
+			continue
 		}
+		deletedRouteTableIds = append(deletedRouteTableIds, routeTableId)
 	}
 
-	return nil
+	return deletedRouteTableIds, errors.Join(errs...)
 }
 
 // createRouteToInternetGateway adds a route to an internet gateway for a route
@@ -302,7 +286,7 @@ func (c *EksClient) createRouteToInternetGateway(
 	internetGatewayId string,
 	destinationCidr string,
 ) error {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	createRouteInput := aws_ec2.CreateRouteInput{
 		RouteTableId:         routeTable.RouteTableId,
@@ -327,7 +311,7 @@ func (c *EksClient) createRouteToNatGateway(
 	natGatewayId string,
 	destinationCidr string,
 ) error {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	createRouteInput := aws_ec2.CreateRouteInput{
 		RouteTableId:         routeTable.RouteTableId,
@@ -351,7 +335,7 @@ func (c *EksClient) associateRouteTableWithSubnet(
 	routeTableId string,
 	subnetIds []string,
 ) error {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	for _, subnetId := range subnetIds {
 		associatePublicRouteTableInput := aws_ec2.AssociateRouteTableInput{
@@ -369,3 +353,22 @@ func (c *EksClient) associateRouteTableWithSubnet(
 
 	return nil
 }
+
+// publicRouteTableTags returns a copy of the resource stack tags with a
+// PublicRouteTableRef tag added.
+func publicRouteTableTags(tags *[]types.Tag) []types.Tag {
+	return append(append([]types.Tag{}, *tags...), types.Tag{
+		Key:   aws.String("PublicRouteTableRef"),
+		Value: aws.String("1"),
+	})
+}
+
+// privateRouteTableTags returns a copy of the resource stack tags with a
+// PrivateRouteTableRef tag added so each private route table can be uniquely
+// referenced.
+func privateRouteTableTags(tags *[]types.Tag, ref int) []types.Tag {
+	return append(append([]types.Tag{}, *tags...), types.Tag{
+		Key:   aws.String("PrivateRouteTableRef"),
+		Value: aws.String(strconv.Itoa(ref)),
+	})
+}
@@ -0,0 +1,137 @@
+package eks
+
+import (
+	"fmt"
+
+	"github.com/nukleros/aws-builder/pkg/schema"
+)
+
+// ConfigSchema returns the JSON Schema for EKS config files.
+func ConfigSchema() *schema.Schema {
+	defaults := NewEksConfig()
+	defaults.Waiters = defaultWaiters()
+
+	return schema.Generate("EKS resource stack config", defaults)
+}
+
+// SchemaFields describes the config fields in generated schemas.
+func (EksConfig) SchemaFields() map[string]schema.Field {
+	serviceAccount := func(feature string) string {
+		return fmt.Sprintf("The service account that is granted the IAM role for %s.", feature)
+	}
+
+	return map[string]schema.Field{
+		"name": {
+			Description: "The name of the EKS cluster.  Also used in the names of the IAM roles and node group.",
+			Pattern:     clusterNamePattern.String(),
+		},
+		"region": {
+			Description: "The AWS region to create resources in.  Defaults to the region in AWS config.",
+		},
+		"awsAccountId": {
+			Description: "The AWS account ID resources are created in.",
+			Pattern:     awsAccountIdPattern.String(),
+			Required:    true,
+		},
+		"kubernetesVersion": {
+			Description: "The Kubernetes minor version for the cluster and node group.",
+			Pattern:     kubernetesVersionPattern.String(),
+		},
+		"clusterCidr": {
+			Description: "The CIDR block for the VPC.  All subnets must be within it.",
+		},
+		"desiredAzCount": {
+			Description: fmt.Sprintf(
+				"The number of availability zones to use if availabilityZones is not set, up to %d.  Defaults to %d.",
+				maxAzCount,
+				DefaultAzCount,
+			),
+		},
+		"availabilityZones": {
+			Description: "The availability zones to use and the subnets to create in each.  If not set, desiredAzCount zones are used with default subnets.",
+		},
+		"instanceTypes": {
+			Description: "The EC2 instance types for the node group.",
+		},
+		"initialNodes": {
+			Description: "The number of nodes the node group starts with.  Defaults to minNodes.",
+		},
+		"minNodes": {
+			Description: "The minimum number of nodes in the node group.",
+		},
+		"maxNodes": {
+			Description: "The maximum number of nodes in the node group.",
+		},
+		"dnsManagement": {
+			Description: "Create an IAM role for managing Route 53 DNS records, e.g. for external-dns.",
+		},
+		"dns01Challenge": {
+			Description: "Create an IAM role for solving DNS01 challenges, e.g. for cert-manager.",
+		},
+		"secretsManager": {
+			Description: "Create an IAM role for reading secrets from AWS Secrets Manager.",
+		},
+		"dnsManagementServiceAccount": {
+			Description: serviceAccount("DNS management") + "  Required if dnsManagement is true.",
+		},
+		"dns01ChallengeServiceAccount": {
+			Description: serviceAccount("DNS01 challenges") + "  Required if dns01Challenge is true.",
+		},
+		"secretsManagerServiceAccount": {
+			Description: serviceAccount("AWS Secrets Manager") + "  Required if secretsManager is true.",
+		},
+		"storageManagementServiceAccount": {
+			Description: serviceAccount("the EBS CSI driver"),
+		},
+		"clusterAutoscaling": {
+			Description: "Create an IAM role for the cluster autoscaler.",
+		},
+		"clusterAutoscalingServiceAccount": {
+			Description: serviceAccount("the cluster autoscaler") + "  Required if clusterAutoscaling is true.",
+		},
+		"keyPair": {
+			Description: "The name of an EC2 key pair for SSH access to nodes.",
+		},
+		"tags": {
+			Description: "Tags added to every resource.  Use unique tags for each resource stack.",
+		},
+		"waiters": {
+			Description: fmt.Sprintf(
+				"Settings for waiting on resources by resource: %s, %s or %s.",
+				ClusterWaiter,
+				NatGatewayWaiter,
+				NodeGroupWaiter,
+			),
+		},
+	}
+}
+
+// SchemaFields describes the config fields in generated schemas.
+func (AvailabilityZoneConfig) SchemaFields() map[string]schema.Field {
+	return map[string]schema.Field{
+		"zone": {
+			Description: "The name of the availability zone, e.g. us-east-2a.",
+			Required:    true,
+		},
+		"privateSubnetCidr": {
+			Description: "The CIDR block for the private subnet nodes run in.",
+			Required:    true,
+		},
+		"publicSubnetCidr": {
+			Description: "The CIDR block for the public subnet with the NAT gateway.",
+			Required:    true,
+		},
+	}
+}
+
+// SchemaFields describes the config fields in generated schemas.
+func (ServiceAccountConfig) SchemaFields() map[string]schema.Field {
+	return map[string]schema.Field{
+		"name": {
+			Description: "The name of the Kubernetes service account.",
+		},
+		"namespace": {
+			Description: "The namespace of the Kubernetes service account.",
+		},
+	}
+}
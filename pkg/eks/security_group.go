@@ -10,7 +10,7 @@ import (
 // GetClusterSecurityGroup retrieves the security group created for the EKS
 // cluster by AWS during provisioning.
 func (c *EksClient) GetClusterSecurityGroup(clusterName string) (string, error) {
-	svc := ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	filterName := fmt.Sprintf("tag:aws:eks:cluster-name")
 	filters := []types.Filter{
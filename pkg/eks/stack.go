@@ -0,0 +1,190 @@
+package eks
+
+import (
+	"sync"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/orphan"
+	"github.com/nukleros/aws-builder/pkg/plan"
+	"github.com/nukleros/aws-builder/pkg/schema"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+func init() {
+	stack.Register(&EksClient{})
+}
+
+// Name returns the name the EKS resource stack is registered as.
+func (c *EksClient) Name() string {
+	return "eks"
+}
+
+// Description returns the AWS service the EKS resource stack provides.
+func (c *EksClient) Description() string {
+	return "Elastic Kubernetes Service"
+}
+
+// New returns an EKS client that uses the resource client.
+func (c *EksClient) New(resourceClient *client.ResourceClient) stack.Stack {
+	return &EksClient{
+		ResourceClient:     *resourceClient,
+		OidcThumbprintFunc: c.OidcThumbprintFunc,
+	}
+}
+
+// NewConfig returns an empty EKS config.
+func (c *EksClient) NewConfig() stack.Config {
+	return &EksConfig{}
+}
+
+// LoadConfig loads EKS config files, resolving references to the outputs of
+// other resource stacks, and applies defaults.
+func (c *EksClient) LoadConfig(outputs configfile.Outputs, configFiles ...string) (stack.Config, error) {
+	return LoadEksConfigWithOutputs(outputs, configFiles...)
+}
+
+// ConfigSchema returns the JSON Schema for EKS config files.
+func (c *EksClient) ConfigSchema() *schema.Schema {
+	return ConfigSchema()
+}
+
+// WaiterNames returns the EKS resources that can be waited on.
+func (c *EksClient) WaiterNames() []string {
+	return []string{ClusterWaiter, NodeGroupWaiter, NatGatewayWaiter}
+}
+
+// NewInventory returns an empty EKS inventory.
+func (c *EksClient) NewInventory() stack.Inventory {
+	return &EksInventory{}
+}
+
+// Outputs returns the outputs of the EKS resource stack.
+func (c *EksClient) Outputs() []stack.Output {
+	return stack.Outputs(inventoryOutputs)
+}
+
+// InventorySchemaVersion returns the current EKS inventory schema version.
+func (c *EksClient) InventorySchemaVersion() int {
+	return InventorySchemaVersion
+}
+
+// StartStateWriter creates the client's inventory channel and starts a
+// goroutine to write inventory sent on it to state.  The returned function
+// closes the channel.
+func (c *EksClient) StartStateWriter(
+	backend state.Backend,
+	lockInfo state.LockInfo,
+	wait *sync.WaitGroup,
+) func() {
+	inventoryChan := make(chan EksInventory)
+	c.InventoryChan = &inventoryChan
+	state.StartWriter(c.Context, backend, lockInfo, inventoryChan, wait)
+
+	return func() { close(inventoryChan) }
+}
+
+// Create creates the EKS resource stack.
+func (c *EksClient) Create(config stack.Config, inventory stack.Inventory) error {
+	eksConfig, err := stack.ConfigAs[*EksConfig](config)
+	if err != nil {
+		return err
+	}
+	eksInventory, err := stack.InventoryAs[*EksInventory](inventory)
+	if err != nil {
+		return err
+	}
+
+	return c.CreateEksResourceStack(eksConfig, eksInventory)
+}
+
+// Delete deletes the EKS resource stack.
+func (c *EksClient) Delete(inventory stack.Inventory) error {
+	eksInventory, err := stack.InventoryAs[*EksInventory](inventory)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteEksResourceStack(eksInventory)
+}
+
+// Plan returns the changes creating the EKS resource stack would make.
+func (c *EksClient) Plan(config stack.Config, inventory stack.Inventory) (*plan.ChangeSet, error) {
+	eksConfig, err := stack.ConfigAs[*EksConfig](config)
+	if err != nil {
+		return nil, err
+	}
+	eksInventory, err := stack.InventoryAs[*EksInventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.PlanEksResourceStack(eksConfig, eksInventory)
+}
+
+// Verify checks the EKS resource stack for drift.
+func (c *EksClient) Verify(inventory stack.Inventory, config stack.Config) (*drift.Report, error) {
+	eksInventory, err := stack.InventoryAs[*EksInventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+	eksConfig, err := stack.ConfigAs[*EksConfig](config)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.VerifyEksResourceStack(eksInventory, eksConfig)
+}
+
+// Import rebuilds an EKS inventory from existing resources.
+func (c *EksClient) Import(config stack.Config, inventory stack.Inventory) (*discovery.Report, error) {
+	eksConfig, err := stack.ConfigAs[*EksConfig](config)
+	if err != nil {
+		return nil, err
+	}
+	eksInventory, err := stack.InventoryAs[*EksInventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ImportEksResourceStack(eksConfig, eksInventory)
+}
+
+// Adopt records an existing EKS cluster and its dependencies in an EKS
+// inventory.
+func (c *EksClient) Adopt(
+	name string,
+	tags map[string]string,
+	applyTags bool,
+	inventory stack.Inventory,
+) (*discovery.Report, error) {
+	eksInventory, err := stack.InventoryAs[*EksInventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.AdoptEksResourceStack(name, tags, applyTags, eksInventory)
+}
+
+// Orphans searches for resources of the EKS resource stack that are not in
+// the EKS inventory.
+func (c *EksClient) Orphans(config stack.Config, inventory stack.Inventory, broad bool) (*orphan.Report, error) {
+	eksConfig, err := stack.ConfigAs[*EksConfig](config)
+	if err != nil {
+		return nil, err
+	}
+	eksInventory, err := stack.InventoryAs[*EksInventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.OrphansEksResourceStack(eksConfig, eksInventory, broad)
+}
+
+// DeleteOrphans deletes orphaned EKS resources.
+func (c *EksClient) DeleteOrphans(report *orphan.Report) error {
+	return c.DeleteEksOrphans(report)
+}
@@ -3,12 +3,14 @@ package eks
 import (
 	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	"github.com/aws/smithy-go"
 
 	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreatePublicSubnets creates the public subnets used by an EKS cluster for
@@ -20,7 +22,7 @@ func (c *EksClient) CreatePublicSubnets(
 	clusterName string,
 	azInventory *[]AvailabilityZoneInventory,
 ) (*[]AvailabilityZoneInventory, []string, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	// make a copy of inventory for changes so we don't change existing AZ
 	// inventory incrementally - wWe want to apply all changes or none at all
@@ -29,22 +31,7 @@ func (c *EksClient) CreatePublicSubnets(
 
 	// the following tags are used by the Kubernetes controller that integrates
 	// with AWS
-	subnetTags := *tags
-	elbTagKey := "kubernetes.io/role/elb"
-	elbTagValue := "1"
-	elbTag := types.Tag{
-		Key:   &elbTagKey,
-		Value: &elbTagValue,
-	}
-	subnetTags = append(subnetTags, elbTag)
-
-	clusterNameSharedTagKey := fmt.Sprintf("kubernetes.io/cluster/%s", clusterName)
-	clusterNameSharedTagValue := "shared"
-	clusterNameSharedTag := types.Tag{
-		Key:   &clusterNameSharedTagKey,
-		Value: &clusterNameSharedTagValue,
-	}
-	subnetTags = append(subnetTags, clusterNameSharedTag)
+	subnetTags := publicSubnetTags(tags, clusterName)
 
 	for azIdx, az := range modifiedAzInventory {
 		for subnetIdx, subnet := range az.PublicSubnets {
@@ -103,7 +90,7 @@ func (c *EksClient) CreatePrivateSubnets(
 	clusterName string,
 	azInventory *[]AvailabilityZoneInventory,
 ) (*[]AvailabilityZoneInventory, []string, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	// make a copy of inventory for changes so we don't change existing AZ
 	// inventory incrementally - wWe want to apply all changes or none at all
@@ -112,22 +99,7 @@ func (c *EksClient) CreatePrivateSubnets(
 
 	// the following tags are used by the Kubernetes controller that integrates
 	// with AWS
-	subnetTags := *tags
-	internalElbTagKey := "kubernetes.io/role/internal-elb"
-	internalElbTagValue := "1"
-	internalElbTag := types.Tag{
-		Key:   &internalElbTagKey,
-		Value: &internalElbTagValue,
-	}
-	subnetTags = append(subnetTags, internalElbTag)
-
-	clusterNameSharedTagKey := fmt.Sprintf("kubernetes.io/cluster/%s", clusterName)
-	clusterNameSharedTagValue := "shared"
-	clusterNameSharedTag := types.Tag{
-		Key:   &clusterNameSharedTagKey,
-		Value: &clusterNameSharedTagValue,
-	}
-	subnetTags = append(subnetTags, clusterNameSharedTag)
+	subnetTags := privateSubnetTags(tags, clusterName)
 
 	for azIdx, az := range modifiedAzInventory {
 		for subnetIdx, subnet := range az.PrivateSubnets {
@@ -170,63 +142,73 @@ func (c *EksClient) CreatePrivateSubnets(
 
 // DeleteSubnets deletes the subnets used by the EKS cluster.  If no subnet IDs
 // are supplied, or if the subnets are not found it returns without error.
+// Every subnet is attempted and an error joining each failure is returned.
+// On success it returns a copy of the availability zone inventory without
+// subnet IDs and the IDs of the deleted subnets.
 func (c *EksClient) DeleteSubnets(
 	azInventory *[]AvailabilityZoneInventory,
 ) (*[]AvailabilityZoneInventory, []string, error) {
-	// make a copy of AZ inventory to make updates and send when all changes are
-	// successful
-	updatedAzInventory := *azInventory
+	subnetIds, err := c.deleteSubnets(azInventory)
+	if err != nil || len(subnetIds) == 0 {
+		return nil, subnetIds, err
+	}
+
+	updatedAzInventory := make([]AvailabilityZoneInventory, len(*azInventory))
+	for azIdx, azInv := range *azInventory {
+		azInv.PublicSubnets = slices.Clone(azInv.PublicSubnets)
+		for subnetIdx := range azInv.PublicSubnets {
+			azInv.PublicSubnets[subnetIdx].SubnetId = ""
+		}
+		azInv.PrivateSubnets = slices.Clone(azInv.PrivateSubnets)
+		for subnetIdx := range azInv.PrivateSubnets {
+			azInv.PrivateSubnets[subnetIdx].SubnetId = ""
+		}
+		updatedAzInventory[azIdx] = azInv
+	}
+
+	return &updatedAzInventory, subnetIds, nil
+}
 
+// deleteSubnets deletes the subnets in each availability zone and returns the
+// IDs of those deleted or not found.  Every subnet is attempted and an error
+// joining each failure is returned.
+func (c *EksClient) deleteSubnets(azInventory *[]AvailabilityZoneInventory) ([]string, error) {
 	// collect subnet inventory
 	var subnetIds []string
-	for azIdx, azInv := range updatedAzInventory {
-		for publicSubnetIdx, publicSubnet := range azInv.PublicSubnets {
+	for _, azInv := range *azInventory {
+		for _, publicSubnet := range azInv.PublicSubnets {
 			if publicSubnet.SubnetId != "" {
 				subnetIds = append(subnetIds, publicSubnet.SubnetId)
 			}
-			updatedAzInventory[azIdx].PublicSubnets[publicSubnetIdx].SubnetId = ""
 		}
-		for privateSubnetIdx, privateSubnet := range azInv.PrivateSubnets {
+		for _, privateSubnet := range azInv.PrivateSubnets {
 			if privateSubnet.SubnetId != "" {
 				subnetIds = append(subnetIds, privateSubnet.SubnetId)
 			}
-			updatedAzInventory[azIdx].PrivateSubnets[privateSubnetIdx].SubnetId = ""
 		}
 	}
 
-	// if there are no subnet IDs there is nothing to do
-	if len(subnetIds) == 0 {
-		return nil, subnetIds, nil
-	}
-
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
+	var deletedSubnetIds []string
+	var errs []error
 	for _, id := range subnetIds {
 		deleteSubnetInput := aws_ec2.DeleteSubnetInput{SubnetId: &id}
 		_, err := svc.DeleteSubnet(c.Context, &deleteSubnetInput)
-		if err != nil {
-			var ae smithy.APIError
-			if errors.As(err, &ae) {
-				if ae.ErrorCode() == "InvalidSubnetID.NotFound" {
-					// attempting to delete a subnet that doesn't exist so
-					// continue with deleting any other subnets
-					continue
-				} else {
-					return nil, subnetIds, fmt.Errorf("failed to delete subnet with ID %s: %w", id, err)
-				}
-			} else {
-				return nil, subnetIds, fmt.Errorf("failed to delete subnet with ID %s: %w", id, err)
-			}
+		if err != nil && !util.HasErrorCode(err, "InvalidSubnetID.NotFound") {
+			errs = append(errs, fmt.Errorf("failed to delete subnet with ID %s: %w", id, err))
+			continue
 		}
+		deletedSubnetIds = append(deletedSubnetIds, id)
 	}
 
-	return &updatedAzInventory, subnetIds, nil
+	return deletedSubnetIds, errors.Join(errs...)
 }
 
 // mapPublicIpsForSubnet configures a subnet to have instances launched in it
 // get a public IP address.
 func (c *EksClient) mapPublicIpsForSubnet(subnetId string) error {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	mapPublicIp := true
 	modifySubnetAttributeInput := aws_ec2.ModifySubnetAttributeInput{
@@ -240,5 +222,34 @@ func (c *EksClient) mapPublicIpsForSubnet(subnetId string) error {
 	}
 
 	return nil
+}
+
+// publicSubnetTags returns the tags for public subnets.  The role tag allows
+// the Kubernetes controller that integrates with AWS to place internet-facing
+// load balancers in these subnets.
+func publicSubnetTags(tags *[]types.Tag, clusterName string) []types.Tag {
+	return subnetTags(tags, "kubernetes.io/role/elb", clusterName)
+}
+
+// privateSubnetTags returns the tags for private subnets.  The role tag allows
+// the Kubernetes controller that integrates with AWS to place internal load
+// balancers in these subnets.
+func privateSubnetTags(tags *[]types.Tag, clusterName string) []types.Tag {
+	return subnetTags(tags, "kubernetes.io/role/internal-elb", clusterName)
+}
 
+// subnetTags returns a copy of the resource stack tags with the load balancer
+// role tag and cluster shared tag added.
+func subnetTags(tags *[]types.Tag, roleTagKey, clusterName string) []types.Tag {
+	subnetTags := append([]types.Tag{}, *tags...)
+	subnetTags = append(subnetTags, types.Tag{
+		Key:   aws.String(roleTagKey),
+		Value: aws.String("1"),
+	})
+	subnetTags = append(subnetTags, types.Tag{
+		Key:   aws.String(fmt.Sprintf("kubernetes.io/cluster/%s", clusterName)),
+		Value: aws.String("shared"),
+	})
+
+	return subnetTags
 }
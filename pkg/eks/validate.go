@@ -0,0 +1,178 @@
+package eks
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+var (
+	// clusterNamePattern matches the names EKS accepts for clusters.
+	clusterNamePattern = regexp.MustCompile(`^[0-9A-Za-z][A-Za-z0-9\-_]*$`)
+
+	// kubernetesVersionPattern matches Kubernetes minor versions, e.g. "1.32".
+	kubernetesVersionPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+	// awsAccountIdPattern matches 12 digit AWS account IDs.
+	awsAccountIdPattern = regexp.MustCompile(`^[0-9]{12}$`)
+)
+
+// maxNodeGroupNameLength is the longest node group name EKS accepts.
+const maxNodeGroupNameLength = 63
+
+// subnet is a subnet CIDR block and the config field it was set in.
+type subnet struct {
+	field  string
+	prefix netip.Prefix
+}
+
+// Validate checks the config for values that would cause resource creation to
+// fail.  If any are found a validation.Errors containing every problem is
+// returned.
+func (c *EksConfig) Validate() error {
+	var errs validation.Errors
+
+	// cluster name
+	switch {
+	case c.Name == "":
+		errs.Add("name", "is required")
+	case utf8.RuneCountInString(c.Name) > 100:
+		errs.Add("name", "must be 100 characters or less")
+	case !clusterNamePattern.MatchString(c.Name):
+		errs.Add("name", "must begin with a letter or digit and contain only letters, digits, hyphens and underscores")
+	}
+	if nodeGroupName := fmt.Sprintf("%s-private-node-group", c.Name); utf8.RuneCountInString(nodeGroupName) > maxNodeGroupNameLength {
+		errs.Add("name", "node group name %s must be %d characters or less", nodeGroupName, maxNodeGroupNameLength)
+	}
+
+	if !awsAccountIdPattern.MatchString(c.AwsAccountId) {
+		errs.Add("awsAccountId", "must be a 12 digit AWS account ID")
+	}
+	if c.KubernetesVersion != "" && !kubernetesVersionPattern.MatchString(c.KubernetesVersion) {
+		errs.Add("kubernetesVersion", "%q is not a Kubernetes minor version, e.g. %q", c.KubernetesVersion, DefaultKubernetesVersion)
+	}
+
+	// networking
+	clusterPrefix, clusterCidrValid := errs.ParseCidr("clusterCidr", c.ClusterCidr)
+	var subnets []subnet
+	if len(c.AvailabilityZones) > 0 {
+		zones := map[string]bool{}
+		for i, az := range c.AvailabilityZones {
+			azField := validation.Index("availabilityZones", i)
+			switch {
+			case az.Zone == "":
+				errs.Add(validation.Key(azField, "zone"), "is required")
+			case zones[az.Zone]:
+				errs.Add(validation.Key(azField, "zone"), "%s is used by more than one availability zone", az.Zone)
+			}
+			zones[az.Zone] = true
+
+			for _, cidr := range []struct {
+				field string
+				value string
+			}{
+				{validation.Key(azField, "privateSubnetCidr"), az.PrivateSubnetCidr},
+				{validation.Key(azField, "publicSubnetCidr"), az.PublicSubnetCidr},
+			} {
+				if prefix, ok := errs.ParseCidr(cidr.field, cidr.value); ok {
+					subnets = append(subnets, subnet{cidr.field, prefix})
+				}
+			}
+		}
+	} else {
+		switch {
+		case c.DesiredAzCount < 0:
+			errs.Add("desiredAzCount", "must not be negative")
+		case c.DesiredAzCount > maxAzCount:
+			errs.Add("desiredAzCount", "must be %d or less", maxAzCount)
+		}
+
+		// default subnets are used for each availability zone
+		azCount := c.DesiredAzCount
+		if azCount == 0 {
+			azCount = DefaultAzCount
+		}
+		for _, cidr := range defaultCidrs()[:2*min(max(azCount, 0), maxAzCount)] {
+			subnets = append(subnets, subnet{"availabilityZones", netip.MustParsePrefix(cidr)})
+		}
+	}
+	for i, s := range subnets {
+		if clusterCidrValid && !validation.Contains(clusterPrefix, s.prefix) {
+			errs.Add(s.field, "subnet %s is not within clusterCidr %s", s.prefix, clusterPrefix)
+		}
+		for _, other := range subnets[:i] {
+			if s.prefix.Overlaps(other.prefix) {
+				errs.Add(s.field, "subnet %s overlaps %s subnet %s", s.prefix, other.field, other.prefix)
+			}
+		}
+	}
+
+	// node group scaling
+	for _, nodes := range []struct {
+		field string
+		value int32
+	}{
+		{"initialNodes", c.InitialNodes},
+		{"minNodes", c.MinNodes},
+		{"maxNodes", c.MaxNodes},
+	} {
+		if nodes.value < 0 {
+			errs.Add(nodes.field, "must not be negative")
+		}
+	}
+	switch {
+	case c.MaxNodes < 1:
+		errs.Add("maxNodes", "must be 1 or greater")
+	case c.MinNodes > c.MaxNodes:
+		errs.Add("minNodes", "%d is greater than maxNodes %d", c.MinNodes, c.MaxNodes)
+	case c.InitialNodes < c.MinNodes || c.InitialNodes > c.MaxNodes:
+		errs.Add("initialNodes", "%d must be between minNodes %d and maxNodes %d", c.InitialNodes, c.MinNodes, c.MaxNodes)
+	}
+	for i, instanceType := range c.InstanceTypes {
+		if instanceType == "" {
+			errs.Add(validation.Index("instanceTypes", i), "must not be empty")
+		}
+	}
+
+	// IAM roles and the service accounts that assume them
+	roles := []struct {
+		feature        string
+		enabled        bool
+		roleName       string
+		serviceAccount *ServiceAccountConfig
+	}{
+		{"", true, ClusterRoleName, nil},
+		{"", true, WorkerRoleName, nil},
+		{"", true, StorageManagementRoleName, nil},
+		{"dnsManagement", c.DnsManagement, DnsManagementRoleName, &c.DnsManagementServiceAccount},
+		{"dns01Challenge", c.Dns01Challenge, Dns01ChallengeRoleName, &c.Dns01ChallengeServiceAccount},
+		{"secretsManager", c.SecretsManager, SecretsManagerRoleName, &c.SecretsManagerServiceAccount},
+		{"clusterAutoscaling", c.ClusterAutoscaling, ClusterAutoscalingRoleName, &c.ClusterAutoscalingServiceAccount},
+	}
+	for _, role := range roles {
+		if !role.enabled {
+			continue
+		}
+		if err := CheckRoleName(fmt.Sprintf("%s-%s", role.roleName, c.Name)); err != nil && c.Name != "" {
+			errs.Add("name", "%s", err)
+		}
+		if role.serviceAccount == nil {
+			continue
+		}
+		serviceAccountField := role.feature + "ServiceAccount"
+		if role.serviceAccount.Name == "" {
+			errs.Add(validation.Key(serviceAccountField, "name"), "is required when %s is enabled", role.feature)
+		}
+		if role.serviceAccount.Namespace == "" {
+			errs.Add(validation.Key(serviceAccountField, "namespace"), "is required when %s is enabled", role.feature)
+		}
+	}
+
+	errs.Tags("tags", c.Tags)
+	c.Waiters.Validate(&errs, "waiters", ClusterWaiter, NatGatewayWaiter, NodeGroupWaiter)
+
+	return errs.Err()
+}
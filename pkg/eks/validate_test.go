@@ -0,0 +1,122 @@
+package eks
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		update   func(resourceConfig *EksConfig)
+		expected validation.Errors
+	}{
+		{
+			name:   "sample config",
+			update: func(resourceConfig *EksConfig) {},
+		},
+		{
+			name: "overlapping subnets",
+			update: func(resourceConfig *EksConfig) {
+				resourceConfig.AvailabilityZones = []AvailabilityZoneConfig{
+					{Zone: "us-east-2a", PrivateSubnetCidr: "10.0.0.0/22", PublicSubnetCidr: "10.0.2.0/24"},
+					{Zone: "us-east-2b", PrivateSubnetCidr: "10.0.8.0/22", PublicSubnetCidr: "10.0.0.0/22"},
+				}
+			},
+			expected: validation.Errors{
+				{
+					Field:   "availabilityZones[0].publicSubnetCidr",
+					Message: "subnet 10.0.2.0/24 overlaps availabilityZones[0].privateSubnetCidr subnet 10.0.0.0/22",
+				},
+				{
+					Field:   "availabilityZones[1].publicSubnetCidr",
+					Message: "subnet 10.0.0.0/22 overlaps availabilityZones[0].privateSubnetCidr subnet 10.0.0.0/22",
+				},
+				{
+					Field:   "availabilityZones[1].publicSubnetCidr",
+					Message: "subnet 10.0.0.0/22 overlaps availabilityZones[0].publicSubnetCidr subnet 10.0.2.0/24",
+				},
+			},
+		},
+		{
+			name: "subnet outside cluster CIDR",
+			update: func(resourceConfig *EksConfig) {
+				resourceConfig.AvailabilityZones = []AvailabilityZoneConfig{
+					{Zone: "us-east-2a", PrivateSubnetCidr: "10.0.0.0/22", PublicSubnetCidr: "10.1.0.0/22"},
+				}
+			},
+			expected: validation.Errors{
+				{
+					Field:   "availabilityZones[0].publicSubnetCidr",
+					Message: "subnet 10.1.0.0/22 is not within clusterCidr 10.0.0.0/16",
+				},
+			},
+		},
+		{
+			name: "default subnets outside cluster CIDR",
+			update: func(resourceConfig *EksConfig) {
+				resourceConfig.ClusterCidr = "10.0.0.0/21"
+				resourceConfig.DesiredAzCount = 2
+			},
+			expected: validation.Errors{
+				{Field: "availabilityZones", Message: "subnet 10.0.8.0/22 is not within clusterCidr 10.0.0.0/21"},
+				{Field: "availabilityZones", Message: "subnet 10.0.12.0/22 is not within clusterCidr 10.0.0.0/21"},
+			},
+		},
+		{
+			name: "min nodes greater than max nodes",
+			update: func(resourceConfig *EksConfig) {
+				resourceConfig.InitialNodes = 4
+				resourceConfig.MinNodes = 5
+				resourceConfig.MaxNodes = 3
+			},
+			expected: validation.Errors{
+				{Field: "minNodes", Message: "5 is greater than maxNodes 3"},
+			},
+		},
+		{
+			name: "every problem",
+			update: func(resourceConfig *EksConfig) {
+				resourceConfig.Name = ""
+				resourceConfig.AwsAccountId = "12345"
+				resourceConfig.ClusterCidr = "10.0.0.1/16"
+				resourceConfig.MaxNodes = 0
+				resourceConfig.DnsManagementServiceAccount.Name = ""
+				resourceConfig.Tags["aws:owner"] = "team"
+			},
+			expected: validation.Errors{
+				{Field: "name", Message: "is required"},
+				{Field: "awsAccountId", Message: "must be a 12 digit AWS account ID"},
+				{Field: "clusterCidr", Message: `"10.0.0.1/16" has host bits set, use 10.0.0.0/16`},
+				{Field: "maxNodes", Message: "must be 1 or greater"},
+				{Field: "dnsManagementServiceAccount.name", Message: "is required when dnsManagement is enabled"},
+				{Field: "tags.aws:owner", Message: `tag keys beginning with "aws:" are reserved`},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resourceConfig := sampleConfig(t)
+			testCase.update(resourceConfig)
+
+			err := resourceConfig.Validate()
+			if testCase.expected == nil {
+				if err != nil {
+					t.Fatalf("expected config to be valid, got %v", err)
+				}
+				return
+			}
+			var errs validation.Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+			if !reflect.DeepEqual(errs, testCase.expected) {
+				t.Errorf("expected problems\n%v\ngot\n%v", testCase.expected, errs)
+			}
+		})
+	}
+}
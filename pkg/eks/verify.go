@@ -0,0 +1,509 @@
+package eks
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// VerifyEksResourceStack checks that each resource in the inventory still
+// exists and is in the state the resource stack expects.  If a resource config
+// is supplied, configurable values such as the Kubernetes version and node
+// group scaling are also compared against it.  Only read-only calls are made.
+func (c *EksClient) VerifyEksResourceStack(
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) (*drift.Report, error) {
+	if inventory.Region != "" {
+		c.AwsConfig.Region = inventory.Region
+	}
+	report := drift.NewReport("eks", c.AwsConfig.Region)
+
+	if err := c.verifyNetwork(report, inventory, resourceConfig); err != nil {
+		return nil, err
+	}
+	if err := c.verifyIam(report, inventory); err != nil {
+		return nil, err
+	}
+	if err := c.verifyCluster(report, inventory, resourceConfig); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// verifyNetwork verifies the VPC, internet gateway, subnets, elastic IPs, NAT
+// gateways, route tables and cluster security group.
+func (c *EksClient) verifyNetwork(
+	report *drift.Report,
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) error {
+	svc := c.GetEc2Api()
+
+	// VPC
+	if inventory.VpcId != "" {
+		resp, err := svc.DescribeVpcs(c.Context, &aws_ec2.DescribeVpcsInput{
+			VpcIds: []string{inventory.VpcId},
+		})
+		switch {
+		case util.HasErrorCode(err, "InvalidVpcID.NotFound"), err == nil && len(resp.Vpcs) == 0:
+			report.Missing("vpc", inventory.VpcId)
+		case err != nil:
+			return fmt.Errorf("failed to describe VPC %s: %w", inventory.VpcId, err)
+		default:
+			result := report.Found("vpc", inventory.VpcId)
+			vpc := resp.Vpcs[0]
+			if resourceConfig != nil && resourceConfig.ClusterCidr != "" && *vpc.CidrBlock != resourceConfig.ClusterCidr {
+				result.Modified("CIDR block is %s, expected %s", *vpc.CidrBlock, resourceConfig.ClusterCidr)
+			}
+		}
+	}
+
+	// Internet Gateway
+	if inventory.InternetGatewayId != "" {
+		resp, err := svc.DescribeInternetGateways(c.Context, &aws_ec2.DescribeInternetGatewaysInput{
+			InternetGatewayIds: []string{inventory.InternetGatewayId},
+		})
+		switch {
+		case util.HasErrorCode(err, "InvalidInternetGatewayID.NotFound"), err == nil && len(resp.InternetGateways) == 0:
+			report.Missing("internet-gateway", inventory.InternetGatewayId)
+		case err != nil:
+			return fmt.Errorf("failed to describe internet gateway %s: %w", inventory.InternetGatewayId, err)
+		default:
+			result := report.Found("internet-gateway", inventory.InternetGatewayId)
+			attached := false
+			for _, attachment := range resp.InternetGateways[0].Attachments {
+				if attachment.VpcId != nil && *attachment.VpcId == inventory.VpcId {
+					attached = true
+				}
+			}
+			if !attached {
+				result.Modified("not attached to VPC %s", inventory.VpcId)
+			}
+		}
+	}
+
+	// Subnets
+	var publicSubnetIds, privateSubnetIds []string
+	for _, az := range inventory.AvailabilityZones {
+		for _, subnet := range az.PublicSubnets {
+			if subnet.SubnetId == "" {
+				continue
+			}
+			publicSubnetIds = append(publicSubnetIds, subnet.SubnetId)
+			if err := c.verifySubnet(report, "public-subnet", subnet, az.Zone, inventory.VpcId); err != nil {
+				return err
+			}
+		}
+		for _, subnet := range az.PrivateSubnets {
+			if subnet.SubnetId == "" {
+				continue
+			}
+			privateSubnetIds = append(privateSubnetIds, subnet.SubnetId)
+			if err := c.verifySubnet(report, "private-subnet", subnet, az.Zone, inventory.VpcId); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Elastic IPs
+	for _, elasticIpId := range inventory.ElasticIpIds {
+		resp, err := svc.DescribeAddresses(c.Context, &aws_ec2.DescribeAddressesInput{
+			AllocationIds: []string{elasticIpId},
+		})
+		switch {
+		case util.HasErrorCode(err, "InvalidAllocationID.NotFound"), err == nil && len(resp.Addresses) == 0:
+			report.Missing("elastic-ip", elasticIpId)
+		case err != nil:
+			return fmt.Errorf("failed to describe elastic IP %s: %w", elasticIpId, err)
+		default:
+			report.Found("elastic-ip", elasticIpId)
+		}
+	}
+
+	// NAT Gateways
+	var natGatewayIds []string
+	for _, az := range inventory.AvailabilityZones {
+		if az.NatGatewayId == "" {
+			continue
+		}
+		natGatewayIds = append(natGatewayIds, az.NatGatewayId)
+		resp, err := svc.DescribeNatGateways(c.Context, &aws_ec2.DescribeNatGatewaysInput{
+			NatGatewayIds: []string{az.NatGatewayId},
+		})
+		switch {
+		case util.HasErrorCode(err, "NatGatewayNotFound", "InvalidNATGatewayID.NotFound"),
+			err == nil && len(resp.NatGateways) == 0,
+			err == nil && (resp.NatGateways[0].State == ec2_types.NatGatewayStateDeleted ||
+				resp.NatGateways[0].State == ec2_types.NatGatewayStateDeleting):
+			report.Missing("nat-gateway", az.NatGatewayId)
+		case err != nil:
+			return fmt.Errorf("failed to describe NAT gateway %s: %w", az.NatGatewayId, err)
+		default:
+			result := report.Found("nat-gateway", az.NatGatewayId)
+			natGateway := resp.NatGateways[0]
+			if natGateway.State != ec2_types.NatGatewayStateAvailable {
+				result.Modified("state is %s", natGateway.State)
+			}
+			inPublicSubnet := false
+			for _, subnet := range az.PublicSubnets {
+				if natGateway.SubnetId != nil && *natGateway.SubnetId == subnet.SubnetId {
+					inPublicSubnet = true
+				}
+			}
+			if !inPublicSubnet {
+				result.Modified("not in a public subnet for zone %s", az.Zone)
+			}
+		}
+	}
+
+	// Public Route Table
+	if inventory.PublicRouteTableId != "" {
+		result, routeTable, err := c.verifyRouteTable(report, "public-route-table", inventory.PublicRouteTableId)
+		if err != nil {
+			return err
+		}
+		if routeTable != nil {
+			checkRouteTableAssociations(result, routeTable, publicSubnetIds, publicSubnetIds)
+			if !hasDefaultRoute(routeTable, []string{inventory.InternetGatewayId}, nil) {
+				result.Modified("no default route to internet gateway %s", inventory.InternetGatewayId)
+			}
+		}
+	}
+
+	// Private Route Tables
+	for _, routeTableId := range inventory.PrivateRouteTableIds {
+		result, routeTable, err := c.verifyRouteTable(report, "private-route-table", routeTableId)
+		if err != nil {
+			return err
+		}
+		if routeTable != nil {
+			checkRouteTableAssociations(result, routeTable, nil, privateSubnetIds)
+			if len(routeTable.Associations) == 0 {
+				result.Modified("not associated with a private subnet")
+			}
+			if !hasDefaultRoute(routeTable, nil, natGatewayIds) {
+				result.Modified("no default route to a NAT gateway in inventory")
+			}
+		}
+	}
+
+	// EKS Cluster Security Group
+	if inventory.SecurityGroupId != "" {
+		resp, err := svc.DescribeSecurityGroups(c.Context, &aws_ec2.DescribeSecurityGroupsInput{
+			GroupIds: []string{inventory.SecurityGroupId},
+		})
+		switch {
+		case util.HasErrorCode(err, "InvalidGroup.NotFound", "InvalidGroupId.NotFound"), err == nil && len(resp.SecurityGroups) == 0:
+			report.Missing("security-group", inventory.SecurityGroupId)
+		case err != nil:
+			return fmt.Errorf("failed to describe security group %s: %w", inventory.SecurityGroupId, err)
+		default:
+			result := report.Found("security-group", inventory.SecurityGroupId)
+			securityGroup := resp.SecurityGroups[0]
+			if securityGroup.VpcId != nil && *securityGroup.VpcId != inventory.VpcId {
+				result.Modified("in VPC %s, expected %s", *securityGroup.VpcId, inventory.VpcId)
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifySubnet verifies a single subnet exists in the expected VPC, zone and
+// CIDR block.
+func (c *EksClient) verifySubnet(
+	report *drift.Report,
+	kind string,
+	subnet SubnetInventory,
+	zone string,
+	vpcId string,
+) error {
+	svc := c.GetEc2Api()
+
+	resp, err := svc.DescribeSubnets(c.Context, &aws_ec2.DescribeSubnetsInput{
+		SubnetIds: []string{subnet.SubnetId},
+	})
+	switch {
+	case util.HasErrorCode(err, "InvalidSubnetID.NotFound"), err == nil && len(resp.Subnets) == 0:
+		report.Missing(kind, subnet.SubnetId)
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to describe subnet %s: %w", subnet.SubnetId, err)
+	}
+
+	result := report.Found(kind, subnet.SubnetId)
+	existingSubnet := resp.Subnets[0]
+	if existingSubnet.VpcId != nil && *existingSubnet.VpcId != vpcId {
+		result.Modified("in VPC %s, expected %s", *existingSubnet.VpcId, vpcId)
+	}
+	if existingSubnet.CidrBlock != nil && *existingSubnet.CidrBlock != subnet.SubnetCidr {
+		result.Modified("CIDR block is %s, expected %s", *existingSubnet.CidrBlock, subnet.SubnetCidr)
+	}
+	if existingSubnet.AvailabilityZone != nil && *existingSubnet.AvailabilityZone != zone {
+		result.Modified("in zone %s, expected %s", *existingSubnet.AvailabilityZone, zone)
+	}
+	if kind == "public-subnet" && (existingSubnet.MapPublicIpOnLaunch == nil || !*existingSubnet.MapPublicIpOnLaunch) {
+		result.Modified("public IPs are not mapped on launch")
+	}
+
+	return nil
+}
+
+// verifyRouteTable adds the result for a route table to the report and returns
+// the route table if it exists.
+func (c *EksClient) verifyRouteTable(
+	report *drift.Report,
+	kind string,
+	routeTableId string,
+) (*drift.Result, *ec2_types.RouteTable, error) {
+	svc := c.GetEc2Api()
+
+	resp, err := svc.DescribeRouteTables(c.Context, &aws_ec2.DescribeRouteTablesInput{
+		RouteTableIds: []string{routeTableId},
+	})
+	switch {
+	case util.HasErrorCode(err, "InvalidRouteTableID.NotFound"), err == nil && len(resp.RouteTables) == 0:
+		return report.Missing(kind, routeTableId), nil, nil
+	case err != nil:
+		return nil, nil, fmt.Errorf("failed to describe route table %s: %w", routeTableId, err)
+	}
+
+	return report.Found(kind, routeTableId), &resp.RouteTables[0], nil
+}
+
+// checkRouteTableAssociations records subnets that should be associated with
+// the route table but are not, and associations with subnets that are not in
+// the allowed list.
+func checkRouteTableAssociations(
+	result *drift.Result,
+	routeTable *ec2_types.RouteTable,
+	requiredSubnetIds []string,
+	allowedSubnetIds []string,
+) {
+	var associatedSubnetIds []string
+	for _, assoc := range routeTable.Associations {
+		if assoc.SubnetId == nil {
+			continue
+		}
+		associatedSubnetIds = append(associatedSubnetIds, *assoc.SubnetId)
+		if !slices.Contains(allowedSubnetIds, *assoc.SubnetId) {
+			result.Unexpected("associated with subnet %s not in inventory", *assoc.SubnetId)
+		}
+	}
+	for _, subnetId := range requiredSubnetIds {
+		if !slices.Contains(associatedSubnetIds, subnetId) {
+			result.Modified("not associated with subnet %s", subnetId)
+		}
+	}
+}
+
+// hasDefaultRoute returns true if the route table has a default route to one
+// of the provided gateways or NAT gateways.
+func hasDefaultRoute(routeTable *ec2_types.RouteTable, gatewayIds, natGatewayIds []string) bool {
+	for _, route := range routeTable.Routes {
+		if route.DestinationCidrBlock == nil || *route.DestinationCidrBlock != "0.0.0.0/0" {
+			continue
+		}
+		if route.GatewayId != nil && slices.Contains(gatewayIds, *route.GatewayId) {
+			return true
+		}
+		if route.NatGatewayId != nil && slices.Contains(natGatewayIds, *route.NatGatewayId) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyIam verifies the IAM roles and their attached policies, the IAM
+// policies and the OIDC provider.
+func (c *EksClient) verifyIam(report *drift.Report, inventory *EksInventory) error {
+	svc := c.GetIamApi()
+
+	// IAM Roles
+	roles := []RoleInventory{
+		inventory.ClusterRole,
+		inventory.WorkerRole,
+		inventory.DnsManagementRole,
+		inventory.Dns01ChallengeRole,
+		inventory.SecretsManagerRole,
+		inventory.ClusterAutoscalingRole,
+		inventory.StorageManagementRole,
+	}
+	for _, role := range roles {
+		if role.RoleName == "" {
+			continue
+		}
+		existingRole, err := c.getRole(role.RoleName)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+			report.Missing("iam-role", role.RoleName)
+			continue
+		case err != nil:
+			return err
+		}
+		result := report.Found("iam-role", role.RoleName)
+		if role.RoleArn != "" && *existingRole.Arn != role.RoleArn {
+			result.Modified("ARN is %s, expected %s", *existingRole.Arn, role.RoleArn)
+		}
+
+		resp, err := svc.ListAttachedRolePolicies(c.Context, &iam.ListAttachedRolePoliciesInput{
+			RoleName: &role.RoleName,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list policies for role %s: %w", role.RoleName, err)
+		}
+		var attachedPolicyArns []string
+		for _, policy := range resp.AttachedPolicies {
+			attachedPolicyArns = append(attachedPolicyArns, *policy.PolicyArn)
+			if !slices.Contains(role.RolePolicyArns, *policy.PolicyArn) {
+				result.Unexpected("policy %s attached outside of resource stack", *policy.PolicyArn)
+			}
+		}
+		for _, policyArn := range role.RolePolicyArns {
+			if !slices.Contains(attachedPolicyArns, policyArn) {
+				result.Modified("policy %s not attached", policyArn)
+			}
+		}
+	}
+
+	// IAM Policies
+	for _, policyArn := range inventory.PolicyArns {
+		_, err := svc.GetPolicy(c.Context, &iam.GetPolicyInput{PolicyArn: &policyArn})
+		switch {
+		case util.HasErrorCode(err, "NoSuchEntity"):
+			report.Missing("iam-policy", policyArn)
+		case err != nil:
+			return fmt.Errorf("failed to get policy %s: %w", policyArn, err)
+		default:
+			report.Found("iam-policy", policyArn)
+		}
+	}
+
+	// OIDC Provider
+	if inventory.OidcProviderArn != "" {
+		resp, err := svc.ListOpenIDConnectProviders(c.Context, &iam.ListOpenIDConnectProvidersInput{})
+		if err != nil {
+			return fmt.Errorf("failed to list OIDC providers: %w", err)
+		}
+		providerFound := false
+		for _, provider := range resp.OpenIDConnectProviderList {
+			if provider.Arn != nil && *provider.Arn == inventory.OidcProviderArn {
+				providerFound = true
+			}
+		}
+		if providerFound {
+			report.Found("iam-oidc-provider", inventory.OidcProviderArn)
+		} else {
+			report.Missing("iam-oidc-provider", inventory.OidcProviderArn)
+		}
+	}
+
+	return nil
+}
+
+// verifyCluster verifies the EKS cluster, node groups and addon.
+func (c *EksClient) verifyCluster(
+	report *drift.Report,
+	inventory *EksInventory,
+	resourceConfig *EksConfig,
+) error {
+	clusterName := inventory.Cluster.ClusterName
+	if clusterName == "" {
+		return nil
+	}
+
+	// EKS Cluster
+	cluster, err := c.getCluster(clusterName)
+	switch {
+	case errors.Is(err, util.ErrResourceNotFound):
+		report.Missing("eks-cluster", clusterName)
+	case err != nil:
+		return err
+	default:
+		result := report.Found("eks-cluster", clusterName)
+		if cluster.Status != types.ClusterStatusActive {
+			result.Modified("status is %s", cluster.Status)
+		}
+		if inventory.Cluster.ClusterArn != "" && *cluster.Arn != inventory.Cluster.ClusterArn {
+			result.Modified("ARN is %s, expected %s", *cluster.Arn, inventory.Cluster.ClusterArn)
+		}
+		if inventory.Cluster.OidcProviderUrl != "" && cluster.Identity != nil && cluster.Identity.Oidc != nil &&
+			cluster.Identity.Oidc.Issuer != nil && *cluster.Identity.Oidc.Issuer != inventory.Cluster.OidcProviderUrl {
+			result.Modified("OIDC issuer is %s, expected %s", *cluster.Identity.Oidc.Issuer, inventory.Cluster.OidcProviderUrl)
+		}
+		if resourceConfig != nil && resourceConfig.KubernetesVersion != "" && cluster.Version != nil &&
+			*cluster.Version != resourceConfig.KubernetesVersion {
+			result.Modified("Kubernetes version is %s, expected %s", *cluster.Version, resourceConfig.KubernetesVersion)
+		}
+	}
+
+	// Node Groups
+	for _, nodeGroupName := range inventory.NodeGroupNames {
+		nodeGroup, err := c.getNodeGroup(clusterName, nodeGroupName)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+			report.Missing("eks-node-group", nodeGroupName)
+			continue
+		case err != nil:
+			return err
+		}
+		result := report.Found("eks-node-group", nodeGroupName)
+		if nodeGroup.Status != types.NodegroupStatusActive {
+			result.Modified("status is %s", nodeGroup.Status)
+		}
+		if nodeGroup.Health != nil {
+			for _, issue := range getHealthIssues(*nodeGroup.Health) {
+				result.Modified("health issue: %s", issue)
+			}
+		}
+		if inventory.WorkerRole.RoleArn != "" && nodeGroup.NodeRole != nil && *nodeGroup.NodeRole != inventory.WorkerRole.RoleArn {
+			result.Modified("node role is %s, expected %s", *nodeGroup.NodeRole, inventory.WorkerRole.RoleArn)
+		}
+		if resourceConfig == nil {
+			continue
+		}
+		if scaling := nodeGroup.ScalingConfig; scaling != nil {
+			if scaling.MinSize != nil && resourceConfig.MinNodes != 0 && *scaling.MinSize != resourceConfig.MinNodes {
+				result.Modified("minimum size is %d, expected %d", *scaling.MinSize, resourceConfig.MinNodes)
+			}
+			if scaling.MaxSize != nil && resourceConfig.MaxNodes != 0 && *scaling.MaxSize != resourceConfig.MaxNodes {
+				result.Modified("maximum size is %d, expected %d", *scaling.MaxSize, resourceConfig.MaxNodes)
+			}
+		}
+		if len(resourceConfig.InstanceTypes) != 0 && !slices.Equal(nodeGroup.InstanceTypes, resourceConfig.InstanceTypes) {
+			result.Modified("instance types are %s, expected %s", nodeGroup.InstanceTypes, resourceConfig.InstanceTypes)
+		}
+		if resourceConfig.KubernetesVersion != "" && nodeGroup.Version != nil && *nodeGroup.Version != resourceConfig.KubernetesVersion {
+			result.Modified("Kubernetes version is %s, expected %s", *nodeGroup.Version, resourceConfig.KubernetesVersion)
+		}
+	}
+
+	// EBS CSI Addon
+	if inventory.ClusterAddon {
+		addon, err := c.getAddon(clusterName, EbsStorageAddonName)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+			report.Missing("eks-addon", EbsStorageAddonName)
+		case err != nil:
+			return err
+		default:
+			result := report.Found("eks-addon", EbsStorageAddonName)
+			if addon.Status != types.AddonStatusActive {
+				result.Modified("status is %s", addon.Status)
+			}
+		}
+	}
+
+	return nil
+}
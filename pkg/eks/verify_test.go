@@ -0,0 +1,144 @@
+package eks
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+// findResult returns the report's result for a resource or nil if the
+// resource was not verified.
+func findResult(report *drift.Report, kind, id string) *drift.Result {
+	for _, result := range report.Results {
+		if result.Kind == kind && result.Id == id {
+			return result
+		}
+	}
+
+	return nil
+}
+
+func TestVerifyEksResourceStack(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	eksClient := testClient(backend)
+	apis := backend.Apis()
+	ctx := context.Background()
+
+	var inventory EksInventory
+	if err := eksClient.CreateEksResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+
+	report, err := eksClient.VerifyEksResourceStack(&inventory, resourceConfig)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	if report.HasDrift() {
+		t.Fatalf("expected no drift after create, got %s", report.Summary())
+	}
+
+	// a deleted NAT gateway is missing, a detached policy and changed config
+	// values are modifications and an extra policy is unexpected
+	natGatewayId := inventory.AvailabilityZones[0].NatGatewayId
+	if _, err := apis.Ec2.DeleteNatGateway(ctx, &aws_ec2.DeleteNatGatewayInput{
+		NatGatewayId: aws.String(natGatewayId),
+	}); err != nil {
+		t.Fatalf("failed to delete NAT gateway: %v", err)
+	}
+	// describing the NAT gateway completes its deletion
+	if _, err := apis.Ec2.DescribeNatGateways(ctx, &aws_ec2.DescribeNatGatewaysInput{
+		NatGatewayIds: []string{natGatewayId},
+	}); err != nil {
+		t.Fatalf("failed to describe NAT gateway: %v", err)
+	}
+	detachedPolicyArn := inventory.ClusterRole.RolePolicyArns[0]
+	if _, err := apis.Iam.DetachRolePolicy(ctx, &iam.DetachRolePolicyInput{
+		RoleName:  aws.String(inventory.ClusterRole.RoleName),
+		PolicyArn: aws.String(detachedPolicyArn),
+	}); err != nil {
+		t.Fatalf("failed to detach policy: %v", err)
+	}
+	extraPolicyArn := "arn:aws:iam::aws:policy/AdministratorAccess"
+	if _, err := apis.Iam.AttachRolePolicy(ctx, &iam.AttachRolePolicyInput{
+		RoleName:  aws.String(inventory.WorkerRole.RoleName),
+		PolicyArn: aws.String(extraPolicyArn),
+	}); err != nil {
+		t.Fatalf("failed to attach policy: %v", err)
+	}
+	kubernetesVersion := resourceConfig.KubernetesVersion
+	resourceConfig.KubernetesVersion = "1.0"
+	resourceConfig.MaxNodes++
+	before := backend.Resources()
+
+	report, err = eksClient.VerifyEksResourceStack(&inventory, resourceConfig)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	for _, expected := range []struct {
+		kind    string
+		id      string
+		status  drift.Status
+		details []string
+	}{
+		{kind: "nat-gateway", id: natGatewayId, status: drift.StatusMissing},
+		{
+			kind:    "iam-role",
+			id:      inventory.ClusterRole.RoleName,
+			status:  drift.StatusModified,
+			details: []string{"policy " + detachedPolicyArn + " not attached"},
+		},
+		{
+			kind:    "iam-role",
+			id:      inventory.WorkerRole.RoleName,
+			status:  drift.StatusUnexpected,
+			details: []string{"policy " + extraPolicyArn + " attached outside of resource stack"},
+		},
+		{
+			kind:    "eks-cluster",
+			id:      inventory.Cluster.ClusterName,
+			status:  drift.StatusModified,
+			details: []string{"Kubernetes version is " + kubernetesVersion + ", expected 1.0"},
+		},
+	} {
+		result := findResult(report, expected.kind, expected.id)
+		if result == nil {
+			t.Errorf("expected %s %s in report", expected.kind, expected.id)
+			continue
+		}
+		if result.Status != expected.status || !reflect.DeepEqual(result.Details, expected.details) {
+			t.Errorf("expected %s %s to be %s with details %v, got %s with details %v",
+				expected.kind, expected.id, expected.status, expected.details, result.Status, result.Details)
+		}
+	}
+	for _, nodeGroupName := range inventory.NodeGroupNames {
+		result := findResult(report, "eks-node-group", nodeGroupName)
+		if result == nil || result.Status != drift.StatusModified ||
+			!slices.Contains(result.Details, "Kubernetes version is "+kubernetesVersion+", expected 1.0") {
+			t.Errorf("expected node group %s to be modified, got %+v", nodeGroupName, result)
+		}
+	}
+	if count := report.Count(drift.StatusMissing); count != 1 {
+		t.Errorf("expected only the NAT gateway to be missing, got %s", report.Summary())
+	}
+	if resources := backend.Resources(); !reflect.DeepEqual(resources, before) {
+		t.Errorf("expected verifying to change nothing, found %v", resources)
+	}
+
+	// without a config only the inventory is compared
+	report, err = eksClient.VerifyEksResourceStack(&inventory, nil)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	if result := findResult(report, "eks-cluster", inventory.Cluster.ClusterName); result == nil || result.Status != drift.StatusOk {
+		t.Errorf("expected cluster not to be compared to a config, got %+v", result)
+	}
+}
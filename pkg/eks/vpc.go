@@ -18,7 +18,7 @@ func (c *EksClient) CreateVpc(
 	cidrBlock string,
 	clusterName string,
 ) (*types.Vpc, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	// because VPCs don't have unique names we have to check for an existing VPC
 	// with matching tags up front
@@ -107,7 +107,7 @@ func (c *EksClient) DeleteVpc(vpcId string) error {
 		return nil
 	}
 
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	deleteVpcInput := aws_ec2.DeleteVpcInput{VpcId: &vpcId}
 	_, err := svc.DeleteVpc(c.Context, &deleteVpcInput)
@@ -131,7 +131,7 @@ func (c *EksClient) DeleteVpc(vpcId string) error {
 
 // enableDnsResolutionOnVpc takes a VPC ID and enables DNS resolution for it.
 func (c *EksClient) enableDnsResolutionOnVpc(vpcId string) error {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	valueTrue := true
 	attributeTrue := types.AttributeBooleanValue{Value: &valueTrue}
@@ -150,7 +150,7 @@ func (c *EksClient) enableDnsResolutionOnVpc(vpcId string) error {
 // enableDnsHostnamesOnVpc takes a VPC ID and enables DNS hostnames for
 // instances launched in that VPC.
 func (c *EksClient) enableDnsHostnamesOnVpc(vpcId string) error {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	valueTrue := true
 	attributeTrue := types.AttributeBooleanValue{Value: &valueTrue}
@@ -0,0 +1,166 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+// Create creates each resource stack in the manifest in dependency order.
+// References to the outputs of other resource stacks are resolved with the
+// inventory of those resource stacks once they have been created.  Resource
+// stacks that already have inventory reuse the resources in it rather than
+// creating new ones, so an interrupted or completed create can be run again.
+// Creation stops at the first resource stack that fails.
+func Create(resourceClient *client.ResourceClient, manifest *Manifest) error {
+	order, err := manifest.CreateOrder()
+	if err != nil {
+		return err
+	}
+
+	outputs := configfile.Outputs{}
+	for _, stackManifest := range order {
+		resourceClient.SendMessage(fmt.Sprintf("creating %s resource stack %s", stackManifest.Type, stackManifest.Name))
+		inventory, err := createStack(resourceClient, stackManifest, outputs)
+		if err != nil {
+			return fmt.Errorf("failed to create resource stack %s: %w", stackManifest.Name, err)
+		}
+		outputs[stackManifest.Name] = inventory.Outputs()
+	}
+
+	return nil
+}
+
+// createStack creates a resource stack in the environment and returns its
+// inventory.
+func createStack(
+	resourceClient *client.ResourceClient,
+	stackManifest StackManifest,
+	outputs configfile.Outputs,
+) (stack.Inventory, error) {
+	resourceStack, err := stack.Get(stackManifest.Type)
+	if err != nil {
+		return nil, err
+	}
+	stackResourceClient := copyResourceClient(resourceClient)
+	backend, err := state.New(stackManifest.State, stackResourceClient.AwsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var createWait sync.WaitGroup
+	stackClient, config, closeInventory, err := stack.InitCreate(
+		resourceStack,
+		stackResourceClient,
+		stackManifest.ConfigFiles,
+		outputs,
+		backend,
+		&createWait,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// reuse resources from an earlier create
+	inventory := resourceStack.NewInventory()
+	err = inventory.LoadState(resourceClient.Context, backend)
+	if err == nil || errors.Is(err, state.ErrNotFound) {
+		err = stackClient.Create(config, inventory)
+	}
+	closeInventory()
+	createWait.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	// the inventory passed to create is not updated by every resource
+	// stack so the latest inventory is read from state
+	inventory = resourceStack.NewInventory()
+	if err := inventory.LoadState(context.WithoutCancel(resourceClient.Context), backend); err != nil {
+		return nil, err
+	}
+
+	return inventory, nil
+}
+
+// Delete deletes each resource stack in the manifest in the reverse of
+// dependency order and removes its inventory.  Resource stacks with no
+// inventory are skipped.  Deletion stops at the first resource stack that
+// fails.
+func Delete(resourceClient *client.ResourceClient, manifest *Manifest) error {
+	order, err := manifest.DeleteOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, stackManifest := range order {
+		if err := deleteStack(resourceClient, stackManifest); err != nil {
+			return fmt.Errorf("failed to delete resource stack %s: %w", stackManifest.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteStack deletes a resource stack in the environment and removes its
+// inventory.
+func deleteStack(resourceClient *client.ResourceClient, stackManifest StackManifest) error {
+	resourceStack, err := stack.Get(stackManifest.Type)
+	if err != nil {
+		return err
+	}
+	stackResourceClient := copyResourceClient(resourceClient)
+	backend, err := state.New(stackManifest.State, stackResourceClient.AwsConfig)
+	if err != nil {
+		return err
+	}
+
+	// skip resource stacks that were never created
+	if _, err := backend.Read(resourceClient.Context); errors.Is(err, state.ErrNotFound) {
+		resourceClient.SendMessage(fmt.Sprintf("no inventory for %s resource stack %s, skipping", stackManifest.Type, stackManifest.Name))
+		return nil
+	} else if err != nil {
+		return err
+	}
+	resourceClient.SendMessage(fmt.Sprintf("deleting %s resource stack %s", stackManifest.Type, stackManifest.Name))
+
+	var deleteWait sync.WaitGroup
+	stackClient, inventory, closeInventory, err := stack.InitDelete(
+		resourceStack,
+		stackResourceClient,
+		backend,
+		&deleteWait,
+	)
+	if err != nil {
+		return err
+	}
+	err = stackClient.Delete(inventory)
+	closeInventory()
+	deleteWait.Wait()
+	if err != nil {
+		return err
+	}
+
+	// remove inventory from state
+	if err := backend.Delete(context.WithoutCancel(resourceClient.Context)); err != nil {
+		return fmt.Errorf("failed to remove inventory: %w", err)
+	}
+
+	return nil
+}
+
+// copyResourceClient returns a copy of the resource client with its own AWS
+// config so the region set by one resource stack is not used by the next.
+func copyResourceClient(resourceClient *client.ResourceClient) *client.ResourceClient {
+	stackResourceClient := *resourceClient
+	awsConfig := resourceClient.AwsConfig.Copy()
+	stackResourceClient.AwsConfig = &awsConfig
+
+	return &stackResourceClient
+}
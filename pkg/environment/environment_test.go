@@ -0,0 +1,65 @@
+package environment
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+	"github.com/nukleros/aws-builder/pkg/s3"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+func TestCreateResolvesOutputReferences(t *testing.T) {
+	dir := t.TempDir()
+	sampleConfig, err := filepath.Abs("../../sample/s3-config.yaml")
+	if err != nil {
+		t.Fatalf("failed to get sample config path: %v", err)
+	}
+	manifest := Manifest{Stacks: []StackManifest{
+		{
+			Name:        "logs",
+			Type:        "s3",
+			ConfigFiles: []string{sampleConfig, writeFile(t, dir, "logs.yaml", "name: logs\nregion: ${data.region}\n")},
+			State:       filepath.Join(dir, "logs-inventory.json"),
+		},
+		{
+			Name:        "data",
+			Type:        "s3",
+			ConfigFiles: []string{sampleConfig, writeFile(t, dir, "data.yaml", "name: data\nregion: us-west-2\n")},
+			State:       filepath.Join(dir, "data-inventory.json"),
+		},
+	}}
+	backend := fake.NewBackend("")
+
+	if err := Create(backend.ResourceClient(), &manifest); err != nil {
+		t.Fatalf("failed to create environment: %v", err)
+	}
+	for _, stackManifest := range manifest.Stacks {
+		var inventory s3.S3Inventory
+		if err := inventory.LoadState(context.Background(), &state.LocalBackend{Path: stackManifest.State}); err != nil {
+			t.Fatalf("failed to load %s inventory: %v", stackManifest.Name, err)
+		}
+		if inventory.Region != "us-west-2" {
+			t.Errorf("expected %s resources in region from data outputs, got %q", stackManifest.Name, inventory.Region)
+		}
+	}
+	if buckets := backend.Resources()["s3-bucket"]; len(buckets) != 2 {
+		t.Errorf("expected 2 buckets, found %v", buckets)
+	}
+
+	// creating again reuses the resources in inventory
+	if err := Create(backend.ResourceClient(), &manifest); err != nil {
+		t.Fatalf("failed to create environment again: %v", err)
+	}
+	if buckets := backend.Resources()["s3-bucket"]; len(buckets) != 2 {
+		t.Errorf("expected buckets to be reused, found %v", buckets)
+	}
+
+	if err := Delete(backend.ResourceClient(), &manifest); err != nil {
+		t.Fatalf("failed to delete environment: %v", err)
+	}
+	if resources := backend.Resources(); len(resources) != 0 {
+		t.Errorf("expected all resources to be deleted, found %v", resources)
+	}
+}
@@ -0,0 +1,300 @@
+// Package environment manages several resource stacks together.  An
+// environment manifest lists the resource stacks and their config files.
+// Config values can reference the outputs of other resource stacks in the
+// environment, e.g. ${eks.vpcId}, so resource stacks are created in
+// dependency order and deleted in the reverse order.
+package environment
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+// stackNamePattern matches the names of resource stacks in a manifest.  Names
+// can't contain dots since they are used in ${stack.output} references.
+var stackNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Manifest describes the resource stacks in an environment.
+type Manifest struct {
+	Stacks []StackManifest `yaml:"stacks"`
+}
+
+// StackManifest describes a resource stack in an environment.
+type StackManifest struct {
+	// The name of the resource stack in the environment, used in references
+	// to its outputs.
+	Name string `yaml:"name"`
+
+	// The type of resource stack, e.g. eks.  Defaults to the name.
+	Type string `yaml:"type"`
+
+	// The config files for the resource stack.  Values in later files
+	// override values in earlier files.
+	ConfigFiles []string `yaml:"configFiles"`
+
+	// The location inventory is stored in, e.g. s3://bucket/key or a file
+	// path.  Defaults to <name>-inventory.json.
+	State string `yaml:"state"`
+
+	// Other resource stacks in the environment that must be created first.
+	// Resource stacks whose outputs are referenced in config files are
+	// added automatically.
+	DependsOn []string `yaml:"dependsOn"`
+}
+
+// LoadManifest loads an environment manifest from a YAML or JSON file.
+// Relative config file and state paths are relative to the directory the
+// manifest is in.  If the manifest has problems, such as unknown fields,
+// unrecognized resource stack types or references to resource stacks not in
+// the environment, a validation.Errors containing every problem is returned.
+func LoadManifest(manifestFile string) (*Manifest, error) {
+	manifestBytes, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environment manifest: %w", err)
+	}
+
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(manifestBytes))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse environment manifest %s: %w", manifestFile, err)
+	}
+
+	// set defaults and resolve paths relative to the manifest
+	dir := filepath.Dir(manifestFile)
+	for i := range manifest.Stacks {
+		stackManifest := &manifest.Stacks[i]
+		if stackManifest.Type == "" {
+			stackManifest.Type = stackManifest.Name
+		}
+		if stackManifest.State == "" {
+			stackManifest.State = fmt.Sprintf("%s-inventory.json", stackManifest.Name)
+		}
+		if !strings.HasPrefix(stackManifest.State, state.S3Prefix) {
+			stackManifest.State = relativeTo(dir, stackManifest.State)
+		}
+		for j, configFile := range stackManifest.ConfigFiles {
+			stackManifest.ConfigFiles[j] = relativeTo(dir, configFile)
+		}
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// relativeTo returns path relative to dir unless it is absolute.
+func relativeTo(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// Validate checks the manifest for problems and returns a validation.Errors
+// containing every problem, or nil if there are none.  Config files are read
+// to find references to the outputs of other resource stacks.
+func (m *Manifest) Validate() error {
+	var errs validation.Errors
+
+	if len(m.Stacks) == 0 {
+		errs.Add("stacks", "at least one resource stack is required")
+	}
+
+	names := map[string]bool{}
+	states := map[string]bool{}
+	for i, stackManifest := range m.Stacks {
+		field := validation.Index("stacks", i)
+		switch {
+		case stackManifest.Name == "":
+			errs.Add(field+".name", "is required")
+		case !stackNamePattern.MatchString(stackManifest.Name):
+			errs.Add(field+".name", "must begin with a letter and contain only letters, digits, underscores and hyphens")
+		case names[stackManifest.Name]:
+			errs.Add(field+".name", "%q is used by more than one resource stack", stackManifest.Name)
+		}
+		names[stackManifest.Name] = true
+
+		if _, err := stack.Get(stackManifest.Type); err != nil {
+			errs.Add(field+".type", "%s", err)
+		}
+		if len(stackManifest.ConfigFiles) == 0 {
+			errs.Add(field+".configFiles", "at least one config file is required")
+		}
+		if states[stackManifest.State] {
+			errs.Add(field+".state", "%q is used by more than one resource stack", stackManifest.State)
+		}
+		states[stackManifest.State] = true
+	}
+
+	for i, stackManifest := range m.Stacks {
+		field := validation.Index("stacks", i)
+		for j, dependency := range stackManifest.DependsOn {
+			if !names[dependency] {
+				errs.Add(validation.Index(field+".dependsOn", j), "%q is not a resource stack in the environment", dependency)
+			}
+		}
+		references, err := configfile.OutputReferences(stackManifest.ConfigFiles...)
+		if err != nil {
+			errs.Add(field+".configFiles", "%s", err)
+			continue
+		}
+		for _, reference := range references {
+			if !names[reference] {
+				errs.Add(field+".configFiles", "outputs of %q are referenced but it is not a resource stack in the environment", reference)
+			}
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	// only check for cycles once every dependency is known to exist
+	if _, err := m.CreateOrder(); err != nil {
+		errs.Add("stacks", "%s", err)
+	}
+
+	return errs.Err()
+}
+
+// Dependencies returns the names of the resource stacks that a resource stack
+// depends on, either explicitly or by referencing their outputs in its config
+// files, in sorted order.
+func (m *Manifest) Dependencies(stackManifest StackManifest) ([]string, error) {
+	dependencies, err := configfile.OutputReferences(stackManifest.ConfigFiles...)
+	if err != nil {
+		return nil, err
+	}
+	for _, dependency := range stackManifest.DependsOn {
+		if !slices.Contains(dependencies, dependency) {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	slices.Sort(dependencies)
+
+	return dependencies, nil
+}
+
+// CreateOrder returns the resource stacks in the order they must be created
+// so each is created after the resource stacks it depends on.  Resource
+// stacks that don't depend on each other are kept in manifest order.  An
+// error is returned if resource stack names are used more than once, a
+// resource stack depends on one not in the manifest or resource stacks depend
+// on each other in a cycle.
+func (m *Manifest) CreateOrder() ([]StackManifest, error) {
+	dependencies := map[string][]string{}
+	for _, stackManifest := range m.Stacks {
+		if _, exists := dependencies[stackManifest.Name]; exists {
+			return nil, fmt.Errorf("%q is used by more than one resource stack", stackManifest.Name)
+		}
+		stackDependencies, err := m.Dependencies(stackManifest)
+		if err != nil {
+			return nil, err
+		}
+		dependencies[stackManifest.Name] = stackDependencies
+	}
+	for _, stackManifest := range m.Stacks {
+		for _, dependency := range dependencies[stackManifest.Name] {
+			if _, exists := dependencies[dependency]; !exists {
+				return nil, fmt.Errorf(
+					"resource stack %s depends on %q which is not a resource stack in the environment",
+					stackManifest.Name,
+					dependency,
+				)
+			}
+		}
+	}
+
+	var order []StackManifest
+	created := map[string]bool{}
+	for len(order) < len(m.Stacks) {
+		progressed := false
+		for _, stackManifest := range m.Stacks {
+			if created[stackManifest.Name] {
+				continue
+			}
+			ready := true
+			for _, dependency := range dependencies[stackManifest.Name] {
+				if !created[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, stackManifest)
+				created[stackManifest.Name] = true
+				progressed = true
+				break
+			}
+		}
+		if !progressed {
+			return nil, fmt.Errorf("resource stacks depend on each other in a cycle: %s", findCycle(dependencies, created))
+		}
+	}
+
+	return order, nil
+}
+
+// findCycle returns a description of a dependency cycle between resource
+// stacks that have not been created, e.g. "eks -> rds -> eks".  If no cycle is
+// found the dependencies that were followed are returned.
+func findCycle(dependencies map[string][]string, created map[string]bool) string {
+	var path []string
+	for _, name := range slices.Sorted(maps.Keys(dependencies)) {
+		if !created[name] {
+			path = append(path, name)
+			break
+		}
+	}
+
+	// follow dependencies that have not been created until one repeats,
+	// adding a resource stack to the path at each step so no more steps are
+	// taken than there are resource stacks
+	for len(path) > 0 && len(path) <= len(dependencies) {
+		next := ""
+		for _, dependency := range dependencies[path[len(path)-1]] {
+			if !created[dependency] {
+				next = dependency
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		if start := slices.Index(path, next); start >= 0 {
+			return strings.Join(append(path[start:], next), " -> ")
+		}
+		path = append(path, next)
+	}
+
+	return strings.Join(path, " -> ")
+}
+
+// DeleteOrder returns the resource stacks in the order they must be deleted,
+// which is the reverse of the create order, so each is deleted before the
+// resource stacks it depends on.
+func (m *Manifest) DeleteOrder() ([]StackManifest, error) {
+	order, err := m.CreateOrder()
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(order)
+
+	return order, nil
+}
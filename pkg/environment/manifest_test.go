@@ -0,0 +1,129 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFile writes a file to a directory and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+
+	return path
+}
+
+// stackNames returns the names of the resource stacks in order.
+func stackNames(stackManifests []StackManifest) []string {
+	var names []string
+	for _, stackManifest := range stackManifests {
+		names = append(names, stackManifest.Name)
+	}
+
+	return names
+}
+
+func TestCreateOrder(t *testing.T) {
+	dir := t.TempDir()
+	network := writeFile(t, dir, "network.yaml", "name: network\n")
+	database := writeFile(t, dir, "database.yaml", "vpcId: ${network.vpcId}\n")
+	bucket := writeFile(t, dir, "bucket.yaml", "name: bucket\n")
+	manifest := Manifest{Stacks: []StackManifest{
+		{Name: "app", ConfigFiles: []string{bucket}, DependsOn: []string{"database"}},
+		{Name: "database", ConfigFiles: []string{database}},
+		{Name: "bucket", ConfigFiles: []string{bucket}},
+		{Name: "network", ConfigFiles: []string{network}},
+	}}
+
+	order, err := manifest.CreateOrder()
+	if err != nil {
+		t.Fatalf("failed to get create order: %v", err)
+	}
+	expected := []string{"bucket", "network", "database", "app"}
+	if names := stackNames(order); !slices.Equal(names, expected) {
+		t.Errorf("expected create order %v, got %v", expected, names)
+	}
+
+	order, err = manifest.DeleteOrder()
+	if err != nil {
+		t.Fatalf("failed to get delete order: %v", err)
+	}
+	slices.Reverse(expected)
+	if names := stackNames(order); !slices.Equal(names, expected) {
+		t.Errorf("expected delete order %v, got %v", expected, names)
+	}
+}
+
+func TestCreateOrderErrors(t *testing.T) {
+	dir := t.TempDir()
+	first := writeFile(t, dir, "first.yaml", "vpcId: ${second.vpcId}\n")
+	second := writeFile(t, dir, "second.yaml", "name: second\n")
+	independent := writeFile(t, dir, "independent.yaml", "name: independent\n")
+
+	cases := map[string]struct {
+		stacks []StackManifest
+		err    string
+	}{
+		"cycle": {
+			stacks: []StackManifest{
+				{Name: "independent", ConfigFiles: []string{independent}},
+				{Name: "first", ConfigFiles: []string{first}},
+				{Name: "second", ConfigFiles: []string{second}, DependsOn: []string{"first"}},
+			},
+			err: "cycle: first -> second -> first",
+		},
+		"self dependency": {
+			stacks: []StackManifest{
+				{Name: "independent", ConfigFiles: []string{independent}, DependsOn: []string{"independent"}},
+			},
+			err: "cycle: independent -> independent",
+		},
+		"unknown dependency": {
+			stacks: []StackManifest{
+				{Name: "first", ConfigFiles: []string{first}},
+			},
+			err: `first depends on "second" which is not a resource stack`,
+		},
+		"unknown explicit dependency": {
+			stacks: []StackManifest{
+				{Name: "independent", ConfigFiles: []string{independent}, DependsOn: []string{"missing"}},
+			},
+			err: `independent depends on "missing"`,
+		},
+		"duplicate names": {
+			stacks: []StackManifest{
+				{Name: "second", ConfigFiles: []string{second}},
+				{Name: "second", ConfigFiles: []string{independent}},
+			},
+			err: `"second" is used by more than one resource stack`,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			manifest := Manifest{Stacks: c.stacks}
+			_, err := manifest.CreateOrder()
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("expected error containing %q, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestFindCycleWithoutCycle(t *testing.T) {
+	// a dependency with no dependencies of its own ends the search rather
+	// than being followed forever
+	dependencies := map[string][]string{"first": {"missing"}}
+	if cycle := findCycle(dependencies, map[string]bool{}); cycle != "first -> missing" {
+		t.Errorf("expected followed dependencies, got %q", cycle)
+	}
+	if cycle := findCycle(dependencies, map[string]bool{"first": true}); cycle != "" {
+		t.Errorf("expected no cycle when every resource stack is created, got %q", cycle)
+	}
+}
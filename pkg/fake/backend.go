@@ -0,0 +1,243 @@
+// Package fake provides a stateful, in-memory implementation of the AWS
+// service APIs used by aws-builder so that resource stacks can be created and
+// deleted without an AWS account.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+)
+
+const (
+	DefaultRegion    = "us-east-1"
+	DefaultAccountId = "123456789012"
+)
+
+// Backend holds the state of all fake AWS services.  Resources that AWS
+// provisions asynchronously (NAT gateways, clusters, node groups, DB
+// instances) are reported in a transitional state by create and delete calls
+// and have reached their final state by the time they are next described.
+type Backend struct {
+	Region    string
+	AccountId string
+
+	mu       sync.Mutex
+	counter  int
+	failures map[string][]error
+	calls    map[string]int
+
+	ec2State
+	eksState
+	iamState
+	rdsState
+	s3State
+}
+
+// NewBackend returns an empty fake backend for the given region.  If region is
+// empty, DefaultRegion is used.
+func NewBackend(region string) *Backend {
+	if region == "" {
+		region = DefaultRegion
+	}
+	b := Backend{
+		Region:    region,
+		AccountId: DefaultAccountId,
+		failures:  make(map[string][]error),
+		calls:     make(map[string]int),
+	}
+	b.ec2State.init()
+	b.eksState.init()
+	b.iamState.init()
+	b.rdsState.init()
+	b.s3State.init()
+
+	return &b
+}
+
+// Apis returns the fake service APIs for use in a resource client.
+func (b *Backend) Apis() client.ServiceApis {
+	return client.ServiceApis{
+		Ec2:       &Ec2{b},
+		Eks:       &Eks{b},
+		Iam:       &Iam{b},
+		Rds:       &Rds{b},
+		S3:        &S3{b},
+		S3Control: &S3Control{b},
+	}
+}
+
+// ResourceClient returns a resource client that calls the fake backend.  It
+// has no message channel so messages are discarded.
+func (b *Backend) ResourceClient() *client.ResourceClient {
+	return &client.ResourceClient{
+		Context:   context.Background(),
+		AwsConfig: &aws.Config{Region: b.Region},
+		Apis:      b.Apis(),
+	}
+}
+
+// FailNext causes the next call to the named operation, e.g.
+// "CreateNatGateway", to return err instead of being applied.  Multiple calls
+// queue errors for successive calls.
+func (b *Backend) FailNext(operation string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures[operation] = append(b.failures[operation], err)
+}
+
+// CallCount returns the number of times the named operation has been called.
+func (b *Backend) CallCount(operation string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[operation]
+}
+
+// Resources returns the IDs of all existing resources keyed by resource kind.
+// Kinds with no resources are omitted.
+func (b *Backend) Resources() map[string][]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	resources := make(map[string][]string)
+	add := func(kind string, id string) {
+		resources[kind] = append(resources[kind], id)
+	}
+	for id := range b.vpcs {
+		add("vpc", id)
+	}
+	for id := range b.subnets {
+		add("subnet", id)
+	}
+	for id := range b.internetGateways {
+		add("internet-gateway", id)
+	}
+	for id := range b.addresses {
+		add("elastic-ip", id)
+	}
+	for id, ngw := range b.natGateways {
+		if ngw.State != "deleted" {
+			add("nat-gateway", id)
+		}
+	}
+	for id := range b.routeTables {
+		add("route-table", id)
+	}
+	for id := range b.securityGroups {
+		add("security-group", id)
+	}
+	for name := range b.clusters {
+		add("eks-cluster", name)
+	}
+	for key := range b.nodegroups {
+		add("eks-node-group", key)
+	}
+	for key := range b.addons {
+		add("eks-addon", key)
+	}
+	for name := range b.roles {
+		add("iam-role", name)
+	}
+	for arn := range b.policies {
+		add("iam-policy", arn)
+	}
+	for arn := range b.oidcProviders {
+		add("iam-oidc-provider", arn)
+	}
+	for id := range b.dbInstances {
+		add("rds-instance", id)
+	}
+	for name := range b.dbSubnetGroups {
+		add("rds-subnet-group", name)
+	}
+	for name := range b.buckets {
+		add("s3-bucket", name)
+	}
+	for name := range b.accessPoints {
+		add("s3-access-point", name)
+	}
+	for kind := range resources {
+		sort.Strings(resources[kind])
+	}
+
+	return resources
+}
+
+// OidcThumbprint is a stand-in for eks.GetOidcThumbprint that returns a fixed
+// thumbprint without connecting to the provider.
+func OidcThumbprint(providerUrl string) (string, error) {
+	return "9e99a48a9960b14926bb7f3b02e22da2b0ab7280", nil
+}
+
+// begin locks the backend for an operation and returns any error queued for
+// it with FailNext.  The caller must call b.mu.Unlock when done.
+func (b *Backend) begin(operation string) error {
+	b.mu.Lock()
+	b.calls[operation]++
+	if queued := b.failures[operation]; len(queued) > 0 {
+		b.failures[operation] = queued[1:]
+		return queued[0]
+	}
+
+	return nil
+}
+
+// nextId returns a new unique resource ID with the given prefix.
+func (b *Backend) nextId(prefix string) string {
+	b.counter++
+	return fmt.Sprintf("%s-%017x", prefix, b.counter)
+}
+
+// arn returns an ARN in the backend's region and account.
+func (b *Backend) arn(service, resource string) string {
+	return fmt.Sprintf("arn:aws:%s:%s:%s:%s", service, b.Region, b.AccountId, resource)
+}
+
+// apiError returns a generic API error as returned by AWS services that do not
+// model typed errors, such as EC2.
+func apiError(code, format string, args ...any) error {
+	return &smithy.GenericAPIError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Fault:   smithy.FaultClient,
+	}
+}
+
+// hasValue returns true if value is in values.
+func hasValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedKeys returns the keys of a map in sorted order so that describe
+// calls return resources in a stable order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ensure the fakes implement the service APIs
+var (
+	_ client.Ec2Api       = (*Ec2)(nil)
+	_ client.EksApi       = (*Eks)(nil)
+	_ client.IamApi       = (*Iam)(nil)
+	_ client.RdsApi       = (*Rds)(nil)
+	_ client.S3Api        = (*S3)(nil)
+	_ client.S3ControlApi = (*S3Control)(nil)
+)
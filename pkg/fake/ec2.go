@@ -0,0 +1,1025 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// ec2State contains the fake EC2 resources.
+type ec2State struct {
+	vpcs             map[string]*types.Vpc
+	vpcDnsSupport    map[string]bool
+	vpcDnsHostnames  map[string]bool
+	subnets          map[string]*types.Subnet
+	internetGateways map[string]*types.InternetGateway
+	addresses        map[string]*types.Address
+	natGateways      map[string]*types.NatGateway
+	routeTables      map[string]*types.RouteTable
+	securityGroups   map[string]*types.SecurityGroup
+}
+
+func (s *ec2State) init() {
+	s.vpcs = make(map[string]*types.Vpc)
+	s.vpcDnsSupport = make(map[string]bool)
+	s.vpcDnsHostnames = make(map[string]bool)
+	s.subnets = make(map[string]*types.Subnet)
+	s.internetGateways = make(map[string]*types.InternetGateway)
+	s.addresses = make(map[string]*types.Address)
+	s.natGateways = make(map[string]*types.NatGateway)
+	s.routeTables = make(map[string]*types.RouteTable)
+	s.securityGroups = make(map[string]*types.SecurityGroup)
+}
+
+// Ec2 is a fake implementation of client.Ec2Api.
+type Ec2 struct {
+	b *Backend
+}
+
+// matchEc2Filters returns true if a resource with the given tags and
+// attributes matches all filters.  Filters on attributes that are not provided
+// never match.
+func matchEc2Filters(filters []types.Filter, tags []types.Tag, attributes map[string]string) bool {
+	for _, filter := range filters {
+		name := aws.ToString(filter.Name)
+		matched := false
+		switch {
+		case strings.HasPrefix(name, "tag:"):
+			key := strings.TrimPrefix(name, "tag:")
+			for _, tag := range tags {
+				if aws.ToString(tag.Key) == key && hasValue(filter.Values, aws.ToString(tag.Value)) {
+					matched = true
+					break
+				}
+			}
+		case name == "tag-key":
+			for _, tag := range tags {
+				if hasValue(filter.Values, aws.ToString(tag.Key)) {
+					matched = true
+					break
+				}
+			}
+		default:
+			value, ok := attributes[name]
+			matched = ok && hasValue(filter.Values, value)
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// tagsFor returns the tags from the tag specification for a resource type.
+func tagsFor(specs []types.TagSpecification, resourceType types.ResourceType) []types.Tag {
+	var tags []types.Tag
+	for _, spec := range specs {
+		if spec.ResourceType == resourceType {
+			tags = append(tags, spec.Tags...)
+		}
+	}
+
+	return tags
+}
+
+// mergeTags returns the tags with the new tags added, replacing the values of
+// tags with the same key.
+func mergeTags(tags []types.Tag, newTags []types.Tag) []types.Tag {
+	merged := append([]types.Tag{}, tags...)
+	for _, newTag := range newTags {
+		replaced := false
+		for i, tag := range merged {
+			if aws.ToString(tag.Key) == aws.ToString(newTag.Key) {
+				merged[i] = newTag
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, newTag)
+		}
+	}
+
+	return merged
+}
+
+func (f *Ec2) CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	b := f.b
+	err := b.begin("CreateTags")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// find every resource before changing any so a missing resource leaves
+	// all tags unchanged
+	var resourceTags []*[]types.Tag
+	for _, id := range params.Resources {
+		var tags *[]types.Tag
+		switch {
+		case b.vpcs[id] != nil:
+			tags = &b.vpcs[id].Tags
+		case b.subnets[id] != nil:
+			tags = &b.subnets[id].Tags
+		case b.internetGateways[id] != nil:
+			tags = &b.internetGateways[id].Tags
+		case b.addresses[id] != nil:
+			tags = &b.addresses[id].Tags
+		case b.natGateways[id] != nil:
+			tags = &b.natGateways[id].Tags
+		case b.routeTables[id] != nil:
+			tags = &b.routeTables[id].Tags
+		case b.securityGroups[id] != nil:
+			tags = &b.securityGroups[id].Tags
+		default:
+			return nil, apiError("InvalidID", "The ID '%s' is not valid", id)
+		}
+		resourceTags = append(resourceTags, tags)
+	}
+	for _, tags := range resourceTags {
+		*tags = mergeTags(*tags, params.Tags)
+	}
+
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (f *Ec2) CreateVpc(ctx context.Context, params *ec2.CreateVpcInput, optFns ...func(*ec2.Options)) (*ec2.CreateVpcOutput, error) {
+	b := f.b
+	err := b.begin("CreateVpc")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	vpc := types.Vpc{
+		VpcId:     aws.String(b.nextId("vpc")),
+		CidrBlock: params.CidrBlock,
+		State:     types.VpcStateAvailable,
+		OwnerId:   aws.String(b.AccountId),
+		Tags:      tagsFor(params.TagSpecifications, types.ResourceTypeVpc),
+	}
+	b.vpcs[*vpc.VpcId] = &vpc
+	out := vpc
+
+	return &ec2.CreateVpcOutput{Vpc: &out}, nil
+}
+
+func (f *Ec2) DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	b := f.b
+	err := b.begin("DescribeVpcs")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var vpcs []types.Vpc
+	for _, id := range sortedKeys(b.vpcs) {
+		vpc := b.vpcs[id]
+		if len(params.VpcIds) > 0 && !hasValue(params.VpcIds, id) {
+			continue
+		}
+		attributes := map[string]string{
+			"vpc-id":     id,
+			"cidr-block": aws.ToString(vpc.CidrBlock),
+			"state":      string(vpc.State),
+		}
+		if matchEc2Filters(params.Filters, vpc.Tags, attributes) {
+			vpcs = append(vpcs, *vpc)
+		}
+	}
+	for _, id := range params.VpcIds {
+		if _, ok := b.vpcs[id]; !ok {
+			return nil, apiError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", id)
+		}
+	}
+
+	return &ec2.DescribeVpcsOutput{Vpcs: vpcs}, nil
+}
+
+func (f *Ec2) DescribeVpcAttribute(ctx context.Context, params *ec2.DescribeVpcAttributeInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcAttributeOutput, error) {
+	b := f.b
+	err := b.begin("DescribeVpcAttribute")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	vpcId := aws.ToString(params.VpcId)
+	if _, ok := b.vpcs[vpcId]; !ok {
+		return nil, apiError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", vpcId)
+	}
+	out := ec2.DescribeVpcAttributeOutput{VpcId: params.VpcId}
+	switch params.Attribute {
+	case types.VpcAttributeNameEnableDnsSupport:
+		out.EnableDnsSupport = &types.AttributeBooleanValue{Value: aws.Bool(b.vpcDnsSupport[vpcId])}
+	case types.VpcAttributeNameEnableDnsHostnames:
+		out.EnableDnsHostnames = &types.AttributeBooleanValue{Value: aws.Bool(b.vpcDnsHostnames[vpcId])}
+	}
+
+	return &out, nil
+}
+
+func (f *Ec2) ModifyVpcAttribute(ctx context.Context, params *ec2.ModifyVpcAttributeInput, optFns ...func(*ec2.Options)) (*ec2.ModifyVpcAttributeOutput, error) {
+	b := f.b
+	err := b.begin("ModifyVpcAttribute")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	vpcId := aws.ToString(params.VpcId)
+	if _, ok := b.vpcs[vpcId]; !ok {
+		return nil, apiError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", vpcId)
+	}
+	if params.EnableDnsSupport != nil {
+		b.vpcDnsSupport[vpcId] = aws.ToBool(params.EnableDnsSupport.Value)
+	}
+	if params.EnableDnsHostnames != nil {
+		b.vpcDnsHostnames[vpcId] = aws.ToBool(params.EnableDnsHostnames.Value)
+	}
+
+	return &ec2.ModifyVpcAttributeOutput{}, nil
+}
+
+func (f *Ec2) DeleteVpc(ctx context.Context, params *ec2.DeleteVpcInput, optFns ...func(*ec2.Options)) (*ec2.DeleteVpcOutput, error) {
+	b := f.b
+	err := b.begin("DeleteVpc")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	vpcId := aws.ToString(params.VpcId)
+	if _, ok := b.vpcs[vpcId]; !ok {
+		return nil, apiError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", vpcId)
+	}
+	for _, subnet := range b.subnets {
+		if aws.ToString(subnet.VpcId) == vpcId {
+			return nil, apiError("DependencyViolation", "The vpc '%s' has dependencies and cannot be deleted.", vpcId)
+		}
+	}
+	for _, igw := range b.internetGateways {
+		for _, attachment := range igw.Attachments {
+			if aws.ToString(attachment.VpcId) == vpcId {
+				return nil, apiError("DependencyViolation", "The vpc '%s' has dependencies and cannot be deleted.", vpcId)
+			}
+		}
+	}
+	for _, rt := range b.routeTables {
+		if aws.ToString(rt.VpcId) == vpcId {
+			return nil, apiError("DependencyViolation", "The vpc '%s' has dependencies and cannot be deleted.", vpcId)
+		}
+	}
+	for _, sg := range b.securityGroups {
+		if aws.ToString(sg.VpcId) == vpcId {
+			return nil, apiError("DependencyViolation", "The vpc '%s' has dependencies and cannot be deleted.", vpcId)
+		}
+	}
+	delete(b.vpcs, vpcId)
+	delete(b.vpcDnsSupport, vpcId)
+	delete(b.vpcDnsHostnames, vpcId)
+
+	return &ec2.DeleteVpcOutput{}, nil
+}
+
+func (f *Ec2) DescribeAvailabilityZones(ctx context.Context, params *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	b := f.b
+	err := b.begin("DescribeAvailabilityZones")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []types.AvailabilityZone
+	for _, suffix := range []string{"a", "b", "c", "d"} {
+		zone := types.AvailabilityZone{
+			ZoneName:   aws.String(b.Region + suffix),
+			RegionName: aws.String(b.Region),
+			State:      types.AvailabilityZoneStateAvailable,
+		}
+		attributes := map[string]string{
+			"region-name": b.Region,
+			"zone-name":   *zone.ZoneName,
+		}
+		if matchEc2Filters(params.Filters, nil, attributes) {
+			zones = append(zones, zone)
+		}
+	}
+
+	return &ec2.DescribeAvailabilityZonesOutput{AvailabilityZones: zones}, nil
+}
+
+func (f *Ec2) CreateSubnet(ctx context.Context, params *ec2.CreateSubnetInput, optFns ...func(*ec2.Options)) (*ec2.CreateSubnetOutput, error) {
+	b := f.b
+	err := b.begin("CreateSubnet")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	vpcId := aws.ToString(params.VpcId)
+	if _, ok := b.vpcs[vpcId]; !ok {
+		return nil, apiError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", vpcId)
+	}
+	for _, subnet := range b.subnets {
+		if aws.ToString(subnet.VpcId) == vpcId && aws.ToString(subnet.CidrBlock) == aws.ToString(params.CidrBlock) {
+			return nil, apiError("InvalidSubnet.Conflict", "The CIDR '%s' conflicts with another subnet", aws.ToString(params.CidrBlock))
+		}
+	}
+	subnet := types.Subnet{
+		SubnetId:            aws.String(b.nextId("subnet")),
+		VpcId:               params.VpcId,
+		CidrBlock:           params.CidrBlock,
+		AvailabilityZone:    params.AvailabilityZone,
+		MapPublicIpOnLaunch: aws.Bool(false),
+		State:               types.SubnetStateAvailable,
+		OwnerId:             aws.String(b.AccountId),
+		Tags:                tagsFor(params.TagSpecifications, types.ResourceTypeSubnet),
+	}
+	b.subnets[*subnet.SubnetId] = &subnet
+	out := subnet
+
+	return &ec2.CreateSubnetOutput{Subnet: &out}, nil
+}
+
+func (f *Ec2) DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	b := f.b
+	err := b.begin("DescribeSubnets")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range params.SubnetIds {
+		if _, ok := b.subnets[id]; !ok {
+			return nil, apiError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", id)
+		}
+	}
+	var subnets []types.Subnet
+	for _, id := range sortedKeys(b.subnets) {
+		subnet := b.subnets[id]
+		if len(params.SubnetIds) > 0 && !hasValue(params.SubnetIds, id) {
+			continue
+		}
+		attributes := map[string]string{
+			"subnet-id":         id,
+			"vpc-id":            aws.ToString(subnet.VpcId),
+			"cidr-block":        aws.ToString(subnet.CidrBlock),
+			"availability-zone": aws.ToString(subnet.AvailabilityZone),
+		}
+		if matchEc2Filters(params.Filters, subnet.Tags, attributes) {
+			subnets = append(subnets, *subnet)
+		}
+	}
+
+	return &ec2.DescribeSubnetsOutput{Subnets: subnets}, nil
+}
+
+func (f *Ec2) ModifySubnetAttribute(ctx context.Context, params *ec2.ModifySubnetAttributeInput, optFns ...func(*ec2.Options)) (*ec2.ModifySubnetAttributeOutput, error) {
+	b := f.b
+	err := b.begin("ModifySubnetAttribute")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	subnetId := aws.ToString(params.SubnetId)
+	subnet, ok := b.subnets[subnetId]
+	if !ok {
+		return nil, apiError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetId)
+	}
+	if params.MapPublicIpOnLaunch != nil {
+		subnet.MapPublicIpOnLaunch = aws.Bool(aws.ToBool(params.MapPublicIpOnLaunch.Value))
+	}
+
+	return &ec2.ModifySubnetAttributeOutput{}, nil
+}
+
+func (f *Ec2) DeleteSubnet(ctx context.Context, params *ec2.DeleteSubnetInput, optFns ...func(*ec2.Options)) (*ec2.DeleteSubnetOutput, error) {
+	b := f.b
+	err := b.begin("DeleteSubnet")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	subnetId := aws.ToString(params.SubnetId)
+	if _, ok := b.subnets[subnetId]; !ok {
+		return nil, apiError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetId)
+	}
+	for _, ngw := range b.natGateways {
+		if aws.ToString(ngw.SubnetId) == subnetId && ngw.State != types.NatGatewayStateDeleted {
+			return nil, apiError("DependencyViolation", "The subnet '%s' has dependencies and cannot be deleted.", subnetId)
+		}
+	}
+	for _, ng := range b.nodegroups {
+		if hasValue(ng.Subnets, subnetId) {
+			return nil, apiError("DependencyViolation", "The subnet '%s' has dependencies and cannot be deleted.", subnetId)
+		}
+	}
+	delete(b.subnets, subnetId)
+
+	// removing a subnet removes its route table associations
+	for _, rt := range b.routeTables {
+		var associations []types.RouteTableAssociation
+		for _, assoc := range rt.Associations {
+			if aws.ToString(assoc.SubnetId) != subnetId {
+				associations = append(associations, assoc)
+			}
+		}
+		rt.Associations = associations
+	}
+
+	return &ec2.DeleteSubnetOutput{}, nil
+}
+
+func (f *Ec2) CreateInternetGateway(ctx context.Context, params *ec2.CreateInternetGatewayInput, optFns ...func(*ec2.Options)) (*ec2.CreateInternetGatewayOutput, error) {
+	b := f.b
+	err := b.begin("CreateInternetGateway")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	igw := types.InternetGateway{
+		InternetGatewayId: aws.String(b.nextId("igw")),
+		OwnerId:           aws.String(b.AccountId),
+		Tags:              tagsFor(params.TagSpecifications, types.ResourceTypeInternetGateway),
+	}
+	b.internetGateways[*igw.InternetGatewayId] = &igw
+	out := igw
+
+	return &ec2.CreateInternetGatewayOutput{InternetGateway: &out}, nil
+}
+
+func (f *Ec2) DescribeInternetGateways(ctx context.Context, params *ec2.DescribeInternetGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInternetGatewaysOutput, error) {
+	b := f.b
+	err := b.begin("DescribeInternetGateways")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var igws []types.InternetGateway
+	for _, id := range sortedKeys(b.internetGateways) {
+		igw := b.internetGateways[id]
+		if len(params.InternetGatewayIds) > 0 && !hasValue(params.InternetGatewayIds, id) {
+			continue
+		}
+		attributes := map[string]string{"internet-gateway-id": id}
+		if len(igw.Attachments) > 0 {
+			attributes["attachment.vpc-id"] = aws.ToString(igw.Attachments[0].VpcId)
+		}
+		if matchEc2Filters(params.Filters, igw.Tags, attributes) {
+			igws = append(igws, *igw)
+		}
+	}
+
+	return &ec2.DescribeInternetGatewaysOutput{InternetGateways: igws}, nil
+}
+
+func (f *Ec2) AttachInternetGateway(ctx context.Context, params *ec2.AttachInternetGatewayInput, optFns ...func(*ec2.Options)) (*ec2.AttachInternetGatewayOutput, error) {
+	b := f.b
+	err := b.begin("AttachInternetGateway")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	igwId := aws.ToString(params.InternetGatewayId)
+	igw, ok := b.internetGateways[igwId]
+	if !ok {
+		return nil, apiError("InvalidInternetGatewayID.NotFound", "The internetGateway ID '%s' does not exist", igwId)
+	}
+	if _, ok := b.vpcs[aws.ToString(params.VpcId)]; !ok {
+		return nil, apiError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", aws.ToString(params.VpcId))
+	}
+	if len(igw.Attachments) > 0 {
+		return nil, apiError("Resource.AlreadyAssociated", "resource %s is already attached to network %s", igwId, aws.ToString(igw.Attachments[0].VpcId))
+	}
+	igw.Attachments = []types.InternetGatewayAttachment{
+		{
+			State: types.AttachmentStatusAttached,
+			VpcId: params.VpcId,
+		},
+	}
+
+	return &ec2.AttachInternetGatewayOutput{}, nil
+}
+
+func (f *Ec2) DetachInternetGateway(ctx context.Context, params *ec2.DetachInternetGatewayInput, optFns ...func(*ec2.Options)) (*ec2.DetachInternetGatewayOutput, error) {
+	b := f.b
+	err := b.begin("DetachInternetGateway")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	igwId := aws.ToString(params.InternetGatewayId)
+	igw, ok := b.internetGateways[igwId]
+	if !ok {
+		return nil, apiError("InvalidInternetGatewayID.NotFound", "The internetGateway ID '%s' does not exist", igwId)
+	}
+	if len(igw.Attachments) == 0 || aws.ToString(igw.Attachments[0].VpcId) != aws.ToString(params.VpcId) {
+		return nil, apiError("Gateway.NotAttached", "resource %s is not attached to network %s", igwId, aws.ToString(params.VpcId))
+	}
+	igw.Attachments = nil
+
+	return &ec2.DetachInternetGatewayOutput{}, nil
+}
+
+func (f *Ec2) DeleteInternetGateway(ctx context.Context, params *ec2.DeleteInternetGatewayInput, optFns ...func(*ec2.Options)) (*ec2.DeleteInternetGatewayOutput, error) {
+	b := f.b
+	err := b.begin("DeleteInternetGateway")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	igwId := aws.ToString(params.InternetGatewayId)
+	igw, ok := b.internetGateways[igwId]
+	if !ok {
+		return nil, apiError("InvalidInternetGatewayID.NotFound", "The internetGateway ID '%s' does not exist", igwId)
+	}
+	if len(igw.Attachments) > 0 {
+		return nil, apiError("DependencyViolation", "The internetGateway '%s' has dependencies and cannot be deleted.", igwId)
+	}
+	delete(b.internetGateways, igwId)
+
+	return &ec2.DeleteInternetGatewayOutput{}, nil
+}
+
+func (f *Ec2) AllocateAddress(ctx context.Context, params *ec2.AllocateAddressInput, optFns ...func(*ec2.Options)) (*ec2.AllocateAddressOutput, error) {
+	b := f.b
+	err := b.begin("AllocateAddress")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	allocationId := b.nextId("eipalloc")
+	address := types.Address{
+		AllocationId: aws.String(allocationId),
+		PublicIp:     aws.String(fmt.Sprintf("203.0.113.%d", b.counter%256)),
+		Domain:       params.Domain,
+		Tags:         tagsFor(params.TagSpecifications, types.ResourceTypeElasticIp),
+	}
+	b.addresses[allocationId] = &address
+
+	return &ec2.AllocateAddressOutput{
+		AllocationId: address.AllocationId,
+		PublicIp:     address.PublicIp,
+		Domain:       address.Domain,
+	}, nil
+}
+
+func (f *Ec2) DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	b := f.b
+	err := b.begin("DescribeAddresses")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range params.AllocationIds {
+		if _, ok := b.addresses[id]; !ok {
+			return nil, apiError("InvalidAllocationID.NotFound", "The allocation ID '%s' does not exist", id)
+		}
+	}
+	var addresses []types.Address
+	for _, id := range sortedKeys(b.addresses) {
+		address := b.addresses[id]
+		if len(params.AllocationIds) > 0 && !hasValue(params.AllocationIds, id) {
+			continue
+		}
+		attributes := map[string]string{
+			"allocation-id": id,
+			"public-ip":     aws.ToString(address.PublicIp),
+			"domain":        string(address.Domain),
+		}
+		if matchEc2Filters(params.Filters, address.Tags, attributes) {
+			addresses = append(addresses, *address)
+		}
+	}
+
+	return &ec2.DescribeAddressesOutput{Addresses: addresses}, nil
+}
+
+func (f *Ec2) ReleaseAddress(ctx context.Context, params *ec2.ReleaseAddressInput, optFns ...func(*ec2.Options)) (*ec2.ReleaseAddressOutput, error) {
+	b := f.b
+	err := b.begin("ReleaseAddress")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	allocationId := aws.ToString(params.AllocationId)
+	if _, ok := b.addresses[allocationId]; !ok {
+		return nil, apiError("InvalidAllocationID.NotFound", "The allocation ID '%s' does not exist", allocationId)
+	}
+	for _, ngw := range b.natGateways {
+		if ngw.State == types.NatGatewayStateDeleted {
+			continue
+		}
+		for _, address := range ngw.NatGatewayAddresses {
+			if aws.ToString(address.AllocationId) == allocationId {
+				return nil, apiError("InvalidIPAddress.InUse", "Address '%s' is in use.", allocationId)
+			}
+		}
+	}
+	delete(b.addresses, allocationId)
+
+	return &ec2.ReleaseAddressOutput{}, nil
+}
+
+func (f *Ec2) CreateNatGateway(ctx context.Context, params *ec2.CreateNatGatewayInput, optFns ...func(*ec2.Options)) (*ec2.CreateNatGatewayOutput, error) {
+	b := f.b
+	err := b.begin("CreateNatGateway")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	subnetId := aws.ToString(params.SubnetId)
+	subnet, ok := b.subnets[subnetId]
+	if !ok {
+		return nil, apiError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetId)
+	}
+	allocationId := aws.ToString(params.AllocationId)
+	address, ok := b.addresses[allocationId]
+	if !ok {
+		return nil, apiError("InvalidAllocationID.NotFound", "The allocation ID '%s' does not exist", allocationId)
+	}
+	ngw := types.NatGateway{
+		NatGatewayId: aws.String(b.nextId("nat")),
+		SubnetId:     params.SubnetId,
+		VpcId:        subnet.VpcId,
+		State:        types.NatGatewayStatePending,
+		NatGatewayAddresses: []types.NatGatewayAddress{
+			{
+				AllocationId: address.AllocationId,
+				PublicIp:     address.PublicIp,
+			},
+		},
+		Tags: tagsFor(params.TagSpecifications, types.ResourceTypeNatgateway),
+	}
+	b.natGateways[*ngw.NatGatewayId] = &ngw
+	out := ngw
+
+	return &ec2.CreateNatGatewayOutput{NatGateway: &out}, nil
+}
+
+func (f *Ec2) DescribeNatGateways(ctx context.Context, params *ec2.DescribeNatGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error) {
+	b := f.b
+	err := b.begin("DescribeNatGateways")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var natGateways []types.NatGateway
+	for _, id := range sortedKeys(b.natGateways) {
+		ngw := b.natGateways[id]
+		if len(params.NatGatewayIds) > 0 && !hasValue(params.NatGatewayIds, id) {
+			continue
+		}
+		attributes := map[string]string{
+			"nat-gateway-id": id,
+			"subnet-id":      aws.ToString(ngw.SubnetId),
+			"vpc-id":         aws.ToString(ngw.VpcId),
+			"state":          string(ngw.State),
+		}
+		if !matchEc2Filters(params.Filter, ngw.Tags, attributes) {
+			continue
+		}
+
+		// transitional states complete by the time they are described
+		switch ngw.State {
+		case types.NatGatewayStatePending:
+			ngw.State = types.NatGatewayStateAvailable
+		case types.NatGatewayStateDeleting:
+			ngw.State = types.NatGatewayStateDeleted
+		}
+		natGateways = append(natGateways, *ngw)
+	}
+
+	return &ec2.DescribeNatGatewaysOutput{NatGateways: natGateways}, nil
+}
+
+func (f *Ec2) DeleteNatGateway(ctx context.Context, params *ec2.DeleteNatGatewayInput, optFns ...func(*ec2.Options)) (*ec2.DeleteNatGatewayOutput, error) {
+	b := f.b
+	err := b.begin("DeleteNatGateway")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	natGatewayId := aws.ToString(params.NatGatewayId)
+	ngw, ok := b.natGateways[natGatewayId]
+	if !ok || ngw.State == types.NatGatewayStateDeleted {
+		return nil, apiError("InvalidNATGatewayID.NotFound", "The NAT gateway ID '%s' does not exist", natGatewayId)
+	}
+	ngw.State = types.NatGatewayStateDeleting
+
+	return &ec2.DeleteNatGatewayOutput{NatGatewayId: params.NatGatewayId}, nil
+}
+
+func (f *Ec2) CreateRouteTable(ctx context.Context, params *ec2.CreateRouteTableInput, optFns ...func(*ec2.Options)) (*ec2.CreateRouteTableOutput, error) {
+	b := f.b
+	err := b.begin("CreateRouteTable")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	vpcId := aws.ToString(params.VpcId)
+	vpc, ok := b.vpcs[vpcId]
+	if !ok {
+		return nil, apiError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", vpcId)
+	}
+	rt := types.RouteTable{
+		RouteTableId: aws.String(b.nextId("rtb")),
+		VpcId:        params.VpcId,
+		OwnerId:      aws.String(b.AccountId),
+		Routes: []types.Route{
+			{
+				DestinationCidrBlock: vpc.CidrBlock,
+				GatewayId:            aws.String("local"),
+				State:                types.RouteStateActive,
+			},
+		},
+		Tags: tagsFor(params.TagSpecifications, types.ResourceTypeRouteTable),
+	}
+	b.routeTables[*rt.RouteTableId] = &rt
+	out := rt
+
+	return &ec2.CreateRouteTableOutput{RouteTable: &out}, nil
+}
+
+func (f *Ec2) DescribeRouteTables(ctx context.Context, params *ec2.DescribeRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error) {
+	b := f.b
+	err := b.begin("DescribeRouteTables")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range params.RouteTableIds {
+		if _, ok := b.routeTables[id]; !ok {
+			return nil, apiError("InvalidRouteTableID.NotFound", "The routeTable ID '%s' does not exist", id)
+		}
+	}
+	var routeTables []types.RouteTable
+	for _, id := range sortedKeys(b.routeTables) {
+		rt := b.routeTables[id]
+		if len(params.RouteTableIds) > 0 && !hasValue(params.RouteTableIds, id) {
+			continue
+		}
+		attributes := map[string]string{
+			"route-table-id": id,
+			"vpc-id":         aws.ToString(rt.VpcId),
+		}
+		if matchEc2Filters(params.Filters, rt.Tags, attributes) {
+			routeTables = append(routeTables, *rt)
+		}
+	}
+
+	return &ec2.DescribeRouteTablesOutput{RouteTables: routeTables}, nil
+}
+
+func (f *Ec2) CreateRoute(ctx context.Context, params *ec2.CreateRouteInput, optFns ...func(*ec2.Options)) (*ec2.CreateRouteOutput, error) {
+	b := f.b
+	err := b.begin("CreateRoute")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	routeTableId := aws.ToString(params.RouteTableId)
+	rt, ok := b.routeTables[routeTableId]
+	if !ok {
+		return nil, apiError("InvalidRouteTableID.NotFound", "The routeTable ID '%s' does not exist", routeTableId)
+	}
+	for _, route := range rt.Routes {
+		if aws.ToString(route.DestinationCidrBlock) == aws.ToString(params.DestinationCidrBlock) {
+			return nil, apiError("RouteAlreadyExists", "The route identified by %s already exists.", aws.ToString(params.DestinationCidrBlock))
+		}
+	}
+	if params.NatGatewayId != nil {
+		if ngw, ok := b.natGateways[*params.NatGatewayId]; !ok || ngw.State == types.NatGatewayStateDeleted {
+			return nil, apiError("InvalidNatGatewayID.NotFound", "The natGateway ID '%s' does not exist", *params.NatGatewayId)
+		}
+	}
+	if params.GatewayId != nil {
+		if _, ok := b.internetGateways[*params.GatewayId]; !ok {
+			return nil, apiError("InvalidGatewayID.NotFound", "The gateway ID '%s' does not exist", *params.GatewayId)
+		}
+	}
+	routes := append([]types.Route{}, rt.Routes...)
+	rt.Routes = append(routes, types.Route{
+		DestinationCidrBlock: params.DestinationCidrBlock,
+		GatewayId:            params.GatewayId,
+		NatGatewayId:         params.NatGatewayId,
+		State:                types.RouteStateActive,
+	})
+
+	return &ec2.CreateRouteOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *Ec2) AssociateRouteTable(ctx context.Context, params *ec2.AssociateRouteTableInput, optFns ...func(*ec2.Options)) (*ec2.AssociateRouteTableOutput, error) {
+	b := f.b
+	err := b.begin("AssociateRouteTable")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	routeTableId := aws.ToString(params.RouteTableId)
+	rt, ok := b.routeTables[routeTableId]
+	if !ok {
+		return nil, apiError("InvalidRouteTableID.NotFound", "The routeTable ID '%s' does not exist", routeTableId)
+	}
+	subnetId := aws.ToString(params.SubnetId)
+	if _, ok := b.subnets[subnetId]; !ok {
+		return nil, apiError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetId)
+	}
+	for _, other := range b.routeTables {
+		for _, assoc := range other.Associations {
+			if aws.ToString(assoc.SubnetId) == subnetId {
+				return nil, apiError("Resource.AlreadyAssociated", "the specified association for route table %s conflicts with an existing association", routeTableId)
+			}
+		}
+	}
+	associationId := b.nextId("rtbassoc")
+	associations := append([]types.RouteTableAssociation{}, rt.Associations...)
+	rt.Associations = append(associations, types.RouteTableAssociation{
+		RouteTableAssociationId: aws.String(associationId),
+		RouteTableId:            params.RouteTableId,
+		SubnetId:                params.SubnetId,
+		Main:                    aws.Bool(false),
+		AssociationState: &types.RouteTableAssociationState{
+			State: types.RouteTableAssociationStateCodeAssociated,
+		},
+	})
+
+	return &ec2.AssociateRouteTableOutput{AssociationId: aws.String(associationId)}, nil
+}
+
+func (f *Ec2) DeleteRouteTable(ctx context.Context, params *ec2.DeleteRouteTableInput, optFns ...func(*ec2.Options)) (*ec2.DeleteRouteTableOutput, error) {
+	b := f.b
+	err := b.begin("DeleteRouteTable")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	routeTableId := aws.ToString(params.RouteTableId)
+	rt, ok := b.routeTables[routeTableId]
+	if !ok {
+		return nil, apiError("InvalidRouteTableID.NotFound", "The routeTable ID '%s' does not exist", routeTableId)
+	}
+	if len(rt.Associations) > 0 {
+		return nil, apiError("DependencyViolation", "The routeTable '%s' has dependencies and cannot be deleted.", routeTableId)
+	}
+	delete(b.routeTables, routeTableId)
+
+	return &ec2.DeleteRouteTableOutput{}, nil
+}
+
+func (f *Ec2) CreateSecurityGroup(ctx context.Context, params *ec2.CreateSecurityGroupInput, optFns ...func(*ec2.Options)) (*ec2.CreateSecurityGroupOutput, error) {
+	b := f.b
+	err := b.begin("CreateSecurityGroup")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	vpcId := aws.ToString(params.VpcId)
+	if _, ok := b.vpcs[vpcId]; !ok {
+		return nil, apiError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", vpcId)
+	}
+	groupName := aws.ToString(params.GroupName)
+	for _, sg := range b.securityGroups {
+		if aws.ToString(sg.VpcId) == vpcId && aws.ToString(sg.GroupName) == groupName {
+			return nil, apiError("InvalidGroup.Duplicate", "The security group '%s' already exists for VPC '%s'", groupName, vpcId)
+		}
+	}
+	groupId := b.createSecurityGroup(groupName, aws.ToString(params.Description), vpcId,
+		tagsFor(params.TagSpecifications, types.ResourceTypeSecurityGroup))
+
+	return &ec2.CreateSecurityGroupOutput{GroupId: aws.String(groupId)}, nil
+}
+
+// createSecurityGroup adds a security group to the backend and returns its ID.
+// The caller must hold the backend lock.
+func (b *Backend) createSecurityGroup(name, description, vpcId string, tags []types.Tag) string {
+	sg := types.SecurityGroup{
+		GroupId:     aws.String(b.nextId("sg")),
+		GroupName:   aws.String(name),
+		Description: aws.String(description),
+		VpcId:       aws.String(vpcId),
+		OwnerId:     aws.String(b.AccountId),
+		Tags:        tags,
+	}
+	b.securityGroups[*sg.GroupId] = &sg
+
+	return *sg.GroupId
+}
+
+func (f *Ec2) DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+	b := f.b
+	err := b.begin("DescribeSecurityGroups")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range params.GroupIds {
+		if _, ok := b.securityGroups[id]; !ok {
+			return nil, apiError("InvalidGroup.NotFound", "The security group '%s' does not exist", id)
+		}
+	}
+	var securityGroups []types.SecurityGroup
+	for _, id := range sortedKeys(b.securityGroups) {
+		sg := b.securityGroups[id]
+		if len(params.GroupIds) > 0 && !hasValue(params.GroupIds, id) {
+			continue
+		}
+		attributes := map[string]string{
+			"group-id":   id,
+			"group-name": aws.ToString(sg.GroupName),
+			"vpc-id":     aws.ToString(sg.VpcId),
+		}
+		if matchEc2Filters(params.Filters, sg.Tags, attributes) {
+			securityGroups = append(securityGroups, *sg)
+		}
+	}
+
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: securityGroups}, nil
+}
+
+func (f *Ec2) AuthorizeSecurityGroupIngress(ctx context.Context, params *ec2.AuthorizeSecurityGroupIngressInput, optFns ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+	b := f.b
+	err := b.begin("AuthorizeSecurityGroupIngress")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	groupId := aws.ToString(params.GroupId)
+	sg, ok := b.securityGroups[groupId]
+	if !ok {
+		return nil, apiError("InvalidGroup.NotFound", "The security group '%s' does not exist", groupId)
+	}
+	permissions := append([]types.IpPermission{}, sg.IpPermissions...)
+	sg.IpPermissions = append(permissions, params.IpPermissions...)
+
+	return &ec2.AuthorizeSecurityGroupIngressOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *Ec2) AuthorizeSecurityGroupEgress(ctx context.Context, params *ec2.AuthorizeSecurityGroupEgressInput, optFns ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupEgressOutput, error) {
+	b := f.b
+	err := b.begin("AuthorizeSecurityGroupEgress")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	groupId := aws.ToString(params.GroupId)
+	sg, ok := b.securityGroups[groupId]
+	if !ok {
+		return nil, apiError("InvalidGroup.NotFound", "The security group '%s' does not exist", groupId)
+	}
+	permissions := append([]types.IpPermission{}, sg.IpPermissionsEgress...)
+	sg.IpPermissionsEgress = append(permissions, params.IpPermissions...)
+
+	return &ec2.AuthorizeSecurityGroupEgressOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *Ec2) DeleteSecurityGroup(ctx context.Context, params *ec2.DeleteSecurityGroupInput, optFns ...func(*ec2.Options)) (*ec2.DeleteSecurityGroupOutput, error) {
+	b := f.b
+	err := b.begin("DeleteSecurityGroup")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	groupId := aws.ToString(params.GroupId)
+	if _, ok := b.securityGroups[groupId]; !ok {
+		return nil, apiError("InvalidGroup.NotFound", "The security group '%s' does not exist", groupId)
+	}
+	for _, instance := range b.dbInstances {
+		for _, membership := range instance.VpcSecurityGroups {
+			if aws.ToString(membership.VpcSecurityGroupId) == groupId {
+				return nil, apiError("DependencyViolation", "resource %s has a dependent object", groupId)
+			}
+		}
+	}
+	delete(b.securityGroups, groupId)
+
+	return &ec2.DeleteSecurityGroupOutput{}, nil
+}
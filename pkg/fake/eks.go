@@ -0,0 +1,362 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+// eksState contains the fake EKS resources.  Node groups and addons are keyed
+// by "<cluster name>/<name>".
+type eksState struct {
+	clusters   map[string]*types.Cluster
+	nodegroups map[string]*types.Nodegroup
+	addons     map[string]*types.Addon
+}
+
+func (s *eksState) init() {
+	s.clusters = make(map[string]*types.Cluster)
+	s.nodegroups = make(map[string]*types.Nodegroup)
+	s.addons = make(map[string]*types.Addon)
+}
+
+// Eks is a fake implementation of client.EksApi.
+type Eks struct {
+	b *Backend
+}
+
+func resourceInUse(format string, args ...any) error {
+	return &types.ResourceInUseException{Message: aws.String(fmt.Sprintf(format, args...))}
+}
+
+func resourceNotFound(format string, args ...any) error {
+	return &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf(format, args...))}
+}
+
+func (f *Eks) CreateCluster(ctx context.Context, params *eks.CreateClusterInput, optFns ...func(*eks.Options)) (*eks.CreateClusterOutput, error) {
+	b := f.b
+	err := b.begin("CreateCluster")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := aws.ToString(params.Name)
+	if _, ok := b.clusters[clusterName]; ok {
+		return nil, resourceInUse("Cluster already exists with name: %s", clusterName)
+	}
+	var subnetIds []string
+	var vpcId string
+	if params.ResourcesVpcConfig != nil {
+		subnetIds = params.ResourcesVpcConfig.SubnetIds
+	}
+	for _, subnetId := range subnetIds {
+		subnet, ok := b.subnets[subnetId]
+		if !ok {
+			return nil, &types.InvalidParameterException{
+				Message: aws.String(fmt.Sprintf("The subnet ID '%s' does not exist", subnetId)),
+			}
+		}
+		vpcId = aws.ToString(subnet.VpcId)
+	}
+	roleName := roleNameFromArn(aws.ToString(params.RoleArn))
+	if _, ok := b.roles[roleName]; !ok {
+		return nil, &types.InvalidParameterException{
+			Message: aws.String(fmt.Sprintf("Role with arn: %s, does not exist", aws.ToString(params.RoleArn))),
+		}
+	}
+
+	// EKS creates a security group for the cluster in the cluster's VPC
+	securityGroupId := b.createSecurityGroup(
+		fmt.Sprintf("eks-cluster-sg-%s", clusterName),
+		"EKS created security group applied to ENI that is attached to EKS Control Plane master nodes",
+		vpcId,
+		[]aws_ec2_types.Tag{
+			{Key: aws.String("aws:eks:cluster-name"), Value: aws.String(clusterName)},
+		},
+	)
+
+	cluster := types.Cluster{
+		Name:      params.Name,
+		Arn:       aws.String(b.arn("eks", "cluster/"+clusterName)),
+		RoleArn:   params.RoleArn,
+		Version:   params.Version,
+		Status:    types.ClusterStatusCreating,
+		CreatedAt: aws.Time(time.Now()),
+		Tags:      params.Tags,
+		ResourcesVpcConfig: &types.VpcConfigResponse{
+			SubnetIds:              subnetIds,
+			VpcId:                  aws.String(vpcId),
+			ClusterSecurityGroupId: aws.String(securityGroupId),
+		},
+		Endpoint: aws.String(fmt.Sprintf("https://%s.gr7.%s.eks.amazonaws.com", clusterName, b.Region)),
+		Identity: &types.Identity{
+			Oidc: &types.OIDC{
+				Issuer: aws.String(fmt.Sprintf("https://oidc.eks.%s.amazonaws.com/id/%s", b.Region, b.nextId("oidc"))),
+			},
+		},
+	}
+	b.clusters[clusterName] = &cluster
+	out := cluster
+
+	return &eks.CreateClusterOutput{Cluster: &out}, nil
+}
+
+func (f *Eks) DescribeCluster(ctx context.Context, params *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error) {
+	b := f.b
+	err := b.begin("DescribeCluster")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := aws.ToString(params.Name)
+	cluster, ok := b.clusters[clusterName]
+	if ok {
+		// transitional states complete by the time they are described
+		switch cluster.Status {
+		case types.ClusterStatusCreating:
+			cluster.Status = types.ClusterStatusActive
+		case types.ClusterStatusDeleting:
+			delete(b.clusters, clusterName)
+			if cluster.ResourcesVpcConfig != nil {
+				delete(b.securityGroups, aws.ToString(cluster.ResourcesVpcConfig.ClusterSecurityGroupId))
+			}
+			ok = false
+		}
+	}
+	if !ok {
+		return nil, resourceNotFound("No cluster found for name: %s.", clusterName)
+	}
+	out := *cluster
+
+	return &eks.DescribeClusterOutput{Cluster: &out}, nil
+}
+
+func (f *Eks) DeleteCluster(ctx context.Context, params *eks.DeleteClusterInput, optFns ...func(*eks.Options)) (*eks.DeleteClusterOutput, error) {
+	b := f.b
+	err := b.begin("DeleteCluster")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := aws.ToString(params.Name)
+	cluster, ok := b.clusters[clusterName]
+	if !ok {
+		return nil, resourceNotFound("No cluster found for name: %s.", clusterName)
+	}
+	for key, ng := range b.nodegroups {
+		if aws.ToString(ng.ClusterName) == clusterName {
+			return nil, resourceInUse("Cluster has nodegroups attached: %s", key)
+		}
+	}
+	for key, addon := range b.addons {
+		if aws.ToString(addon.ClusterName) == clusterName {
+			delete(b.addons, key)
+		}
+	}
+	cluster.Status = types.ClusterStatusDeleting
+	out := *cluster
+
+	return &eks.DeleteClusterOutput{Cluster: &out}, nil
+}
+
+func (f *Eks) CreateNodegroup(ctx context.Context, params *eks.CreateNodegroupInput, optFns ...func(*eks.Options)) (*eks.CreateNodegroupOutput, error) {
+	b := f.b
+	err := b.begin("CreateNodegroup")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := aws.ToString(params.ClusterName)
+	nodegroupName := aws.ToString(params.NodegroupName)
+	if _, ok := b.clusters[clusterName]; !ok {
+		return nil, resourceNotFound("No cluster found for name: %s.", clusterName)
+	}
+	key := clusterName + "/" + nodegroupName
+	if _, ok := b.nodegroups[key]; ok {
+		return nil, resourceInUse("NodeGroup already exists with name %s and cluster name %s", nodegroupName, clusterName)
+	}
+	nodegroup := types.Nodegroup{
+		ClusterName:   params.ClusterName,
+		NodegroupName: params.NodegroupName,
+		NodegroupArn:  aws.String(b.arn("eks", fmt.Sprintf("nodegroup/%s/%s", clusterName, nodegroupName))),
+		NodeRole:      params.NodeRole,
+		Subnets:       params.Subnets,
+		InstanceTypes: params.InstanceTypes,
+		Version:       params.Version,
+		RemoteAccess:  params.RemoteAccess,
+		ScalingConfig: params.ScalingConfig,
+		Status:        types.NodegroupStatusCreating,
+		CreatedAt:     aws.Time(time.Now()),
+		Health:        &types.NodegroupHealth{},
+		Tags:          params.Tags,
+	}
+	b.nodegroups[key] = &nodegroup
+	out := nodegroup
+
+	return &eks.CreateNodegroupOutput{Nodegroup: &out}, nil
+}
+
+func (f *Eks) DescribeNodegroup(ctx context.Context, params *eks.DescribeNodegroupInput, optFns ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+	b := f.b
+	err := b.begin("DescribeNodegroup")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	key := aws.ToString(params.ClusterName) + "/" + aws.ToString(params.NodegroupName)
+	nodegroup, ok := b.nodegroups[key]
+	if ok {
+		// transitional states complete by the time they are described
+		switch nodegroup.Status {
+		case types.NodegroupStatusCreating:
+			nodegroup.Status = types.NodegroupStatusActive
+		case types.NodegroupStatusDeleting:
+			delete(b.nodegroups, key)
+			ok = false
+		}
+	}
+	if !ok {
+		return nil, resourceNotFound("No node group found for name: %s.", aws.ToString(params.NodegroupName))
+	}
+	out := *nodegroup
+
+	return &eks.DescribeNodegroupOutput{Nodegroup: &out}, nil
+}
+
+func (f *Eks) DeleteNodegroup(ctx context.Context, params *eks.DeleteNodegroupInput, optFns ...func(*eks.Options)) (*eks.DeleteNodegroupOutput, error) {
+	b := f.b
+	err := b.begin("DeleteNodegroup")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	key := aws.ToString(params.ClusterName) + "/" + aws.ToString(params.NodegroupName)
+	nodegroup, ok := b.nodegroups[key]
+	if !ok {
+		return nil, resourceNotFound("No node group found for name: %s.", aws.ToString(params.NodegroupName))
+	}
+	nodegroup.Status = types.NodegroupStatusDeleting
+	out := *nodegroup
+
+	return &eks.DeleteNodegroupOutput{Nodegroup: &out}, nil
+}
+
+func (f *Eks) ListNodegroups(ctx context.Context, params *eks.ListNodegroupsInput, optFns ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error) {
+	b := f.b
+	err := b.begin("ListNodegroups")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := aws.ToString(params.ClusterName)
+	if _, ok := b.clusters[clusterName]; !ok {
+		return nil, resourceNotFound("No cluster found for name: %s.", clusterName)
+	}
+	var nodegroupNames []string
+	for _, key := range sortedKeys(b.nodegroups) {
+		nodegroup := b.nodegroups[key]
+		if aws.ToString(nodegroup.ClusterName) == clusterName {
+			nodegroupNames = append(nodegroupNames, aws.ToString(nodegroup.NodegroupName))
+		}
+	}
+
+	return &eks.ListNodegroupsOutput{Nodegroups: nodegroupNames}, nil
+}
+
+func (f *Eks) TagResource(ctx context.Context, params *eks.TagResourceInput, optFns ...func(*eks.Options)) (*eks.TagResourceOutput, error) {
+	b := f.b
+	err := b.begin("TagResource")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceArn := aws.ToString(params.ResourceArn)
+	var tags *map[string]string
+	for _, cluster := range b.clusters {
+		if aws.ToString(cluster.Arn) == resourceArn {
+			tags = &cluster.Tags
+		}
+	}
+	for _, nodegroup := range b.nodegroups {
+		if aws.ToString(nodegroup.NodegroupArn) == resourceArn {
+			tags = &nodegroup.Tags
+		}
+	}
+	if tags == nil {
+		return nil, resourceNotFound("Resource not found: %s", resourceArn)
+	}
+	if *tags == nil {
+		*tags = make(map[string]string)
+	}
+	for key, value := range params.Tags {
+		(*tags)[key] = value
+	}
+
+	return &eks.TagResourceOutput{}, nil
+}
+
+func (f *Eks) CreateAddon(ctx context.Context, params *eks.CreateAddonInput, optFns ...func(*eks.Options)) (*eks.CreateAddonOutput, error) {
+	b := f.b
+	err := b.begin("CreateAddon")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := aws.ToString(params.ClusterName)
+	addonName := aws.ToString(params.AddonName)
+	if _, ok := b.clusters[clusterName]; !ok {
+		return nil, resourceNotFound("No cluster found for name: %s.", clusterName)
+	}
+	key := clusterName + "/" + addonName
+	if _, ok := b.addons[key]; ok {
+		return nil, resourceInUse("Addon already exists with name %s and cluster name %s", addonName, clusterName)
+	}
+	addon := types.Addon{
+		AddonName:             params.AddonName,
+		ClusterName:           params.ClusterName,
+		AddonArn:              aws.String(b.arn("eks", fmt.Sprintf("addon/%s/%s", clusterName, addonName))),
+		ServiceAccountRoleArn: params.ServiceAccountRoleArn,
+		Status:                types.AddonStatusActive,
+		CreatedAt:             aws.Time(time.Now()),
+		Tags:                  params.Tags,
+	}
+	b.addons[key] = &addon
+	out := addon
+
+	return &eks.CreateAddonOutput{Addon: &out}, nil
+}
+
+func (f *Eks) DescribeAddon(ctx context.Context, params *eks.DescribeAddonInput, optFns ...func(*eks.Options)) (*eks.DescribeAddonOutput, error) {
+	b := f.b
+	err := b.begin("DescribeAddon")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := aws.ToString(params.ClusterName)
+	if _, ok := b.clusters[clusterName]; !ok {
+		return nil, resourceNotFound("No cluster found for name: %s.", clusterName)
+	}
+	addon, ok := b.addons[clusterName+"/"+aws.ToString(params.AddonName)]
+	if !ok {
+		return nil, resourceNotFound("No addon: %s found in cluster: %s", aws.ToString(params.AddonName), clusterName)
+	}
+	out := *addon
+
+	return &eks.DescribeAddonOutput{Addon: &out}, nil
+}
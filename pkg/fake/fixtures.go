@@ -0,0 +1,163 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	eks_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+)
+
+// Network is a VPC and its subnets created with CreateNetwork.
+type Network struct {
+	VpcId     string
+	SubnetIds []string
+}
+
+// CreateNetwork creates a VPC with the CIDR block and a subnet for each of
+// the subnet CIDR blocks in successive availability zones, as if they had
+// been created outside of aws-builder.  Resources are created with the fake
+// APIs so the calls are counted.
+func (b *Backend) CreateNetwork(vpcCidr string, subnetCidrs ...string) (*Network, error) {
+	ctx := context.Background()
+	ec2Api := b.Apis().Ec2
+
+	vpc, err := ec2Api.CreateVpc(ctx, &ec2.CreateVpcInput{CidrBlock: aws.String(vpcCidr)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VPC: %w", err)
+	}
+	network := Network{VpcId: *vpc.Vpc.VpcId}
+	for i, subnetCidr := range subnetCidrs {
+		subnet, err := ec2Api.CreateSubnet(ctx, &ec2.CreateSubnetInput{
+			VpcId:            vpc.Vpc.VpcId,
+			CidrBlock:        aws.String(subnetCidr),
+			AvailabilityZone: aws.String(fmt.Sprintf("%s%c", b.Region, 'a'+i%4)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create subnet: %w", err)
+		}
+		network.SubnetIds = append(network.SubnetIds, *subnet.Subnet.SubnetId)
+	}
+
+	return &network, nil
+}
+
+// Cluster is an EKS cluster and the resources it uses created with
+// CreateCluster.
+type Cluster struct {
+	Name            string
+	RoleName        string
+	NodeRoleName    string
+	NodeGroupNames  []string
+	OidcProviderArn string
+}
+
+// CreateCluster creates an active EKS cluster in the network's subnets, a
+// node group for each of the node group names, the IAM roles they use and the
+// cluster's OIDC provider, as if they had been created outside of
+// aws-builder.  Resources are created with the fake APIs so the calls are
+// counted.
+func (b *Backend) CreateCluster(name string, network *Network, nodeGroupNames ...string) (*Cluster, error) {
+	ctx := context.Background()
+	apis := b.Apis()
+	cluster := Cluster{
+		Name:           name,
+		RoleName:       name + "-cluster-role",
+		NodeRoleName:   name + "-node-role",
+		NodeGroupNames: nodeGroupNames,
+	}
+
+	clusterRole, err := apis.Iam.CreateRole(ctx, &iam.CreateRoleInput{RoleName: aws.String(cluster.RoleName)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster role: %w", err)
+	}
+	nodeRole, err := apis.Iam.CreateRole(ctx, &iam.CreateRoleInput{RoleName: aws.String(cluster.NodeRoleName)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create node role: %w", err)
+	}
+	if _, err := apis.Eks.CreateCluster(ctx, &eks.CreateClusterInput{
+		Name:               aws.String(name),
+		RoleArn:            clusterRole.Role.Arn,
+		ResourcesVpcConfig: &eks_types.VpcConfigRequest{SubnetIds: network.SubnetIds},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create cluster: %w", err)
+	}
+
+	// describing the cluster completes its creation
+	described, err := apis.Eks.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: aws.String(name)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe cluster: %w", err)
+	}
+	for _, nodeGroupName := range nodeGroupNames {
+		if _, err := apis.Eks.CreateNodegroup(ctx, &eks.CreateNodegroupInput{
+			ClusterName:   aws.String(name),
+			NodegroupName: aws.String(nodeGroupName),
+			NodeRole:      nodeRole.Role.Arn,
+			Subnets:       network.SubnetIds,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to create node group: %w", err)
+		}
+	}
+	provider, err := apis.Iam.CreateOpenIDConnectProvider(ctx, &iam.CreateOpenIDConnectProviderInput{
+		Url: described.Cluster.Identity.Oidc.Issuer,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OIDC provider: %w", err)
+	}
+	cluster.OidcProviderArn = *provider.OpenIDConnectProviderArn
+
+	return &cluster, nil
+}
+
+// Database is an RDS instance and the resources it uses created with
+// CreateDatabase.
+type Database struct {
+	InstanceId      string
+	SubnetGroupName string
+	SecurityGroupId string
+}
+
+// CreateDatabase creates an RDS instance in the network's subnets with a
+// subnet group and security group named after it, as if they had been
+// created outside of aws-builder.  Resources are created with the fake APIs
+// so the calls are counted.
+func (b *Backend) CreateDatabase(instanceId string, network *Network) (*Database, error) {
+	ctx := context.Background()
+	apis := b.Apis()
+	database := Database{
+		InstanceId:      instanceId,
+		SubnetGroupName: instanceId + "-subnet-group",
+	}
+
+	securityGroup, err := apis.Ec2.CreateSecurityGroup(ctx, &ec2.CreateSecurityGroupInput{
+		GroupName:   aws.String(instanceId + "-security-group"),
+		Description: aws.String("security group for " + instanceId),
+		VpcId:       aws.String(network.VpcId),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create security group: %w", err)
+	}
+	database.SecurityGroupId = *securityGroup.GroupId
+	if _, err := apis.Rds.CreateDBSubnetGroup(ctx, &rds.CreateDBSubnetGroupInput{
+		DBSubnetGroupName:        aws.String(database.SubnetGroupName),
+		DBSubnetGroupDescription: aws.String("subnet group for " + instanceId),
+		SubnetIds:                network.SubnetIds,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create subnet group: %w", err)
+	}
+	if _, err := apis.Rds.CreateDBInstance(ctx, &rds.CreateDBInstanceInput{
+		DBInstanceIdentifier: aws.String(instanceId),
+		DBInstanceClass:      aws.String("db.t3.small"),
+		Engine:               aws.String("postgres"),
+		DBSubnetGroupName:    aws.String(database.SubnetGroupName),
+		VpcSecurityGroupIds:  []string{database.SecurityGroupId},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create RDS instance: %w", err)
+	}
+
+	return &database, nil
+}
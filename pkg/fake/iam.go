@@ -0,0 +1,357 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// awsManagedPolicyPrefix is the ARN prefix for policies managed by AWS.  These
+// always exist and may be attached to roles without being created.
+const awsManagedPolicyPrefix = "arn:aws:iam::aws:policy/"
+
+// iamState contains the fake IAM resources.  Roles are keyed by name, policies
+// and OIDC providers by ARN.
+type iamState struct {
+	roles            map[string]*types.Role
+	attachedPolicies map[string][]string
+	policies         map[string]*types.Policy
+	oidcProviders    map[string]string
+}
+
+func (s *iamState) init() {
+	s.roles = make(map[string]*types.Role)
+	s.attachedPolicies = make(map[string][]string)
+	s.policies = make(map[string]*types.Policy)
+	s.oidcProviders = make(map[string]string)
+}
+
+// Iam is a fake implementation of client.IamApi.
+type Iam struct {
+	b *Backend
+}
+
+func entityAlreadyExists(format string, args ...any) error {
+	return &types.EntityAlreadyExistsException{Message: aws.String(fmt.Sprintf(format, args...))}
+}
+
+func noSuchEntity(format string, args ...any) error {
+	return &types.NoSuchEntityException{Message: aws.String(fmt.Sprintf(format, args...))}
+}
+
+// iamArn returns an IAM ARN.  IAM is a global service so its ARNs contain no
+// region.
+func (b *Backend) iamArn(resource string) string {
+	return fmt.Sprintf("arn:aws:iam::%s:%s", b.AccountId, resource)
+}
+
+// roleNameFromArn returns the role name at the end of a role ARN.
+func roleNameFromArn(roleArn string) string {
+	return roleArn[strings.LastIndex(roleArn, "/")+1:]
+}
+
+// iamPath returns the path for an IAM entity, defaulting to "/".
+func iamPath(path *string) string {
+	if aws.ToString(path) == "" {
+		return "/"
+	}
+
+	return *path
+}
+
+func (f *Iam) CreateRole(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error) {
+	b := f.b
+	err := b.begin("CreateRole")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	roleName := aws.ToString(params.RoleName)
+	if _, ok := b.roles[roleName]; ok {
+		return nil, entityAlreadyExists("Role with name %s already exists.", roleName)
+	}
+	path := iamPath(params.Path)
+	role := types.Role{
+		RoleName:                 params.RoleName,
+		RoleId:                   aws.String(strings.ToUpper(b.nextId("AROA"))),
+		Arn:                      aws.String(b.iamArn("role" + path + roleName)),
+		Path:                     aws.String(path),
+		AssumeRolePolicyDocument: params.AssumeRolePolicyDocument,
+		CreateDate:               aws.Time(time.Now()),
+		Tags:                     params.Tags,
+	}
+	b.roles[roleName] = &role
+	out := role
+
+	return &iam.CreateRoleOutput{Role: &out}, nil
+}
+
+func (f *Iam) GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	b := f.b
+	err := b.begin("GetRole")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	roleName := aws.ToString(params.RoleName)
+	role, ok := b.roles[roleName]
+	if !ok {
+		return nil, noSuchEntity("The role with name %s cannot be found.", roleName)
+	}
+	out := *role
+
+	return &iam.GetRoleOutput{Role: &out}, nil
+}
+
+func (f *Iam) DeleteRole(ctx context.Context, params *iam.DeleteRoleInput, optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error) {
+	b := f.b
+	err := b.begin("DeleteRole")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	roleName := aws.ToString(params.RoleName)
+	if _, ok := b.roles[roleName]; !ok {
+		return nil, noSuchEntity("The role with name %s cannot be found.", roleName)
+	}
+	if len(b.attachedPolicies[roleName]) > 0 {
+		return nil, &types.DeleteConflictException{
+			Message: aws.String("Cannot delete entity, must detach all policies first."),
+		}
+	}
+	delete(b.roles, roleName)
+	delete(b.attachedPolicies, roleName)
+
+	return &iam.DeleteRoleOutput{}, nil
+}
+
+func (f *Iam) AttachRolePolicy(ctx context.Context, params *iam.AttachRolePolicyInput, optFns ...func(*iam.Options)) (*iam.AttachRolePolicyOutput, error) {
+	b := f.b
+	err := b.begin("AttachRolePolicy")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	roleName := aws.ToString(params.RoleName)
+	policyArn := aws.ToString(params.PolicyArn)
+	if _, ok := b.roles[roleName]; !ok {
+		return nil, noSuchEntity("The role with name %s cannot be found.", roleName)
+	}
+	if _, ok := b.policies[policyArn]; !ok && !strings.HasPrefix(policyArn, awsManagedPolicyPrefix) {
+		return nil, noSuchEntity("Policy %s does not exist or is not attachable.", policyArn)
+	}
+	if !hasValue(b.attachedPolicies[roleName], policyArn) {
+		attached := append([]string{}, b.attachedPolicies[roleName]...)
+		b.attachedPolicies[roleName] = append(attached, policyArn)
+	}
+
+	return &iam.AttachRolePolicyOutput{}, nil
+}
+
+func (f *Iam) DetachRolePolicy(ctx context.Context, params *iam.DetachRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DetachRolePolicyOutput, error) {
+	b := f.b
+	err := b.begin("DetachRolePolicy")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	roleName := aws.ToString(params.RoleName)
+	policyArn := aws.ToString(params.PolicyArn)
+	if _, ok := b.roles[roleName]; !ok {
+		return nil, noSuchEntity("The role with name %s cannot be found.", roleName)
+	}
+	if !hasValue(b.attachedPolicies[roleName], policyArn) {
+		return nil, noSuchEntity("Policy %s was not found.", policyArn)
+	}
+	var attached []string
+	for _, arn := range b.attachedPolicies[roleName] {
+		if arn != policyArn {
+			attached = append(attached, arn)
+		}
+	}
+	b.attachedPolicies[roleName] = attached
+
+	return &iam.DetachRolePolicyOutput{}, nil
+}
+
+func (f *Iam) ListAttachedRolePolicies(ctx context.Context, params *iam.ListAttachedRolePoliciesInput, optFns ...func(*iam.Options)) (*iam.ListAttachedRolePoliciesOutput, error) {
+	b := f.b
+	err := b.begin("ListAttachedRolePolicies")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	roleName := aws.ToString(params.RoleName)
+	if _, ok := b.roles[roleName]; !ok {
+		return nil, noSuchEntity("The role with name %s cannot be found.", roleName)
+	}
+	var attachedPolicies []types.AttachedPolicy
+	for _, policyArn := range b.attachedPolicies[roleName] {
+		attachedPolicies = append(attachedPolicies, types.AttachedPolicy{
+			PolicyArn:  aws.String(policyArn),
+			PolicyName: aws.String(policyArn[strings.LastIndex(policyArn, "/")+1:]),
+		})
+	}
+
+	return &iam.ListAttachedRolePoliciesOutput{AttachedPolicies: attachedPolicies}, nil
+}
+
+func (f *Iam) CreatePolicy(ctx context.Context, params *iam.CreatePolicyInput, optFns ...func(*iam.Options)) (*iam.CreatePolicyOutput, error) {
+	b := f.b
+	err := b.begin("CreatePolicy")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	path := iamPath(params.Path)
+	policyName := aws.ToString(params.PolicyName)
+	policyArn := b.iamArn("policy" + path + policyName)
+	if _, ok := b.policies[policyArn]; ok {
+		return nil, entityAlreadyExists("A policy called %s already exists. Duplicate names are not allowed.", policyName)
+	}
+	policy := types.Policy{
+		PolicyName:   params.PolicyName,
+		PolicyId:     aws.String(strings.ToUpper(b.nextId("ANPA"))),
+		Arn:          aws.String(policyArn),
+		Path:         aws.String(path),
+		Description:  params.Description,
+		IsAttachable: true,
+		CreateDate:   aws.Time(time.Now()),
+		Tags:         params.Tags,
+	}
+	b.policies[policyArn] = &policy
+	out := policy
+
+	return &iam.CreatePolicyOutput{Policy: &out}, nil
+}
+
+func (f *Iam) ListPolicies(ctx context.Context, params *iam.ListPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListPoliciesOutput, error) {
+	b := f.b
+	err := b.begin("ListPolicies")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// only customer managed policies are stored so scope "AWS" matches
+	// nothing
+	var policies []types.Policy
+	if params.Scope != types.PolicyScopeTypeAws {
+		for _, policyArn := range sortedKeys(b.policies) {
+			policy := b.policies[policyArn]
+			if strings.HasPrefix(aws.ToString(policy.Path), iamPath(params.PathPrefix)) {
+				policies = append(policies, *policy)
+			}
+		}
+	}
+
+	return &iam.ListPoliciesOutput{Policies: policies}, nil
+}
+
+func (f *Iam) GetPolicy(ctx context.Context, params *iam.GetPolicyInput, optFns ...func(*iam.Options)) (*iam.GetPolicyOutput, error) {
+	b := f.b
+	err := b.begin("GetPolicy")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	policyArn := aws.ToString(params.PolicyArn)
+	policy, ok := b.policies[policyArn]
+	if !ok {
+		return nil, noSuchEntity("Policy %s was not found.", policyArn)
+	}
+	out := *policy
+
+	return &iam.GetPolicyOutput{Policy: &out}, nil
+}
+
+func (f *Iam) DeletePolicy(ctx context.Context, params *iam.DeletePolicyInput, optFns ...func(*iam.Options)) (*iam.DeletePolicyOutput, error) {
+	b := f.b
+	err := b.begin("DeletePolicy")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	policyArn := aws.ToString(params.PolicyArn)
+	if _, ok := b.policies[policyArn]; !ok {
+		return nil, noSuchEntity("Policy %s was not found.", policyArn)
+	}
+	for _, attached := range b.attachedPolicies {
+		if hasValue(attached, policyArn) {
+			return nil, &types.DeleteConflictException{
+				Message: aws.String("Cannot delete a policy attached to entities."),
+			}
+		}
+	}
+	delete(b.policies, policyArn)
+
+	return &iam.DeletePolicyOutput{}, nil
+}
+
+func (f *Iam) CreateOpenIDConnectProvider(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput, optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+	b := f.b
+	err := b.begin("CreateOpenIDConnectProvider")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	providerUrl := aws.ToString(params.Url)
+	providerArn := b.iamArn("oidc-provider/" + strings.TrimPrefix(providerUrl, "https://"))
+	if _, ok := b.oidcProviders[providerArn]; ok {
+		return nil, entityAlreadyExists("Provider with url %s already exists.", providerUrl)
+	}
+	b.oidcProviders[providerArn] = providerUrl
+
+	return &iam.CreateOpenIDConnectProviderOutput{
+		OpenIDConnectProviderArn: aws.String(providerArn),
+		Tags:                     params.Tags,
+	}, nil
+}
+
+func (f *Iam) ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput, optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+	b := f.b
+	err := b.begin("ListOpenIDConnectProviders")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var providers []types.OpenIDConnectProviderListEntry
+	for _, providerArn := range sortedKeys(b.oidcProviders) {
+		providers = append(providers, types.OpenIDConnectProviderListEntry{Arn: aws.String(providerArn)})
+	}
+
+	return &iam.ListOpenIDConnectProvidersOutput{OpenIDConnectProviderList: providers}, nil
+}
+
+func (f *Iam) DeleteOpenIDConnectProvider(ctx context.Context, params *iam.DeleteOpenIDConnectProviderInput, optFns ...func(*iam.Options)) (*iam.DeleteOpenIDConnectProviderOutput, error) {
+	b := f.b
+	err := b.begin("DeleteOpenIDConnectProvider")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	providerArn := aws.ToString(params.OpenIDConnectProviderArn)
+	if _, ok := b.oidcProviders[providerArn]; !ok {
+		return nil, noSuchEntity("OpenIDConnect Provider not found for arn %s", providerArn)
+	}
+	delete(b.oidcProviders, providerArn)
+
+	return &iam.DeleteOpenIDConnectProviderOutput{}, nil
+}
@@ -0,0 +1,314 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// defaultPorts contains the port instances of each engine listen on when
+// created without one.
+var defaultPorts = map[string]int32{
+	"mariadb":   3306,
+	"mysql":     3306,
+	"oracle":    1521,
+	"postgres":  5432,
+	"sqlserver": 1433,
+}
+
+// rdsState contains the fake RDS resources keyed by identifier or name and
+// their tags keyed by ARN.
+type rdsState struct {
+	dbInstances    map[string]*types.DBInstance
+	dbSubnetGroups map[string]*types.DBSubnetGroup
+	rdsTags        map[string][]types.Tag
+}
+
+func (s *rdsState) init() {
+	s.dbInstances = make(map[string]*types.DBInstance)
+	s.dbSubnetGroups = make(map[string]*types.DBSubnetGroup)
+	s.rdsTags = make(map[string][]types.Tag)
+}
+
+// Rds is a fake implementation of client.RdsApi.
+type Rds struct {
+	b *Backend
+}
+
+func (f *Rds) CreateDBSubnetGroup(ctx context.Context, params *rds.CreateDBSubnetGroupInput, optFns ...func(*rds.Options)) (*rds.CreateDBSubnetGroupOutput, error) {
+	b := f.b
+	err := b.begin("CreateDBSubnetGroup")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	subnetGroupName := aws.ToString(params.DBSubnetGroupName)
+	if _, ok := b.dbSubnetGroups[subnetGroupName]; ok {
+		return nil, &types.DBSubnetGroupAlreadyExistsFault{
+			Message: aws.String(fmt.Sprintf("The DB subnet group '%s' already exists.", subnetGroupName)),
+		}
+	}
+	var vpcId string
+	var subnets []types.Subnet
+	for _, subnetId := range params.SubnetIds {
+		subnet, ok := b.subnets[subnetId]
+		if !ok {
+			return nil, &types.InvalidSubnet{
+				Message: aws.String(fmt.Sprintf("The subnet ID '%s' does not exist", subnetId)),
+			}
+		}
+		vpcId = aws.ToString(subnet.VpcId)
+		subnets = append(subnets, types.Subnet{
+			SubnetIdentifier:       aws.String(subnetId),
+			SubnetAvailabilityZone: &types.AvailabilityZone{Name: subnet.AvailabilityZone},
+			SubnetStatus:           aws.String("Active"),
+		})
+	}
+	subnetGroup := types.DBSubnetGroup{
+		DBSubnetGroupName:        params.DBSubnetGroupName,
+		DBSubnetGroupDescription: params.DBSubnetGroupDescription,
+		DBSubnetGroupArn:         aws.String(b.arn("rds", "subgrp:"+subnetGroupName)),
+		SubnetGroupStatus:        aws.String("Complete"),
+		Subnets:                  subnets,
+		VpcId:                    aws.String(vpcId),
+	}
+	b.dbSubnetGroups[subnetGroupName] = &subnetGroup
+	b.rdsTags[*subnetGroup.DBSubnetGroupArn] = params.Tags
+	out := subnetGroup
+
+	return &rds.CreateDBSubnetGroupOutput{DBSubnetGroup: &out}, nil
+}
+
+func (f *Rds) DescribeDBSubnetGroups(ctx context.Context, params *rds.DescribeDBSubnetGroupsInput, optFns ...func(*rds.Options)) (*rds.DescribeDBSubnetGroupsOutput, error) {
+	b := f.b
+	err := b.begin("DescribeDBSubnetGroups")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	subnetGroupName := aws.ToString(params.DBSubnetGroupName)
+	var subnetGroups []types.DBSubnetGroup
+	for _, name := range sortedKeys(b.dbSubnetGroups) {
+		if subnetGroupName == "" || subnetGroupName == name {
+			subnetGroups = append(subnetGroups, *b.dbSubnetGroups[name])
+		}
+	}
+	if subnetGroupName != "" && len(subnetGroups) == 0 {
+		return nil, &types.DBSubnetGroupNotFoundFault{
+			Message: aws.String(fmt.Sprintf("DB subnet group '%s' not found.", subnetGroupName)),
+		}
+	}
+
+	return &rds.DescribeDBSubnetGroupsOutput{DBSubnetGroups: subnetGroups}, nil
+}
+
+func (f *Rds) DeleteDBSubnetGroup(ctx context.Context, params *rds.DeleteDBSubnetGroupInput, optFns ...func(*rds.Options)) (*rds.DeleteDBSubnetGroupOutput, error) {
+	b := f.b
+	err := b.begin("DeleteDBSubnetGroup")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	subnetGroupName := aws.ToString(params.DBSubnetGroupName)
+	subnetGroup, ok := b.dbSubnetGroups[subnetGroupName]
+	if !ok {
+		return nil, &types.DBSubnetGroupNotFoundFault{
+			Message: aws.String(fmt.Sprintf("DB subnet group '%s' not found.", subnetGroupName)),
+		}
+	}
+	for _, instance := range b.dbInstances {
+		if instance.DBSubnetGroup != nil && aws.ToString(instance.DBSubnetGroup.DBSubnetGroupName) == subnetGroupName {
+			return nil, &types.InvalidDBSubnetGroupStateFault{
+				Message: aws.String(fmt.Sprintf("Cannot delete the subnet group '%s' because at least one database instance is still using it.", subnetGroupName)),
+			}
+		}
+	}
+	delete(b.dbSubnetGroups, subnetGroupName)
+	delete(b.rdsTags, aws.ToString(subnetGroup.DBSubnetGroupArn))
+
+	return &rds.DeleteDBSubnetGroupOutput{}, nil
+}
+
+func (f *Rds) CreateDBInstance(ctx context.Context, params *rds.CreateDBInstanceInput, optFns ...func(*rds.Options)) (*rds.CreateDBInstanceOutput, error) {
+	b := f.b
+	err := b.begin("CreateDBInstance")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	instanceId := aws.ToString(params.DBInstanceIdentifier)
+	if _, ok := b.dbInstances[instanceId]; ok {
+		return nil, &types.DBInstanceAlreadyExistsFault{
+			Message: aws.String("DB instance already exists"),
+		}
+	}
+	subnetGroupName := aws.ToString(params.DBSubnetGroupName)
+	subnetGroup, ok := b.dbSubnetGroups[subnetGroupName]
+	if !ok {
+		return nil, &types.DBSubnetGroupNotFoundFault{
+			Message: aws.String(fmt.Sprintf("DB subnet group '%s' not found.", subnetGroupName)),
+		}
+	}
+	var securityGroups []types.VpcSecurityGroupMembership
+	for _, groupId := range params.VpcSecurityGroupIds {
+		if _, ok := b.securityGroups[groupId]; !ok {
+			return nil, apiError("InvalidParameterValue", "Invalid security group, groupId= %s", groupId)
+		}
+		securityGroups = append(securityGroups, types.VpcSecurityGroupMembership{
+			VpcSecurityGroupId: aws.String(groupId),
+			Status:             aws.String("active"),
+		})
+	}
+	subnetGroupCopy := *subnetGroup
+	port := params.Port
+	if port == nil {
+		engine, _, _ := strings.Cut(aws.ToString(params.Engine), "-")
+		port = aws.Int32(defaultPorts[engine])
+	}
+	instance := types.DBInstance{
+		DBInstanceIdentifier:  params.DBInstanceIdentifier,
+		DBInstanceArn:         aws.String(b.arn("rds", "db:"+instanceId)),
+		DBInstanceClass:       params.DBInstanceClass,
+		DBInstanceStatus:      aws.String("creating"),
+		DBName:                params.DBName,
+		Engine:                params.Engine,
+		EngineVersion:         params.EngineVersion,
+		AllocatedStorage:      params.AllocatedStorage,
+		BackupRetentionPeriod: params.BackupRetentionPeriod,
+		MasterUsername:        params.MasterUsername,
+		MultiAZ:               params.MultiAZ,
+		PubliclyAccessible:    params.PubliclyAccessible,
+		DBSubnetGroup:         &subnetGroupCopy,
+		DbInstancePort:        port,
+		VpcSecurityGroups:     securityGroups,
+		InstanceCreateTime:    aws.Time(time.Now()),
+		TagList:               params.Tags,
+	}
+	b.dbInstances[instanceId] = &instance
+	b.rdsTags[*instance.DBInstanceArn] = params.Tags
+	out := instance
+
+	return &rds.CreateDBInstanceOutput{DBInstance: &out}, nil
+}
+
+func (f *Rds) DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+	b := f.b
+	err := b.begin("DescribeDBInstances")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	instanceId := aws.ToString(params.DBInstanceIdentifier)
+	var instances []types.DBInstance
+	for _, id := range sortedKeys(b.dbInstances) {
+		if instanceId != "" && instanceId != id {
+			continue
+		}
+		instance := b.dbInstances[id]
+
+		// transitional states complete by the time they are described
+		switch aws.ToString(instance.DBInstanceStatus) {
+		case "creating":
+			instance.DBInstanceStatus = aws.String("available")
+			instance.Endpoint = &types.Endpoint{
+				Address: aws.String(fmt.Sprintf("%s.%012x.%s.rds.amazonaws.com", id, b.counter, b.Region)),
+				Port:    instance.DbInstancePort,
+			}
+		case "deleting":
+			delete(b.dbInstances, id)
+			delete(b.rdsTags, aws.ToString(instance.DBInstanceArn))
+			continue
+		}
+		instances = append(instances, *instance)
+	}
+	if instanceId != "" && len(instances) == 0 {
+		return nil, &types.DBInstanceNotFoundFault{
+			Message: aws.String(fmt.Sprintf("DBInstance %s not found.", instanceId)),
+		}
+	}
+
+	return &rds.DescribeDBInstancesOutput{DBInstances: instances}, nil
+}
+
+func (f *Rds) DeleteDBInstance(ctx context.Context, params *rds.DeleteDBInstanceInput, optFns ...func(*rds.Options)) (*rds.DeleteDBInstanceOutput, error) {
+	b := f.b
+	err := b.begin("DeleteDBInstance")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	instanceId := aws.ToString(params.DBInstanceIdentifier)
+	instance, ok := b.dbInstances[instanceId]
+	if !ok || aws.ToString(instance.DBInstanceStatus) == "deleting" {
+		return nil, &types.DBInstanceNotFoundFault{
+			Message: aws.String(fmt.Sprintf("DBInstance %s not found.", instanceId)),
+		}
+	}
+	instance.DBInstanceStatus = aws.String("deleting")
+	out := *instance
+
+	return &rds.DeleteDBInstanceOutput{DBInstance: &out}, nil
+}
+
+func (f *Rds) ListTagsForResource(ctx context.Context, params *rds.ListTagsForResourceInput, optFns ...func(*rds.Options)) (*rds.ListTagsForResourceOutput, error) {
+	b := f.b
+	err := b.begin("ListTagsForResource")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceArn := aws.ToString(params.ResourceName)
+	tags, ok := b.rdsTags[resourceArn]
+	if !ok {
+		return nil, apiError("InvalidParameterValue", "Unable to find resource %s", resourceArn)
+	}
+
+	return &rds.ListTagsForResourceOutput{TagList: tags}, nil
+}
+
+func (f *Rds) AddTagsToResource(ctx context.Context, params *rds.AddTagsToResourceInput, optFns ...func(*rds.Options)) (*rds.AddTagsToResourceOutput, error) {
+	b := f.b
+	err := b.begin("AddTagsToResource")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceArn := aws.ToString(params.ResourceName)
+	tags, ok := b.rdsTags[resourceArn]
+	if !ok {
+		return nil, apiError("InvalidParameterValue", "Unable to find resource %s", resourceArn)
+	}
+	for _, newTag := range params.Tags {
+		replaced := false
+		for i, tag := range tags {
+			if aws.ToString(tag.Key) == aws.ToString(newTag.Key) {
+				tags[i] = newTag
+				replaced = true
+			}
+		}
+		if !replaced {
+			tags = append(tags, newTag)
+		}
+	}
+	b.rdsTags[resourceArn] = tags
+	for _, instance := range b.dbInstances {
+		if aws.ToString(instance.DBInstanceArn) == resourceArn {
+			instance.TagList = tags
+		}
+	}
+
+	return &rds.AddTagsToResourceOutput{}, nil
+}
@@ -0,0 +1,519 @@
+package fake
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	s3control_types "github.com/aws/aws-sdk-go-v2/service/s3control/types"
+)
+
+// bucket is the state of a fake S3 bucket.  New buckets block public access
+// until the public access block is deleted.
+type bucket struct {
+	Name              string
+	Region            string
+	CreationDate      time.Time
+	Versioning        types.BucketVersioningStatus
+	Tags              []types.Tag
+	Acl               types.BucketCannedACL
+	Policy            string
+	PublicAccessBlock bool
+	Objects           map[string][]byte
+}
+
+// accessPoint is the state of a fake S3 access point.
+type accessPoint struct {
+	Name      string
+	AccountId string
+	Bucket    string
+	VpcId     string
+}
+
+// s3State contains the fake S3 buckets and access points keyed by name.
+type s3State struct {
+	buckets      map[string]*bucket
+	accessPoints map[string]*accessPoint
+}
+
+func (s *s3State) init() {
+	s.buckets = make(map[string]*bucket)
+	s.accessPoints = make(map[string]*accessPoint)
+}
+
+// S3 is a fake implementation of client.S3Api.
+type S3 struct {
+	b *Backend
+}
+
+// S3Control is a fake implementation of client.S3ControlApi.
+type S3Control struct {
+	b *Backend
+}
+
+func noSuchBucket(bucketName string) error {
+	return &types.NoSuchBucket{Message: aws.String(fmt.Sprintf("The specified bucket %s does not exist", bucketName))}
+}
+
+func (f *S3) CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+	b := f.b
+	err := b.begin("CreateBucket")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	if _, ok := b.buckets[bucketName]; ok {
+		return nil, &types.BucketAlreadyOwnedByYou{
+			Message: aws.String("Your previous request to create the named bucket succeeded and you already own it."),
+		}
+	}
+	region := DefaultRegion
+	if params.CreateBucketConfiguration != nil && params.CreateBucketConfiguration.LocationConstraint != "" {
+		region = string(params.CreateBucketConfiguration.LocationConstraint)
+	}
+	b.buckets[bucketName] = &bucket{
+		Name:              bucketName,
+		Region:            region,
+		CreationDate:      time.Now(),
+		PublicAccessBlock: true,
+		Acl:               types.BucketCannedACLPrivate,
+		Objects:           make(map[string][]byte),
+	}
+
+	return &s3.CreateBucketOutput{Location: aws.String("/" + bucketName)}, nil
+}
+
+func (f *S3) DeleteBucket(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error) {
+	b := f.b
+	err := b.begin("DeleteBucket")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	if len(bkt.Objects) > 0 {
+		return nil, apiError("BucketNotEmpty", "The bucket you tried to delete is not empty")
+	}
+	for _, ap := range b.accessPoints {
+		if ap.Bucket == bucketName {
+			return nil, apiError("BucketNotEmpty", "The bucket %s has access points attached", bucketName)
+		}
+	}
+	delete(b.buckets, bucketName)
+
+	return &s3.DeleteBucketOutput{}, nil
+}
+
+func (f *S3) PutBucketVersioning(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
+	b := f.b
+	err := b.begin("PutBucketVersioning")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	if params.VersioningConfiguration != nil {
+		bkt.Versioning = params.VersioningConfiguration.Status
+	}
+
+	return &s3.PutBucketVersioningOutput{}, nil
+}
+
+func (f *S3) PutBucketTagging(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+	b := f.b
+	err := b.begin("PutBucketTagging")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	if params.Tagging != nil {
+		bkt.Tags = append([]types.Tag{}, params.Tagging.TagSet...)
+	}
+
+	return &s3.PutBucketTaggingOutput{}, nil
+}
+
+func (f *S3) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	b := f.b
+	err := b.begin("GetBucketTagging")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	if len(bkt.Tags) == 0 {
+		return nil, apiError("NoSuchTagSet", "The TagSet does not exist")
+	}
+
+	return &s3.GetBucketTaggingOutput{TagSet: append([]types.Tag{}, bkt.Tags...)}, nil
+}
+
+func (f *S3) DeletePublicAccessBlock(ctx context.Context, params *s3.DeletePublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.DeletePublicAccessBlockOutput, error) {
+	b := f.b
+	err := b.begin("DeletePublicAccessBlock")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	bkt.PublicAccessBlock = false
+
+	return &s3.DeletePublicAccessBlockOutput{}, nil
+}
+
+func (f *S3) PutBucketPolicy(ctx context.Context, params *s3.PutBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.PutBucketPolicyOutput, error) {
+	b := f.b
+	err := b.begin("PutBucketPolicy")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	if bkt.PublicAccessBlock {
+		return nil, apiError("AccessDenied", "Access Denied")
+	}
+	bkt.Policy = aws.ToString(params.Policy)
+
+	return &s3.PutBucketPolicyOutput{}, nil
+}
+
+func (f *S3) PutBucketAcl(ctx context.Context, params *s3.PutBucketAclInput, optFns ...func(*s3.Options)) (*s3.PutBucketAclOutput, error) {
+	b := f.b
+	err := b.begin("PutBucketAcl")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	if params.ACL != types.BucketCannedACLPrivate && bkt.PublicAccessBlock {
+		return nil, apiError("AccessDenied", "Access Denied")
+	}
+	bkt.Acl = params.ACL
+
+	return &s3.PutBucketAclOutput{}, nil
+}
+
+// PutObject stores an object.  An IfNoneMatch value of "*" is supported to
+// only store the object if the key does not exist.
+func (f *S3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	var data []byte
+	if params.Body != nil {
+		var err error
+		if data, err = io.ReadAll(params.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	b := f.b
+	err := b.begin("PutObject")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	key := aws.ToString(params.Key)
+	if _, exists := bkt.Objects[key]; exists && aws.ToString(params.IfNoneMatch) == "*" {
+		return nil, apiError("PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+	}
+	bkt.Objects[key] = data
+
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *S3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	b := f.b
+	err := b.begin("GetObject")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	data, ok := bkt.Objects[aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{Message: aws.String("The specified key does not exist.")}
+	}
+
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: aws.Int64(int64(len(data))),
+	}, nil
+}
+
+// ListObjectsV2 lists objects in a bucket in key order.  All matching objects
+// are returned in a single page.
+func (f *S3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	b := f.b
+	err := b.begin("ListObjectsV2")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	var keys []string
+	for key := range bkt.Objects {
+		if strings.HasPrefix(key, aws.ToString(params.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var contents []types.Object
+	for _, key := range keys {
+		contents = append(contents, types.Object{
+			Key:  aws.String(key),
+			Size: aws.Int64(int64(len(bkt.Objects[key]))),
+		})
+	}
+
+	return &s3.ListObjectsV2Output{
+		Contents:    contents,
+		KeyCount:    aws.Int32(int32(len(contents))),
+		IsTruncated: aws.Bool(false),
+	}, nil
+}
+
+// DeleteObject removes an object.  It is not an error if the object does not
+// exist.
+func (f *S3) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	b := f.b
+	err := b.begin("DeleteObject")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	delete(bkt.Objects, aws.ToString(params.Key))
+
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *S3) GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	b := f.b
+	err := b.begin("GetBucketVersioning")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+
+	return &s3.GetBucketVersioningOutput{Status: bkt.Versioning}, nil
+}
+
+func (f *S3) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	b := f.b
+	err := b.begin("ListBuckets")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []types.Bucket
+	for _, name := range sortedKeys(b.buckets) {
+		bkt := b.buckets[name]
+		if !strings.HasPrefix(name, aws.ToString(params.Prefix)) {
+			continue
+		}
+		if params.BucketRegion != nil && *params.BucketRegion != bkt.Region {
+			continue
+		}
+		buckets = append(buckets, types.Bucket{
+			Name:         aws.String(name),
+			BucketRegion: aws.String(bkt.Region),
+			CreationDate: aws.Time(bkt.CreationDate),
+		})
+	}
+
+	return &s3.ListBucketsOutput{Buckets: buckets, Prefix: params.Prefix}, nil
+}
+
+// allUsersUri is the grantee URI used in ACLs to grant access to everyone.
+const allUsersUri = "http://acs.amazonaws.com/groups/global/AllUsers"
+
+func (f *S3) GetBucketAcl(ctx context.Context, params *s3.GetBucketAclInput, optFns ...func(*s3.Options)) (*s3.GetBucketAclOutput, error) {
+	b := f.b
+	err := b.begin("GetBucketAcl")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := aws.ToString(params.Bucket)
+	bkt, ok := b.buckets[bucketName]
+	if !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+
+	// expand the canned ACL into the grants AWS reports for it
+	owner := types.Owner{ID: aws.String(b.AccountId)}
+	grants := []types.Grant{
+		{
+			Grantee:    &types.Grantee{Type: types.TypeCanonicalUser, ID: owner.ID},
+			Permission: types.PermissionFullControl,
+		},
+	}
+	switch bkt.Acl {
+	case types.BucketCannedACLPublicRead:
+		grants = append(grants, types.Grant{
+			Grantee:    &types.Grantee{Type: types.TypeGroup, URI: aws.String(allUsersUri)},
+			Permission: types.PermissionRead,
+		})
+	case types.BucketCannedACLPublicReadWrite:
+		grants = append(grants,
+			types.Grant{
+				Grantee:    &types.Grantee{Type: types.TypeGroup, URI: aws.String(allUsersUri)},
+				Permission: types.PermissionRead,
+			},
+			types.Grant{
+				Grantee:    &types.Grantee{Type: types.TypeGroup, URI: aws.String(allUsersUri)},
+				Permission: types.PermissionWrite,
+			},
+		)
+	}
+
+	return &s3.GetBucketAclOutput{Owner: &owner, Grants: grants}, nil
+}
+
+func (f *S3Control) CreateAccessPoint(ctx context.Context, params *s3control.CreateAccessPointInput, optFns ...func(*s3control.Options)) (*s3control.CreateAccessPointOutput, error) {
+	b := f.b
+	err := b.begin("CreateAccessPoint")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	name := aws.ToString(params.Name)
+	if _, ok := b.accessPoints[name]; ok {
+		return nil, apiError("AccessPointAlreadyOwnedByYou", "Your previous request to create the named accesspoint succeeded and you already own it.")
+	}
+	bucketName := aws.ToString(params.Bucket)
+	if _, ok := b.buckets[bucketName]; !ok {
+		return nil, noSuchBucket(bucketName)
+	}
+	ap := accessPoint{
+		Name:      name,
+		AccountId: aws.ToString(params.AccountId),
+		Bucket:    bucketName,
+	}
+	if params.VpcConfiguration != nil {
+		ap.VpcId = aws.ToString(params.VpcConfiguration.VpcId)
+	}
+	b.accessPoints[name] = &ap
+
+	return &s3control.CreateAccessPointOutput{
+		AccessPointArn: aws.String(b.arn("s3", "accesspoint/"+name)),
+		Alias:          aws.String(fmt.Sprintf("%s-%012x-s3alias", name, b.counter)),
+	}, nil
+}
+
+func (f *S3Control) DeleteAccessPoint(ctx context.Context, params *s3control.DeleteAccessPointInput, optFns ...func(*s3control.Options)) (*s3control.DeleteAccessPointOutput, error) {
+	b := f.b
+	err := b.begin("DeleteAccessPoint")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	name := aws.ToString(params.Name)
+	if _, ok := b.accessPoints[name]; !ok {
+		return nil, apiError("NoSuchAccessPoint", "The specified accesspoint does not exist")
+	}
+	delete(b.accessPoints, name)
+
+	return &s3control.DeleteAccessPointOutput{}, nil
+}
+
+func (f *S3Control) GetAccessPoint(ctx context.Context, params *s3control.GetAccessPointInput, optFns ...func(*s3control.Options)) (*s3control.GetAccessPointOutput, error) {
+	b := f.b
+	err := b.begin("GetAccessPoint")
+	defer b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	name := aws.ToString(params.Name)
+	ap, ok := b.accessPoints[name]
+	if !ok {
+		return nil, apiError("NoSuchAccessPoint", "The specified accesspoint does not exist")
+	}
+	out := s3control.GetAccessPointOutput{
+		Name:          aws.String(ap.Name),
+		Bucket:        aws.String(ap.Bucket),
+		NetworkOrigin: s3control_types.NetworkOriginInternet,
+	}
+	if ap.VpcId != "" {
+		out.NetworkOrigin = s3control_types.NetworkOriginVpc
+		out.VpcConfiguration = &s3control_types.VpcConfiguration{VpcId: aws.String(ap.VpcId)}
+	}
+
+	return &out, nil
+}
@@ -0,0 +1,198 @@
+// Package inventory upgrades resource stack inventories written by older
+// versions of aws-builder to the current schema version.
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnversionedSchemaVersion is the schema version of inventories written before
+// schema versions were added.
+const UnversionedSchemaVersion = 1
+
+// Migration upgrades an inventory for a resource stack from one schema version
+// to the next.
+type Migration struct {
+	// The resource stack the inventory is for, e.g. "eks".
+	Stack string
+
+	// The schema version the migration upgrades from.
+	FromVersion int
+
+	// A description of the changes made by the migration.
+	Description string
+
+	// Migrate changes the inventory, decoded as a JSON object, to the next
+	// schema version.  If nil, only the schema version is changed.
+	Migrate func(inventory map[string]any) error
+}
+
+// migrations contains registered migrations by resource stack and the schema
+// version they upgrade from.
+var migrations = map[string]map[int]Migration{}
+
+// Register adds a migration to the registry.  It is intended to be called from
+// the init function of the package that defines the inventory.  It panics if
+// a migration is already registered for the same resource stack and schema
+// version.
+func Register(migration Migration) {
+	if migrations[migration.Stack] == nil {
+		migrations[migration.Stack] = map[int]Migration{}
+	}
+	if _, exists := migrations[migration.Stack][migration.FromVersion]; exists {
+		panic(fmt.Sprintf(
+			"migration for %s inventory from schema version %d already registered",
+			migration.Stack,
+			migration.FromVersion,
+		))
+	}
+	migrations[migration.Stack][migration.FromVersion] = migration
+}
+
+// Migrations returns the registered migrations for a resource stack in the
+// order they are applied.
+func Migrations(stack string) []Migration {
+	var stackMigrations []Migration
+	for _, migration := range migrations[stack] {
+		stackMigrations = append(stackMigrations, migration)
+	}
+	sort.Slice(stackMigrations, func(i, j int) bool {
+		return stackMigrations[i].FromVersion < stackMigrations[j].FromVersion
+	})
+
+	return stackMigrations
+}
+
+// VersionTooNewError is returned when an inventory was written with a newer
+// schema version than this version of aws-builder supports.
+type VersionTooNewError struct {
+	Stack     string
+	Version   int
+	Supported int
+}
+
+func (e *VersionTooNewError) Error() string {
+	return fmt.Sprintf(
+		"%s inventory has schema version %d but only versions up to %d are supported, upgrade aws-builder to use it",
+		e.Stack,
+		e.Version,
+		e.Supported,
+	)
+}
+
+// Version returns the schema version of a JSON inventory.  Inventories without
+// a schema version have UnversionedSchemaVersion.
+func Version(inventoryBytes []byte) (int, error) {
+	var versioned struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(inventoryBytes, &versioned); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal inventory schema version: %w", err)
+	}
+	if versioned.SchemaVersion == 0 {
+		return UnversionedSchemaVersion, nil
+	}
+
+	return versioned.SchemaVersion, nil
+}
+
+// Migrate upgrades a JSON inventory for a resource stack to the current
+// schema version by applying each registered migration in turn.  The
+// inventory is returned unchanged if it is already at the current version.  A
+// *VersionTooNewError is returned if the inventory's version is newer than
+// the current version.
+func Migrate(stack string, inventoryBytes []byte, currentVersion int) ([]byte, error) {
+	version, err := Version(inventoryBytes)
+	if err != nil {
+		return nil, err
+	}
+	if version > currentVersion {
+		return nil, &VersionTooNewError{
+			Stack:     stack,
+			Version:   version,
+			Supported: currentVersion,
+		}
+	}
+	if version == currentVersion {
+		return inventoryBytes, nil
+	}
+
+	// decode numbers as json.Number so values are unchanged when re-encoded
+	decoder := json.NewDecoder(bytes.NewReader(inventoryBytes))
+	decoder.UseNumber()
+	var inventory map[string]any
+	if err := decoder.Decode(&inventory); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s inventory: %w", stack, err)
+	}
+
+	for ; version < currentVersion; version++ {
+		migration, ok := migrations[stack][version]
+		if !ok {
+			return nil, fmt.Errorf("no migration registered for %s inventory from schema version %d", stack, version)
+		}
+		if migration.Migrate != nil {
+			if err := migration.Migrate(inventory); err != nil {
+				return nil, fmt.Errorf(
+					"failed to migrate %s inventory from schema version %d: %w",
+					stack,
+					version,
+					err,
+				)
+			}
+		}
+		inventory["schemaVersion"] = version + 1
+	}
+
+	migratedBytes, err := json.Marshal(inventory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal migrated %s inventory: %w", stack, err)
+	}
+
+	return migratedBytes, nil
+}
+
+// CheckFields returns an error if a JSON inventory has fields that are not in
+// the inventory type, e.g. *eks.EksInventory, which happens when the inventory
+// is for another type of resource stack.  Only top-level fields are checked.
+func CheckFields(stack string, inventoryBytes []byte, inventory any) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(inventoryBytes, &fields); err != nil {
+		return fmt.Errorf("failed to unmarshal %s inventory: %w", stack, err)
+	}
+
+	inventoryType := reflect.TypeOf(inventory)
+	for inventoryType.Kind() == reflect.Pointer {
+		inventoryType = inventoryType.Elem()
+	}
+	known := map[string]bool{}
+	for i := 0; i < inventoryType.NumField(); i++ {
+		field := inventoryType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		known[name] = field.IsExported() && name != "-"
+	}
+
+	var unknown []string
+	for name := range fields {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf(
+			"inventory has fields that are not in %s inventories, it may be for another resource stack: %s",
+			stack,
+			strings.Join(unknown, ", "),
+		)
+	}
+
+	return nil
+}
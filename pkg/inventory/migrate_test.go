@@ -0,0 +1,120 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testInventory struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	ClusterName   string `json:"clusterName"`
+	NodeCount     int    `json:"nodeCount,omitempty"`
+	internal      string // not in the JSON inventory
+}
+
+func init() {
+	Register(Migration{
+		Stack:       "test",
+		FromVersion: UnversionedSchemaVersion,
+		Description: "add schema version",
+	})
+	Register(Migration{
+		Stack:       "test",
+		FromVersion: 2,
+		Description: "rename cluster to clusterName",
+		Migrate: func(inventory map[string]any) error {
+			inventory["clusterName"] = inventory["cluster"]
+			delete(inventory, "cluster")
+			return nil
+		},
+	})
+	Register(Migration{
+		Stack:       "test-missing",
+		FromVersion: UnversionedSchemaVersion,
+		Description: "add schema version",
+	})
+}
+
+// unmarshal returns a JSON inventory decoded as a JSON object.
+func unmarshal(t *testing.T, inventoryBytes []byte) map[string]any {
+	t.Helper()
+
+	var inventory map[string]any
+	if err := json.Unmarshal(inventoryBytes, &inventory); err != nil {
+		t.Fatalf("failed to unmarshal inventory: %v", err)
+	}
+
+	return inventory
+}
+
+func TestMigrate(t *testing.T) {
+	migrated, err := Migrate("test", []byte(`{"cluster":"test-cluster"}`), 3)
+	if err != nil {
+		t.Fatalf("failed to migrate inventory: %v", err)
+	}
+	inventory := unmarshal(t, migrated)
+	if inventory["schemaVersion"] != float64(3) {
+		t.Errorf("expected schema version 3, got %v", inventory["schemaVersion"])
+	}
+	if inventory["clusterName"] != "test-cluster" {
+		t.Errorf("expected cluster to be renamed to clusterName, got %v", inventory)
+	}
+	if _, ok := inventory["cluster"]; ok {
+		t.Errorf("expected cluster to be removed, got %v", inventory)
+	}
+
+	// inventories at the current version are unchanged
+	current := []byte(`{"schemaVersion": 3, "clusterName": "test-cluster"}`)
+	migrated, err = Migrate("test", current, 3)
+	if err != nil {
+		t.Fatalf("failed to migrate inventory: %v", err)
+	}
+	if string(migrated) != string(current) {
+		t.Errorf("expected current inventory to be unchanged, got %s", migrated)
+	}
+}
+
+func TestMigratePreservesNumbers(t *testing.T) {
+	migrated, err := Migrate("test", []byte(`{"cluster":"c","nodeCount":12345678901234567890,"ratio":1.50}`), 3)
+	if err != nil {
+		t.Fatalf("failed to migrate inventory: %v", err)
+	}
+	for _, number := range []string{`"nodeCount":12345678901234567890`, `"ratio":1.50`} {
+		if !strings.Contains(string(migrated), number) {
+			t.Errorf("expected %s to be unchanged, got %s", number, migrated)
+		}
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	var tooNewErr *VersionTooNewError
+	_, err := Migrate("test", []byte(`{"schemaVersion":4}`), 3)
+	if !errors.As(err, &tooNewErr) {
+		t.Fatalf("expected VersionTooNewError, got %v", err)
+	}
+	if tooNewErr.Stack != "test" || tooNewErr.Version != 4 || tooNewErr.Supported != 3 {
+		t.Errorf("expected version 4 of test inventory with 3 supported, got %+v", tooNewErr)
+	}
+
+	_, err = Migrate("test-missing", []byte(`{}`), 3)
+	if err == nil || !strings.Contains(err.Error(), "no migration registered for test-missing inventory from schema version 2") {
+		t.Errorf("expected missing migration error, got %v", err)
+	}
+
+	if _, err := Migrate("test", []byte(`[]`), 3); err == nil {
+		t.Error("expected error for inventory that is not a JSON object")
+	}
+}
+
+func TestCheckFields(t *testing.T) {
+	if err := CheckFields("test", []byte(`{"schemaVersion":3,"clusterName":"c","nodeCount":1}`), &testInventory{}); err != nil {
+		t.Errorf("expected inventory fields to be known, got %v", err)
+	}
+
+	err := CheckFields("test", []byte(`{"schemaVersion":3,"dbInstanceId":"db","internal":"x"}`), &testInventory{})
+	if err == nil || !strings.Contains(err.Error(), "dbInstanceId, internal") {
+		t.Errorf("expected error listing unknown fields, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package orphan contains the report returned when searching for resources
+// that carry a resource stack's tags or names but are not recorded in its
+// inventory, such as those left behind by a failed create.
+package orphan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// HoursPerMonth is the number of hours in a month used to estimate monthly
+// costs.
+const HoursPerMonth = 730
+
+// hourlyCosts contains the approximate on-demand price in USD per hour in
+// us-east-1 of resources that are billed for as long as they exist regardless
+// of use.  Resources that are not listed are free or billed by use, e.g. a
+// node group by its instances or an S3 bucket by the objects stored in it, and
+// are estimated at zero.
+var hourlyCosts = map[string]float64{
+	"eks-cluster": 0.10,
+	"nat-gateway": 0.045,
+	"elastic-ip":  0.005,
+}
+
+// Status is the state of a single orphaned resource.
+type Status string
+
+const (
+	// StatusOrphaned indicates the resource exists but is not in inventory.
+	StatusOrphaned Status = "orphaned"
+
+	// StatusDeleted indicates the orphaned resource was deleted.
+	StatusDeleted Status = "deleted"
+
+	// StatusFailed indicates deleting the orphaned resource failed.
+	StatusFailed Status = "failed"
+)
+
+// Orphan is a resource with the resource stack's tags or names that is not in
+// its inventory.
+type Orphan struct {
+	Kind string `json:"kind"`
+	Id   string `json:"id"`
+
+	// The resource the orphan belongs to, if any, e.g. the VPC of a subnet
+	// or the cluster of a node group.
+	Parent string `json:"parent,omitempty"`
+
+	// The estimated cost in USD of keeping the resource for a month.
+	MonthlyCost float64 `json:"monthlyCost"`
+
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Deleted records that the orphan was deleted.
+func (o *Orphan) Deleted() {
+	o.Status = StatusDeleted
+	o.Error = ""
+}
+
+// Failed records that deleting the orphan failed and returns the error.
+func (o *Orphan) Failed(err error) error {
+	o.Status = StatusFailed
+	o.Error = err.Error()
+
+	return err
+}
+
+// Report contains the orphaned resources of a resource stack.
+type Report struct {
+	Stack  string `json:"stack"`
+	Region string `json:"region"`
+
+	// Whether there was no inventory to compare resources to, in which case
+	// every resource with the resource stack's tags or names is reported.
+	Broad bool `json:"broad"`
+
+	Orphans []*Orphan `json:"orphans"`
+}
+
+// NewReport returns an empty report for a resource stack.
+func NewReport(stack, region string, broad bool) *Report {
+	return &Report{
+		Stack:   stack,
+		Region:  region,
+		Broad:   broad,
+		Orphans: []*Orphan{},
+	}
+}
+
+// Add adds an orphaned resource with its estimated monthly cost and returns
+// it.
+func (r *Report) Add(kind, id, parent string) *Orphan {
+	orphan := Orphan{
+		Kind:        kind,
+		Id:          id,
+		Parent:      parent,
+		MonthlyCost: hourlyCosts[kind] * HoursPerMonth,
+		Status:      StatusOrphaned,
+	}
+	r.Orphans = append(r.Orphans, &orphan)
+
+	return &orphan
+}
+
+// Kind returns the orphans of a kind that have not been deleted.
+func (r *Report) Kind(kind string) []*Orphan {
+	var orphans []*Orphan
+	for _, orphan := range r.Orphans {
+		if orphan.Kind == kind && orphan.Status != StatusDeleted {
+			orphans = append(orphans, orphan)
+		}
+	}
+
+	return orphans
+}
+
+// Count returns the number of orphans with the given status.
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, orphan := range r.Orphans {
+		if orphan.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// MonthlyCost returns the estimated monthly cost of the orphans that have not
+// been deleted.
+func (r *Report) MonthlyCost() float64 {
+	var cost float64
+	for _, orphan := range r.Orphans {
+		if orphan.Status != StatusDeleted {
+			cost += orphan.MonthlyCost
+		}
+	}
+
+	return cost
+}
+
+// Err returns an error joining the error of every orphan that failed to be
+// deleted, or nil if there are none.
+func (r *Report) Err() error {
+	var errs []error
+	for _, orphan := range r.Orphans {
+		if orphan.Status == StatusFailed {
+			errs = append(errs, fmt.Errorf("%s %s: %s", orphan.Kind, orphan.Id, orphan.Error))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Summary returns a one line summary of the number of orphans per status and
+// the estimated monthly cost of those remaining.
+func (r *Report) Summary() string {
+	return fmt.Sprintf(
+		"%d orphaned, %d deleted, %d failed, estimated cost $%.2f/month",
+		r.Count(StatusOrphaned),
+		r.Count(StatusDeleted),
+		r.Count(StatusFailed),
+		r.MonthlyCost(),
+	)
+}
+
+// WriteText writes the report as a human readable table.
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Orphaned resources of %s resource stack in region %s:\n\n", r.Stack, r.Region)
+	if r.Broad {
+		fmt.Fprint(w, "No inventory was found so every resource with the resource stack's tags is included.\n\n")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tKIND\tID\tPARENT\tMONTHLY COST\tERROR")
+	for _, orphan := range r.Orphans {
+		cost := "-"
+		if orphan.MonthlyCost > 0 {
+			cost = fmt.Sprintf("$%.2f", orphan.MonthlyCost)
+		}
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			orphan.Status,
+			orphan.Kind,
+			orphan.Id,
+			valueOrDash(orphan.Parent),
+			cost,
+			orphan.Error,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write orphan report: %w", err)
+	}
+	fmt.Fprintf(w, "\n%s\n", r.Summary())
+
+	return nil
+}
+
+// WriteJson writes the report as indented JSON.
+func (r *Report) WriteJson(w io.Writer) error {
+	reportJson, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal orphan report to JSON: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(reportJson)); err != nil {
+		return fmt.Errorf("failed to write orphan report: %w", err)
+	}
+
+	return nil
+}
+
+// valueOrDash returns the value or a dash if it is empty so table columns
+// stay aligned.
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
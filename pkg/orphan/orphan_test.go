@@ -0,0 +1,84 @@
+package orphan
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// testReport returns a report with a deleted, a failed and a remaining
+// orphan.
+func testReport() *Report {
+	report := NewReport("eks", "us-east-1", false)
+	report.Add("nat-gateway", "nat-1", "vpc-1").Deleted()
+	report.Add("nat-gateway", "nat-2", "vpc-1").Failed(errors.New("NAT gateway in use"))
+	report.Add("elastic-ip", "eipalloc-1", "")
+	report.Add("iam-policy", "arn:aws:iam::123456789012:policy/test/policy", "")
+
+	return report
+}
+
+func TestReportKind(t *testing.T) {
+	report := testReport()
+
+	natGateways := report.Kind("nat-gateway")
+	if len(natGateways) != 1 || natGateways[0].Id != "nat-2" {
+		t.Errorf("expected only the NAT gateway that was not deleted, got %+v", natGateways)
+	}
+	if orphans := report.Kind("vpc"); len(orphans) != 0 {
+		t.Errorf("expected no VPC orphans, got %+v", orphans)
+	}
+}
+
+func TestReportErr(t *testing.T) {
+	report := NewReport("eks", "us-east-1", false)
+	report.Add("nat-gateway", "nat-1", "").Deleted()
+	if err := report.Err(); err != nil {
+		t.Errorf("expected no error without failures, got %v", err)
+	}
+
+	report = testReport()
+	report.Add("subnet", "subnet-1", "vpc-1").Failed(errors.New("subnet has dependencies"))
+	err := report.Err()
+	if err == nil {
+		t.Fatal("expected an error for the failed orphans")
+	}
+	for _, message := range []string{
+		"nat-gateway nat-2: NAT gateway in use",
+		"subnet subnet-1: subnet has dependencies",
+	} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("expected error to contain %q, got %v", message, err)
+		}
+	}
+
+	// a failed orphan that is deleted on a later attempt is no longer an
+	// error
+	for _, o := range report.Orphans {
+		if o.Status == StatusFailed {
+			o.Deleted()
+		}
+	}
+	if err := report.Err(); err != nil {
+		t.Errorf("expected no error once failed orphans are deleted, got %v", err)
+	}
+}
+
+func TestReportMonthlyCost(t *testing.T) {
+	report := testReport()
+
+	// the deleted NAT gateway is not included and the policy is free
+	expected := (0.045 + 0.005) * HoursPerMonth
+	if cost := report.MonthlyCost(); math.Abs(cost-expected) > 0.001 {
+		t.Errorf("expected monthly cost %.2f, got %.2f", expected, cost)
+	}
+	for _, o := range report.Orphans {
+		if o.Kind == "iam-policy" && o.MonthlyCost != 0 {
+			t.Errorf("expected IAM policy to be free, got %.2f", o.MonthlyCost)
+		}
+	}
+	if summary := report.Summary(); summary != "2 orphaned, 1 deleted, 1 failed, estimated cost $36.50/month" {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+}
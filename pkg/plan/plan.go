@@ -0,0 +1,135 @@
+// Package plan contains the change set returned when planning the creation of
+// a resource stack without making any changes.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Action is the action that creating a resource stack would take for a
+// resource.
+type Action string
+
+const (
+	// ActionCreate indicates the resource does not exist and would be created.
+	ActionCreate Action = "create"
+
+	// ActionReuse indicates the resource was found in the input inventory and
+	// would be used as-is.
+	ActionReuse Action = "reuse"
+
+	// ActionAdopt indicates the resource was not in the inventory but an
+	// existing resource with matching tags or name was found and would be
+	// added to the inventory.
+	ActionAdopt Action = "adopt"
+
+	// ActionSkip indicates the resource is optional and was not requested in
+	// the config.
+	ActionSkip Action = "skip-not-requested"
+)
+
+// ResourceChange is the planned action for a single resource.
+type ResourceChange struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name,omitempty"`
+	Id     string `json:"id,omitempty"`
+	Action Action `json:"action"`
+}
+
+// ChangeSet contains the planned actions for all resources in a resource
+// stack in the order they would be created.
+type ChangeSet struct {
+	Stack   string           `json:"stack"`
+	Region  string           `json:"region"`
+	Changes []ResourceChange `json:"changes"`
+}
+
+// NewChangeSet returns an empty change set for a resource stack.
+func NewChangeSet(stack, region string) *ChangeSet {
+	return &ChangeSet{
+		Stack:   stack,
+		Region:  region,
+		Changes: []ResourceChange{},
+	}
+}
+
+// Add appends a planned action for a resource to the change set.
+func (cs *ChangeSet) Add(kind, name, id string, action Action) {
+	cs.Changes = append(cs.Changes, ResourceChange{
+		Kind:   kind,
+		Name:   name,
+		Id:     id,
+		Action: action,
+	})
+}
+
+// Count returns the number of resources with the given action.
+func (cs *ChangeSet) Count(action Action) int {
+	count := 0
+	for _, change := range cs.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Summary returns a one line summary of the number of resources per action.
+func (cs *ChangeSet) Summary() string {
+	return fmt.Sprintf(
+		"%d to create, %d to reuse, %d to adopt, %d not requested",
+		cs.Count(ActionCreate),
+		cs.Count(ActionReuse),
+		cs.Count(ActionAdopt),
+		cs.Count(ActionSkip),
+	)
+}
+
+// WriteText writes the change set as a human readable table.
+func (cs *ChangeSet) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Plan for %s resource stack in region %s:\n\n", cs.Stack, cs.Region)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tKIND\tNAME\tID")
+	for _, change := range cs.Changes {
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\n",
+			change.Action,
+			change.Kind,
+			valueOrDash(change.Name),
+			valueOrDash(change.Id),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write change set: %w", err)
+	}
+	fmt.Fprintf(w, "\n%s\n", cs.Summary())
+
+	return nil
+}
+
+// WriteJson writes the change set as indented JSON.
+func (cs *ChangeSet) WriteJson(w io.Writer) error {
+	changeSetJson, err := json.MarshalIndent(cs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal change set to JSON: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(changeSetJson)); err != nil {
+		return fmt.Errorf("failed to write change set: %w", err)
+	}
+
+	return nil
+}
+
+// valueOrDash returns a dash for empty values so table columns stay aligned.
+func valueOrDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+
+	return value
+}
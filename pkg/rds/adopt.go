@@ -0,0 +1,109 @@
+package rds
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// AdoptRdsResourceStack records an existing RDS instance that was not created
+// by aws-builder, with its subnet group and security group, in the inventory
+// as adopted.  The inventory has a single security group so an instance with
+// more than one is reported as ambiguous and none are recorded.  If applyTags
+// is true, the Name tag and custom tags are added to the adopted resources.
+func (c *RdsClient) AdoptRdsResourceStack(
+	rdsInstanceId string,
+	tags map[string]string,
+	applyTags bool,
+	inventory *RdsInventory,
+) (*discovery.Report, error) {
+	inventory.Region = c.AwsConfig.Region
+	report := discovery.NewReport("rds", inventory.Region)
+
+	// RDS Instance
+	instance, err := c.getRdsInstance(rdsInstanceId)
+	if errors.Is(err, util.ErrResourceNotFound) {
+		return nil, fmt.Errorf("RDS instance %s not found in region %s", rdsInstanceId, inventory.Region)
+	}
+	if err != nil {
+		return nil, err
+	}
+	inventory.RdsInstanceId = report.Add("rds-instance", rdsInstanceId, *instance.DBInstanceIdentifier)
+	inventory.Adopted = append(inventory.Adopted, inventory.RdsInstanceId)
+	if instance.Endpoint != nil {
+		inventory.RdsInstanceEndpoint = aws.ToString(instance.Endpoint.Address)
+	}
+	resourceArns := []string{aws.ToString(instance.DBInstanceArn)}
+
+	// Subnet Group
+	if instance.DBSubnetGroup != nil && instance.DBSubnetGroup.DBSubnetGroupName != nil {
+		subnetGroupName := *instance.DBSubnetGroup.DBSubnetGroupName
+		inventory.SubnetGroupName = report.Add("rds-subnet-group", subnetGroupName, subnetGroupName)
+		inventory.Adopted = append(inventory.Adopted, subnetGroupName)
+		resp, err := c.GetRdsApi().DescribeDBSubnetGroups(c.Context, &aws_rds.DescribeDBSubnetGroupsInput{
+			DBSubnetGroupName: &subnetGroupName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe subnet group %s: %w", subnetGroupName, err)
+		}
+		for _, subnetGroup := range resp.DBSubnetGroups {
+			resourceArns = append(resourceArns, aws.ToString(subnetGroup.DBSubnetGroupArn))
+		}
+	} else {
+		report.Add("rds-subnet-group", "")
+	}
+
+	// Security Group
+	var securityGroupIds []string
+	for _, securityGroup := range instance.VpcSecurityGroups {
+		securityGroupIds = append(securityGroupIds, aws.ToString(securityGroup.VpcSecurityGroupId))
+	}
+	inventory.SecurityGroupId = report.Add("security-group", rdsInstanceId, securityGroupIds...)
+	if inventory.SecurityGroupId != "" {
+		inventory.Adopted = append(inventory.Adopted, inventory.SecurityGroupId)
+	}
+
+	if applyTags {
+		rdsTags := CreateRdsTags(rdsInstanceId, tags)
+		for _, resourceArn := range resourceArns {
+			if _, err := c.GetRdsApi().AddTagsToResource(c.Context, &aws_rds.AddTagsToResourceInput{
+				ResourceName: aws.String(resourceArn),
+				Tags:         *rdsTags,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to tag RDS resource %s: %w", resourceArn, err)
+			}
+		}
+		if inventory.SecurityGroupId != "" {
+			ec2Tags := ec2.CreateEc2Tags(rdsInstanceId, tags)
+			if _, err := c.GetEc2Api().CreateTags(c.Context, &aws_ec2.CreateTagsInput{
+				Resources: []string{inventory.SecurityGroupId},
+				Tags:      *ec2Tags,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to tag security group %s: %w", inventory.SecurityGroupId, err)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// keepAdopted returns true if the resource was adopted and adopted resources
+// are not being deleted, in which case an event is sent reporting the
+// resource is kept.
+func (c *RdsClient) keepAdopted(inventory *RdsInventory, kind string, id string) bool {
+	if c.DeleteAdopted || id == "" || !slices.Contains(inventory.Adopted, id) {
+		return false
+	}
+	c.sendEvent(kind, client.PhaseKeptAdopted, fmt.Sprintf("%s %s was adopted and is kept", kind, id), id)
+
+	return true
+}
@@ -0,0 +1,80 @@
+package rds
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+func TestAdoptRdsResourceStack(t *testing.T) {
+	testCases := []struct {
+		name          string
+		deleteAdopted bool
+	}{
+		{name: "adopted resources kept", deleteAdopted: false},
+		{name: "adopted resources deleted", deleteAdopted: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			backend := fake.NewBackend(fake.DefaultRegion)
+			network, err := backend.CreateNetwork("10.0.0.0/16", "10.0.1.0/24", "10.0.2.0/24")
+			if err != nil {
+				t.Fatalf("failed to create network: %v", err)
+			}
+			database, err := backend.CreateDatabase("adopted-db-0", network)
+			if err != nil {
+				t.Fatalf("failed to create database: %v", err)
+			}
+			rdsClient := RdsClient{ResourceClient: *backend.ResourceClient()}
+
+			var inventory RdsInventory
+			report, err := rdsClient.AdoptRdsResourceStack(database.InstanceId, map[string]string{"App": "test"}, true, &inventory)
+			if err != nil {
+				t.Fatalf("failed to adopt resource stack: %v", err)
+			}
+			if report.HasAmbiguity() {
+				t.Errorf("expected no ambiguous resources, got %s", report.Summary())
+			}
+			expected := []string{database.InstanceId, database.SubnetGroupName, database.SecurityGroupId}
+			if !reflect.DeepEqual(inventory.Adopted, expected) {
+				t.Errorf("expected %v to be adopted, got %v", expected, inventory.Adopted)
+			}
+			if inventory.RdsInstanceId != database.InstanceId ||
+				inventory.SubnetGroupName != database.SubnetGroupName ||
+				inventory.SecurityGroupId != database.SecurityGroupId {
+				t.Errorf("expected adopted resources in inventory, got %+v", inventory)
+			}
+			adopted := backend.Resources()
+
+			rdsClient.DeleteAdopted = testCase.deleteAdopted
+			if err := rdsClient.DeleteRdsResourceStack(&inventory); err != nil {
+				t.Fatalf("failed to delete resource stack: %v", err)
+			}
+
+			resources := backend.Resources()
+			if !testCase.deleteAdopted {
+				if !reflect.DeepEqual(resources, adopted) {
+					t.Errorf("expected adopted resources %v to be kept, found %v", adopted, resources)
+				}
+				if inventory.RdsInstanceId == "" || inventory.SubnetGroupName == "" || inventory.SecurityGroupId == "" {
+					t.Errorf("expected adopted resources to stay in inventory, got %+v", inventory)
+				}
+				return
+			}
+			for _, kind := range []string{"rds-instance", "rds-subnet-group"} {
+				if len(resources[kind]) != 0 {
+					t.Errorf("expected %s to be deleted, found %v", kind, resources[kind])
+				}
+			}
+			if slices.Contains(resources["security-group"], database.SecurityGroupId) {
+				t.Errorf("expected security group %s to be deleted", database.SecurityGroupId)
+			}
+			if inventory.RdsInstanceId != "" || inventory.SubnetGroupName != "" || inventory.SecurityGroupId != "" {
+				t.Errorf("expected deleted resources to be removed from inventory, got %+v", inventory)
+			}
+		})
+	}
+}
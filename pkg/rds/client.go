@@ -28,3 +28,37 @@ func (c *RdsClient) GetContext() context.Context {
 func (c *RdsClient) GetAwsConfig() *aws.Config {
 	return c.AwsConfig
 }
+
+// sendEvent sends a progress event for resources in the RDS resource stack.
+// The message is the text rendering of the event.
+func (c *RdsClient) sendEvent(kind string, phase client.Phase, message string, ids ...string) {
+	c.SendEvent(client.Event{
+		Stack:   "rds",
+		Kind:    kind,
+		Ids:     ids,
+		Phase:   phase,
+		Message: message,
+	})
+}
+
+// sendFailed sends a failed event for a resource in the RDS resource stack
+// and returns the error.
+func (c *RdsClient) sendFailed(kind string, err error) error {
+	c.SendEvent(client.Event{
+		Stack: "rds",
+		Kind:  kind,
+		Phase: client.PhaseFailed,
+		Error: err.Error(),
+	})
+
+	return err
+}
+
+// sendInventory notifies the observer and sends on the inventory channel, if
+// present, the latest inventory.
+func (c *RdsClient) sendInventory(inventory *RdsInventory) {
+	c.SendInventory(*inventory)
+	if c.InventoryChan != nil {
+		*c.InventoryChan <- client.CopyInventory(*inventory)
+	}
+}
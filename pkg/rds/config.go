@@ -1,10 +1,11 @@
 package rds
 
 import (
-	"fmt"
-	"io/ioutil"
+	"maps"
+	"slices"
 
-	"gopkg.in/yaml.v3"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/waiter"
 )
 
 // RdsConfig contains the configurable parameters for an RDS instance.
@@ -25,19 +26,102 @@ type RdsConfig struct {
 	DbUser                string            `yaml:"dbUser"`
 	DbUserPassword        string            `yaml:"dbUserPassword"`
 	SourceSecurityGroupId string            `yaml:"sourceSecurityGroupId"`
+	Waiters               waiter.Configs    `yaml:"waiters"`
 }
 
-// LoadRdsConfig loads an RDS config from a config file and returns the
-// RdsConfig object.
-func LoadRdsConfig(configFile string) (*RdsConfig, error) {
-	configYaml, err := ioutil.ReadFile(configFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+const (
+	// DefaultClass is the DB instance class used if none is provided.
+	DefaultClass = "db.t3.small"
+
+	// DefaultStorageGb is the storage allocated to the DB instance if none is
+	// provided.
+	DefaultStorageGb = int32(20)
+)
+
+// EnginePorts contains the RDS engines that can be used and the port each one
+// listens on.  The DB instance is created without a port so it always listens
+// on the engine's default port.
+var EnginePorts = map[string]int32{
+	"mariadb":        3306,
+	"mysql":          3306,
+	"oracle-ee":      1521,
+	"oracle-ee-cdb":  1521,
+	"oracle-se2":     1521,
+	"oracle-se2-cdb": 1521,
+	"postgres":       5432,
+	"sqlserver-ee":   1433,
+	"sqlserver-ex":   1433,
+	"sqlserver-se":   1433,
+	"sqlserver-web":  1433,
+}
+
+// Engines returns the RDS engines that can be used in sorted order.
+func Engines() []string {
+	return slices.Sorted(maps.Keys(EnginePorts))
+}
+
+// LoadRdsConfig loads an RDS config from one or more YAML or JSON config
+// files and returns the RdsConfig object.  Values in later files override
+// values in earlier files.  Values not set in any file are set to the defaults
+// from NewRdsConfig and SetDefaults.  See configfile.Load for details.
+func LoadRdsConfig(configFiles ...string) (*RdsConfig, error) {
+	return LoadRdsConfigWithOutputs(nil, configFiles...)
+}
+
+// LoadRdsConfigWithOutputs loads an RDS config like LoadRdsConfig and also
+// replaces ${stack.output} references with the outputs of other resource
+// stacks.  See configfile.LoadWithOutputs for details.
+func LoadRdsConfigWithOutputs(outputs configfile.Outputs, configFiles ...string) (*RdsConfig, error) {
+	rdsConfig := NewRdsConfig()
+	if err := configfile.LoadWithOutputs(rdsConfig, outputs, configFiles...); err != nil {
+		return nil, err
+	}
+	rdsConfig.SetDefaults()
+
+	return rdsConfig, nil
+}
+
+// NewRdsConfig returns an RdsConfig with default values set.
+func NewRdsConfig() *RdsConfig {
+	return &RdsConfig{
+		Class:     DefaultClass,
+		StorageGb: DefaultStorageGb,
+	}
+}
+
+// SetDefaults sets defaults that depend on other values in the config:
+//   - dbPort defaults to the port the engine listens on
+//   - waiters default to the default waiter for each resource
+//
+// Values that are already set are not changed.
+func (c *RdsConfig) SetDefaults() {
+	if c.DbPort == 0 {
+		c.DbPort = EnginePorts[c.Engine]
 	}
-	var rdsConfig RdsConfig
-	if err := yaml.Unmarshal(configYaml, &rdsConfig); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal yaml from config file: %w", err)
+	c.Waiters = c.Waiters.Merge(defaultWaiters())
+}
+
+// GetRegion returns the region resources are created in.
+func (c *RdsConfig) GetRegion() string {
+	return c.Region
+}
+
+// SetRegion sets the region resources are created in.
+func (c *RdsConfig) SetRegion(region string) {
+	c.Region = region
+}
+
+// Redact replaces the DB user password so the config can be printed.
+func (c *RdsConfig) Redact() {
+	if c.DbUserPassword != "" {
+		c.DbUserPassword = "<redacted>"
 	}
+}
 
-	return &rdsConfig, nil
+// defaultWaiters returns the default waiter for each resource in an RDS
+// resource stack.
+func defaultWaiters() waiter.Configs {
+	return waiter.Configs{
+		RdsInstanceWaiter: DefaultRdsInstanceWaiter,
+	}
 }
@@ -0,0 +1,73 @@
+package rds
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nukleros/aws-builder/pkg/waiter"
+)
+
+func TestLoadRdsConfigDefaults(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected func(resourceConfig *RdsConfig)
+	}{
+		{
+			name:    "defaults",
+			content: "engine: postgres\n",
+			expected: func(resourceConfig *RdsConfig) {
+				resourceConfig.Engine = "postgres"
+				resourceConfig.DbPort = 5432
+				resourceConfig.Waiters = defaultWaiters()
+			},
+		},
+		{
+			name: "file values",
+			content: `
+engine: mysql
+class: db.r5.large
+storageGb: 100
+dbPort: 3307
+waiters:
+  rds-instance:
+    interval: 1m
+`,
+			expected: func(resourceConfig *RdsConfig) {
+				resourceConfig.Engine = "mysql"
+				resourceConfig.Class = "db.r5.large"
+				resourceConfig.StorageGb = 100
+				resourceConfig.DbPort = 3307
+				resourceConfig.Waiters = waiter.Configs{
+					RdsInstanceWaiter: {
+						Timeout:  DefaultRdsInstanceWaiter.Timeout,
+						Interval: time.Minute,
+						Backoff:  DefaultRdsInstanceWaiter.Backoff,
+					},
+				}
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "rds-config.yaml")
+			if err := os.WriteFile(configFile, []byte(testCase.content), 0644); err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+
+			resourceConfig, err := LoadRdsConfig(configFile)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			expected := NewRdsConfig()
+			testCase.expected(expected)
+			if !reflect.DeepEqual(resourceConfig, expected) {
+				t.Errorf("expected config\n%+v\ngot\n%+v", expected, resourceConfig)
+			}
+		})
+	}
+}
@@ -8,17 +8,25 @@ import (
 	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/rds/types"
 	"github.com/nukleros/aws-builder/pkg/util"
+	"github.com/nukleros/aws-builder/pkg/waiter"
 )
 
 type RdsCondition string
 
 const (
-	RdsConditionCreated     RdsCondition = "RdsCreated"
-	RdsConditionDeleted     RdsCondition = "RdsDelete"
-	RdsCheckIntervalSeconds              = 15 // check cluster status every 15 seconds
-	RdsCheckMaxCount                     = 60 // check 60 time before giving up (15 minutes)
+	RdsConditionCreated RdsCondition = "RdsCreated"
+	RdsConditionDeleted RdsCondition = "RdsDelete"
+	RdsInstanceWaiter                = "rds-instance"
 )
 
+// DefaultRdsInstanceWaiter checks RDS instance status every 15 seconds for up
+// to 15 minutes.
+var DefaultRdsInstanceWaiter = waiter.Config{
+	Timeout:  15 * time.Minute,
+	Interval: 15 * time.Second,
+	Backoff:  1,
+}
+
 // CreateRdsInstance creates a new RDS instance.  If an RDS instance with
 // matching name and tags already exists, that DB instance will be returned and
 // used in the resource stack to ensure idempotency.
@@ -36,7 +44,7 @@ func (c *RdsClient) CreateRdsInstance(
 	securityGroupId string,
 	subnetGroupName string,
 ) (*types.DBInstance, error) {
-	svc := aws_rds.NewFromConfig(*c.AwsConfig)
+	svc := c.GetRdsApi()
 
 	copyTagsToSnapshot := true
 	//managePassword := true
@@ -84,13 +92,14 @@ func (c *RdsClient) CreateRdsInstance(
 	return rdsResp.DBInstance, nil
 }
 
-// DeleteRdsInstance removes an existing RDS instance.
+// DeleteRdsInstance removes an existing RDS instance.  If the instance is not
+// found it returns without error.
 func (c *RdsClient) DeleteRdsInstance(rdsInstanceId string) error {
 	if rdsInstanceId == "" {
 		return nil
 	}
 
-	svc := aws_rds.NewFromConfig(*c.AwsConfig)
+	svc := c.GetRdsApi()
 
 	skipFinalSnapshot := true
 	deleteRdsInput := aws_rds.DeleteDBInstanceInput{
@@ -99,6 +108,10 @@ func (c *RdsClient) DeleteRdsInstance(rdsInstanceId string) error {
 	}
 	_, err := svc.DeleteDBInstance(c.Context, &deleteRdsInput)
 	if err != nil {
+		var notFoundErr *types.DBInstanceNotFoundFault
+		if errors.As(err, &notFoundErr) {
+			return nil
+		}
 		return fmt.Errorf("failed to delete RDS instance %s: %w", rdsInstanceId, err)
 	}
 
@@ -108,8 +121,8 @@ func (c *RdsClient) DeleteRdsInstance(rdsInstanceId string) error {
 // WaitForRdsInstance waits for an instance to reach the desired condition.  If
 // waiting for creation, it returns when the DB instance is available and
 // returns its endpoint.  If waiting for deletion, it returns when the DB
-// instance is not found.  In either case, it times out after 10 min if the
-// desired condition is not reached.
+// instance is not found.  In either case, it times out after the
+// RdsInstanceWaiter timeout if the desired condition is not reached.
 func (c *RdsClient) WaitForRdsInstance(
 	rdsInstanceId string,
 	rdsCondition RdsCondition,
@@ -120,40 +133,38 @@ func (c *RdsClient) WaitForRdsInstance(
 		return dbEndpoint, nil
 	}
 
-	rdsCheckCount := 0
-	for {
-		rdsCheckCount += 1
-		if rdsCheckCount > RdsCheckMaxCount {
-			return dbEndpoint, errors.New("RDS instance check timed out waiting for the desired condition")
-		}
+	err := waiter.Wait(
+		c.Context,
+		c.Waiters.Get(RdsInstanceWaiter, DefaultRdsInstanceWaiter),
+		fmt.Sprintf("RDS instance with identifier %s", rdsInstanceId),
+		func() (bool, string, error) {
+			rdsInstance, err := c.getRdsInstance(rdsInstanceId)
+			if err != nil {
+				if errors.Is(err, util.ErrResourceNotFound) && rdsCondition == RdsConditionDeleted {
+					// RDS instance was not found and we're waiting for deletion so
+					// condition is met
+					return true, "", nil
+				}
+				return false, "", fmt.Errorf("failed to get RDS instance status with identifier %s: %w", rdsInstanceId, err)
+			}
 
-		rdsInstance, err := c.getRdsInstance(rdsInstanceId)
-		if err != nil {
-			if errors.Is(err, util.ErrResourceNotFound) && rdsCondition == RdsConditionDeleted {
-				// RDS instance was not found and we're waiting for deletion so
+			if *rdsInstance.DBInstanceStatus == "available" && rdsCondition == RdsConditionCreated {
+				dbEndpoint = *rdsInstance.Endpoint.Address
+				// RDS instance is available and we're waiting for creation so
 				// condition is met
-				break
-			} else {
-				return dbEndpoint, fmt.Errorf("failed to get RDS instance status with identifier %s: %w", rdsInstanceId, err)
+				return true, "", nil
 			}
-		}
 
-		if *rdsInstance.DBInstanceStatus == "available" && rdsCondition == RdsConditionCreated {
-			dbEndpoint = *rdsInstance.Endpoint.Address
-			// RDS instance is available and we're waiting for creation so
-			// condition is met
-			break
-		}
-
-		time.Sleep(time.Second * time.Duration(RdsCheckIntervalSeconds))
-	}
+			return false, *rdsInstance.DBInstanceStatus, nil
+		},
+	)
 
-	return dbEndpoint, nil
+	return dbEndpoint, err
 }
 
 // getRdsInstance retrieves an RDS DBInstance.
 func (c *RdsClient) getRdsInstance(rdsInstanceId string) (*types.DBInstance, error) {
-	svc := aws_rds.NewFromConfig(*c.AwsConfig)
+	svc := c.GetRdsApi()
 
 	describeRdsInput := aws_rds.DescribeDBInstancesInput{
 		DBInstanceIdentifier: &rdsInstanceId,
@@ -184,7 +195,7 @@ func (c *RdsClient) checkRdsInstanceUniqueTags(
 	instanceName string,
 	tags *[]types.Tag,
 ) (*types.DBInstance, bool, error) {
-	svc := aws_rds.NewFromConfig(*c.AwsConfig)
+	svc := c.GetRdsApi()
 
 	describeRdsInput := aws_rds.DescribeDBInstancesInput{
 		DBInstanceIdentifier: &instanceName,
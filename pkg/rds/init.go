@@ -1,75 +1,90 @@
 package rds
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/state"
 )
 
-// InitCreate initializes RDS resource creation by creating an inventory
-// channel, starting a goroutine to write inventory to file, creating the RDS
-// client and loading the RDS configuration.
+// InitCreate initializes RDS resource creation by loading and validating
+// the RDS configuration, locking the state, adding the current inventory to
+// the state history, creating an inventory channel, starting a goroutine to
+// write inventory to state and creating the RDS client.  The state is
+// unlocked once the inventory channel is closed and the last inventory has
+// been written.
 func InitCreate(
 	resourceClient *client.ResourceClient,
-	configFile string,
-	inventoryFile string,
+	configFiles []string,
+	backend state.Backend,
 	inventoryChan *chan RdsInventory,
 	createWait *sync.WaitGroup,
 ) (*RdsClient, *RdsConfig, error) {
-	// capture inventory and write to file as resources are created
-	createWait.Add(1)
-	go func() {
-		defer createWait.Done()
-		for inventory := range *inventoryChan {
-			if err := inventory.Write(inventoryFile); err != nil {
-				fmt.Printf("failed to write inventory file: %s", err)
-			}
-		}
-	}()
+	// load config
+	rdsConfig, err := LoadRdsConfig(configFiles...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load RDS config file: %w", err)
+	}
+
+	// validate config before any resources are changed
+	if err := rdsConfig.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid RDS config: %w", err)
+	}
 
-	// create client and load config
+	// lock state so no other operation can change the resource stack
+	lockInfo, err := state.Acquire(resourceClient.Context, backend, "create")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// capture inventory and write to state as resources are created
+	state.StartWriter(resourceClient.Context, backend, lockInfo, *inventoryChan, createWait)
+
+	// create client
 	rdsClient := RdsClient{
 		*resourceClient,
 		inventoryChan,
 	}
-	rdsConfig, err := LoadRdsConfig(configFile)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load RDS config file: %w", err)
-	}
 
 	return &rdsClient, rdsConfig, nil
 }
 
-// InitDelete initializes RDS resource deletion by creating an inventory
-// channel, starting a goroutine to write inventory updates to file, creating
-// the RDS client and loading the inventory to be deleted.
+// InitDelete initializes RDS resource deletion by locking the state, adding the
+// current inventory to the state history, loading the inventory to be deleted,
+// creating an inventory channel, starting a goroutine to write inventory
+// updates to state and creating the RDS client.  The state is unlocked once
+// the inventory channel is closed and the last inventory has been written.
 func InitDelete(
 	resourceClient *client.ResourceClient,
-	inventoryFile string,
+	backend state.Backend,
 	inventoryChan *chan RdsInventory,
 	deleteWait *sync.WaitGroup,
 ) (*RdsClient, *RdsInventory, error) {
-	// capture inventory and write to file as resources are deleted
-	deleteWait.Add(1)
-	go func() {
-		defer deleteWait.Done()
-		for inventory := range *inventoryChan {
-			if err := inventory.Write(inventoryFile); err != nil {
-				fmt.Printf("failed to write inventory file: %s", err)
-			}
+	// lock state so no other operation can change the resource stack
+	lockInfo, err := state.Acquire(resourceClient.Context, backend, "delete")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// load inventory to delete
+	var rdsInventory RdsInventory
+	if err := rdsInventory.LoadState(resourceClient.Context, backend); err != nil {
+		if unlockErr := backend.Unlock(resourceClient.Context, lockInfo.Id); unlockErr != nil {
+			return nil, nil, fmt.Errorf("failed to load RDS inventory and unlock state: %w", errors.Join(err, unlockErr))
 		}
-	}()
+		return nil, nil, fmt.Errorf("failed to load RDS inventory: %w", err)
+	}
+
+	// capture inventory and write to state as resources are deleted
+	state.StartWriter(resourceClient.Context, backend, lockInfo, *inventoryChan, deleteWait)
 
-	// create client and load inventory to delete
+	// create client
 	rdsClient := RdsClient{
 		*resourceClient,
 		inventoryChan,
 	}
-	var rdsInventory RdsInventory
-	if err := rdsInventory.Load(inventoryFile); err != nil {
-		return nil, nil, fmt.Errorf("failed to load RDS inventory file: %w", err)
-	}
 
 	return &rdsClient, &rdsInventory, nil
 }
@@ -1,36 +1,57 @@
 package rds
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"os"
+
+	"github.com/nukleros/aws-builder/pkg/inventory"
+	"github.com/nukleros/aws-builder/pkg/state"
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
-// RdsInventory contains RDS inventory resources used for an RDS instance.
-type RdsInventory struct {
-	Region              string `json:"region"`
-	RdsInstanceId       string `json:"rdsInstanceId"`
-	RdsInstanceEndpoint string `json:"rdsInstanceEndpoint"`
-	SubnetGroupName     string `json:"subnetGroupName"`
-	SecurityGroupId     string `json:"securityGroupId"`
+// InventorySchemaVersion is the schema version of RDS inventories written by
+// this version of aws-builder.  When the inventory changes in a way that
+// older inventories need to be upgraded for, increment the version and
+// register a migration from the previous version.
+const InventorySchemaVersion = 3
+
+func init() {
+	inventory.Register(inventory.Migration{
+		Stack:       "rds",
+		FromVersion: inventory.UnversionedSchemaVersion,
+		Description: "add schema version to inventories written before versioning",
+	})
+	inventory.Register(inventory.Migration{
+		Stack:       "rds",
+		FromVersion: 2,
+		Description: "add adopted resources that older versions would delete",
+	})
 }
 
-// send sends the RDS inventory on the inventory channel.
-func (i *RdsInventory) send(inventoryChan *chan RdsInventory) {
-	if inventoryChan != nil {
-		*inventoryChan <- *i
-	}
+// RdsInventory contains RDS inventory resources used for an RDS instance.
+// Adopted contains the IDs and names of resources that were not created by
+// aws-builder.
+type RdsInventory struct {
+	SchemaVersion       int      `json:"schemaVersion"`
+	Region              string   `json:"region"`
+	RdsInstanceId       string   `json:"rdsInstanceId"`
+	RdsInstanceEndpoint string   `json:"rdsInstanceEndpoint"`
+	SubnetGroupName     string   `json:"subnetGroupName"`
+	SecurityGroupId     string   `json:"securityGroupId"`
+	Adopted             []string `json:"adopted"`
 }
 
-// Write writes RDS inventory to file.
+// Write writes RDS inventory to file.  The file is replaced atomically so a
+// crash cannot leave a partially written inventory.
 func (i *RdsInventory) Write(inventoryFile string) error {
 	invJson, err := i.Marshal()
 	if err != nil {
 		return fmt.Errorf("failed to marshal RDS inventory to JSON: %w", err)
 	}
 
-	if err := os.WriteFile(inventoryFile, invJson, 0644); err != nil {
+	if err := util.WriteFileAtomic(inventoryFile, invJson, 0644); err != nil {
 		return fmt.Errorf("failed to write RDS inventory to file: %w", err)
 	}
 
@@ -49,9 +70,37 @@ func (i *RdsInventory) Load(inventoryFile string) error {
 	return i.Unmarshal(inventoryBytes)
 }
 
-// Marshal returns the JSON RDS inventory from an RdsInventory object.
+// WriteState writes RDS inventory to a state backend.
+func (i *RdsInventory) WriteState(ctx context.Context, backend state.Backend) error {
+	invJson, err := i.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal RDS inventory to JSON: %w", err)
+	}
+
+	if err := backend.Write(ctx, invJson); err != nil {
+		return fmt.Errorf("failed to write RDS inventory to %s: %w", backend, err)
+	}
+
+	return nil
+}
+
+// LoadState loads the RDS inventory from a state backend.
+func (i *RdsInventory) LoadState(ctx context.Context, backend state.Backend) error {
+	inventoryBytes, err := backend.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read RDS inventory from %s: %w", backend, err)
+	}
+
+	// unmarshal JSON inventory
+	return i.Unmarshal(inventoryBytes)
+}
+
+// Marshal returns the JSON RDS inventory from an RdsInventory object.  The
+// JSON has the current schema version without changing the object.
 func (i *RdsInventory) Marshal() ([]byte, error) {
-	invJson, err := json.MarshalIndent(i, "", "  ")
+	versioned := *i
+	versioned.SchemaVersion = InventorySchemaVersion
+	invJson, err := json.MarshalIndent(&versioned, "", "  ")
 	if err != nil {
 		return []byte{}, err
 	}
@@ -60,7 +109,14 @@ func (i *RdsInventory) Marshal() ([]byte, error) {
 }
 
 // Unmarshal populates an RdsInventory object from the JSON RDS inventory.
+// Inventories with an older schema version are migrated to the current
+// version and an error is returned for inventories with a newer version.
 func (i *RdsInventory) Unmarshal(inventoryBytes []byte) error {
+	inventoryBytes, err := inventory.Migrate("rds", inventoryBytes, InventorySchemaVersion)
+	if err != nil {
+		return err
+	}
+
 	if err := json.Unmarshal(inventoryBytes, i); err != nil {
 		return err
 	}
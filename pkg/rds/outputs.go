@@ -0,0 +1,37 @@
+package rds
+
+import (
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+// inventoryOutputs are the outputs of the RDS resource stack.  Outputs are
+// used by other resource stacks and scripts so existing names must not be
+// changed.
+var inventoryOutputs = []stack.InventoryOutput[*RdsInventory]{
+	{
+		Output: stack.Output{Name: "region", Description: "The AWS region the resources are in."},
+		Value:  func(i *RdsInventory) any { return i.Region },
+	},
+	{
+		Output: stack.Output{Name: "instanceId", Description: "The DB instance identifier."},
+		Value:  func(i *RdsInventory) any { return i.RdsInstanceId },
+	},
+	{
+		Output: stack.Output{Name: "endpoint", Description: "The hostname clients connect to the DB instance on."},
+		Value:  func(i *RdsInventory) any { return i.RdsInstanceEndpoint },
+	},
+	{
+		Output: stack.Output{Name: "subnetGroupName", Description: "The name of the DB subnet group."},
+		Value:  func(i *RdsInventory) any { return i.SubnetGroupName },
+	},
+	{
+		Output: stack.Output{Name: "securityGroupId", Description: "The ID of the security group attached to the DB instance."},
+		Value:  func(i *RdsInventory) any { return i.SecurityGroupId },
+	},
+}
+
+// Outputs returns the value of each output of the RDS resource stack, e.g.
+// ${rds.endpoint}, by output name.
+func (i *RdsInventory) Outputs() map[string]any {
+	return stack.OutputValues(inventoryOutputs, i)
+}
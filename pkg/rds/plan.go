@@ -0,0 +1,102 @@
+package rds
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+
+	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/plan"
+)
+
+// PlanRdsResourceStack returns the actions CreateRdsResourceStack would take
+// for each resource given the same config and inventory.  Only read-only calls
+// are made so no resources are created or changed.
+func (c *RdsClient) PlanRdsResourceStack(
+	resourceConfig *RdsConfig,
+	inventory *RdsInventory,
+) (*plan.ChangeSet, error) {
+	if inventory == nil {
+		inventory = &RdsInventory{}
+	}
+
+	// return an error if resource config and inventory regions do not match
+	if inventory.Region != "" && inventory.Region != resourceConfig.Region {
+		return nil, fmt.Errorf(
+			"config region %s and inventory region %s do not match",
+			resourceConfig.Region,
+			inventory.Region,
+		)
+	}
+
+	// resource config region takes precedence
+	// if not set, use the region defined in AWS config
+	region := resourceConfig.Region
+	if region != "" {
+		c.AwsConfig.Region = region
+	} else {
+		region = c.AwsConfig.Region
+	}
+
+	changeSet := plan.NewChangeSet("rds", region)
+
+	// Tags
+	rdsTags := CreateRdsTags(resourceConfig.Name, resourceConfig.Tags)
+	ec2Tags := ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags)
+
+	// Security Group
+	groupName := fmt.Sprintf("%s-rds-sg", resourceConfig.Name)
+	if inventory.SecurityGroupId != "" {
+		changeSet.Add("security-group", groupName, inventory.SecurityGroupId, plan.ActionReuse)
+	} else {
+		sgId, uniqueTagsExist, err := ec2.CheckUniqueTagsForSecurityGroup(c, groupName, ec2Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for unique tags on security group with name %s: %w", groupName, err)
+		}
+		if uniqueTagsExist {
+			changeSet.Add("security-group", groupName, sgId, plan.ActionAdopt)
+		} else {
+			changeSet.Add("security-group", groupName, "", plan.ActionCreate)
+		}
+	}
+
+	// Subnet Group
+	subnetGroupName := fmt.Sprintf("%s-subnet-group", resourceConfig.Name)
+	if inventory.SubnetGroupName != "" {
+		changeSet.Add("rds-subnet-group", inventory.SubnetGroupName, "", plan.ActionReuse)
+	} else {
+		_, uniqueTagsExist, err := c.checkSubnetGroupUniqueTags(subnetGroupName, rdsTags)
+		var notFoundErr *types.DBSubnetGroupNotFoundFault
+		switch {
+		case errors.As(err, &notFoundErr):
+			changeSet.Add("rds-subnet-group", subnetGroupName, "", plan.ActionCreate)
+		case err != nil:
+			return nil, fmt.Errorf("failed to check for unique tags on subnet group %s: %w", subnetGroupName, err)
+		case uniqueTagsExist:
+			changeSet.Add("rds-subnet-group", subnetGroupName, "", plan.ActionAdopt)
+		default:
+			return nil, fmt.Errorf("subnet group %s already exists without matching tags", subnetGroupName)
+		}
+	}
+
+	// RDS Instance
+	if inventory.RdsInstanceId != "" {
+		changeSet.Add("rds-instance", inventory.RdsInstanceId, "", plan.ActionReuse)
+	} else {
+		_, uniqueTagsExist, err := c.checkRdsInstanceUniqueTags(resourceConfig.Name, rdsTags)
+		var notFoundErr *types.DBInstanceNotFoundFault
+		switch {
+		case errors.As(err, &notFoundErr):
+			changeSet.Add("rds-instance", resourceConfig.Name, "", plan.ActionCreate)
+		case err != nil:
+			return nil, fmt.Errorf("failed to check for unique tags on RDS instance %s: %w", resourceConfig.Name, err)
+		case uniqueTagsExist:
+			changeSet.Add("rds-instance", resourceConfig.Name, "", plan.ActionAdopt)
+		default:
+			return nil, fmt.Errorf("RDS instance %s already exists without matching tags", resourceConfig.Name)
+		}
+	}
+
+	return changeSet, nil
+}
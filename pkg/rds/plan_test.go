@@ -0,0 +1,112 @@
+package rds
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+	"github.com/nukleros/aws-builder/pkg/plan"
+)
+
+// kindActions returns the planned action for each kind of resource in the
+// change set.
+func kindActions(changeSet *plan.ChangeSet) map[string]plan.Action {
+	actions := make(map[string]plan.Action)
+	for _, change := range changeSet.Changes {
+		actions[change.Kind] = change.Action
+	}
+
+	return actions
+}
+
+func TestPlanRdsResourceStack(t *testing.T) {
+	backend := fake.NewBackend(fake.DefaultRegion)
+	resourceConfig := sampleConfig(t, backend)
+	rdsClient := RdsClient{ResourceClient: *backend.ResourceClient()}
+	before := backend.Resources()
+
+	testCases := []struct {
+		name      string
+		inventory func(created *RdsInventory) *RdsInventory
+		expected  plan.Action
+	}{
+		{
+			name:     "create",
+			expected: plan.ActionCreate,
+		},
+		{
+			name:      "reuse",
+			inventory: func(created *RdsInventory) *RdsInventory { return created },
+			expected:  plan.ActionReuse,
+		},
+		{
+			name:      "adopt",
+			inventory: func(created *RdsInventory) *RdsInventory { return &RdsInventory{} },
+			expected:  plan.ActionAdopt,
+		},
+	}
+
+	var created RdsInventory
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var inventory *RdsInventory
+			if testCase.inventory != nil {
+				if created.RdsInstanceId == "" {
+					if err := rdsClient.CreateRdsResourceStack(resourceConfig, &created); err != nil {
+						t.Fatalf("failed to create resource stack: %v", err)
+					}
+					before = backend.Resources()
+				}
+				inventory = testCase.inventory(&created)
+			}
+
+			changeSet, err := rdsClient.PlanRdsResourceStack(resourceConfig, inventory)
+			if err != nil {
+				t.Fatalf("failed to plan resource stack: %v", err)
+			}
+			expected := map[string]plan.Action{
+				"security-group":   testCase.expected,
+				"rds-subnet-group": testCase.expected,
+				"rds-instance":     testCase.expected,
+			}
+			if actions := kindActions(changeSet); !reflect.DeepEqual(actions, expected) {
+				t.Errorf("expected actions %v, got %v", expected, actions)
+			}
+			if resources := backend.Resources(); !reflect.DeepEqual(resources, before) {
+				t.Errorf("expected planning to change nothing, found %v", resources)
+			}
+		})
+	}
+}
+
+func TestPlanRdsResourceStackUntaggedSubnetGroup(t *testing.T) {
+	backend := fake.NewBackend(fake.DefaultRegion)
+	resourceConfig := sampleConfig(t, backend)
+	network := &fake.Network{VpcId: resourceConfig.VpcId, SubnetIds: resourceConfig.SubnetIds}
+
+	// a subnet group with the name the resource stack would use and tags
+	// that aren't its can't be adopted
+	database, err := backend.CreateDatabase(resourceConfig.Name, network)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	if _, err := backend.Apis().Rds.AddTagsToResource(context.Background(), &aws_rds.AddTagsToResourceInput{
+		ResourceName: aws.String(fmt.Sprintf("arn:aws:rds:%s:%s:subgrp:%s", backend.Region, fake.DefaultAccountId, database.SubnetGroupName)),
+		Tags:         []types.Tag{{Key: aws.String("Owner"), Value: aws.String("other")}},
+	}); err != nil {
+		t.Fatalf("failed to tag subnet group: %v", err)
+	}
+	rdsClient := RdsClient{ResourceClient: *backend.ResourceClient()}
+
+	_, err = rdsClient.PlanRdsResourceStack(resourceConfig, nil)
+	if err == nil || !strings.Contains(err.Error(), "already exists without matching tags") {
+		t.Errorf("expected error for subnet group without matching tags, got %v", err)
+	}
+}
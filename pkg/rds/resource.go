@@ -1,8 +1,10 @@
 package rds
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/nukleros/aws-builder/pkg/client"
 	"github.com/nukleros/aws-builder/pkg/ec2"
 )
 
@@ -40,6 +42,13 @@ func (c *RdsClient) CreateRdsResourceStack(
 		resourceConfig.Region = c.AwsConfig.Region
 	}
 
+	// set defaults that depend on other config values for configs not loaded
+	// from file
+	resourceConfig.SetDefaults()
+
+	// waiter settings on the client take precedence over resource config
+	c.Waiters = c.Waiters.Merge(resourceConfig.Waiters)
+
 	// Tags
 	rdsTags := CreateRdsTags(resourceConfig.Name, resourceConfig.Tags)
 	ec2Tags := ec2.CreateEc2Tags(resourceConfig.Name, resourceConfig.Tags)
@@ -56,14 +65,14 @@ func (c *RdsClient) CreateRdsResourceStack(
 		)
 		if sgId != "" {
 			inventory.SecurityGroupId = sgId
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("security-group", err)
 		}
-		c.SendMessage(fmt.Sprintf("security group with ID %s created", sgId))
+		c.sendEvent("security-group", client.PhaseCreated, fmt.Sprintf("security group with ID %s created", sgId), sgId)
 	} else {
-		c.SendMessage(fmt.Sprintf("security group with ID %s found in inventory", inventory.SecurityGroupId))
+		c.sendEvent("security-group", client.PhaseFoundInInventory, fmt.Sprintf("security group with ID %s found in inventory", inventory.SecurityGroupId), inventory.SecurityGroupId)
 	}
 
 	// Subnet Group
@@ -75,14 +84,14 @@ func (c *RdsClient) CreateRdsResourceStack(
 		)
 		if subnetGroup != nil {
 			inventory.SubnetGroupName = *subnetGroup.DBSubnetGroupName
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("rds-subnet-group", err)
 		}
-		c.SendMessage(fmt.Sprintf("subnet group %s created", *subnetGroup.DBSubnetGroupName))
+		c.sendEvent("rds-subnet-group", client.PhaseCreated, fmt.Sprintf("subnet group %s created", *subnetGroup.DBSubnetGroupName), *subnetGroup.DBSubnetGroupName)
 	} else {
-		c.SendMessage(fmt.Sprintf("subnet group %s found in inventory", inventory.SubnetGroupName))
+		c.sendEvent("rds-subnet-group", client.PhaseFoundInInventory, fmt.Sprintf("subnet group %s found in inventory", inventory.SubnetGroupName), inventory.SubnetGroupName)
 	}
 
 	// RDS Instance
@@ -103,67 +112,101 @@ func (c *RdsClient) CreateRdsResourceStack(
 		)
 		if rdsInstance != nil {
 			inventory.RdsInstanceId = *rdsInstance.DBInstanceIdentifier
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("rds-instance", err)
 		}
-		c.SendMessage(fmt.Sprintf("RDS instance %s created", *rdsInstance.DBInstanceIdentifier))
+		c.sendEvent("rds-instance", client.PhaseCreated, fmt.Sprintf("RDS instance %s created", *rdsInstance.DBInstanceIdentifier), *rdsInstance.DBInstanceIdentifier)
 	} else {
-		c.SendMessage(fmt.Sprintf("RDS instance %s found in inventory", inventory.RdsInstanceId))
+		c.sendEvent("rds-instance", client.PhaseFoundInInventory, fmt.Sprintf("RDS instance %s found in inventory", inventory.RdsInstanceId), inventory.RdsInstanceId)
 	}
 
 	// RDS Instance Endpoint
 	if inventory.RdsInstanceEndpoint == "" {
-		c.SendMessage(fmt.Sprintf("waiting for RDS instance %s to become available", inventory.RdsInstanceId))
+		c.sendEvent("rds-instance", client.PhaseWaiting, fmt.Sprintf("waiting for RDS instance %s to become available", inventory.RdsInstanceId), inventory.RdsInstanceId)
 		endpoint, err := c.WaitForRdsInstance(inventory.RdsInstanceId, RdsConditionCreated)
-		if endpoint != "" && c.InventoryChan != nil {
+		if endpoint != "" {
 			inventory.RdsInstanceEndpoint = endpoint
-			inventory.send(c.InventoryChan)
+			c.sendInventory(inventory)
 		}
 		if err != nil {
-			return err
+			return c.sendFailed("rds-instance", err)
 		}
-		c.SendMessage(fmt.Sprintf("RDS instance %s is available", inventory.RdsInstanceId))
+		c.sendEvent("rds-instance", client.PhaseReady, fmt.Sprintf("RDS instance %s is available", inventory.RdsInstanceId), inventory.RdsInstanceId)
 	}
 
 	return nil
 }
 
-// DeleteResourceStack deletes all the resources for an RDS instance.
+// DeleteResourceStack deletes all the resources for an RDS instance.  Adopted
+// resources are kept unless the client is set to delete them.  The subnet
+// group and security group are each attempted once the RDS instance is
+// deleted, and resources that are not found are treated as deleted.  An error
+// joining every failure and skipped resource is returned.
 func (c *RdsClient) DeleteRdsResourceStack(inventory *RdsInventory) error {
 	c.AwsConfig.Region = inventory.Region
 
+	var errs []error
+	skip := func(kind string, message string, ids ...string) {
+		c.sendEvent(kind, client.PhaseSkipped, message, ids...)
+		errs = append(errs, errors.New(message))
+	}
+
 	// RDS Instance
-	if err := c.DeleteRdsInstance(inventory.RdsInstanceId); err != nil {
-		return err
+	if inventory.RdsInstanceId != "" && !c.keepAdopted(inventory, "rds-instance", inventory.RdsInstanceId) {
+		if err := c.deleteRdsInstance(inventory.RdsInstanceId); err != nil {
+			errs = append(errs, c.sendFailed("rds-instance", err))
+		} else {
+			c.sendEvent("rds-instance", client.PhaseDeleted, fmt.Sprintf("RDS instance %s has been removed", inventory.RdsInstanceId), inventory.RdsInstanceId)
+			inventory.RdsInstanceId = ""
+			inventory.RdsInstanceEndpoint = ""
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("RDS instance %s deleted", inventory.RdsInstanceId))
-	c.SendMessage(fmt.Sprintf("waiting for RDS instance %s to be removed", inventory.RdsInstanceId))
-	_, err := c.WaitForRdsInstance(inventory.RdsInstanceId, RdsConditionDeleted)
-	if err != nil {
-		return err
+
+	// Subnet Group - the RDS instance must be deleted first
+	switch {
+	case inventory.SubnetGroupName == "" || c.keepAdopted(inventory, "rds-subnet-group", inventory.SubnetGroupName):
+	case inventory.RdsInstanceId != "":
+		skip("rds-subnet-group", fmt.Sprintf("subnet group %s not deleted because RDS instance still exists: %s", inventory.SubnetGroupName, inventory.RdsInstanceId), inventory.SubnetGroupName)
+	default:
+		if err := c.DeleteSubnetGroup(inventory.SubnetGroupName); err != nil {
+			errs = append(errs, c.sendFailed("rds-subnet-group", err))
+		} else {
+			c.sendEvent("rds-subnet-group", client.PhaseDeleted, fmt.Sprintf("subnet group %s deleted", inventory.SubnetGroupName), inventory.SubnetGroupName)
+			inventory.SubnetGroupName = ""
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("RDS instance %s has been removed", inventory.RdsInstanceId))
-	inventory.RdsInstanceId = ""
-	inventory.RdsInstanceEndpoint = ""
-	inventory.send(c.InventoryChan)
 
-	// Subnet Group
-	if err := c.DeleteSubnetGroup(inventory.SubnetGroupName); err != nil {
-		return err
+	// Security Group - the RDS instance must be deleted first
+	switch {
+	case inventory.SecurityGroupId == "" || c.keepAdopted(inventory, "security-group", inventory.SecurityGroupId):
+	case inventory.RdsInstanceId != "":
+		skip("security-group", fmt.Sprintf("security group %s not deleted because RDS instance still exists: %s", inventory.SecurityGroupId, inventory.RdsInstanceId), inventory.SecurityGroupId)
+	default:
+		if err := c.DeleteSecurityGroup(inventory.SecurityGroupId); err != nil {
+			errs = append(errs, c.sendFailed("security-group", err))
+		} else {
+			c.sendEvent("security-group", client.PhaseDeleted, fmt.Sprintf("security group %s deleted", inventory.SecurityGroupId), inventory.SecurityGroupId)
+			inventory.SecurityGroupId = ""
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("subnet group %s deleted", inventory.SubnetGroupName))
-	inventory.SubnetGroupName = ""
-	inventory.send(c.InventoryChan)
 
-	// Security Group
-	if err := c.DeleteSecurityGroup(inventory.SecurityGroupId); err != nil {
+	return errors.Join(errs...)
+}
+
+// deleteRdsInstance initiates the deletion of an RDS instance and waits for
+// it to be removed.
+func (c *RdsClient) deleteRdsInstance(rdsInstanceId string) error {
+	if err := c.DeleteRdsInstance(rdsInstanceId); err != nil {
 		return err
 	}
-	c.SendMessage(fmt.Sprintf("security group %s deleted", inventory.SecurityGroupId))
-	inventory.SecurityGroupId = ""
-	inventory.send(c.InventoryChan)
+	c.sendEvent("rds-instance", client.PhaseDeleting, fmt.Sprintf("RDS instance %s deleted", rdsInstanceId), rdsInstanceId)
+	c.sendEvent("rds-instance", client.PhaseWaiting, fmt.Sprintf("waiting for RDS instance %s to be removed", rdsInstanceId), rdsInstanceId)
+	_, err := c.WaitForRdsInstance(rdsInstanceId, RdsConditionDeleted)
 
-	return nil
+	return err
 }
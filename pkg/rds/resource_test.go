@@ -0,0 +1,134 @@
+package rds
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+// sampleConfig returns the sample RDS config updated to use a VPC and
+// subnets created in the fake backend.
+func sampleConfig(t *testing.T, backend *fake.Backend) *RdsConfig {
+	t.Helper()
+
+	resourceConfig, err := LoadRdsConfig("../../sample/rds-config.yaml")
+	if err != nil {
+		t.Fatalf("failed to load sample config: %v", err)
+	}
+	network, err := backend.CreateNetwork("10.0.0.0/16", "10.0.1.0/24", "10.0.2.0/24")
+	if err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	resourceConfig.VpcId = network.VpcId
+	resourceConfig.SubnetIds = network.SubnetIds
+
+	return resourceConfig
+}
+
+func TestCreateRdsResourceStackReusesSubnetGroup(t *testing.T) {
+	backend := fake.NewBackend(fake.DefaultRegion)
+	resourceConfig := sampleConfig(t, backend)
+	rdsClient := RdsClient{ResourceClient: *backend.ResourceClient()}
+	injected := &smithy.GenericAPIError{Code: "InstanceQuotaExceeded", Message: "injected failure"}
+	backend.FailNext("CreateDBInstance", injected)
+
+	var inventory RdsInventory
+	err := rdsClient.CreateRdsResourceStack(resourceConfig, &inventory)
+	if !errors.Is(err, injected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if inventory.SecurityGroupId == "" || inventory.SubnetGroupName == "" {
+		t.Fatalf("expected security group and subnet group created before the failure to be in inventory, got %+v", inventory)
+	}
+	if inventory.RdsInstanceId != "" {
+		t.Errorf("expected no RDS instance in inventory, got %s", inventory.RdsInstanceId)
+	}
+	subnetGroupName := inventory.SubnetGroupName
+
+	if err := rdsClient.CreateRdsResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to resume create: %v", err)
+	}
+	for _, operation := range []string{"CreateSecurityGroup", "CreateDBSubnetGroup"} {
+		if count := backend.CallCount(operation); count != 1 {
+			t.Errorf("expected resource in inventory to be reused, %s called %d times", operation, count)
+		}
+	}
+	if inventory.SubnetGroupName != subnetGroupName {
+		t.Errorf("expected subnet group %s in inventory, got %s", subnetGroupName, inventory.SubnetGroupName)
+	}
+	instances, err := backend.Apis().Rds.DescribeDBInstances(context.Background(), &rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(inventory.RdsInstanceId),
+	})
+	if err != nil {
+		t.Fatalf("failed to describe RDS instance: %v", err)
+	}
+	if name := aws.ToString(instances.DBInstances[0].DBSubnetGroup.DBSubnetGroupName); name != subnetGroupName {
+		t.Errorf("expected RDS instance to use subnet group %s, got %s", subnetGroupName, name)
+	}
+	if inventory.RdsInstanceEndpoint == "" {
+		t.Error("expected RDS instance endpoint in inventory")
+	}
+}
+
+func TestDeleteRdsResourceStackKeepsSubnetGroupForInstance(t *testing.T) {
+	backend := fake.NewBackend(fake.DefaultRegion)
+	resourceConfig := sampleConfig(t, backend)
+	rdsClient := RdsClient{ResourceClient: *backend.ResourceClient()}
+	securityGroups := len(backend.Resources()["security-group"])
+
+	var inventory RdsInventory
+	if err := rdsClient.CreateRdsResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	injected := &smithy.GenericAPIError{Code: "InternalFailure", Message: "injected failure"}
+	backend.FailNext("DeleteDBInstance", injected)
+
+	err := rdsClient.DeleteRdsResourceStack(&inventory)
+	if !errors.Is(err, injected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "not deleted because RDS instance still exists") {
+		t.Errorf("expected dependents of the RDS instance to be skipped, got %v", err)
+	}
+	if count := backend.CallCount("DeleteDBSubnetGroup"); count != 0 {
+		t.Errorf("expected subnet group deletion not to be attempted, DeleteDBSubnetGroup called %d times", count)
+	}
+	if inventory.RdsInstanceId == "" || inventory.SubnetGroupName == "" || inventory.SecurityGroupId == "" {
+		t.Errorf("expected remaining resources to stay in inventory, got %+v", inventory)
+	}
+
+	if err := rdsClient.DeleteRdsResourceStack(&inventory); err != nil {
+		t.Fatalf("failed to resume delete: %v", err)
+	}
+	resources := backend.Resources()
+	for _, kind := range []string{"rds-instance", "rds-subnet-group"} {
+		if len(resources[kind]) != 0 {
+			t.Errorf("expected %s to be deleted, found %v", kind, resources[kind])
+		}
+	}
+	if len(resources["security-group"]) != securityGroups {
+		t.Errorf("expected security group to be deleted, found %v", resources["security-group"])
+	}
+}
+
+func TestCreateRdsResourceStackRegionMismatch(t *testing.T) {
+	backend := fake.NewBackend(fake.DefaultRegion)
+	resourceConfig := sampleConfig(t, backend)
+	rdsClient := RdsClient{ResourceClient: *backend.ResourceClient()}
+
+	inventory := RdsInventory{Region: "us-west-2"}
+	err := rdsClient.CreateRdsResourceStack(resourceConfig, &inventory)
+	if err == nil {
+		t.Fatal("expected create to fail for an inventory in another region")
+	}
+	if count := backend.CallCount("CreateSecurityGroup"); count != 0 {
+		t.Errorf("expected no resources to be created, CreateSecurityGroup called %d times", count)
+	}
+}
@@ -0,0 +1,88 @@
+package rds
+
+import (
+	"github.com/nukleros/aws-builder/pkg/schema"
+)
+
+// ConfigSchema returns the JSON Schema for RDS config files.
+func ConfigSchema() *schema.Schema {
+	defaults := NewRdsConfig()
+	defaults.Waiters = defaultWaiters()
+
+	return schema.Generate("RDS resource stack config", defaults)
+}
+
+// SchemaFields describes the config fields in generated schemas.
+func (RdsConfig) SchemaFields() map[string]schema.Field {
+	var engines []any
+	for _, engine := range Engines() {
+		engines = append(engines, engine)
+	}
+
+	return map[string]schema.Field{
+		"tags": {
+			Description: "Tags added to every resource.  Use unique tags for each resource stack.",
+		},
+		"awsAccount": {
+			Description: "The AWS account ID resources are created in.",
+			Pattern:     awsAccountPattern.String(),
+			Required:    true,
+		},
+		"region": {
+			Description: "The AWS region to create resources in.  Defaults to the region in AWS config.",
+		},
+		"vpcId": {
+			Description: "The VPC to create the DB instance in.",
+			Required:    true,
+		},
+		"subnetIds": {
+			Description: "The subnets to create the DB instance in.  At least 2 subnets in different availability zones are required.",
+			Required:    true,
+		},
+		"name": {
+			Description: "The DB instance identifier.  Also used in the names of the security group and subnet group.",
+			Pattern:     instanceNamePattern.String(),
+			Required:    true,
+		},
+		"dbName": {
+			Description: "The name of the database created on the DB instance.",
+			Pattern:     dbIdentifierPattern.String(),
+		},
+		"class": {
+			Description: "The DB instance class, e.g. db.t3.small.",
+		},
+		"engine": {
+			Description: "The database engine.",
+			Enum:        engines,
+			Required:    true,
+		},
+		"engineVersion": {
+			Description: "The version of the database engine, e.g. \"10.6\".",
+		},
+		"dbPort": {
+			Description: "The port clients connect to.  Must be the port the engine listens on, which is the default.",
+		},
+		"storageGb": {
+			Description: "The storage allocated to the DB instance in GiB.",
+		},
+		"backupDays": {
+			Description: "The number of days automated backups are kept, from 0 to 35.  0 disables automated backups.",
+		},
+		"dbUser": {
+			Description: "The master user name.",
+			Pattern:     dbIdentifierPattern.String(),
+			Required:    true,
+		},
+		"dbUserPassword": {
+			Description: "The master user password.  Use an environment variable reference, e.g. ${DB_PASSWORD}, to keep it out of config files.",
+			Required:    true,
+		},
+		"sourceSecurityGroupId": {
+			Description: "The security group of the workloads allowed to connect to the DB instance.",
+			Required:    true,
+		},
+		"waiters": {
+			Description: "Settings for waiting on resources by resource: " + RdsInstanceWaiter + ".",
+		},
+	}
+}
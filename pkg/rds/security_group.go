@@ -9,6 +9,7 @@ import (
 	"github.com/aws/smithy-go"
 
 	"github.com/nukleros/aws-builder/pkg/ec2"
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreateSecurityGroup creates a security group for the RDS instance and adds an
@@ -25,7 +26,7 @@ func (c *RdsClient) CreateSecurityGroup(
 	sourceSecurityGroupId string,
 	awsAccount string,
 ) (string, error) {
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	// create security group
 	description := fmt.Sprintf("security group for RDS instance %s", instanceName)
@@ -117,19 +118,19 @@ func (c *RdsClient) CreateSecurityGroup(
 }
 
 // DeleteSecurityGroup deletes a security group that was used by an RDS
-// instance.
+// instance.  If the security group is not found it returns without error.
 func (c *RdsClient) DeleteSecurityGroup(securityGroupId string) error {
 	if securityGroupId == "" {
 		return nil
 	}
 
-	svc := aws_ec2.NewFromConfig(*c.AwsConfig)
+	svc := c.GetEc2Api()
 
 	deleteSecurityGroupInput := aws_ec2.DeleteSecurityGroupInput{
 		GroupId: &securityGroupId,
 	}
 	_, err := svc.DeleteSecurityGroup(c.Context, &deleteSecurityGroupInput)
-	if err != nil {
+	if err != nil && !util.HasErrorCode(err, "InvalidGroup.NotFound", "InvalidGroupId.NotFound") {
 		return fmt.Errorf("failed to delete security group with ID %s: %w", securityGroupId, err)
 	}
 
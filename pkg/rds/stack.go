@@ -0,0 +1,152 @@
+package rds
+
+import (
+	"sync"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/plan"
+	"github.com/nukleros/aws-builder/pkg/schema"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+func init() {
+	stack.Register(&RdsClient{})
+}
+
+// Name returns the name the RDS resource stack is registered as.
+func (c *RdsClient) Name() string {
+	return "rds"
+}
+
+// Description returns the AWS service the RDS resource stack provides.
+func (c *RdsClient) Description() string {
+	return "Relational Database Service"
+}
+
+// New returns an RDS client that uses the resource client.
+func (c *RdsClient) New(resourceClient *client.ResourceClient) stack.Stack {
+	return &RdsClient{ResourceClient: *resourceClient}
+}
+
+// NewConfig returns an empty RDS config.
+func (c *RdsClient) NewConfig() stack.Config {
+	return &RdsConfig{}
+}
+
+// LoadConfig loads RDS config files, resolving references to the outputs of
+// other resource stacks, and applies defaults.
+func (c *RdsClient) LoadConfig(outputs configfile.Outputs, configFiles ...string) (stack.Config, error) {
+	return LoadRdsConfigWithOutputs(outputs, configFiles...)
+}
+
+// ConfigSchema returns the JSON Schema for RDS config files.
+func (c *RdsClient) ConfigSchema() *schema.Schema {
+	return ConfigSchema()
+}
+
+// WaiterNames returns the RDS resources that can be waited on.
+func (c *RdsClient) WaiterNames() []string {
+	return []string{RdsInstanceWaiter}
+}
+
+// NewInventory returns an empty RDS inventory.
+func (c *RdsClient) NewInventory() stack.Inventory {
+	return &RdsInventory{}
+}
+
+// Outputs returns the outputs of the RDS resource stack.
+func (c *RdsClient) Outputs() []stack.Output {
+	return stack.Outputs(inventoryOutputs)
+}
+
+// InventorySchemaVersion returns the current RDS inventory schema version.
+func (c *RdsClient) InventorySchemaVersion() int {
+	return InventorySchemaVersion
+}
+
+// StartStateWriter creates the client's inventory channel and starts a
+// goroutine to write inventory sent on it to state.  The returned function
+// closes the channel.
+func (c *RdsClient) StartStateWriter(
+	backend state.Backend,
+	lockInfo state.LockInfo,
+	wait *sync.WaitGroup,
+) func() {
+	inventoryChan := make(chan RdsInventory)
+	c.InventoryChan = &inventoryChan
+	state.StartWriter(c.Context, backend, lockInfo, inventoryChan, wait)
+
+	return func() { close(inventoryChan) }
+}
+
+// Create creates the RDS resource stack.
+func (c *RdsClient) Create(config stack.Config, inventory stack.Inventory) error {
+	rdsConfig, err := stack.ConfigAs[*RdsConfig](config)
+	if err != nil {
+		return err
+	}
+	rdsInventory, err := stack.InventoryAs[*RdsInventory](inventory)
+	if err != nil {
+		return err
+	}
+
+	return c.CreateRdsResourceStack(rdsConfig, rdsInventory)
+}
+
+// Delete deletes the RDS resource stack.
+func (c *RdsClient) Delete(inventory stack.Inventory) error {
+	rdsInventory, err := stack.InventoryAs[*RdsInventory](inventory)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteRdsResourceStack(rdsInventory)
+}
+
+// Plan returns the changes creating the RDS resource stack would make.
+func (c *RdsClient) Plan(config stack.Config, inventory stack.Inventory) (*plan.ChangeSet, error) {
+	rdsConfig, err := stack.ConfigAs[*RdsConfig](config)
+	if err != nil {
+		return nil, err
+	}
+	rdsInventory, err := stack.InventoryAs[*RdsInventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.PlanRdsResourceStack(rdsConfig, rdsInventory)
+}
+
+// Verify checks the RDS resource stack for drift.
+func (c *RdsClient) Verify(inventory stack.Inventory, config stack.Config) (*drift.Report, error) {
+	rdsInventory, err := stack.InventoryAs[*RdsInventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+	rdsConfig, err := stack.ConfigAs[*RdsConfig](config)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.VerifyRdsResourceStack(rdsInventory, rdsConfig)
+}
+
+// Adopt records an existing RDS instance and its subnet group and security
+// group in an RDS inventory.
+func (c *RdsClient) Adopt(
+	name string,
+	tags map[string]string,
+	applyTags bool,
+	inventory stack.Inventory,
+) (*discovery.Report, error) {
+	rdsInventory, err := stack.InventoryAs[*RdsInventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.AdoptRdsResourceStack(name, tags, applyTags, rdsInventory)
+}
@@ -6,6 +6,8 @@ import (
 
 	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreateSubnetGroup creates a new subnet group for an RDS instances.  The
@@ -18,7 +20,7 @@ func (c *RdsClient) CreateSubnetGroup(
 	instanceName string,
 	subnetIds []string,
 ) (*types.DBSubnetGroup, error) {
-	svc := aws_rds.NewFromConfig(*c.AwsConfig)
+	svc := c.GetRdsApi()
 
 	subnetGroupName := fmt.Sprintf("%s-subnet-group", instanceName)
 	subnetGroupDescription := fmt.Sprintf("database subnet group for RDS instance %s", instanceName)
@@ -50,18 +52,19 @@ func (c *RdsClient) CreateSubnetGroup(
 }
 
 // DeleteSubnetGroup deletes a subnet group that was used by an RDS instance.
+// If the subnet group is not found it returns without error.
 func (c *RdsClient) DeleteSubnetGroup(subnetGroupName string) error {
 	if subnetGroupName == "" {
 		return nil
 	}
 
-	svc := aws_rds.NewFromConfig(*c.AwsConfig)
+	svc := c.GetRdsApi()
 
 	deleteSubnetGroupInput := aws_rds.DeleteDBSubnetGroupInput{
 		DBSubnetGroupName: &subnetGroupName,
 	}
 	_, err := svc.DeleteDBSubnetGroup(c.Context, &deleteSubnetGroupInput)
-	if err != nil {
+	if err != nil && !util.HasErrorCode(err, "DBSubnetGroupNotFoundFault") {
 		return fmt.Errorf("failed to delete subnet group %s: %w", subnetGroupName, err)
 	}
 
@@ -74,7 +77,7 @@ func (c *RdsClient) checkSubnetGroupUniqueTags(
 	subnetGroupName string,
 	tags *[]types.Tag,
 ) (*types.DBSubnetGroup, bool, error) {
-	svc := aws_rds.NewFromConfig(*c.AwsConfig)
+	svc := c.GetRdsApi()
 
 	describeSubnetGroupsInput := aws_rds.DescribeDBSubnetGroupsInput{
 		DBSubnetGroupName: &subnetGroupName,
@@ -37,7 +37,7 @@ func (c *RdsClient) CheckUniqueTagsForResource(
 	resourceArn string,
 	tags *[]types.Tag,
 ) (bool, error) {
-	svc := aws_rds.NewFromConfig(*c.AwsConfig)
+	svc := c.GetRdsApi()
 
 	listTagsInput := aws_rds.ListTagsForResourceInput{
 		ResourceName: &resourceArn,
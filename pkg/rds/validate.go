@@ -0,0 +1,96 @@
+package rds
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+var (
+	// instanceNamePattern matches the identifiers RDS accepts for DB
+	// instances.
+	instanceNamePattern = regexp.MustCompile(`^[A-Za-z](-?[A-Za-z0-9])*$`)
+
+	// dbIdentifierPattern matches the database and master user names
+	// accepted by all RDS engines.
+	dbIdentifierPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+	// awsAccountPattern matches 12 digit AWS account IDs.
+	awsAccountPattern = regexp.MustCompile(`^[0-9]{12}$`)
+)
+
+// maxInstanceNameLength is the longest DB instance identifier RDS accepts.
+const maxInstanceNameLength = 63
+
+// Validate checks the config for values that would cause resource creation to
+// fail.  If any are found a validation.Errors containing every problem is
+// returned.
+func (c *RdsConfig) Validate() error {
+	var errs validation.Errors
+
+	switch {
+	case c.Name == "":
+		errs.Add("name", "is required")
+	case utf8.RuneCountInString(c.Name) > maxInstanceNameLength:
+		errs.Add("name", "must be %d characters or less", maxInstanceNameLength)
+	case !instanceNamePattern.MatchString(c.Name):
+		errs.Add("name", "must begin with a letter and contain only letters, digits and single hyphens, and must not end with a hyphen")
+	}
+	if !awsAccountPattern.MatchString(c.AwsAccount) {
+		errs.Add("awsAccount", "must be a 12 digit AWS account ID")
+	}
+
+	// networking
+	if !strings.HasPrefix(c.VpcId, "vpc-") {
+		errs.Add("vpcId", "must be a VPC ID, e.g. vpc-00eadea13c15c5975")
+	}
+	if len(c.SubnetIds) < 2 {
+		errs.Add("subnetIds", "at least 2 subnets in different availability zones are required")
+	}
+	for i, subnetId := range c.SubnetIds {
+		if !strings.HasPrefix(subnetId, "subnet-") {
+			errs.Add(validation.Index("subnetIds", i), "%q is not a subnet ID", subnetId)
+		}
+	}
+	if !strings.HasPrefix(c.SourceSecurityGroupId, "sg-") {
+		errs.Add("sourceSecurityGroupId", "must be the ID of the security group for DB clients, e.g. sg-0de0fd0accc233db1")
+	}
+
+	// DB instance
+	if !strings.HasPrefix(c.Class, "db.") {
+		errs.Add("class", "%q is not a DB instance class, e.g. db.t3.small", c.Class)
+	}
+	switch enginePort, ok := EnginePorts[c.Engine]; {
+	case c.Engine == "":
+		errs.Add("engine", "is required")
+	case !ok:
+		errs.Add("engine", "%q is not a supported engine, must be one of %s", c.Engine, strings.Join(Engines(), ", "))
+	case c.DbPort != enginePort:
+		errs.Add("dbPort", "must be %d, the port the %s engine listens on", enginePort, c.Engine)
+	}
+	if c.StorageGb < 20 {
+		errs.Add("storageGb", "must be 20 or greater")
+	}
+	if c.BackupDays < 0 || c.BackupDays > 35 {
+		errs.Add("backupDays", "must be between 0 and 35")
+	}
+	if c.DbName != "" && !dbIdentifierPattern.MatchString(c.DbName) {
+		errs.Add("dbName", "must begin with a letter and contain only letters, digits and underscores")
+	}
+	if !dbIdentifierPattern.MatchString(c.DbUser) {
+		errs.Add("dbUser", "must begin with a letter and contain only letters, digits and underscores")
+	}
+	switch {
+	case utf8.RuneCountInString(c.DbUserPassword) < 8:
+		errs.Add("dbUserPassword", "must be 8 characters or more")
+	case strings.ContainsAny(c.DbUserPassword, `/"@ `):
+		errs.Add("dbUserPassword", `must not contain "/", '"', "@" or spaces`)
+	}
+
+	errs.Tags("tags", c.Tags)
+	c.Waiters.Validate(&errs, "waiters", RdsInstanceWaiter)
+
+	return errs.Err()
+}
@@ -0,0 +1,84 @@
+package rds
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		update   func(resourceConfig *RdsConfig)
+		expected validation.Errors
+	}{
+		{
+			name:   "sample config",
+			update: func(resourceConfig *RdsConfig) {},
+		},
+		{
+			name: "port for another engine",
+			update: func(resourceConfig *RdsConfig) {
+				resourceConfig.Engine = "postgres"
+			},
+			expected: validation.Errors{
+				{Field: "dbPort", Message: "must be 5432, the port the postgres engine listens on"},
+			},
+		},
+		{
+			name: "unsupported engine",
+			update: func(resourceConfig *RdsConfig) {
+				resourceConfig.Engine = "aurora"
+			},
+			expected: validation.Errors{
+				{
+					Field:   "engine",
+					Message: `"aurora" is not a supported engine, must be one of mariadb, mysql, oracle-ee, oracle-ee-cdb, oracle-se2, oracle-se2-cdb, postgres, sqlserver-ee, sqlserver-ex, sqlserver-se, sqlserver-web`,
+				},
+			},
+		},
+		{
+			name: "every problem",
+			update: func(resourceConfig *RdsConfig) {
+				resourceConfig.Name = "wordpress-db-"
+				resourceConfig.SubnetIds = []string{"sg-0c55f5bcaa09bef68"}
+				resourceConfig.StorageGb = 10
+				resourceConfig.DbUserPassword = "pass word"
+			},
+			expected: validation.Errors{
+				{Field: "name", Message: "must begin with a letter and contain only letters, digits and single hyphens, and must not end with a hyphen"},
+				{Field: "subnetIds", Message: "at least 2 subnets in different availability zones are required"},
+				{Field: "subnetIds[0]", Message: `"sg-0c55f5bcaa09bef68" is not a subnet ID`},
+				{Field: "storageGb", Message: "must be 20 or greater"},
+				{Field: "dbUserPassword", Message: `must not contain "/", '"', "@" or spaces`},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resourceConfig, err := LoadRdsConfig("../../sample/rds-config.yaml")
+			if err != nil {
+				t.Fatalf("failed to load sample config: %v", err)
+			}
+			testCase.update(resourceConfig)
+
+			err = resourceConfig.Validate()
+			if testCase.expected == nil {
+				if err != nil {
+					t.Fatalf("expected config to be valid, got %v", err)
+				}
+				return
+			}
+			var errs validation.Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+			if !reflect.DeepEqual(errs, testCase.expected) {
+				t.Errorf("expected problems\n%v\ngot\n%v", testCase.expected, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package rds
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
+
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// VerifyRdsResourceStack checks that each resource in the inventory still
+// exists and is in the state the resource stack expects.  If a resource config
+// is supplied, the instance class, engine version and port are also compared
+// against it.  Only read-only calls are made.
+func (c *RdsClient) VerifyRdsResourceStack(
+	inventory *RdsInventory,
+	resourceConfig *RdsConfig,
+) (*drift.Report, error) {
+	if inventory.Region != "" {
+		c.AwsConfig.Region = inventory.Region
+	}
+	report := drift.NewReport("rds", c.AwsConfig.Region)
+
+	// Security Group
+	if inventory.SecurityGroupId != "" {
+		resp, err := c.GetEc2Api().DescribeSecurityGroups(c.Context, &aws_ec2.DescribeSecurityGroupsInput{
+			GroupIds: []string{inventory.SecurityGroupId},
+		})
+		switch {
+		case util.HasErrorCode(err, "InvalidGroup.NotFound", "InvalidGroupId.NotFound"), err == nil && len(resp.SecurityGroups) == 0:
+			report.Missing("security-group", inventory.SecurityGroupId)
+		case err != nil:
+			return nil, fmt.Errorf("failed to describe security group %s: %w", inventory.SecurityGroupId, err)
+		default:
+			result := report.Found("security-group", inventory.SecurityGroupId)
+			securityGroup := resp.SecurityGroups[0]
+			if resourceConfig != nil && resourceConfig.VpcId != "" && securityGroup.VpcId != nil &&
+				*securityGroup.VpcId != resourceConfig.VpcId {
+				result.Modified("in VPC %s, expected %s", *securityGroup.VpcId, resourceConfig.VpcId)
+			}
+		}
+	}
+
+	// Subnet Group
+	if inventory.SubnetGroupName != "" {
+		resp, err := c.GetRdsApi().DescribeDBSubnetGroups(c.Context, &aws_rds.DescribeDBSubnetGroupsInput{
+			DBSubnetGroupName: &inventory.SubnetGroupName,
+		})
+		switch {
+		case util.HasErrorCode(err, "DBSubnetGroupNotFoundFault"), err == nil && len(resp.DBSubnetGroups) == 0:
+			report.Missing("rds-subnet-group", inventory.SubnetGroupName)
+		case err != nil:
+			return nil, fmt.Errorf("failed to describe subnet group %s: %w", inventory.SubnetGroupName, err)
+		default:
+			result := report.Found("rds-subnet-group", inventory.SubnetGroupName)
+			if resourceConfig != nil {
+				var subnetIds []string
+				for _, subnet := range resp.DBSubnetGroups[0].Subnets {
+					subnetIds = append(subnetIds, *subnet.SubnetIdentifier)
+				}
+				for _, subnetId := range resourceConfig.SubnetIds {
+					if !slices.Contains(subnetIds, subnetId) {
+						result.Modified("subnet %s not in subnet group", subnetId)
+					}
+				}
+				for _, subnetId := range subnetIds {
+					if !slices.Contains(resourceConfig.SubnetIds, subnetId) {
+						result.Unexpected("subnet %s not in config", subnetId)
+					}
+				}
+			}
+		}
+	}
+
+	// RDS Instance
+	if inventory.RdsInstanceId != "" {
+		instance, err := c.getRdsInstance(inventory.RdsInstanceId)
+		switch {
+		case errors.Is(err, util.ErrResourceNotFound):
+			report.Missing("rds-instance", inventory.RdsInstanceId)
+		case err != nil:
+			return nil, err
+		default:
+			result := report.Found("rds-instance", inventory.RdsInstanceId)
+			if instance.DBInstanceStatus != nil && *instance.DBInstanceStatus != "available" {
+				result.Modified("status is %s", *instance.DBInstanceStatus)
+			}
+			if inventory.RdsInstanceEndpoint != "" && instance.Endpoint != nil && instance.Endpoint.Address != nil &&
+				*instance.Endpoint.Address != inventory.RdsInstanceEndpoint {
+				result.Modified("endpoint is %s, expected %s", *instance.Endpoint.Address, inventory.RdsInstanceEndpoint)
+			}
+			if inventory.SubnetGroupName != "" && instance.DBSubnetGroup != nil && instance.DBSubnetGroup.DBSubnetGroupName != nil &&
+				*instance.DBSubnetGroup.DBSubnetGroupName != inventory.SubnetGroupName {
+				result.Modified("subnet group is %s, expected %s", *instance.DBSubnetGroup.DBSubnetGroupName, inventory.SubnetGroupName)
+			}
+			var securityGroupIds []string
+			for _, securityGroup := range instance.VpcSecurityGroups {
+				securityGroupIds = append(securityGroupIds, *securityGroup.VpcSecurityGroupId)
+				if *securityGroup.VpcSecurityGroupId != inventory.SecurityGroupId {
+					result.Unexpected("security group %s attached outside of resource stack", *securityGroup.VpcSecurityGroupId)
+				}
+			}
+			if inventory.SecurityGroupId != "" && !slices.Contains(securityGroupIds, inventory.SecurityGroupId) {
+				result.Modified("security group %s not attached", inventory.SecurityGroupId)
+			}
+			if resourceConfig != nil {
+				if resourceConfig.Class != "" && instance.DBInstanceClass != nil && *instance.DBInstanceClass != resourceConfig.Class {
+					result.Modified("instance class is %s, expected %s", *instance.DBInstanceClass, resourceConfig.Class)
+				}
+				if resourceConfig.EngineVersion != "" && instance.EngineVersion != nil && *instance.EngineVersion != resourceConfig.EngineVersion {
+					result.Modified("engine version is %s, expected %s", *instance.EngineVersion, resourceConfig.EngineVersion)
+				}
+				if resourceConfig.DbPort != 0 && instance.Endpoint != nil && instance.Endpoint.Port != nil &&
+					*instance.Endpoint.Port != resourceConfig.DbPort {
+					result.Modified("port is %d, expected %d", *instance.Endpoint.Port, resourceConfig.DbPort)
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,106 @@
+package rds
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
+
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+// resultStatuses returns the status of each resource in the report.
+func resultStatuses(report *drift.Report) map[string]drift.Status {
+	statuses := make(map[string]drift.Status)
+	for _, result := range report.Results {
+		statuses[result.Kind] = result.Status
+	}
+
+	return statuses
+}
+
+func TestVerifyRdsResourceStack(t *testing.T) {
+	backend := fake.NewBackend(fake.DefaultRegion)
+	resourceConfig := sampleConfig(t, backend)
+	rdsClient := RdsClient{ResourceClient: *backend.ResourceClient()}
+	rdsApi := backend.Apis().Rds
+	ctx := context.Background()
+
+	var inventory RdsInventory
+	if err := rdsClient.CreateRdsResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+
+	report, err := rdsClient.VerifyRdsResourceStack(&inventory, resourceConfig)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	if report.HasDrift() {
+		t.Fatalf("expected no drift after create, got %s", report.Summary())
+	}
+
+	// config values that differ from the instance are modifications
+	class, port := resourceConfig.Class, resourceConfig.DbPort
+	resourceConfig.Class = "db.r5.large"
+	resourceConfig.DbPort = port + 1
+	report, err = rdsClient.VerifyRdsResourceStack(&inventory, resourceConfig)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	expected := map[string]drift.Status{
+		"security-group":   drift.StatusOk,
+		"rds-subnet-group": drift.StatusOk,
+		"rds-instance":     drift.StatusModified,
+	}
+	if statuses := resultStatuses(report); !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected statuses %v, got %v", expected, statuses)
+	}
+	for _, result := range report.Results {
+		if result.Kind != "rds-instance" {
+			continue
+		}
+		details := []string{
+			"instance class is " + class + ", expected db.r5.large",
+			"port is " + fmt.Sprint(port) + ", expected " + fmt.Sprint(port+1),
+		}
+		if !reflect.DeepEqual(result.Details, details) {
+			t.Errorf("expected details %v, got %v", details, result.Details)
+		}
+	}
+
+	// deleted resources are missing
+	if _, err := rdsApi.DeleteDBInstance(ctx, &aws_rds.DeleteDBInstanceInput{
+		DBInstanceIdentifier: aws.String(inventory.RdsInstanceId),
+		SkipFinalSnapshot:    aws.Bool(true),
+	}); err != nil {
+		t.Fatalf("failed to delete instance: %v", err)
+	}
+	report, err = rdsClient.VerifyRdsResourceStack(&inventory, nil)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	expected = map[string]drift.Status{
+		"security-group":   drift.StatusOk,
+		"rds-subnet-group": drift.StatusOk,
+		"rds-instance":     drift.StatusMissing,
+	}
+	if statuses := resultStatuses(report); !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected statuses %v, got %v", expected, statuses)
+	}
+	if _, err := rdsApi.DeleteDBSubnetGroup(ctx, &aws_rds.DeleteDBSubnetGroupInput{
+		DBSubnetGroupName: aws.String(inventory.SubnetGroupName),
+	}); err != nil {
+		t.Fatalf("failed to delete subnet group: %v", err)
+	}
+	report, err = rdsClient.VerifyRdsResourceStack(&inventory, nil)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	if status := resultStatuses(report)["rds-subnet-group"]; status != drift.StatusMissing {
+		t.Errorf("expected subnet group to be missing, got %s", status)
+	}
+}
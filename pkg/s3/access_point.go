@@ -5,6 +5,8 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/s3control"
 	"github.com/aws/aws-sdk-go-v2/service/s3control/types"
+
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreateAccessPoint creates an access point for an S3 bucket.
@@ -14,7 +16,7 @@ func (c *S3Client) CreateAccessPoint(
 	awsAccount string,
 	putAccessVpcId string,
 ) (string, error) {
-	svc := s3control.NewFromConfig(*c.AwsConfig)
+	svc := c.GetS3ControlApi()
 
 	createAccessPointInput := s3control.CreateAccessPointInput{
 		AccountId: &awsAccount,
@@ -32,7 +34,8 @@ func (c *S3Client) CreateAccessPoint(
 	return name, nil
 }
 
-// DeleteAccessPoint deletes an access point for an S3 bucket.
+// DeleteAccessPoint deletes an access point for an S3 bucket.  If the access
+// point is not found it returns without error.
 func (c *S3Client) DeleteAccessPoint(
 	accessPointName string,
 	awsAccount string,
@@ -41,14 +44,14 @@ func (c *S3Client) DeleteAccessPoint(
 		return nil
 	}
 
-	svc := s3control.NewFromConfig(*c.AwsConfig)
+	svc := c.GetS3ControlApi()
 
 	deleteAccessPointInput := s3control.DeleteAccessPointInput{
 		AccountId: &awsAccount,
 		Name:      &accessPointName,
 	}
 	_, err := svc.DeleteAccessPoint(c.Context, &deleteAccessPointInput)
-	if err != nil {
+	if err != nil && !util.HasErrorCode(err, "NoSuchAccessPoint") {
 		return fmt.Errorf("failed to delete S3 access point: %s: %w", accessPointName, err)
 	}
 
@@ -0,0 +1,88 @@
+package s3
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_s3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// AdoptS3ResourceStack records an existing S3 bucket that was not created by
+// aws-builder in the inventory as adopted.  Only the bucket is adopted since
+// access points, policies and roles for it can't be found from the bucket.
+// If applyTags is true, the Name tag and custom tags are added to the
+// bucket's existing tags.
+func (c *S3Client) AdoptS3ResourceStack(
+	bucketName string,
+	tags map[string]string,
+	applyTags bool,
+	inventory *S3Inventory,
+) (*discovery.Report, error) {
+	inventory.Region = c.AwsConfig.Region
+	report := discovery.NewReport("s3", inventory.Region)
+
+	svc := c.GetS3Api()
+
+	// Bucket
+	_, err := svc.GetBucketVersioning(c.Context, &aws_s3.GetBucketVersioningInput{
+		Bucket: &bucketName,
+	})
+	switch {
+	case util.HasErrorCode(err, "NoSuchBucket"):
+		return nil, fmt.Errorf("S3 bucket %s not found", bucketName)
+	case err != nil:
+		return nil, fmt.Errorf("failed to get versioning for S3 bucket %s: %w", bucketName, err)
+	}
+	inventory.BucketName = report.Add("s3-bucket", bucketName, bucketName)
+	inventory.Adopted = append(inventory.Adopted, bucketName)
+
+	if !applyTags {
+		return report, nil
+	}
+
+	// tagging a bucket replaces all its tags so existing tags are kept
+	// unless overridden
+	var bucketTags []types.Tag
+	tagsResp, err := svc.GetBucketTagging(c.Context, &aws_s3.GetBucketTaggingInput{
+		Bucket: &bucketName,
+	})
+	switch {
+	case util.HasErrorCode(err, "NoSuchTagSet"):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get tags for S3 bucket %s: %w", bucketName, err)
+	default:
+		bucketTags = tagsResp.TagSet
+	}
+	for _, tag := range *CreateS3Tags(bucketName, tags) {
+		bucketTags = slices.DeleteFunc(bucketTags, func(bucketTag types.Tag) bool {
+			return aws.ToString(bucketTag.Key) == aws.ToString(tag.Key)
+		})
+		bucketTags = append(bucketTags, tag)
+	}
+	if _, err := svc.PutBucketTagging(c.Context, &aws_s3.PutBucketTaggingInput{
+		Bucket:  &bucketName,
+		Tagging: &types.Tagging{TagSet: bucketTags},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to tag S3 bucket %s: %w", bucketName, err)
+	}
+
+	return report, nil
+}
+
+// keepAdopted returns true if the resource was adopted and adopted resources
+// are not being deleted, in which case an event is sent reporting the
+// resource is kept.
+func (c *S3Client) keepAdopted(inventory *S3Inventory, kind string, id string) bool {
+	if c.DeleteAdopted || id == "" || !slices.Contains(inventory.Adopted, id) {
+		return false
+	}
+	c.sendEvent(kind, client.PhaseKeptAdopted, fmt.Sprintf("%s %s was adopted and is kept", kind, id), id)
+
+	return true
+}
@@ -0,0 +1,80 @@
+package s3
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_s3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+func TestAdoptS3ResourceStack(t *testing.T) {
+	testCases := []struct {
+		name          string
+		deleteAdopted bool
+	}{
+		{name: "adopted resources kept", deleteAdopted: false},
+		{name: "adopted resources deleted", deleteAdopted: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			backend := fake.NewBackend(fake.DefaultRegion)
+			s3Api := backend.Apis().S3
+			ctx := context.Background()
+			bucketName := "adopted-bucket-0"
+			if _, err := s3Api.CreateBucket(ctx, &aws_s3.CreateBucketInput{Bucket: aws.String(bucketName)}); err != nil {
+				t.Fatalf("failed to create bucket: %v", err)
+			}
+			if _, err := s3Api.PutBucketTagging(ctx, &aws_s3.PutBucketTaggingInput{
+				Bucket: aws.String(bucketName),
+				Tagging: &types.Tagging{TagSet: []types.Tag{
+					{Key: aws.String("Owner"), Value: aws.String("data")},
+					{Key: aws.String("Tier"), Value: aws.String("prod")},
+				}},
+			}); err != nil {
+				t.Fatalf("failed to tag bucket: %v", err)
+			}
+			s3Client := S3Client{ResourceClient: *backend.ResourceClient()}
+
+			var inventory S3Inventory
+			if _, err := s3Client.AdoptS3ResourceStack(bucketName, map[string]string{"Tier": "test"}, true, &inventory); err != nil {
+				t.Fatalf("failed to adopt resource stack: %v", err)
+			}
+			if inventory.BucketName != bucketName || !reflect.DeepEqual(inventory.Adopted, []string{bucketName}) {
+				t.Errorf("expected bucket %s to be adopted, got %+v", bucketName, inventory)
+			}
+			tagging, err := s3Api.GetBucketTagging(ctx, &aws_s3.GetBucketTaggingInput{Bucket: aws.String(bucketName)})
+			if err != nil {
+				t.Fatalf("failed to get bucket tags: %v", err)
+			}
+			tags := make(map[string]string)
+			for _, tag := range tagging.TagSet {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			if tags["Owner"] != "data" || tags["Tier"] != "test" || tags["Name"] != bucketName {
+				t.Errorf("expected existing tags to be kept unless overridden, got %v", tags)
+			}
+
+			s3Client.DeleteAdopted = testCase.deleteAdopted
+			if err := s3Client.DeleteS3ResourceStack(&inventory); err != nil {
+				t.Fatalf("failed to delete resource stack: %v", err)
+			}
+
+			buckets := backend.Resources()["s3-bucket"]
+			if testCase.deleteAdopted {
+				if len(buckets) != 0 || inventory.BucketName != "" {
+					t.Errorf("expected adopted bucket to be deleted, found %v in inventory %+v", buckets, inventory)
+				}
+				return
+			}
+			if !reflect.DeepEqual(buckets, []string{bucketName}) || inventory.BucketName != bucketName {
+				t.Errorf("expected adopted bucket to be kept, found %v in inventory %+v", buckets, inventory)
+			}
+		})
+	}
+}
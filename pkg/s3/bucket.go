@@ -8,6 +8,8 @@ import (
 
 	aws_s3 "github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
 // CreateBucket creates a new S3 bucket.
@@ -16,7 +18,7 @@ func (c *S3Client) CreateBucket(
 	bucketName string,
 	region string,
 ) (string, error) {
-	svc := aws_s3.NewFromConfig(*c.AwsConfig)
+	svc := c.GetS3Api()
 
 	// determine if region is among the supported bucket location constraints
 	createBucketConfig := types.CreateBucketConfiguration{}
@@ -82,19 +84,20 @@ func (c *S3Client) CreateBucket(
 	return uniqueBucketName, nil
 }
 
-// DeleteBucket deletes an S3 bucket.
+// DeleteBucket deletes an S3 bucket.  If the bucket is not found it returns
+// without error.
 func (c *S3Client) DeleteBucket(bucketName string) error {
 	if bucketName == "" {
 		return nil
 	}
 
-	svc := aws_s3.NewFromConfig(*c.AwsConfig)
+	svc := c.GetS3Api()
 
 	deleteBucketInput := aws_s3.DeleteBucketInput{
 		Bucket: &bucketName,
 	}
 	_, err := svc.DeleteBucket(c.Context, &deleteBucketInput)
-	if err != nil {
+	if err != nil && !util.HasErrorCode(err, "NoSuchBucket") {
 		return fmt.Errorf("failed to delete S3 bucket %s: %w", bucketName, err)
 	}
 
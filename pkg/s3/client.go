@@ -12,3 +12,37 @@ type S3Client struct {
 	// as resources are created and deleted.
 	InventoryChan *chan S3Inventory
 }
+
+// sendEvent sends a progress event for resources in the S3 resource stack.
+// The message is the text rendering of the event.
+func (c *S3Client) sendEvent(kind string, phase client.Phase, message string, ids ...string) {
+	c.SendEvent(client.Event{
+		Stack:   "s3",
+		Kind:    kind,
+		Ids:     ids,
+		Phase:   phase,
+		Message: message,
+	})
+}
+
+// sendFailed sends a failed event for a resource in the S3 resource stack
+// and returns the error.
+func (c *S3Client) sendFailed(kind string, err error) error {
+	c.SendEvent(client.Event{
+		Stack: "s3",
+		Kind:  kind,
+		Phase: client.PhaseFailed,
+		Error: err.Error(),
+	})
+
+	return err
+}
+
+// sendInventory notifies the observer and sends on the inventory channel, if
+// present, the latest inventory.
+func (c *S3Client) sendInventory(inventory *S3Inventory) {
+	c.SendInventory(*inventory)
+	if c.InventoryChan != nil {
+		*c.InventoryChan <- client.CopyInventory(*inventory)
+	}
+}
@@ -1,10 +1,8 @@
 package s3
 
 import (
-	"fmt"
-	"io/ioutil"
-
-	"gopkg.in/yaml.v2"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/waiter"
 )
 
 // S3Config contains the configurable parameters for an S3 bucket.
@@ -16,6 +14,7 @@ type S3Config struct {
 	VpcIdReadWriteAccess    string            `yaml:"vpcIdReadWriteAccess"`
 	PublicReadAccess        bool              `yaml:"publicReadAccess"`
 	WorkloadReadWriteAccess WorkloadAccess    `yaml:"workloadReadWriteAccess"`
+	Waiters                 waiter.Configs    `yaml:"waiters"`
 }
 
 type WorkloadAccess struct {
@@ -24,17 +23,45 @@ type WorkloadAccess struct {
 	OidcUrl                 string `yaml:"oidcUrl"`
 }
 
-// LoadS3Config loads an S3 config from a config file and returns the
-// S3Config object.
-func LoadS3Config(configFile string) (*S3Config, error) {
-	configYaml, err := ioutil.ReadFile(configFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// DefaultServiceAccountNamespace is the namespace of the workload's service
+// account if none is provided.
+const DefaultServiceAccountNamespace = "default"
+
+// LoadS3Config loads an S3 config from one or more YAML or JSON config
+// files and returns the S3Config object.  Values in later files override
+// values in earlier files.  Values not set in any file are set to the defaults
+// from NewS3Config.  See configfile.Load for details.
+func LoadS3Config(configFiles ...string) (*S3Config, error) {
+	return LoadS3ConfigWithOutputs(nil, configFiles...)
+}
+
+// LoadS3ConfigWithOutputs loads an S3 config like LoadS3Config and also
+// replaces ${stack.output} references with the outputs of other resource
+// stacks.  See configfile.LoadWithOutputs for details.
+func LoadS3ConfigWithOutputs(outputs configfile.Outputs, configFiles ...string) (*S3Config, error) {
+	s3Config := NewS3Config()
+	if err := configfile.LoadWithOutputs(s3Config, outputs, configFiles...); err != nil {
+		return nil, err
 	}
-	var s3Config S3Config
-	if err := yaml.Unmarshal(configYaml, &s3Config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal yaml from config file: %w", err)
+
+	return s3Config, nil
+}
+
+// NewS3Config returns an S3Config with default values set.
+func NewS3Config() *S3Config {
+	return &S3Config{
+		WorkloadReadWriteAccess: WorkloadAccess{
+			ServiceAccountNamespace: DefaultServiceAccountNamespace,
+		},
 	}
+}
+
+// GetRegion returns the region resources are created in.
+func (c *S3Config) GetRegion() string {
+	return c.Region
+}
 
-	return &s3Config, nil
+// SetRegion sets the region resources are created in.
+func (c *S3Config) SetRegion(region string) {
+	c.Region = region
 }
@@ -0,0 +1,39 @@
+package s3
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadS3ConfigDefaults(t *testing.T) {
+	testCases := []struct {
+		name      string
+		content   string
+		namespace string
+	}{
+		{name: "default namespace", content: "name: test-0\n", namespace: DefaultServiceAccountNamespace},
+		{
+			name:      "file namespace",
+			content:   "workloadReadWriteAccess:\n  serviceAccountNamespace: workloads\n",
+			namespace: "workloads",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "s3-config.yaml")
+			if err := os.WriteFile(configFile, []byte(testCase.content), 0644); err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+
+			resourceConfig, err := LoadS3Config(configFile)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			if namespace := resourceConfig.WorkloadReadWriteAccess.ServiceAccountNamespace; namespace != testCase.namespace {
+				t.Errorf("expected service account namespace %s, got %s", testCase.namespace, namespace)
+			}
+		})
+	}
+}
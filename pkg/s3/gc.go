@@ -0,0 +1,113 @@
+package s3
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_s3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/orphan"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// uuidSuffix matches the UUID CreateBucket adds to bucket names to make them
+// unique.
+const uuidSuffix = `-[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`
+
+// OrphansS3ResourceStack searches for buckets in the region named with the
+// resource stack's name and a UUID suffix, as CreateBucket names them, that
+// are not in the inventory.  Buckets are only reported if they also have the
+// resource stack's tags so buckets with similar names are not mistaken for
+// orphans.  Since every create makes a new bucket, each failed or repeated
+// create can leave one behind.  Only read-only calls are made.
+func (c *S3Client) OrphansS3ResourceStack(
+	resourceConfig *S3Config,
+	inventory *S3Inventory,
+	broad bool,
+) (*orphan.Report, error) {
+	// resource config region takes precedence
+	// if not set, use the region defined in AWS config
+	region := resourceConfig.Region
+	if region != "" {
+		c.AwsConfig.Region = region
+	} else {
+		region = c.AwsConfig.Region
+	}
+
+	report := orphan.NewReport("s3", region, broad)
+
+	// the config's tags are copied since the Name tag is added to them
+	stackTags := util.CreateMapTags(resourceConfig.Name, maps.Clone(resourceConfig.Tags))
+	bucketNamePattern := regexp.MustCompile("^" + regexp.QuoteMeta(resourceConfig.Name) + uuidSuffix)
+	paginator := aws_s3.NewListBucketsPaginator(c.GetS3Api(), &aws_s3.ListBucketsInput{
+		Prefix:       aws.String(resourceConfig.Name + "-"),
+		BucketRegion: aws.String(region),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(c.Context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list S3 buckets to find orphans: %w", err)
+		}
+		for _, bucket := range resp.Buckets {
+			bucketName := aws.ToString(bucket.Name)
+			if !bucketNamePattern.MatchString(bucketName) || bucketName == inventory.BucketName {
+				continue
+			}
+			tagged, err := c.hasTags(bucketName, stackTags)
+			if err != nil {
+				return nil, err
+			}
+			if tagged {
+				report.Add("s3-bucket", bucketName, "")
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// hasTags returns true if the bucket has every one of the tags.  A bucket
+// without tags has none of them.
+func (c *S3Client) hasTags(bucketName string, tags map[string]string) (bool, error) {
+	resp, err := c.GetS3Api().GetBucketTagging(c.Context, &aws_s3.GetBucketTaggingInput{
+		Bucket: &bucketName,
+	})
+	switch {
+	case util.HasErrorCode(err, "NoSuchTagSet"):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to get tags for S3 bucket %s to find orphans: %w", bucketName, err)
+	}
+
+	bucketTags := make(map[string]string)
+	for _, tag := range resp.TagSet {
+		bucketTags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	for key, value := range tags {
+		if bucketValue, ok := bucketTags[key]; !ok || bucketValue != value {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// DeleteS3Orphans deletes the orphaned buckets in the report.  Buckets that
+// still contain objects are not emptied and fail to be deleted.
+func (c *S3Client) DeleteS3Orphans(report *orphan.Report) error {
+	c.AwsConfig.Region = report.Region
+
+	for _, o := range report.Kind("s3-bucket") {
+		if err := c.DeleteBucket(o.Id); err != nil {
+			c.sendFailed(o.Kind, o.Failed(err))
+			continue
+		}
+		o.Deleted()
+		c.sendEvent(o.Kind, client.PhaseDeleted, fmt.Sprintf("orphaned S3 bucket %s deleted", o.Id), o.Id)
+	}
+
+	return report.Err()
+}
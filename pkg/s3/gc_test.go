@@ -0,0 +1,90 @@
+package s3
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_s3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+	"github.com/nukleros/aws-builder/pkg/orphan"
+)
+
+func TestOrphansS3ResourceStack(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	s3Client := S3Client{ResourceClient: *backend.ResourceClient()}
+	s3Api := backend.Apis().S3
+	ctx := context.Background()
+
+	var inventory S3Inventory
+	if err := s3Client.CreateS3ResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+
+	// a bucket left behind by a failed create has the resource stack's tags
+	leftoverBucketName, err := s3Client.CreateBucket(
+		CreateS3Tags(resourceConfig.Name, resourceConfig.Tags),
+		resourceConfig.Name,
+		resourceConfig.Region,
+	)
+	if err != nil {
+		t.Fatalf("failed to create leftover bucket: %v", err)
+	}
+
+	// buckets named like the resource stack's without its tags are not its
+	for bucketName, tags := range map[string][]types.Tag{
+		resourceConfig.Name + "-5d1b1c0e-8f3e-4d8e-9c55-3a0b1e2f4c6d": nil,
+		resourceConfig.Name + "-0c9e2a51-7b44-4f6a-a1d2-6e3f5b8c9d0e": {
+			{Key: aws.String("Name"), Value: aws.String(resourceConfig.Name)},
+			{Key: aws.String("foo"), Value: aws.String("baz")},
+		},
+	} {
+		if _, err := s3Api.CreateBucket(ctx, &aws_s3.CreateBucketInput{Bucket: aws.String(bucketName)}); err != nil {
+			t.Fatalf("failed to create bucket: %v", err)
+		}
+		if tags == nil {
+			continue
+		}
+		if _, err := s3Api.PutBucketTagging(ctx, &aws_s3.PutBucketTaggingInput{
+			Bucket:  aws.String(bucketName),
+			Tagging: &types.Tagging{TagSet: tags},
+		}); err != nil {
+			t.Fatalf("failed to tag bucket: %v", err)
+		}
+	}
+
+	report, err := s3Client.OrphansS3ResourceStack(resourceConfig, &inventory, false)
+	if err != nil {
+		t.Fatalf("failed to find orphans: %v", err)
+	}
+	var orphanIds []string
+	for _, o := range report.Orphans {
+		orphanIds = append(orphanIds, o.Id)
+	}
+	if !reflect.DeepEqual(orphanIds, []string{leftoverBucketName}) {
+		t.Fatalf("expected only leftover bucket %s to be an orphan, got %v", leftoverBucketName, orphanIds)
+	}
+	if _, ok := resourceConfig.Tags["Name"]; ok {
+		t.Errorf("expected config tags not to be changed, got %v", resourceConfig.Tags)
+	}
+
+	if err := s3Client.DeleteS3Orphans(report); err != nil {
+		t.Fatalf("failed to delete orphans: %v", err)
+	}
+	if report.Orphans[0].Status != orphan.StatusDeleted {
+		t.Errorf("expected orphan to be deleted, got %s", report.Orphans[0].Status)
+	}
+	buckets := backend.Resources()["s3-bucket"]
+	if len(buckets) != 3 {
+		t.Errorf("expected the inventory bucket and untagged buckets to remain, found %v", buckets)
+	}
+	for _, bucketName := range buckets {
+		if bucketName == leftoverBucketName {
+			t.Errorf("expected leftover bucket %s to be deleted", leftoverBucketName)
+		}
+	}
+}
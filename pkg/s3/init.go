@@ -1,75 +1,89 @@
 package s3
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/state"
 )
 
-// InitCreate initializes S3 resource creation by creating an inventory
-// channel, starting a goroutine to write inventory to file, creating the S3
-// client and loading the S3 configuration.
+// InitCreate initializes S3 resource creation by loading and validating the S3
+// configuration, locking the state, adding the current inventory to the state history,
+// creating an inventory channel, starting a goroutine to write inventory to
+// state and creating the S3 client.  The state is unlocked once the inventory
+// channel is closed and the last inventory has been written.
 func InitCreate(
 	resourceClient *client.ResourceClient,
-	configFile string,
-	inventoryFile string,
+	configFiles []string,
+	backend state.Backend,
 	inventoryChan *chan S3Inventory,
 	createWait *sync.WaitGroup,
 ) (*S3Client, *S3Config, error) {
-	// capture inventory and write to file as resources are created
-	createWait.Add(1)
-	go func() {
-		defer createWait.Done()
-		for inventory := range *inventoryChan {
-			if err := inventory.Write(inventoryFile); err != nil {
-				fmt.Printf("failed to write inventory file: %s", err)
-			}
-		}
-	}()
+	// load config
+	s3Config, err := LoadS3Config(configFiles...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load S3 config file: %w", err)
+	}
+
+	// validate config before any resources are changed
+	if err := s3Config.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid S3 config: %w", err)
+	}
 
-	// create client and load config
+	// lock state so no other operation can change the resource stack
+	lockInfo, err := state.Acquire(resourceClient.Context, backend, "create")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// capture inventory and write to state as resources are created
+	state.StartWriter(resourceClient.Context, backend, lockInfo, *inventoryChan, createWait)
+
+	// create client
 	s3Client := S3Client{
 		*resourceClient,
 		inventoryChan,
 	}
-	s3Config, err := LoadS3Config(configFile)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load S3 config file: %w", err)
-	}
 
 	return &s3Client, s3Config, nil
 }
 
-// InitDelete initializes S3 resource deletion by creating an inventory
-// channel, starting a goroutine to write inventory updates to file, creating
-// the S3 client and loading the inventory to be deleted.
+// InitDelete initializes S3 resource deletion by locking the state, adding the
+// current inventory to the state history, loading the inventory to be deleted,
+// creating an inventory channel, starting a goroutine to write inventory
+// updates to state and creating the S3 client.  The state is unlocked once
+// the inventory channel is closed and the last inventory has been written.
 func InitDelete(
 	resourceClient *client.ResourceClient,
-	inventoryFile string,
+	backend state.Backend,
 	inventoryChan *chan S3Inventory,
 	deleteWait *sync.WaitGroup,
 ) (*S3Client, *S3Inventory, error) {
-	// capture inventory and write to file as resources are deleted
-	deleteWait.Add(1)
-	go func() {
-		defer deleteWait.Done()
-		for inventory := range *inventoryChan {
-			if err := inventory.Write(inventoryFile); err != nil {
-				fmt.Printf("failed to write inventory file: %s", err)
-			}
+	// lock state so no other operation can change the resource stack
+	lockInfo, err := state.Acquire(resourceClient.Context, backend, "delete")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// load inventory to delete
+	var s3Inventory S3Inventory
+	if err := s3Inventory.LoadState(resourceClient.Context, backend); err != nil {
+		if unlockErr := backend.Unlock(resourceClient.Context, lockInfo.Id); unlockErr != nil {
+			return nil, nil, fmt.Errorf("failed to load S3 inventory and unlock state: %w", errors.Join(err, unlockErr))
 		}
-	}()
+		return nil, nil, fmt.Errorf("failed to load S3 inventory: %w", err)
+	}
+
+	// capture inventory and write to state as resources are deleted
+	state.StartWriter(resourceClient.Context, backend, lockInfo, *inventoryChan, deleteWait)
 
-	// create client and load inventory to delete
+	// create client
 	s3Client := S3Client{
 		*resourceClient,
 		inventoryChan,
 	}
-	var s3Inventory S3Inventory
-	if err := s3Inventory.Load(inventoryFile); err != nil {
-		return nil, nil, fmt.Errorf("failed to load S3 inventory file: %w", err)
-	}
 
 	return &s3Client, &s3Inventory, nil
 }
@@ -1,19 +1,46 @@
 package s3
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"os"
+
+	"github.com/nukleros/aws-builder/pkg/inventory"
+	"github.com/nukleros/aws-builder/pkg/state"
+	"github.com/nukleros/aws-builder/pkg/util"
 )
 
+// InventorySchemaVersion is the schema version of S3 inventories written by
+// this version of aws-builder.  When the inventory changes in a way that
+// older inventories need to be upgraded for, increment the version and
+// register a migration from the previous version.
+const InventorySchemaVersion = 3
+
+func init() {
+	inventory.Register(inventory.Migration{
+		Stack:       "s3",
+		FromVersion: inventory.UnversionedSchemaVersion,
+		Description: "add schema version to inventories written before versioning",
+	})
+	inventory.Register(inventory.Migration{
+		Stack:       "s3",
+		FromVersion: 2,
+		Description: "add adopted resources that older versions would delete",
+	})
+}
+
+// S3Inventory contains the resources for an S3 bucket.  Adopted contains the
+// names of resources that were not created by aws-builder.
 type S3Inventory struct {
+	SchemaVersion   int           `json:"schemaVersion"`
 	AwsAccount      string        `json:"awsAccount"`
 	Region          string        `json:"region"`
 	BucketName      string        `json:"bucketName"`
 	AccessPointName string        `json:"accessPointName"`
 	PolicyArn       string        `json:"policyArn"`
 	Role            RoleInventory `json:"role"`
+	Adopted         []string      `json:"adopted"`
 }
 
 // RoleInventory contains the details for a created role.
@@ -23,21 +50,15 @@ type RoleInventory struct {
 	RolePolicyArns []string `json:"rolePolicyArns"`
 }
 
-// send sends the S3 inventory on the inventory channel.
-func (i *S3Inventory) send(inventoryChan *chan S3Inventory) {
-	if inventoryChan != nil {
-		*inventoryChan <- *i
-	}
-}
-
-// Write writes S3 inventory to file.
+// Write writes S3 inventory to file.  The file is replaced atomically so a
+// crash cannot leave a partially written inventory.
 func (i *S3Inventory) Write(inventoryFile string) error {
 	invJson, err := i.Marshal()
 	if err != nil {
 		return fmt.Errorf("failed to marshal S3 inventory to JSON: %w", err)
 	}
 
-	if err := os.WriteFile(inventoryFile, invJson, 0644); err != nil {
+	if err := util.WriteFileAtomic(inventoryFile, invJson, 0644); err != nil {
 		return fmt.Errorf("failed to write S3 inventory to file: %w", err)
 	}
 
@@ -56,9 +77,37 @@ func (i *S3Inventory) Load(inventoryFile string) error {
 	return i.Unmarshal(inventoryBytes)
 }
 
-// Marshal returns the JSON S3 inventory from an S3Inventory object.
+// WriteState writes S3 inventory to a state backend.
+func (i *S3Inventory) WriteState(ctx context.Context, backend state.Backend) error {
+	invJson, err := i.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal S3 inventory to JSON: %w", err)
+	}
+
+	if err := backend.Write(ctx, invJson); err != nil {
+		return fmt.Errorf("failed to write S3 inventory to %s: %w", backend, err)
+	}
+
+	return nil
+}
+
+// LoadState loads the S3 inventory from a state backend.
+func (i *S3Inventory) LoadState(ctx context.Context, backend state.Backend) error {
+	inventoryBytes, err := backend.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read S3 inventory from %s: %w", backend, err)
+	}
+
+	// unmarshal JSON inventory
+	return i.Unmarshal(inventoryBytes)
+}
+
+// Marshal returns the JSON S3 inventory from an S3Inventory object.  The
+// JSON has the current schema version without changing the object.
 func (i *S3Inventory) Marshal() ([]byte, error) {
-	invJson, err := json.MarshalIndent(i, "", "  ")
+	versioned := *i
+	versioned.SchemaVersion = InventorySchemaVersion
+	invJson, err := json.MarshalIndent(&versioned, "", "  ")
 	if err != nil {
 		return []byte{}, err
 	}
@@ -67,7 +116,14 @@ func (i *S3Inventory) Marshal() ([]byte, error) {
 }
 
 // Unmarshal populates an S3Inventory object from the JSON S3 inventory.
+// Inventories with an older schema version are migrated to the current
+// version and an error is returned for inventories with a newer version.
 func (i *S3Inventory) Unmarshal(inventoryBytes []byte) error {
+	inventoryBytes, err := inventory.Migrate("s3", inventoryBytes, InventorySchemaVersion)
+	if err != nil {
+		return err
+	}
+
 	if err := json.Unmarshal(inventoryBytes, i); err != nil {
 		return err
 	}
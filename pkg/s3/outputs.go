@@ -0,0 +1,40 @@
+package s3
+
+import (
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+// inventoryOutputs are the outputs of the S3 resource stack.  Outputs are used
+// by other resource stacks and scripts so existing names must not be changed.
+var inventoryOutputs = []stack.InventoryOutput[*S3Inventory]{
+	{
+		Output: stack.Output{Name: "region", Description: "The AWS region the resources are in."},
+		Value:  func(i *S3Inventory) any { return i.Region },
+	},
+	{
+		Output: stack.Output{Name: "bucketName", Description: "The name of the S3 bucket."},
+		Value:  func(i *S3Inventory) any { return i.BucketName },
+	},
+	{
+		Output: stack.Output{Name: "accessPointName", Description: "The name of the S3 access point."},
+		Value:  func(i *S3Inventory) any { return i.AccessPointName },
+	},
+	{
+		Output: stack.Output{Name: "policyArn", Description: "The ARN of the IAM policy granting access to the bucket."},
+		Value:  func(i *S3Inventory) any { return i.PolicyArn },
+	},
+	{
+		Output: stack.Output{Name: "roleName", Description: "The name of the IAM role for the workload's service account."},
+		Value:  func(i *S3Inventory) any { return i.Role.RoleName },
+	},
+	{
+		Output: stack.Output{Name: "roleArn", Description: "The ARN of the IAM role for the workload's service account."},
+		Value:  func(i *S3Inventory) any { return i.Role.RoleArn },
+	},
+}
+
+// Outputs returns the value of each output of the S3 resource stack, e.g.
+// ${s3.bucketName}, by output name.
+func (i *S3Inventory) Outputs() map[string]any {
+	return stack.OutputValues(inventoryOutputs, i)
+}
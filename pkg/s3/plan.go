@@ -0,0 +1,68 @@
+package s3
+
+import (
+	"fmt"
+
+	"github.com/nukleros/aws-builder/pkg/plan"
+)
+
+// PlanS3ResourceStack returns the actions CreateS3ResourceStack would take for
+// each resource given the same config and inventory.  Resources in the
+// inventory are reused.  The bucket, IAM policy and IAM role are otherwise
+// given unique names when created so existing resources are never adopted
+// and no calls to AWS are needed.
+func (c *S3Client) PlanS3ResourceStack(
+	resourceConfig *S3Config,
+	inventory *S3Inventory,
+) (*plan.ChangeSet, error) {
+	region := resourceConfig.Region
+	if region == "" {
+		region = c.AwsConfig.Region
+	}
+
+	if inventory == nil {
+		inventory = &S3Inventory{}
+	}
+	if inventory.Region != "" && inventory.Region != region {
+		return nil, fmt.Errorf(
+			"config region %s and inventory region %s do not match",
+			region,
+			inventory.Region,
+		)
+	}
+
+	changeSet := plan.NewChangeSet("s3", region)
+	if inventory.BucketName != "" {
+		changeSet.Add("s3-bucket", inventory.BucketName, inventory.BucketName, plan.ActionReuse)
+	} else {
+		changeSet.Add("s3-bucket", fmt.Sprintf("%s-<uuid>", resourceConfig.Name), "", plan.ActionCreate)
+	}
+	if inventory.AccessPointName != "" {
+		changeSet.Add("s3-access-point", inventory.AccessPointName, inventory.AccessPointName, plan.ActionReuse)
+	} else {
+		changeSet.Add("s3-access-point", resourceConfig.Name, "", plan.ActionCreate)
+	}
+	changeSet.Add("s3-bucket-acl", "", "", plan.ActionCreate)
+	if inventory.PolicyArn != "" {
+		changeSet.Add("iam-policy", inventory.PolicyArn, inventory.PolicyArn, plan.ActionReuse)
+	} else {
+		changeSet.Add(
+			"iam-policy",
+			fmt.Sprintf("%s-<random>", resourceConfig.WorkloadReadWriteAccess.ServiceAccountName),
+			"",
+			plan.ActionCreate,
+		)
+	}
+	if inventory.Role.RoleName != "" {
+		changeSet.Add("iam-role", inventory.Role.RoleName, inventory.Role.RoleArn, plan.ActionReuse)
+	} else {
+		changeSet.Add(
+			"iam-role",
+			fmt.Sprintf("%s-<random>", resourceConfig.WorkloadReadWriteAccess.ServiceAccountName),
+			"",
+			plan.ActionCreate,
+		)
+	}
+
+	return changeSet, nil
+}
@@ -0,0 +1,56 @@
+package s3
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+	"github.com/nukleros/aws-builder/pkg/plan"
+)
+
+func TestPlanS3ResourceStack(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	s3Client := S3Client{ResourceClient: *backend.ResourceClient()}
+
+	// without an inventory every resource is created
+	changeSet, err := s3Client.PlanS3ResourceStack(resourceConfig, nil)
+	if err != nil {
+		t.Fatalf("failed to plan resource stack: %v", err)
+	}
+	for _, change := range changeSet.Changes {
+		if change.Action != plan.ActionCreate {
+			t.Errorf("expected %s to be created, got %s", change.Kind, change.Action)
+		}
+	}
+	if resources := backend.Resources(); len(resources) != 0 {
+		t.Errorf("expected planning to create nothing, found %v", resources)
+	}
+
+	// resources in the inventory are reused and the bucket ACL is always
+	// applied
+	var inventory S3Inventory
+	if err := s3Client.CreateS3ResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	changeSet, err = s3Client.PlanS3ResourceStack(resourceConfig, &inventory)
+	if err != nil {
+		t.Fatalf("failed to plan resource stack: %v", err)
+	}
+	for _, change := range changeSet.Changes {
+		expected := plan.ActionReuse
+		if change.Kind == "s3-bucket-acl" {
+			expected = plan.ActionCreate
+		}
+		if change.Action != expected {
+			t.Errorf("expected %s action for %s, got %s", expected, change.Kind, change.Action)
+		}
+	}
+
+	// an inventory from another region can't be planned against
+	inventory.Region = "eu-west-1"
+	_, err = s3Client.PlanS3ResourceStack(resourceConfig, &inventory)
+	if err == nil || !strings.Contains(err.Error(), "do not match") {
+		t.Errorf("expected region mismatch error, got %v", err)
+	}
+}
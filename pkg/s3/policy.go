@@ -16,7 +16,7 @@ func (c *S3Client) CreatePolicy(
 	serviceAccountName string,
 	nameSuffix string,
 ) (*types.Policy, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	policyName := fmt.Sprintf("%s-%s", serviceAccountName, nameSuffix)
 	policyDescription := "Allow read, create, update and delete of objects in specified bucket"
@@ -65,7 +65,7 @@ func (c *S3Client) DeletePolicy(policyArn string) error {
 		return nil
 	}
 
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	deletePolicyInput := iam.DeletePolicyInput{
 		PolicyArn: &policyArn,
@@ -1,147 +1,223 @@
 package s3
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/nukleros/aws-builder/pkg/client"
 	"github.com/nukleros/aws-builder/pkg/iam"
 	"github.com/nukleros/aws-builder/pkg/util"
 )
 
-// CreateResourceStack creates all the resources for an RDS instance.
-func (c *S3Client) CreateS3ResourceStack(resourceConfig *S3Config) error {
-	var inventory S3Inventory
+// CreateResourceStack creates all the resources for an S3 bucket.  If
+// inventory for pre-existing resources are provided, it will not re-create
+// those resources but instead use them as a part of the stack.
+func (c *S3Client) CreateS3ResourceStack(
+	resourceConfig *S3Config,
+	inventory *S3Inventory,
+) error {
+	// resource config region takes precedence
+	// if not set, use the region defined in AWS config
 	if resourceConfig.Region != "" {
-		inventory.Region = resourceConfig.Region
 		c.AwsConfig.Region = resourceConfig.Region
 	} else {
-		inventory.Region = c.AwsConfig.Region
 		resourceConfig.Region = c.AwsConfig.Region
 	}
 
+	// if inventory not provided or if inventory region not provided, set to the
+	// configured region
+	switch {
+	case inventory == nil:
+		inventory = &S3Inventory{}
+		inventory.Region = resourceConfig.Region
+	case inventory.Region == "":
+		inventory.Region = resourceConfig.Region
+	}
+
+	// return an error if client-supplied resource config and inventory regions
+	// do not match
+	if inventory.Region != resourceConfig.Region {
+		return fmt.Errorf(
+			"config region %s and inventory region %s do not match",
+			resourceConfig.Region,
+			inventory.Region,
+		)
+	}
+
+	// waiter settings on the client take precedence over resource config
+	c.Waiters = c.Waiters.Merge(resourceConfig.Waiters)
+
 	// Tags
 	s3Tags := CreateS3Tags(resourceConfig.Name, resourceConfig.Tags)
 	iamTags := iam.CreateIamTags(resourceConfig.Name, resourceConfig.Tags)
 
 	// Bucket
-	bucketName, err := c.CreateBucket(
-		s3Tags,
-		resourceConfig.Name,
-		resourceConfig.Region,
-	)
-	if bucketName != "" {
-		inventory.BucketName = bucketName
-		inventory.send(c.InventoryChan)
-	}
-	if err != nil {
-		return err
+	if inventory.BucketName == "" {
+		bucketName, err := c.CreateBucket(
+			s3Tags,
+			resourceConfig.Name,
+			resourceConfig.Region,
+		)
+		if bucketName != "" {
+			inventory.BucketName = bucketName
+			c.sendInventory(inventory)
+		}
+		if err != nil {
+			return c.sendFailed("s3-bucket", err)
+		}
+		c.sendEvent("s3-bucket", client.PhaseCreated, fmt.Sprintf("S3 bucket %s created", bucketName), bucketName)
+	} else {
+		c.sendEvent("s3-bucket", client.PhaseFoundInInventory, fmt.Sprintf("S3 bucket found in inventory: %s", inventory.BucketName), inventory.BucketName)
 	}
-	c.SendMessage(fmt.Sprintf("S3 bucket %s created", bucketName))
 
 	// Access Point
-	accessPointName, err := c.CreateAccessPoint(
-		resourceConfig.Name,
-		bucketName,
-		resourceConfig.AwsAccount,
-		resourceConfig.VpcIdReadWriteAccess,
-	)
-	if accessPointName != "" {
-		inventory.AccessPointName = accessPointName
-		inventory.AwsAccount = resourceConfig.AwsAccount
-		inventory.send(c.InventoryChan)
-	}
-	if err != nil {
-		return err
+	if inventory.AccessPointName == "" {
+		accessPointName, err := c.CreateAccessPoint(
+			resourceConfig.Name,
+			inventory.BucketName,
+			resourceConfig.AwsAccount,
+			resourceConfig.VpcIdReadWriteAccess,
+		)
+		if accessPointName != "" {
+			inventory.AccessPointName = accessPointName
+			inventory.AwsAccount = resourceConfig.AwsAccount
+			c.sendInventory(inventory)
+		}
+		if err != nil {
+			return c.sendFailed("s3-access-point", err)
+		}
+		c.sendEvent("s3-access-point", client.PhaseCreated, fmt.Sprintf("S3 bucket access point %s created", accessPointName), accessPointName)
+	} else {
+		c.sendEvent("s3-access-point", client.PhaseFoundInInventory, fmt.Sprintf("S3 bucket access point found in inventory: %s", inventory.AccessPointName), inventory.AccessPointName)
 	}
-	c.SendMessage(fmt.Sprintf("S3 bucket access point %s created", accessPointName))
 
 	// Access Control List
 	if err := c.CreateAcl(
 		s3Tags,
-		bucketName,
+		inventory.BucketName,
 		resourceConfig.PublicReadAccess,
 	); err != nil {
-		return err
+		return c.sendFailed("s3-bucket-acl", err)
 	}
 
 	// IAM Policy
 	nameSuffix := util.RandomAlphaNumericString(12)
-	policy, err := c.CreatePolicy(
-		iamTags,
-		bucketName,
-		resourceConfig.WorkloadReadWriteAccess.ServiceAccountName,
-		nameSuffix,
-	)
-	if policy != nil {
-		inventory.PolicyArn = *policy.Arn
-		inventory.send(c.InventoryChan)
-	}
-	if err != nil {
-		return err
+	if inventory.PolicyArn == "" {
+		policy, err := c.CreatePolicy(
+			iamTags,
+			inventory.BucketName,
+			resourceConfig.WorkloadReadWriteAccess.ServiceAccountName,
+			nameSuffix,
+		)
+		if policy != nil {
+			inventory.PolicyArn = *policy.Arn
+			c.sendInventory(inventory)
+		}
+		if err != nil {
+			return c.sendFailed("iam-policy", err)
+		}
+		c.sendEvent("iam-policy", client.PhaseCreated, fmt.Sprintf("IAM policy %s created", *policy.PolicyName), *policy.PolicyName)
+	} else {
+		c.sendEvent("iam-policy", client.PhaseFoundInInventory, fmt.Sprintf("IAM policy found in inventory: %s", inventory.PolicyArn), inventory.PolicyArn)
 	}
-	c.SendMessage(fmt.Sprintf("IAM policy %s created", *policy.PolicyName))
 
 	// IAM Role
-	role, err := c.CreateRole(
-		iamTags,
-		*policy.Arn,
-		resourceConfig.AwsAccount,
-		resourceConfig.WorkloadReadWriteAccess.OidcUrl,
-		resourceConfig.WorkloadReadWriteAccess.ServiceAccountName,
-		resourceConfig.WorkloadReadWriteAccess.ServiceAccountNamespace,
-		nameSuffix,
-	)
-	if role != nil {
-		roleInventory := RoleInventory{
-			RoleName:       *role.RoleName,
-			RoleArn:        *role.Arn,
-			RolePolicyArns: []string{*policy.Arn},
+	if inventory.Role.RoleName == "" {
+		role, err := c.CreateRole(
+			iamTags,
+			inventory.PolicyArn,
+			resourceConfig.AwsAccount,
+			resourceConfig.WorkloadReadWriteAccess.OidcUrl,
+			resourceConfig.WorkloadReadWriteAccess.ServiceAccountName,
+			resourceConfig.WorkloadReadWriteAccess.ServiceAccountNamespace,
+			nameSuffix,
+		)
+		if role != nil {
+			roleInventory := RoleInventory{
+				RoleName:       *role.RoleName,
+				RoleArn:        *role.Arn,
+				RolePolicyArns: []string{inventory.PolicyArn},
+			}
+			inventory.Role = roleInventory
+			c.sendInventory(inventory)
 		}
-		inventory.Role = roleInventory
-		inventory.send(c.InventoryChan)
-	}
-	if err != nil {
-		return err
+		if err != nil {
+			return c.sendFailed("iam-role", err)
+		}
+		c.sendEvent("iam-role", client.PhaseCreated, fmt.Sprintf("IAM role %s created", *role.RoleName), *role.RoleName)
+	} else {
+		c.sendEvent("iam-role", client.PhaseFoundInInventory, fmt.Sprintf("IAM role found in inventory: %s", inventory.Role.RoleName), inventory.Role.RoleName)
 	}
-	c.SendMessage(fmt.Sprintf("IAM role %s created", *role.RoleName))
 
 	return nil
 }
 
-// DeleteResourceStack deletes all the resources for an RDS instance.
+// DeleteResourceStack deletes all the resources for an S3 bucket.  An adopted
+// bucket is kept unless the client is set to delete it.  Each resource
+// is attempted even if others fail, unless a resource it depends on still
+// exists, and resources that are not found are treated as deleted.  An error
+// joining every failure and skipped resource is returned.
 func (c *S3Client) DeleteS3ResourceStack(inventory *S3Inventory) error {
 	c.AwsConfig.Region = inventory.Region
 
+	var errs []error
+	skip := func(kind string, message string, ids ...string) {
+		c.sendEvent(kind, client.PhaseSkipped, message, ids...)
+		errs = append(errs, errors.New(message))
+	}
+
 	// Access Point
-	if err := c.DeleteAccessPoint(inventory.AccessPointName, inventory.AwsAccount); err != nil {
-		return err
+	if inventory.AccessPointName != "" {
+		if err := c.DeleteAccessPoint(inventory.AccessPointName, inventory.AwsAccount); err != nil {
+			errs = append(errs, c.sendFailed("s3-access-point", err))
+		} else {
+			c.sendEvent("s3-access-point", client.PhaseDeleted, fmt.Sprintf("S3 bucket access point %s deleted", inventory.AccessPointName), inventory.AccessPointName)
+			inventory.AccessPointName = ""
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("S3 bucket access point %s deleted\n", inventory.AccessPointName))
-	inventory.AccessPointName = ""
-	inventory.send(c.InventoryChan)
 
-	// Bucket
-	if err := c.DeleteBucket(inventory.BucketName); err != nil {
-		return err
+	// Bucket - the access point must be deleted first
+	switch {
+	case inventory.BucketName == "" || c.keepAdopted(inventory, "s3-bucket", inventory.BucketName):
+	case inventory.AccessPointName != "":
+		skip("s3-bucket", fmt.Sprintf("S3 bucket %s not deleted because access point still exists: %s", inventory.BucketName, inventory.AccessPointName), inventory.BucketName)
+	default:
+		if err := c.DeleteBucket(inventory.BucketName); err != nil {
+			errs = append(errs, c.sendFailed("s3-bucket", err))
+		} else {
+			c.sendEvent("s3-bucket", client.PhaseDeleted, fmt.Sprintf("S3 bucket %s deleted", inventory.BucketName), inventory.BucketName)
+			inventory.BucketName = ""
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("S3 bucket %s deleted\n", inventory.BucketName))
-	inventory.BucketName = ""
-	inventory.send(c.InventoryChan)
 
 	// IAM Role
-	if err := c.DeleteRole(&inventory.Role); err != nil {
-		return err
+	if inventory.Role.RoleName != "" {
+		if err := c.DeleteRole(&inventory.Role); err != nil {
+			errs = append(errs, c.sendFailed("iam-role", err))
+		} else {
+			c.sendEvent("iam-role", client.PhaseDeleted, fmt.Sprintf("IAM role %s deleted", inventory.Role.RoleName), inventory.Role.RoleName)
+			inventory.Role = RoleInventory{}
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("IAM role %s deleted\n", inventory.Role.RoleName))
-	inventory.Role = RoleInventory{}
-	inventory.send(c.InventoryChan)
 
-	// IAM Policy
-	if err := c.DeletePolicy(inventory.PolicyArn); err != nil {
-		return err
+	// IAM Policy - can't be deleted while attached to the role
+	switch {
+	case inventory.PolicyArn == "":
+	case slices.Contains(inventory.Role.RolePolicyArns, inventory.PolicyArn):
+		skip("iam-policy", fmt.Sprintf("IAM policy with ARN %s not deleted because IAM role still exists: %s", inventory.PolicyArn, inventory.Role.RoleName), inventory.PolicyArn)
+	default:
+		if err := c.DeletePolicy(inventory.PolicyArn); err != nil {
+			errs = append(errs, c.sendFailed("iam-policy", err))
+		} else {
+			c.sendEvent("iam-policy", client.PhaseDeleted, fmt.Sprintf("IAM policy with ARN %s deleted", inventory.PolicyArn), inventory.PolicyArn)
+			inventory.PolicyArn = ""
+			c.sendInventory(inventory)
+		}
 	}
-	c.SendMessage(fmt.Sprintf("IAM policy with ARN %s deleted\n", inventory.PolicyArn))
-	inventory.PolicyArn = ""
-	inventory.send(c.InventoryChan)
 
-	return nil
+	return errors.Join(errs...)
 }
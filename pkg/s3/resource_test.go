@@ -0,0 +1,133 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_s3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+// sampleConfig returns the sample S3 config.
+func sampleConfig(t *testing.T) *S3Config {
+	t.Helper()
+
+	resourceConfig, err := LoadS3Config("../../sample/s3-config.yaml")
+	if err != nil {
+		t.Fatalf("failed to load sample config: %v", err)
+	}
+
+	return resourceConfig
+}
+
+// publicRead returns whether the bucket's ACL grants read access to all
+// users.
+func publicRead(t *testing.T, backend *fake.Backend, bucketName string) bool {
+	t.Helper()
+
+	acl, err := backend.Apis().S3.GetBucketAcl(context.Background(), &aws_s3.GetBucketAclInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		t.Fatalf("failed to get bucket ACL: %v", err)
+	}
+	for _, grant := range acl.Grants {
+		if grant.Grantee.Type == types.TypeGroup &&
+			aws.ToString(grant.Grantee.URI) == "http://acs.amazonaws.com/groups/global/AllUsers" &&
+			grant.Permission == types.PermissionRead {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestCreateS3ResourceStackAppliesAclToReusedBucket(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	s3Client := S3Client{ResourceClient: *backend.ResourceClient()}
+
+	var inventory S3Inventory
+	if err := s3Client.CreateS3ResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	if publicRead(t, backend, inventory.BucketName) {
+		t.Errorf("expected bucket %s to be private", inventory.BucketName)
+	}
+	bucketName := inventory.BucketName
+
+	// creating again with public read access reuses the bucket and applies
+	// the new ACL to it
+	resourceConfig.PublicReadAccess = true
+	if err := s3Client.CreateS3ResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack again: %v", err)
+	}
+	if inventory.BucketName != bucketName {
+		t.Errorf("expected bucket %s to be reused, got %s", bucketName, inventory.BucketName)
+	}
+	for operation, expected := range map[string]int{
+		"CreateBucket":            1,
+		"PutBucketAcl":            2,
+		"DeletePublicAccessBlock": 1,
+		"PutBucketPolicy":         1,
+	} {
+		if count := backend.CallCount(operation); count != expected {
+			t.Errorf("expected %s to be called %d times, called %d times", operation, expected, count)
+		}
+	}
+	if !publicRead(t, backend, bucketName) {
+		t.Errorf("expected bucket %s to allow public read access", bucketName)
+	}
+	resources := backend.Resources()
+	for _, kind := range []string{"s3-bucket", "s3-access-point", "iam-policy", "iam-role"} {
+		if len(resources[kind]) != 1 {
+			t.Errorf("expected 1 %s, found %v", kind, resources[kind])
+		}
+	}
+}
+
+func TestDeleteS3ResourceStackKeepsBucketForAccessPoint(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	s3Client := S3Client{ResourceClient: *backend.ResourceClient()}
+
+	var inventory S3Inventory
+	if err := s3Client.CreateS3ResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	injected := &smithy.GenericAPIError{Code: "InternalError", Message: "injected failure"}
+	backend.FailNext("DeleteAccessPoint", injected)
+
+	err := s3Client.DeleteS3ResourceStack(&inventory)
+	if !errors.Is(err, injected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "not deleted because access point still exists") {
+		t.Errorf("expected bucket to be skipped, got %v", err)
+	}
+	if count := backend.CallCount("DeleteBucket"); count != 0 {
+		t.Errorf("expected bucket deletion not to be attempted, DeleteBucket called %d times", count)
+	}
+	resources := backend.Resources()
+	for _, kind := range []string{"iam-policy", "iam-role"} {
+		if len(resources[kind]) != 0 {
+			t.Errorf("expected %s to be deleted despite the failure, found %v", kind, resources[kind])
+		}
+	}
+	if inventory.BucketName == "" || inventory.AccessPointName == "" {
+		t.Errorf("expected bucket and access point to stay in inventory, got %+v", inventory)
+	}
+
+	if err := s3Client.DeleteS3ResourceStack(&inventory); err != nil {
+		t.Fatalf("failed to resume delete: %v", err)
+	}
+	if resources := backend.Resources(); len(resources) != 0 {
+		t.Errorf("expected all resources to be deleted, found %v", resources)
+	}
+}
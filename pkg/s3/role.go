@@ -21,7 +21,7 @@ func (c *S3Client) CreateRole(
 	serviceAccountNamespace string,
 	nameSuffix string,
 ) (*types.Role, error) {
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	oidcUrlBare := strings.Trim(oidcUrl, "https://")
 	roleName := fmt.Sprintf("%s-%s", serviceAccountName, nameSuffix)
@@ -73,7 +73,7 @@ func (c *S3Client) DeleteRole(role *RoleInventory) error {
 		return nil
 	}
 
-	svc := iam.NewFromConfig(*c.AwsConfig)
+	svc := c.GetIamApi()
 
 	for _, policyArn := range role.RolePolicyArns {
 		detachRolePolicyInput := iam.DetachRolePolicyInput{
@@ -0,0 +1,70 @@
+package s3
+
+import (
+	"fmt"
+
+	"github.com/nukleros/aws-builder/pkg/schema"
+)
+
+// ConfigSchema returns the JSON Schema for S3 config files.
+func ConfigSchema() *schema.Schema {
+	return schema.Generate("S3 resource stack config", NewS3Config())
+}
+
+// SchemaFields describes the config fields in generated schemas.
+func (S3Config) SchemaFields() map[string]schema.Field {
+	return map[string]schema.Field{
+		"tags": {
+			Description: "Tags added to every resource.  Use unique tags for each resource stack.",
+		},
+		"awsAccount": {
+			Description: "The AWS account ID resources are created in.",
+			Pattern:     awsAccountPattern.String(),
+			Required:    true,
+		},
+		"region": {
+			Description: "The AWS region to create the bucket in.  Defaults to the region in AWS config.",
+		},
+		"name": {
+			Description: fmt.Sprintf(
+				"The name of the access point and the prefix of the bucket name, up to %d characters.",
+				maxNameLength,
+			),
+			Pattern:  bucketNamePattern.String(),
+			Required: true,
+		},
+		"vpcIdReadWriteAccess": {
+			Description: "The VPC the access point allows access from.",
+			Required:    true,
+		},
+		"publicReadAccess": {
+			Description: "Allow anyone to read objects in the bucket.",
+		},
+		"workloadReadWriteAccess": {
+			Description: "The workload that is granted read and write access to the bucket.",
+			Required:    true,
+		},
+		"waiters": {
+			Description: "Settings for waiting on resources.  No resources in an S3 resource stack are waited on.",
+		},
+	}
+}
+
+// SchemaFields describes the config fields in generated schemas.
+func (WorkloadAccess) SchemaFields() map[string]schema.Field {
+	return map[string]schema.Field{
+		"serviceAccountName": {
+			Description: "The name of the workload's Kubernetes service account.",
+			Pattern:     serviceAccountNamePattern.String(),
+			Required:    true,
+		},
+		"serviceAccountNamespace": {
+			Description: "The namespace of the workload's Kubernetes service account.",
+			Pattern:     serviceAccountNamePattern.String(),
+		},
+		"oidcUrl": {
+			Description: "The https:// URL of the OIDC provider for the EKS cluster the workload runs in.",
+			Required:    true,
+		},
+	}
+}
@@ -0,0 +1,174 @@
+package s3
+
+import (
+	"sync"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/orphan"
+	"github.com/nukleros/aws-builder/pkg/plan"
+	"github.com/nukleros/aws-builder/pkg/schema"
+	"github.com/nukleros/aws-builder/pkg/stack"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+func init() {
+	stack.Register(&S3Client{})
+}
+
+// Name returns the name the S3 resource stack is registered as.
+func (c *S3Client) Name() string {
+	return "s3"
+}
+
+// Description returns the AWS service the S3 resource stack provides.
+func (c *S3Client) Description() string {
+	return "Simple Storage Service"
+}
+
+// New returns an S3 client that uses the resource client.
+func (c *S3Client) New(resourceClient *client.ResourceClient) stack.Stack {
+	return &S3Client{ResourceClient: *resourceClient}
+}
+
+// NewConfig returns an empty S3 config.
+func (c *S3Client) NewConfig() stack.Config {
+	return &S3Config{}
+}
+
+// LoadConfig loads S3 config files, resolving references to the outputs of
+// other resource stacks, and applies defaults.
+func (c *S3Client) LoadConfig(outputs configfile.Outputs, configFiles ...string) (stack.Config, error) {
+	return LoadS3ConfigWithOutputs(outputs, configFiles...)
+}
+
+// ConfigSchema returns the JSON Schema for S3 config files.
+func (c *S3Client) ConfigSchema() *schema.Schema {
+	return ConfigSchema()
+}
+
+// WaiterNames returns the S3 resources that can be waited on.  None are waited
+// on.
+func (c *S3Client) WaiterNames() []string {
+	return nil
+}
+
+// NewInventory returns an empty S3 inventory.
+func (c *S3Client) NewInventory() stack.Inventory {
+	return &S3Inventory{}
+}
+
+// Outputs returns the outputs of the S3 resource stack.
+func (c *S3Client) Outputs() []stack.Output {
+	return stack.Outputs(inventoryOutputs)
+}
+
+// InventorySchemaVersion returns the current S3 inventory schema version.
+func (c *S3Client) InventorySchemaVersion() int {
+	return InventorySchemaVersion
+}
+
+// StartStateWriter creates the client's inventory channel and starts a
+// goroutine to write inventory sent on it to state.  The returned function
+// closes the channel.
+func (c *S3Client) StartStateWriter(
+	backend state.Backend,
+	lockInfo state.LockInfo,
+	wait *sync.WaitGroup,
+) func() {
+	inventoryChan := make(chan S3Inventory)
+	c.InventoryChan = &inventoryChan
+	state.StartWriter(c.Context, backend, lockInfo, inventoryChan, wait)
+
+	return func() { close(inventoryChan) }
+}
+
+// Create creates the S3 resource stack, reusing any resources in the
+// inventory.
+func (c *S3Client) Create(config stack.Config, inventory stack.Inventory) error {
+	s3Config, err := stack.ConfigAs[*S3Config](config)
+	if err != nil {
+		return err
+	}
+	s3Inventory, err := stack.InventoryAs[*S3Inventory](inventory)
+	if err != nil {
+		return err
+	}
+
+	return c.CreateS3ResourceStack(s3Config, s3Inventory)
+}
+
+// Delete deletes the S3 resource stack.
+func (c *S3Client) Delete(inventory stack.Inventory) error {
+	s3Inventory, err := stack.InventoryAs[*S3Inventory](inventory)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteS3ResourceStack(s3Inventory)
+}
+
+// Plan returns the changes creating the S3 resource stack would make.
+func (c *S3Client) Plan(config stack.Config, inventory stack.Inventory) (*plan.ChangeSet, error) {
+	s3Config, err := stack.ConfigAs[*S3Config](config)
+	if err != nil {
+		return nil, err
+	}
+	s3Inventory, err := stack.InventoryAs[*S3Inventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.PlanS3ResourceStack(s3Config, s3Inventory)
+}
+
+// Verify checks the S3 resource stack for drift.
+func (c *S3Client) Verify(inventory stack.Inventory, config stack.Config) (*drift.Report, error) {
+	s3Inventory, err := stack.InventoryAs[*S3Inventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+	s3Config, err := stack.ConfigAs[*S3Config](config)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.VerifyS3ResourceStack(s3Inventory, s3Config)
+}
+
+// Adopt records an existing S3 bucket in an S3 inventory.
+func (c *S3Client) Adopt(
+	name string,
+	tags map[string]string,
+	applyTags bool,
+	inventory stack.Inventory,
+) (*discovery.Report, error) {
+	s3Inventory, err := stack.InventoryAs[*S3Inventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.AdoptS3ResourceStack(name, tags, applyTags, s3Inventory)
+}
+
+// Orphans searches for S3 buckets of the S3 resource stack that are not in the
+// S3 inventory.
+func (c *S3Client) Orphans(config stack.Config, inventory stack.Inventory, broad bool) (*orphan.Report, error) {
+	s3Config, err := stack.ConfigAs[*S3Config](config)
+	if err != nil {
+		return nil, err
+	}
+	s3Inventory, err := stack.InventoryAs[*S3Inventory](inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.OrphansS3ResourceStack(s3Config, s3Inventory, broad)
+}
+
+// DeleteOrphans deletes orphaned S3 buckets.
+func (c *S3Client) DeleteOrphans(report *orphan.Report) error {
+	return c.DeleteS3Orphans(report)
+}
@@ -0,0 +1,88 @@
+package s3
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+var (
+	// bucketNamePattern matches the names S3 accepts for buckets and access
+	// points.
+	bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*[a-z0-9]$`)
+
+	// serviceAccountNamePattern matches Kubernetes service account and
+	// namespace names.
+	serviceAccountNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+	// awsAccountPattern matches 12 digit AWS account IDs.
+	awsAccountPattern = regexp.MustCompile(`^[0-9]{12}$`)
+)
+
+const (
+	// maxNameLength is the longest name that leaves room for the UUID added
+	// to the bucket name within the 63 character limit.
+	maxNameLength = 63 - len("-") - 36
+
+	// maxServiceAccountNameLength is the longest service account name that
+	// leaves room for the random suffix added to the IAM role and policy
+	// names within the 64 character limit.
+	maxServiceAccountNameLength = 64 - len("-") - 12
+)
+
+// Validate checks the config for values that would cause resource creation to
+// fail.  If any are found a validation.Errors containing every problem is
+// returned.
+func (c *S3Config) Validate() error {
+	var errs validation.Errors
+
+	// the name is used for the access point and as the bucket name prefix
+	switch {
+	case c.Name == "":
+		errs.Add("name", "is required")
+	case utf8.RuneCountInString(c.Name) > maxNameLength:
+		errs.Add("name", "must be %d characters or less so the bucket name with a unique suffix is 63 characters or less", maxNameLength)
+	case len(c.Name) < 3 || !bucketNamePattern.MatchString(c.Name):
+		errs.Add("name", "must be at least 3 characters, begin and end with a lowercase letter or digit and contain only lowercase letters, digits and hyphens")
+	}
+	if !awsAccountPattern.MatchString(c.AwsAccount) {
+		errs.Add("awsAccount", "must be a 12 digit AWS account ID")
+	}
+	if c.Region != "" && c.Region != "us-east-1" &&
+		!slices.Contains(types.BucketLocationConstraint("").Values(), types.BucketLocationConstraint(c.Region)) {
+		errs.Add("region", "%s is not a supported region for S3 buckets", c.Region)
+	}
+	if !strings.HasPrefix(c.VpcIdReadWriteAccess, "vpc-") {
+		errs.Add("vpcIdReadWriteAccess", "must be a VPC ID, e.g. vpc-011a5ad93b522fd8a")
+	}
+
+	// workload access
+	workload := c.WorkloadReadWriteAccess
+	switch {
+	case workload.ServiceAccountName == "":
+		errs.Add("workloadReadWriteAccess.serviceAccountName", "is required")
+	case utf8.RuneCountInString(workload.ServiceAccountName) > maxServiceAccountNameLength:
+		errs.Add("workloadReadWriteAccess.serviceAccountName", "must be %d characters or less so the IAM role and policy names with a random suffix are 64 characters or less", maxServiceAccountNameLength)
+	case !serviceAccountNamePattern.MatchString(workload.ServiceAccountName):
+		errs.Add("workloadReadWriteAccess.serviceAccountName", "%q is not a valid Kubernetes service account name", workload.ServiceAccountName)
+	}
+	switch {
+	case workload.ServiceAccountNamespace == "":
+		errs.Add("workloadReadWriteAccess.serviceAccountNamespace", "is required")
+	case !serviceAccountNamePattern.MatchString(workload.ServiceAccountNamespace):
+		errs.Add("workloadReadWriteAccess.serviceAccountNamespace", "%q is not a valid Kubernetes namespace", workload.ServiceAccountNamespace)
+	}
+	if !strings.HasPrefix(workload.OidcUrl, "https://") {
+		errs.Add("workloadReadWriteAccess.oidcUrl", "must be the https:// URL of the EKS cluster's OIDC provider")
+	}
+
+	errs.Tags("tags", c.Tags)
+	c.Waiters.Validate(&errs, "waiters")
+
+	return errs.Err()
+}
@@ -0,0 +1,89 @@
+package s3
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		update   func(resourceConfig *S3Config)
+		expected validation.Errors
+	}{
+		{
+			name:   "sample config",
+			update: func(resourceConfig *S3Config) {},
+		},
+		{
+			name: "name too long for unique suffix",
+			update: func(resourceConfig *S3Config) {
+				resourceConfig.Name = strings.Repeat("a", maxNameLength+1)
+			},
+			expected: validation.Errors{
+				{Field: "name", Message: "must be 26 characters or less so the bucket name with a unique suffix is 63 characters or less"},
+			},
+		},
+		{
+			name: "every problem",
+			update: func(resourceConfig *S3Config) {
+				resourceConfig.Name = "Test_0"
+				resourceConfig.Region = "us-nowhere-1"
+				resourceConfig.WorkloadReadWriteAccess.ServiceAccountNamespace = ""
+				resourceConfig.WorkloadReadWriteAccess.OidcUrl = "oidc.eks.us-east-1.amazonaws.com"
+			},
+			expected: validation.Errors{
+				{Field: "name", Message: "must be at least 3 characters, begin and end with a lowercase letter or digit and contain only lowercase letters, digits and hyphens"},
+				{Field: "region", Message: "us-nowhere-1 is not a supported region for S3 buckets"},
+				{Field: "workloadReadWriteAccess.serviceAccountNamespace", Message: "is required"},
+				{Field: "workloadReadWriteAccess.oidcUrl", Message: "must be the https:// URL of the EKS cluster's OIDC provider"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resourceConfig := sampleConfig(t)
+			testCase.update(resourceConfig)
+
+			err := resourceConfig.Validate()
+			if testCase.expected == nil {
+				if err != nil {
+					t.Fatalf("expected config to be valid, got %v", err)
+				}
+				return
+			}
+			var errs validation.Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+			if !reflect.DeepEqual(errs, testCase.expected) {
+				t.Errorf("expected problems\n%v\ngot\n%v", testCase.expected, errs)
+			}
+		})
+	}
+}
+
+func TestValidateLongestNameBucket(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	resourceConfig.Name = strings.Repeat("a", maxNameLength)
+	if err := resourceConfig.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+	backend := fake.NewBackend(resourceConfig.Region)
+	s3Client := S3Client{ResourceClient: *backend.ResourceClient()}
+
+	// the longest valid name leaves room for the UUID suffix
+	var inventory S3Inventory
+	if err := s3Client.CreateS3ResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+	if length := len(inventory.BucketName); length != 63 {
+		t.Errorf("expected bucket name %s to be 63 characters, got %d", inventory.BucketName, length)
+	}
+}
@@ -0,0 +1,161 @@
+package s3
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// allUsersGroupUri is the grantee URI S3 uses for public access grants.
+const allUsersGroupUri = "http://acs.amazonaws.com/groups/global/AllUsers"
+
+// VerifyS3ResourceStack checks that each resource in the inventory still
+// exists and is in the state the resource stack expects.  The bucket must have
+// versioning enabled and public grants on the bucket ACL are only expected if
+// the resource config supplied enables public read access.  Only read-only
+// calls are made.
+func (c *S3Client) VerifyS3ResourceStack(
+	inventory *S3Inventory,
+	resourceConfig *S3Config,
+) (*drift.Report, error) {
+	if inventory.Region != "" {
+		c.AwsConfig.Region = inventory.Region
+	}
+	report := drift.NewReport("s3", c.AwsConfig.Region)
+
+	// Bucket
+	if inventory.BucketName != "" {
+		versioningResp, err := c.GetS3Api().GetBucketVersioning(c.Context, &s3.GetBucketVersioningInput{
+			Bucket: &inventory.BucketName,
+		})
+		switch {
+		case util.HasErrorCode(err, "NoSuchBucket"):
+			report.Missing("s3-bucket", inventory.BucketName)
+		case err != nil:
+			return nil, fmt.Errorf("failed to get versioning for S3 bucket %s: %w", inventory.BucketName, err)
+		default:
+			result := report.Found("s3-bucket", inventory.BucketName)
+			if versioningResp.Status != types.BucketVersioningStatusEnabled {
+				result.Modified("versioning is not enabled")
+			}
+
+			aclResp, err := c.GetS3Api().GetBucketAcl(c.Context, &s3.GetBucketAclInput{
+				Bucket: &inventory.BucketName,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get ACL for S3 bucket %s: %w", inventory.BucketName, err)
+			}
+			publicReadAccess := resourceConfig != nil && resourceConfig.PublicReadAccess
+			var publicPermissions []types.Permission
+			for _, grant := range aclResp.Grants {
+				if grant.Grantee != nil && grant.Grantee.URI != nil && *grant.Grantee.URI == allUsersGroupUri {
+					publicPermissions = append(publicPermissions, grant.Permission)
+				}
+			}
+			for _, permission := range publicPermissions {
+				if !publicReadAccess || permission != types.PermissionRead {
+					result.Unexpected("ACL grants %s to all users", permission)
+				}
+			}
+			if publicReadAccess && !slices.Contains(publicPermissions, types.PermissionRead) {
+				result.Modified("ACL does not grant READ to all users")
+			}
+		}
+	}
+
+	// Access Point
+	if inventory.AccessPointName != "" {
+		resp, err := c.GetS3ControlApi().GetAccessPoint(c.Context, &s3control.GetAccessPointInput{
+			AccountId: &inventory.AwsAccount,
+			Name:      &inventory.AccessPointName,
+		})
+		switch {
+		case util.HasErrorCode(err, "NoSuchAccessPoint"):
+			report.Missing("s3-access-point", inventory.AccessPointName)
+		case err != nil:
+			return nil, fmt.Errorf("failed to get S3 access point %s: %w", inventory.AccessPointName, err)
+		default:
+			result := report.Found("s3-access-point", inventory.AccessPointName)
+			if resp.Bucket != nil && *resp.Bucket != inventory.BucketName {
+				result.Modified("bucket is %s, expected %s", *resp.Bucket, inventory.BucketName)
+			}
+			if resourceConfig != nil && resourceConfig.VpcIdReadWriteAccess != "" {
+				if resp.VpcConfiguration == nil || resp.VpcConfiguration.VpcId == nil {
+					result.Modified("not restricted to VPC %s", resourceConfig.VpcIdReadWriteAccess)
+				} else if *resp.VpcConfiguration.VpcId != resourceConfig.VpcIdReadWriteAccess {
+					result.Modified("restricted to VPC %s, expected %s", *resp.VpcConfiguration.VpcId, resourceConfig.VpcIdReadWriteAccess)
+				}
+			}
+		}
+	}
+
+	// IAM Policy
+	if inventory.PolicyArn != "" {
+		_, err := c.GetIamApi().GetPolicy(c.Context, &iam.GetPolicyInput{PolicyArn: &inventory.PolicyArn})
+		switch {
+		case util.HasErrorCode(err, "NoSuchEntity"):
+			report.Missing("iam-policy", inventory.PolicyArn)
+		case err != nil:
+			return nil, fmt.Errorf("failed to get policy %s: %w", inventory.PolicyArn, err)
+		default:
+			report.Found("iam-policy", inventory.PolicyArn)
+		}
+	}
+
+	// IAM Role
+	if inventory.Role.RoleName != "" {
+		if err := c.verifyRole(report, &inventory.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// verifyRole verifies the IAM role exists and has exactly the policies in
+// inventory attached.
+func (c *S3Client) verifyRole(report *drift.Report, role *RoleInventory) error {
+	svc := c.GetIamApi()
+
+	roleResp, err := svc.GetRole(c.Context, &iam.GetRoleInput{RoleName: &role.RoleName})
+	switch {
+	case util.HasErrorCode(err, "NoSuchEntity"):
+		report.Missing("iam-role", role.RoleName)
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get role %s: %w", role.RoleName, err)
+	}
+
+	result := report.Found("iam-role", role.RoleName)
+	if role.RoleArn != "" && *roleResp.Role.Arn != role.RoleArn {
+		result.Modified("ARN is %s, expected %s", *roleResp.Role.Arn, role.RoleArn)
+	}
+
+	policiesResp, err := svc.ListAttachedRolePolicies(c.Context, &iam.ListAttachedRolePoliciesInput{
+		RoleName: &role.RoleName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list policies for role %s: %w", role.RoleName, err)
+	}
+	var attachedPolicyArns []string
+	for _, policy := range policiesResp.AttachedPolicies {
+		attachedPolicyArns = append(attachedPolicyArns, *policy.PolicyArn)
+		if !slices.Contains(role.RolePolicyArns, *policy.PolicyArn) {
+			result.Unexpected("policy %s attached outside of resource stack", *policy.PolicyArn)
+		}
+	}
+	for _, policyArn := range role.RolePolicyArns {
+		if !slices.Contains(attachedPolicyArns, policyArn) {
+			result.Modified("policy %s not attached", policyArn)
+		}
+	}
+
+	return nil
+}
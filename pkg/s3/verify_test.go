@@ -0,0 +1,96 @@
+package s3
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_s3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+func TestVerifyS3ResourceStack(t *testing.T) {
+	resourceConfig := sampleConfig(t)
+	backend := fake.NewBackend(resourceConfig.Region)
+	s3Client := S3Client{ResourceClient: *backend.ResourceClient()}
+	apis := backend.Apis()
+	ctx := context.Background()
+
+	var inventory S3Inventory
+	if err := s3Client.CreateS3ResourceStack(resourceConfig, &inventory); err != nil {
+		t.Fatalf("failed to create resource stack: %v", err)
+	}
+
+	report, err := s3Client.VerifyS3ResourceStack(&inventory, resourceConfig)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	if report.HasDrift() {
+		t.Fatalf("expected no drift after create, got %s", report.Summary())
+	}
+
+	// suspended versioning is a modification, public read access not in the
+	// config is unexpected and a deleted access point is missing
+	if _, err := apis.S3.PutBucketVersioning(ctx, &aws_s3.PutBucketVersioningInput{
+		Bucket: aws.String(inventory.BucketName),
+		VersioningConfiguration: &types.VersioningConfiguration{
+			Status: types.BucketVersioningStatusSuspended,
+		},
+	}); err != nil {
+		t.Fatalf("failed to suspend versioning: %v", err)
+	}
+	if _, err := apis.S3.DeletePublicAccessBlock(ctx, &aws_s3.DeletePublicAccessBlockInput{
+		Bucket: aws.String(inventory.BucketName),
+	}); err != nil {
+		t.Fatalf("failed to delete public access block: %v", err)
+	}
+	if _, err := apis.S3.PutBucketAcl(ctx, &aws_s3.PutBucketAclInput{
+		Bucket: aws.String(inventory.BucketName),
+		ACL:    types.BucketCannedACLPublicRead,
+	}); err != nil {
+		t.Fatalf("failed to put bucket ACL: %v", err)
+	}
+	if _, err := apis.S3Control.DeleteAccessPoint(ctx, &s3control.DeleteAccessPointInput{
+		AccountId: aws.String(inventory.AwsAccount),
+		Name:      aws.String(inventory.AccessPointName),
+	}); err != nil {
+		t.Fatalf("failed to delete access point: %v", err)
+	}
+
+	report, err = s3Client.VerifyS3ResourceStack(&inventory, resourceConfig)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	expected := []*drift.Result{
+		{
+			Kind:    "s3-bucket",
+			Id:      inventory.BucketName,
+			Status:  drift.StatusModified,
+			Details: []string{"versioning is not enabled", "ACL grants READ to all users"},
+		},
+		{Kind: "s3-access-point", Id: inventory.AccessPointName, Status: drift.StatusMissing},
+		{Kind: "iam-policy", Id: inventory.PolicyArn, Status: drift.StatusOk},
+		{Kind: "iam-role", Id: inventory.Role.RoleName, Status: drift.StatusOk},
+	}
+	if !reflect.DeepEqual(report.Results, expected) {
+		t.Errorf("unexpected drift report")
+		for _, result := range report.Results {
+			t.Logf("got %+v", result)
+		}
+	}
+
+	// public read access is expected when the config enables it
+	resourceConfig.PublicReadAccess = true
+	report, err = s3Client.VerifyS3ResourceStack(&inventory, resourceConfig)
+	if err != nil {
+		t.Fatalf("failed to verify resource stack: %v", err)
+	}
+	if details := report.Results[0].Details; !reflect.DeepEqual(details, []string{"versioning is not enabled"}) {
+		t.Errorf("expected public read access not to be reported, got %v", details)
+	}
+}
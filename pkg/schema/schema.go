@@ -0,0 +1,242 @@
+// Package schema generates JSON Schema documents for resource stack configs
+// so config files can be validated by editors and CI before they are used.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nukleros/aws-builder/pkg/configfile"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// referencePattern matches a value that is only an environment variable
+// reference, e.g. ${AWS_ACCOUNT_ID}, or a resource stack output reference,
+// e.g. ${eks.privateSubnetIds}, which is expanded before the value is parsed.
+const referencePattern = `^\$\{([A-Za-z_][A-Za-z0-9_]*|[A-Za-z][A-Za-z0-9_-]*\.[A-Za-z][A-Za-z0-9_]*)\}$`
+
+// durationPattern matches Go durations, e.g. "1m30s".
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// Schema is a JSON Schema document or subschema.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Field describes a config field in generated schemas.
+type Field struct {
+	// A description of the field.
+	Description string
+
+	// The values the field can have.
+	Enum []any
+
+	// A regular expression string values must match.
+	Pattern string
+
+	// Whether the field must be set.
+	Required bool
+}
+
+// Describer is implemented by config types to describe their fields in
+// generated schemas.  Fields are keyed by their YAML field name.
+type Describer interface {
+	SchemaFields() map[string]Field
+}
+
+// generator holds the subschemas for struct types shared by more than one
+// field.
+type generator struct {
+	defs map[string]*Schema
+}
+
+// Generate returns the schema for a config struct.  Non-zero values in
+// defaults, which must be a pointer to the config struct, are used as the
+// default values for fields.
+func Generate(title string, defaults any) *Schema {
+	g := generator{defs: map[string]*Schema{}}
+	configValue := reflect.ValueOf(defaults).Elem()
+	root := g.structSchema(configValue.Type(), configValue)
+	root.Schema = Draft
+	root.Title = title
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+
+	return root
+}
+
+// structSchema returns the schema for a struct type.  Only fields with a
+// value in defaults have a default in the schema.
+func (g *generator) structSchema(structType reflect.Type, defaults reflect.Value) *Schema {
+	var fields map[string]Field
+	if describer, ok := reflect.Zero(structType).Interface().(Describer); ok {
+		fields = describer.SchemaFields()
+	}
+
+	structSchema := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		name := configfile.FieldName(structField)
+		if name == "" {
+			continue
+		}
+
+		property := g.typeSchema(structField.Type)
+		field := fields[name]
+		if len(field.Enum) > 0 || field.Pattern != "" {
+			property = constrain(property, field)
+		}
+		property.Description = field.Description
+		if field.Required {
+			structSchema.Required = append(structSchema.Required, name)
+		}
+		if defaults.IsValid() && !defaults.Field(i).IsZero() {
+			property.Default = defaultValue(defaults.Field(i))
+		}
+		structSchema.Properties[name] = property
+	}
+	slices.Sort(structSchema.Required)
+
+	return structSchema
+}
+
+// typeSchema returns the schema for a field type.  Struct types are added to
+// the schema's definitions and referenced.  Numbers, booleans, durations and
+// arrays can also be a reference.
+func (g *generator) typeSchema(fieldType reflect.Type) *Schema {
+	if fieldType == reflect.TypeOf(time.Duration(0)) {
+		return withReference(&Schema{
+			Type:    "string",
+			Pattern: durationPattern,
+		})
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Pointer:
+		return g.typeSchema(fieldType.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return withReference(&Schema{Type: "boolean"})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return withReference(&Schema{Type: "integer"})
+	case reflect.Float32, reflect.Float64:
+		return withReference(&Schema{Type: "number"})
+	case reflect.Slice, reflect.Array:
+		return withReference(&Schema{
+			Type:  "array",
+			Items: g.typeSchema(fieldType.Elem()),
+		})
+	case reflect.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: g.typeSchema(fieldType.Elem()),
+		}
+	case reflect.Struct:
+		name := defName(fieldType)
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = g.structSchema(fieldType, reflect.Value{})
+		}
+		return &Schema{Ref: "#/$defs/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// withReference returns a schema that allows a value matching valueSchema or
+// a reference.
+func withReference(valueSchema *Schema) *Schema {
+	return &Schema{
+		AnyOf: []*Schema{
+			valueSchema,
+			{Type: "string", Pattern: referencePattern},
+		},
+	}
+}
+
+// constrain returns a field's schema with the field's allowed values and
+// pattern applied to the value rather than the reference alternative.
+// Strings are otherwise not given a reference alternative since any string
+// can contain references, but a reference would not match the allowed values
+// or pattern.
+func constrain(property *Schema, field Field) *Schema {
+	valueSchema := property
+	if len(property.AnyOf) > 0 {
+		valueSchema = property.AnyOf[0]
+	}
+	if len(field.Enum) > 0 {
+		valueSchema.Enum = field.Enum
+	}
+	if field.Pattern != "" {
+		valueSchema.Pattern = field.Pattern
+	}
+	if len(property.AnyOf) == 0 {
+		return withReference(valueSchema)
+	}
+
+	return property
+}
+
+// defaultValue returns a value as it would be written in a config file.
+func defaultValue(value reflect.Value) any {
+	switch {
+	case value.Type() == reflect.TypeOf(time.Duration(0)):
+		return time.Duration(value.Int()).String()
+	case value.Kind() == reflect.Pointer:
+		return defaultValue(value.Elem())
+	case value.Kind() == reflect.Struct:
+		fields := map[string]any{}
+		for i := 0; i < value.NumField(); i++ {
+			name := configfile.FieldName(value.Type().Field(i))
+			if name != "" && !value.Field(i).IsZero() {
+				fields[name] = defaultValue(value.Field(i))
+			}
+		}
+		return fields
+	case value.Kind() == reflect.Map:
+		entries := map[string]any{}
+		iter := value.MapRange()
+		for iter.Next() {
+			entries[fmt.Sprint(iter.Key().Interface())] = defaultValue(iter.Value())
+		}
+		return entries
+	case value.Kind() == reflect.Slice:
+		items := make([]any, value.Len())
+		for i := range items {
+			items[i] = defaultValue(value.Index(i))
+		}
+		return items
+	default:
+		return value.Interface()
+	}
+}
+
+// defName returns the name of the definition for a struct type, e.g.
+// "eks.AvailabilityZoneConfig".
+func defName(structType reflect.Type) string {
+	return strings.ReplaceAll(structType.String(), "*", "")
+}
@@ -0,0 +1,92 @@
+package schema
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	AwsAccount string        `yaml:"awsAccount"`
+	Engine     string        `yaml:"engine"`
+	Name       string        `yaml:"name"`
+	SubnetIds  []string      `yaml:"subnetIds"`
+	Port       int           `yaml:"port"`
+	Timeout    time.Duration `yaml:"timeout"`
+}
+
+func (testConfig) SchemaFields() map[string]Field {
+	return map[string]Field{
+		"awsAccount": {Pattern: `^[0-9]{12}$`},
+		"engine":     {Enum: []any{"mysql", "postgres"}},
+	}
+}
+
+// matches returns whether a string value is allowed by a schema's type,
+// allowed values and pattern, including any of its alternatives.
+func matches(t *testing.T, schema *Schema, value string) bool {
+	t.Helper()
+
+	if len(schema.AnyOf) > 0 {
+		for _, alternative := range schema.AnyOf {
+			if matches(t, alternative, value) {
+				return true
+			}
+		}
+		return false
+	}
+	if schema.Type != "string" {
+		return false
+	}
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, allowed := range schema.Enum {
+			found = found || allowed == value
+		}
+		if !found {
+			return false
+		}
+	}
+	if schema.Pattern != "" {
+		return regexp.MustCompile(schema.Pattern).MatchString(value)
+	}
+
+	return true
+}
+
+func TestGenerateAllowsReferences(t *testing.T) {
+	schema := Generate("test", &testConfig{})
+
+	cases := []struct {
+		field   string
+		value   string
+		allowed bool
+	}{
+		{"awsAccount", "012345678901", true},
+		{"awsAccount", "${AWS_ACCOUNT_ID}", true},
+		{"awsAccount", "${eks.awsAccountId}", true},
+		{"awsAccount", "12345", false},
+		{"awsAccount", "account-${AWS_ACCOUNT_ID}", false},
+		{"engine", "mysql", true},
+		{"engine", "${DB_ENGINE}", true},
+		{"engine", "oracle", false},
+		{"name", "anything", true},
+		{"subnetIds", "${eks.privateSubnetIds}", true},
+		{"subnetIds", "${rds-db.subnetIds}", true},
+		{"subnetIds", "subnet-1", false},
+		{"port", "${DB_PORT}", true},
+		{"port", "3306", false},
+		{"timeout", "15m", true},
+		{"timeout", "${TIMEOUT}", true},
+		{"timeout", "${.timeout}", false},
+	}
+	for _, c := range cases {
+		property, ok := schema.Properties[c.field]
+		if !ok {
+			t.Fatalf("expected property %s in schema", c.field)
+		}
+		if allowed := matches(t, property, c.value); allowed != c.allowed {
+			t.Errorf("expected %s value %q allowed to be %t, got %t", c.field, c.value, c.allowed, allowed)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+// InitCreate initializes resource creation for any resource stack by loading
+// and validating the config, locking the state, adding the current inventory
+// to the state history, starting a goroutine to write inventory to state and
+// creating the resource stack client.  References in the config to the
+// outputs of other resource stacks are resolved with outputs, which may be
+// nil.  The returned function must be called once resources are created to
+// unlock the state after the last inventory has been written.
+func InitCreate(
+	stack Stack,
+	resourceClient *client.ResourceClient,
+	configFiles []string,
+	outputs configfile.Outputs,
+	backend state.Backend,
+	createWait *sync.WaitGroup,
+) (Stack, Config, func(), error) {
+	title := strings.ToUpper(stack.Name())
+
+	// load config
+	config, err := stack.LoadConfig(outputs, configFiles...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load %s config file: %w", title, err)
+	}
+
+	// validate config before any resources are changed
+	if err := config.Validate(); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid %s config: %w", title, err)
+	}
+
+	// lock state so no other operation can change the resource stack
+	lockInfo, err := state.Acquire(resourceClient.Context, backend, "create")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// capture inventory and write to state as resources are created
+	stackClient := stack.New(resourceClient)
+	stopStateWriter := stackClient.StartStateWriter(backend, lockInfo, createWait)
+
+	return stackClient, config, stopStateWriter, nil
+}
+
+// InitDelete initializes resource deletion for any resource stack by locking
+// the state, adding the current inventory to the state history, loading the
+// inventory to be deleted, starting a goroutine to write inventory updates to
+// state and creating the resource stack client.  The returned function must
+// be called once resources are deleted to unlock the state after the last
+// inventory has been written.
+func InitDelete(
+	stack Stack,
+	resourceClient *client.ResourceClient,
+	backend state.Backend,
+	deleteWait *sync.WaitGroup,
+) (Stack, Inventory, func(), error) {
+	title := strings.ToUpper(stack.Name())
+
+	// lock state so no other operation can change the resource stack
+	lockInfo, err := state.Acquire(resourceClient.Context, backend, "delete")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// load inventory to delete
+	inventory := stack.NewInventory()
+	if err := inventory.LoadState(resourceClient.Context, backend); err != nil {
+		if unlockErr := backend.Unlock(resourceClient.Context, lockInfo.Id); unlockErr != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load %s inventory and unlock state: %w", title, errors.Join(err, unlockErr))
+		}
+		return nil, nil, nil, fmt.Errorf("failed to load %s inventory: %w", title, err)
+	}
+
+	// capture inventory and write to state as resources are deleted
+	stackClient := stack.New(resourceClient)
+	stopStateWriter := stackClient.StartStateWriter(backend, lockInfo, deleteWait)
+
+	return stackClient, inventory, stopStateWriter, nil
+}
+
+// ConfigAs returns the config as the config type of a resource stack, or an
+// error if it is for another type of resource stack.  A nil config is
+// returned as nil.
+func ConfigAs[T Config](config Config) (T, error) {
+	var typed T
+	if config == nil {
+		return typed, nil
+	}
+	typed, ok := config.(T)
+	if !ok {
+		return typed, fmt.Errorf("expected config of type %T but got %T", typed, config)
+	}
+
+	return typed, nil
+}
+
+// InventoryAs returns the inventory as the inventory type of a resource stack,
+// or an error if it is for another type of resource stack.
+func InventoryAs[T Inventory](inventory Inventory) (T, error) {
+	typed, ok := inventory.(T)
+	if !ok {
+		return typed, fmt.Errorf("expected inventory of type %T but got %T", typed, inventory)
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,36 @@
+package stack
+
+// InventoryOutput is an output of a resource stack and the function that reads
+// its value from an inventory of type I.
+type InventoryOutput[I Inventory] struct {
+	Output
+
+	// Value returns the output's value, which must be a string if List is
+	// false and a []string if List is true.
+	Value func(inventory I) any
+}
+
+// Outputs returns the outputs without the functions that read their values.
+func Outputs[I Inventory](outputs []InventoryOutput[I]) []Output {
+	var definitions []Output
+	for _, output := range outputs {
+		definitions = append(definitions, output.Output)
+	}
+
+	return definitions
+}
+
+// OutputValues returns the value of each output read from the inventory by
+// output name.  Nil lists are returned as empty lists.
+func OutputValues[I Inventory](outputs []InventoryOutput[I], inventory I) map[string]any {
+	values := map[string]any{}
+	for _, output := range outputs {
+		value := output.Value(inventory)
+		if list, ok := value.([]string); ok && list == nil {
+			value = []string{}
+		}
+		values[output.Name] = value
+	}
+
+	return values
+}
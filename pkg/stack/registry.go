@@ -0,0 +1,58 @@
+package stack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// stacks contains registered resource stacks by name.
+var stacks = map[string]Stack{}
+
+// Register adds a resource stack to the registry.  It is intended to be called
+// from the init function of the package that defines the resource stack with
+// a zero value client, e.g. &eks.EksClient{}.  It panics if a resource stack
+// is already registered with the same name.
+func Register(stack Stack) {
+	if _, exists := stacks[stack.Name()]; exists {
+		panic(fmt.Sprintf("resource stack %s already registered", stack.Name()))
+	}
+	stacks[stack.Name()] = stack
+}
+
+// Get returns the registered resource stack with the name.
+func Get(name string) (Stack, error) {
+	stack, ok := stacks[name]
+	if !ok {
+		return nil, fmt.Errorf(
+			"unrecognized resource stack %s, must be one of: %s",
+			name,
+			strings.Join(Names(), ", "),
+		)
+	}
+
+	return stack, nil
+}
+
+// All returns the registered resource stacks sorted by name.
+func All() []Stack {
+	var all []Stack
+	for _, stack := range stacks {
+		all = append(all, stack)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+
+	return all
+}
+
+// Names returns the names of the registered resource stacks in sorted order.
+func Names() []string {
+	var names []string
+	for _, stack := range All() {
+		names = append(names, stack.Name())
+	}
+
+	return names
+}
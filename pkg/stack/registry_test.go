@@ -0,0 +1,52 @@
+package stack_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nukleros/aws-builder/pkg/eks"
+	"github.com/nukleros/aws-builder/pkg/rds"
+	"github.com/nukleros/aws-builder/pkg/s3"
+	"github.com/nukleros/aws-builder/pkg/stack"
+)
+
+func TestGet(t *testing.T) {
+	for name, expected := range map[string]stack.Stack{
+		"eks": &eks.EksClient{},
+		"rds": &rds.RdsClient{},
+		"s3":  &s3.S3Client{},
+	} {
+		resourceStack, err := stack.Get(name)
+		if err != nil {
+			t.Errorf("failed to get %s resource stack: %v", name, err)
+			continue
+		}
+		if resourceStack.Name() != name || reflect.TypeOf(resourceStack) != reflect.TypeOf(expected) {
+			t.Errorf("expected %s resource stack of type %T, got %s of type %T", name, expected, resourceStack.Name(), resourceStack)
+		}
+	}
+
+	_, err := stack.Get("dynamodb")
+	if err == nil || err.Error() != "unrecognized resource stack dynamodb, must be one of: eks, rds, s3" {
+		t.Errorf("expected unrecognized resource stack error, got %v", err)
+	}
+}
+
+func TestNames(t *testing.T) {
+	if names := stack.Names(); !reflect.DeepEqual(names, []string{"eks", "rds", "s3"}) {
+		t.Errorf("expected registered resource stacks in sorted order, got %v", names)
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recovered := recover(); recovered != "resource stack eks already registered" {
+			t.Errorf("expected panic for duplicate resource stack, got %v", recovered)
+		}
+		if names := stack.Names(); len(names) != 3 {
+			t.Errorf("expected registry not to change, got %v", names)
+		}
+	}()
+
+	stack.Register(&eks.EksClient{})
+}
@@ -0,0 +1,160 @@
+// Package stack defines the interface implemented by each type of resource
+// stack and a registry of them so commands can manage any resource stack
+// without knowing about each one.
+package stack
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nukleros/aws-builder/pkg/client"
+	"github.com/nukleros/aws-builder/pkg/configfile"
+	"github.com/nukleros/aws-builder/pkg/discovery"
+	"github.com/nukleros/aws-builder/pkg/drift"
+	"github.com/nukleros/aws-builder/pkg/orphan"
+	"github.com/nukleros/aws-builder/pkg/plan"
+	"github.com/nukleros/aws-builder/pkg/schema"
+	"github.com/nukleros/aws-builder/pkg/state"
+)
+
+// Config is the config for a resource stack, e.g. *eks.EksConfig.
+type Config interface {
+	// Validate returns a validation.Errors containing every problem found
+	// in the config, or nil if there are none.
+	Validate() error
+
+	// GetRegion returns the region resources are created in.  If empty,
+	// the region in AWS config is used.
+	GetRegion() string
+
+	// SetRegion sets the region resources are created in.
+	SetRegion(region string)
+}
+
+// Redacter is implemented by configs that contain secrets.
+type Redacter interface {
+	// Redact replaces secrets in the config so it can be printed.
+	Redact()
+}
+
+// Importer is implemented by resource stacks that can rebuild a lost
+// inventory from existing resources.
+type Importer interface {
+	// Import searches for the resources the config would create by their
+	// tags and names and records those found in the inventory.  Resources
+	// with more than one match are left out of the inventory and reported
+	// as ambiguous.
+	Import(config Config, inventory Inventory) (*discovery.Report, error)
+}
+
+// Adopter is implemented by resource stacks that can bring resources created
+// outside of aws-builder under management.
+type Adopter interface {
+	// Adopt describes the existing resource with the name, e.g. an EKS
+	// cluster name, and the resources it depends on and records them in the
+	// inventory as adopted so they are kept when the resource stack is
+	// deleted.  If applyTags is true, the resource stack's Name tag with the
+	// name and the custom tags are added to the adopted resources.
+	Adopt(name string, tags map[string]string, applyTags bool, inventory Inventory) (*discovery.Report, error)
+}
+
+// Collector is implemented by resource stacks that can find and delete
+// resources left behind by failed operations.
+type Collector interface {
+	// Orphans searches for resources with the tags, names and IAM path the
+	// config gives the resource stack's resources and reports those that are
+	// not in the inventory.  If broad is true the inventory is empty because
+	// none was found.  No resources are changed.
+	Orphans(config Config, inventory Inventory, broad bool) (*orphan.Report, error)
+
+	// DeleteOrphans deletes the orphans in the report in dependency order.
+	// Each orphan is attempted and recorded in the report as deleted or
+	// failed, and an error joining every failure is returned.
+	DeleteOrphans(report *orphan.Report) error
+}
+
+// Inventory is the record of resources in a resource stack, e.g.
+// *eks.EksInventory.
+type Inventory interface {
+	Load(inventoryFile string) error
+	Write(inventoryFile string) error
+	LoadState(ctx context.Context, backend state.Backend) error
+	WriteState(ctx context.Context, backend state.Backend) error
+
+	// Outputs returns the value of every output of the resource stack by
+	// output name.  Each output is a string or a []string and is empty if
+	// its resource has not been created.
+	Outputs() map[string]any
+}
+
+// Output describes a value from a resource stack's inventory that other
+// resource stacks and scripts can use.
+type Output struct {
+	// The name of the output, e.g. vpcId.
+	Name string `json:"name"`
+
+	// A description of the output.
+	Description string `json:"description"`
+
+	// Whether the output is a list of strings rather than a string.
+	List bool `json:"list"`
+}
+
+// Stack is a resource stack client, e.g. *eks.EksClient.  The configs and
+// inventories passed to its methods must be those returned by the same type
+// of resource stack.
+type Stack interface {
+	// Name returns the name the resource stack is registered as, e.g. "eks".
+	Name() string
+
+	// Description returns the AWS service the resource stack provides,
+	// e.g. "Elastic Kubernetes Service".
+	Description() string
+
+	// New returns a client for the resource stack that uses the resource
+	// client.
+	New(resourceClient *client.ResourceClient) Stack
+
+	// NewConfig returns an empty config with no defaults applied.
+	NewConfig() Config
+
+	// LoadConfig loads config files and applies defaults.  References to
+	// the outputs of other resource stacks are resolved with outputs, which
+	// may be nil.
+	LoadConfig(outputs configfile.Outputs, configFiles ...string) (Config, error)
+
+	// ConfigSchema returns the JSON Schema for config files.
+	ConfigSchema() *schema.Schema
+
+	// WaiterNames returns the resources that can be waited on.
+	WaiterNames() []string
+
+	// NewInventory returns an empty inventory.
+	NewInventory() Inventory
+
+	// Outputs returns the outputs of the resource stack in the order they
+	// are listed.
+	Outputs() []Output
+
+	// InventorySchemaVersion returns the schema version of inventories
+	// written by this version of aws-builder.
+	InventorySchemaVersion() int
+
+	// StartStateWriter starts a goroutine that writes each inventory update
+	// to state and unlocks the state once the returned function is called
+	// and the last inventory has been written.
+	StartStateWriter(backend state.Backend, lockInfo state.LockInfo, wait *sync.WaitGroup) func()
+
+	// Create creates the resource stack, reusing resources in the inventory.
+	Create(config Config, inventory Inventory) error
+
+	// Delete deletes the resources in the inventory.
+	Delete(inventory Inventory) error
+
+	// Plan returns the changes creating the resource stack would make.
+	Plan(config Config, inventory Inventory) (*plan.ChangeSet, error)
+
+	// Verify checks the resources in the inventory for drift.  The config
+	// may be nil.
+	Verify(inventory Inventory, config Config) (*drift.Report, error)
+}
@@ -0,0 +1,97 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultHistoryLimit is the number of inventory snapshots kept when a backend
+// has no history limit set.
+const DefaultHistoryLimit = 20
+
+// snapshotIdLayout is the time layout used for snapshot IDs.  IDs sort in
+// the order the snapshots were taken.
+const snapshotIdLayout = "20060102T150405.000000000Z"
+
+// Snapshot describes a previous inventory kept in the state history.
+type Snapshot struct {
+	// The ID used to restore the snapshot.
+	Id string `json:"id"`
+
+	// The time the snapshot was taken.
+	Time time.Time `json:"time"`
+
+	// The size of the inventory in bytes.
+	Size int64 `json:"size"`
+}
+
+// newSnapshotId returns the ID for a snapshot taken now.
+func newSnapshotId() string {
+	return time.Now().UTC().Format(snapshotIdLayout)
+}
+
+// parseSnapshotId returns the time a snapshot was taken from its ID.
+func parseSnapshotId(id string) (time.Time, error) {
+	snapshotTime, err := time.Parse(snapshotIdLayout, id)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid snapshot ID %s: %w", id, err)
+	}
+
+	return snapshotTime, nil
+}
+
+// sortSnapshots sorts snapshots newest first.
+func sortSnapshots(snapshots []Snapshot) {
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Id > snapshots[j].Id
+	})
+}
+
+// historyLimit returns limit or DefaultHistoryLimit if limit is not set.
+func historyLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultHistoryLimit
+	}
+
+	return limit
+}
+
+// snapshotName returns the file or object name for a snapshot ID.
+func snapshotName(id string) string {
+	return id + ".json"
+}
+
+// snapshotIdFromName returns the snapshot ID for a file or object name and
+// false if the name is not a snapshot.
+func snapshotIdFromName(name string) (string, bool) {
+	id, ok := strings.CutSuffix(name, ".json")
+	if !ok {
+		return "", false
+	}
+	if _, err := parseSnapshotId(id); err != nil {
+		return "", false
+	}
+
+	return id, true
+}
+
+// Restore replaces the current inventory with a snapshot from the state
+// history.  The current inventory is added to the history first so the
+// restore can be undone.
+func Restore(ctx context.Context, backend Backend, id string) error {
+	data, err := backend.ReadSnapshot(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", id, err)
+	}
+	if err := backend.Snapshot(ctx); err != nil {
+		return fmt.Errorf("failed to snapshot current inventory: %w", err)
+	}
+	if err := backend.Write(ctx, data); err != nil {
+		return fmt.Errorf("failed to write snapshot %s to %s: %w", id, backend, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// LocalBackend stores state in a file on the local filesystem.  The lock is
+// held by creating a file next to the state file so it only prevents
+// concurrent operations on the same host.  Snapshots are kept in a directory
+// next to the state file.
+type LocalBackend struct {
+	// The path to the state file.
+	Path string
+
+	// The number of snapshots to keep.  If zero, DefaultHistoryLimit is used.
+	HistoryLimit int
+}
+
+// lockPath returns the path to the lock file.
+func (b *LocalBackend) lockPath() string {
+	return b.Path + ".lock"
+}
+
+// Read returns the contents of the state file.
+func (b *LocalBackend) Read(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(b.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return data, err
+}
+
+// Write replaces the state file atomically.
+func (b *LocalBackend) Write(ctx context.Context, data []byte) error {
+	return util.WriteFileAtomic(b.Path, data, 0644)
+}
+
+// Delete removes the state file.
+func (b *LocalBackend) Delete(ctx context.Context) error {
+	if err := os.Remove(b.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Lock creates the lock file.  It fails if the lock file already exists.
+func (b *LocalBackend) Lock(ctx context.Context, info LockInfo) error {
+	lockJson, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock info: %w", err)
+	}
+
+	lockFile, err := os.OpenFile(b.lockPath(), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		held, err := b.readLock()
+		if err != nil {
+			return err
+		}
+		return &LockedError{Location: b.String(), Info: *held}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create lock file %s: %w", b.lockPath(), err)
+	}
+	defer lockFile.Close()
+
+	if _, err := lockFile.Write(lockJson); err != nil {
+		return fmt.Errorf("failed to write lock file %s: %w", b.lockPath(), err)
+	}
+
+	return nil
+}
+
+// Unlock removes the lock file if it is held by the lock with the given ID.
+func (b *LocalBackend) Unlock(ctx context.Context, id string) error {
+	if id != "" {
+		held, err := b.readLock()
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if held.Id != id {
+			return &LockedError{Location: b.String(), Info: *held}
+		}
+	}
+
+	if err := os.Remove(b.lockPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file %s: %w", b.lockPath(), err)
+	}
+
+	return nil
+}
+
+// readLock returns the info for the lock currently held.
+func (b *LocalBackend) readLock() (*LockInfo, error) {
+	lockJson, err := os.ReadFile(b.lockPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file %s: %w", b.lockPath(), err)
+	}
+
+	var info LockInfo
+	if err := json.Unmarshal(lockJson, &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lock file %s: %w", b.lockPath(), err)
+	}
+
+	return &info, nil
+}
+
+// historyPath returns the path to the directory snapshots are kept in.
+func (b *LocalBackend) historyPath() string {
+	return b.Path + ".history"
+}
+
+// Snapshot copies the state file to the history directory.
+func (b *LocalBackend) Snapshot(ctx context.Context) error {
+	data, err := b.Read(ctx)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state file %s: %w", b.Path, err)
+	}
+
+	if err := os.MkdirAll(b.historyPath(), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory %s: %w", b.historyPath(), err)
+	}
+	snapshotPath := filepath.Join(b.historyPath(), snapshotName(newSnapshotId()))
+	if err := util.WriteFileAtomic(snapshotPath, data, 0644); err != nil {
+		return err
+	}
+
+	// remove snapshots beyond the history limit
+	snapshots, err := b.History(ctx)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots[min(len(snapshots), historyLimit(b.HistoryLimit)):] {
+		if err := os.Remove(filepath.Join(b.historyPath(), snapshotName(snapshot.Id))); err != nil {
+			return fmt.Errorf("failed to remove snapshot %s: %w", snapshot.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// History returns the snapshots in the history directory.
+func (b *LocalBackend) History(ctx context.Context) ([]Snapshot, error) {
+	entries, err := os.ReadDir(b.historyPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history directory %s: %w", b.historyPath(), err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		id, ok := snapshotIdFromName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get info for snapshot %s: %w", id, err)
+		}
+		snapshotTime, _ := parseSnapshotId(id)
+		snapshots = append(snapshots, Snapshot{
+			Id:   id,
+			Time: snapshotTime,
+			Size: info.Size(),
+		})
+	}
+	sortSnapshots(snapshots)
+
+	return snapshots, nil
+}
+
+// ReadSnapshot returns the contents of a snapshot in the history directory.
+func (b *LocalBackend) ReadSnapshot(ctx context.Context, id string) ([]byte, error) {
+	if _, err := parseSnapshotId(id); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(b.historyPath(), snapshotName(id)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return data, err
+}
+
+func (b *LocalBackend) String() string {
+	return b.Path
+}
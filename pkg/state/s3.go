@@ -0,0 +1,260 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// S3Api contains the S3 operations used to store state.  It is satisfied by
+// *s3.Client.
+type S3Api interface {
+	DeleteObject(context.Context, *s3.DeleteObjectInput, ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	GetObject(context.Context, *s3.GetObjectInput, ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	ListObjectsV2(context.Context, *s3.ListObjectsV2Input, ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	PutObject(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Backend stores state in an S3 object.  The lock is held by creating an
+// object next to the state object using a conditional write so that it cannot
+// be acquired by two operations at once.  Snapshots are kept in objects with
+// the state object's key as a prefix.
+type S3Backend struct {
+	// The API used to read and write objects.
+	Api S3Api
+
+	// The bucket the state is stored in.
+	Bucket string
+
+	// The key of the state object.
+	Key string
+
+	// The number of snapshots to keep.  If zero, DefaultHistoryLimit is used.
+	HistoryLimit int
+}
+
+// lockKey returns the key of the lock object.
+func (b *S3Backend) lockKey() string {
+	return b.Key + ".lock"
+}
+
+// Read returns the contents of the state object.
+func (b *S3Backend) Read(ctx context.Context) ([]byte, error) {
+	data, err := b.getObject(ctx, b.Key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to get state object %s: %w", b, err)
+	}
+
+	return data, err
+}
+
+// Write writes the state object.
+func (b *S3Backend) Write(ctx context.Context, data []byte) error {
+	if _, err := b.Api.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: &b.Bucket,
+		Key:    &b.Key,
+		Body:   bytes.NewReader(data),
+	}); err != nil {
+		return fmt.Errorf("failed to put state object %s: %w", b, err)
+	}
+
+	return nil
+}
+
+// Delete removes the state object.
+func (b *S3Backend) Delete(ctx context.Context) error {
+	if _, err := b.Api.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &b.Bucket,
+		Key:    &b.Key,
+	}); err != nil {
+		return fmt.Errorf("failed to delete state object %s: %w", b, err)
+	}
+
+	return nil
+}
+
+// Lock creates the lock object.  It fails if the lock object already exists.
+func (b *S3Backend) Lock(ctx context.Context, info LockInfo) error {
+	lockJson, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock info: %w", err)
+	}
+
+	_, err = b.Api.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &b.Bucket,
+		Key:         aws.String(b.lockKey()),
+		Body:        bytes.NewReader(lockJson),
+		IfNoneMatch: aws.String("*"),
+	})
+	if util.HasErrorCode(err, "PreconditionFailed", "ConditionalRequestConflict") {
+		held, err := b.readLock(ctx)
+		if err != nil {
+			return err
+		}
+		return &LockedError{Location: b.String(), Info: *held}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put lock object for %s: %w", b, err)
+	}
+
+	return nil
+}
+
+// Unlock removes the lock object if it is held by the lock with the given ID.
+func (b *S3Backend) Unlock(ctx context.Context, id string) error {
+	if id != "" {
+		held, err := b.readLock(ctx)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if held.Id != id {
+			return &LockedError{Location: b.String(), Info: *held}
+		}
+	}
+
+	if _, err := b.Api.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &b.Bucket,
+		Key:    aws.String(b.lockKey()),
+	}); err != nil {
+		return fmt.Errorf("failed to delete lock object for %s: %w", b, err)
+	}
+
+	return nil
+}
+
+// readLock returns the info for the lock currently held.
+func (b *S3Backend) readLock(ctx context.Context) (*LockInfo, error) {
+	lockJson, err := b.getObject(ctx, b.lockKey())
+	if errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lock object for %s: %w", b, err)
+	}
+
+	var info LockInfo
+	if err := json.Unmarshal(lockJson, &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lock object for %s: %w", b, err)
+	}
+
+	return &info, nil
+}
+
+// historyPrefix returns the key prefix for snapshot objects.
+func (b *S3Backend) historyPrefix() string {
+	return b.Key + ".history/"
+}
+
+// Snapshot copies the state object to a snapshot object.
+func (b *S3Backend) Snapshot(ctx context.Context) error {
+	data, err := b.Read(ctx)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	snapshotKey := b.historyPrefix() + snapshotName(newSnapshotId())
+	if _, err := b.Api.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: &b.Bucket,
+		Key:    &snapshotKey,
+		Body:   bytes.NewReader(data),
+	}); err != nil {
+		return fmt.Errorf("failed to put snapshot object for %s: %w", b, err)
+	}
+
+	// remove snapshots beyond the history limit
+	snapshots, err := b.History(ctx)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots[min(len(snapshots), historyLimit(b.HistoryLimit)):] {
+		if _, err := b.Api.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: &b.Bucket,
+			Key:    aws.String(b.historyPrefix() + snapshotName(snapshot.Id)),
+		}); err != nil {
+			return fmt.Errorf("failed to delete snapshot %s for %s: %w", snapshot.Id, b, err)
+		}
+	}
+
+	return nil
+}
+
+// History returns the snapshot objects for the state object.
+func (b *S3Backend) History(ctx context.Context) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	paginator := s3.NewListObjectsV2Paginator(b.Api, &s3.ListObjectsV2Input{
+		Bucket: &b.Bucket,
+		Prefix: aws.String(b.historyPrefix()),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots for %s: %w", b, err)
+		}
+		for _, object := range page.Contents {
+			id, ok := snapshotIdFromName(strings.TrimPrefix(aws.ToString(object.Key), b.historyPrefix()))
+			if !ok {
+				continue
+			}
+			snapshotTime, _ := parseSnapshotId(id)
+			snapshots = append(snapshots, Snapshot{
+				Id:   id,
+				Time: snapshotTime,
+				Size: aws.ToInt64(object.Size),
+			})
+		}
+	}
+	sortSnapshots(snapshots)
+
+	return snapshots, nil
+}
+
+// ReadSnapshot returns the contents of a snapshot object.
+func (b *S3Backend) ReadSnapshot(ctx context.Context, id string) ([]byte, error) {
+	if _, err := parseSnapshotId(id); err != nil {
+		return nil, err
+	}
+
+	data, err := b.getObject(ctx, b.historyPrefix()+snapshotName(id))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to get snapshot %s for %s: %w", id, b, err)
+	}
+
+	return data, err
+}
+
+// getObject returns the contents of an object in the state bucket or
+// ErrNotFound if it does not exist.
+func (b *S3Backend) getObject(ctx context.Context, key string) ([]byte, error) {
+	resp, err := b.Api.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &b.Bucket,
+		Key:    &key,
+	})
+	if util.HasErrorCode(err, "NoSuchKey") {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+func (b *S3Backend) String() string {
+	return fmt.Sprintf("s3://%s/%s", b.Bucket, b.Key)
+}
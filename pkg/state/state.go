@@ -0,0 +1,165 @@
+// Package state stores resource stack inventories in a local file or an S3
+// object and provides locking so only one operation can change a resource
+// stack at a time.
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/nukleros/aws-builder/pkg/util"
+)
+
+// S3Prefix is the prefix of state locations stored in S3.
+const S3Prefix = "s3://"
+
+// ErrNotFound is returned when reading state that has not been written.
+var ErrNotFound = errors.New("state not found")
+
+// Backend stores the inventory for a resource stack.
+type Backend interface {
+	// Read returns the stored inventory or ErrNotFound if there is none.
+	Read(ctx context.Context) ([]byte, error)
+
+	// Write stores the inventory.
+	Write(ctx context.Context, data []byte) error
+
+	// Delete removes the stored inventory.  It is not an error if there is
+	// no stored inventory.
+	Delete(ctx context.Context) error
+
+	// Lock acquires the lock on the state.  A *LockedError is returned if
+	// the lock is held by another operation.
+	Lock(ctx context.Context, info LockInfo) error
+
+	// Unlock releases the lock with the given ID.  If id is empty the lock is
+	// released regardless of which operation holds it.
+	Unlock(ctx context.Context, id string) error
+
+	// Snapshot adds the stored inventory to the state history, removing the
+	// oldest snapshots beyond the history limit.  It does nothing if there is
+	// no stored inventory.
+	Snapshot(ctx context.Context) error
+
+	// History returns the snapshots in the state history, newest first.
+	History(ctx context.Context) ([]Snapshot, error)
+
+	// ReadSnapshot returns the inventory in the snapshot with the given ID.
+	ReadSnapshot(ctx context.Context, id string) ([]byte, error)
+
+	// String returns the location of the state, e.g. "s3://bucket/key".
+	String() string
+}
+
+// LockInfo describes the operation holding a state lock.
+type LockInfo struct {
+	Id        string    `json:"id"`
+	Operation string    `json:"operation"`
+	Who       string    `json:"who"`
+	Created   time.Time `json:"created"`
+}
+
+// NewLockInfo returns lock info with a unique ID for an operation, e.g.
+// "create", run by the current user on this host.
+func NewLockInfo(operation string) LockInfo {
+	who := "unknown"
+	if currentUser, err := user.Current(); err == nil {
+		who = currentUser.Username
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		who = fmt.Sprintf("%s@%s", who, hostname)
+	}
+
+	return LockInfo{
+		Id:        util.RandomAlphaNumericString(16),
+		Operation: operation,
+		Who:       who,
+		Created:   time.Now().UTC(),
+	}
+}
+
+// LockedError is returned when a lock is held by another operation.
+type LockedError struct {
+	// The location of the locked state.
+	Location string
+
+	// The lock held on the state.
+	Info LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf(
+		"state %s is locked by %s for %s since %s (lock ID %s)",
+		e.Location,
+		e.Info.Who,
+		e.Info.Operation,
+		e.Info.Created.Format(time.RFC3339),
+		e.Info.Id,
+	)
+}
+
+// Acquire locks the state for an operation, e.g. "create", and adds the
+// stored inventory to the state history so it can be restored if the
+// operation goes wrong.  The returned lock info is used to unlock the state.
+func Acquire(ctx context.Context, backend Backend, operation string) (LockInfo, error) {
+	lockInfo := NewLockInfo(operation)
+	if err := backend.Lock(ctx, lockInfo); err != nil {
+		return LockInfo{}, fmt.Errorf("failed to lock state: %w", err)
+	}
+
+	if err := backend.Snapshot(ctx); err != nil {
+		if unlockErr := backend.Unlock(ctx, lockInfo.Id); unlockErr != nil {
+			err = errors.Join(err, unlockErr)
+		}
+		return LockInfo{}, fmt.Errorf("failed to add inventory to state history: %w", err)
+	}
+
+	return lockInfo, nil
+}
+
+// New returns the backend for a state location.  Locations of the form
+// s3://bucket/key are stored in S3, optionally with "region" and "endpoint"
+// query parameters to use a bucket in another region or an S3-compatible
+// service.  Any other location is a local file path and awsConfig is not
+// used.
+func New(location string, awsConfig *aws.Config) (Backend, error) {
+	if !strings.HasPrefix(location, S3Prefix) {
+		return &LocalBackend{Path: strings.TrimPrefix(location, "file://")}, nil
+	}
+
+	stateUrl, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state location %s: %w", location, err)
+	}
+	bucket := stateUrl.Host
+	key := strings.TrimPrefix(stateUrl.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid state location %s, must be of the form s3://bucket/key", location)
+	}
+
+	query := stateUrl.Query()
+	api := s3.NewFromConfig(*awsConfig, func(o *s3.Options) {
+		if region := query.Get("region"); region != "" {
+			o.Region = region
+		}
+		if endpoint := query.Get("endpoint"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3Backend{
+		Api:    api,
+		Bucket: bucket,
+		Key:    key,
+	}, nil
+}
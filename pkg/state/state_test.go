@@ -0,0 +1,245 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/nukleros/aws-builder/pkg/fake"
+)
+
+// testBackends returns a local backend in a temporary directory and an S3
+// backend that stores state in a fake S3 bucket, both with the given history
+// limit.
+func testBackends(t *testing.T, historyLimit int) map[string]Backend {
+	t.Helper()
+
+	s3Api := fake.NewBackend("").Apis().S3
+	if _, err := s3Api.CreateBucket(context.Background(), &s3.CreateBucketInput{
+		Bucket: aws.String("state"),
+	}); err != nil {
+		t.Fatalf("failed to create state bucket: %v", err)
+	}
+	stateApi, ok := s3Api.(S3Api)
+	if !ok {
+		t.Fatal("fake S3 API does not support state operations")
+	}
+
+	return map[string]Backend{
+		"local": &LocalBackend{
+			Path:         filepath.Join(t.TempDir(), "inventory.json"),
+			HistoryLimit: historyLimit,
+		},
+		"s3": &S3Backend{
+			Api:          stateApi,
+			Bucket:       "state",
+			Key:          "eks/inventory.json",
+			HistoryLimit: historyLimit,
+		},
+	}
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	for name, backend := range testBackends(t, 0) {
+		t.Run(name, func(t *testing.T) {
+			first := NewLockInfo("create")
+			if err := backend.Lock(ctx, first); err != nil {
+				t.Fatalf("failed to lock state: %v", err)
+			}
+
+			var lockedErr *LockedError
+			err := backend.Lock(ctx, NewLockInfo("delete"))
+			if !errors.As(err, &lockedErr) {
+				t.Fatalf("expected LockedError when lock is held, got %v", err)
+			}
+			if lockedErr.Info.Id != first.Id || lockedErr.Info.Operation != "create" {
+				t.Errorf("expected lock held by %s for create, got %+v", first.Id, lockedErr.Info)
+			}
+
+			if err := backend.Unlock(ctx, "other"); !errors.As(err, &lockedErr) {
+				t.Errorf("expected LockedError when unlocking another lock, got %v", err)
+			}
+			if err := backend.Unlock(ctx, first.Id); err != nil {
+				t.Fatalf("failed to unlock state: %v", err)
+			}
+			if err := backend.Unlock(ctx, first.Id); err != nil {
+				t.Errorf("expected unlocking a released lock to succeed, got %v", err)
+			}
+
+			second := NewLockInfo("delete")
+			if err := backend.Lock(ctx, second); err != nil {
+				t.Fatalf("failed to lock released state: %v", err)
+			}
+			if err := backend.Unlock(ctx, ""); err != nil {
+				t.Fatalf("failed to force unlock state: %v", err)
+			}
+			if err := backend.Lock(ctx, NewLockInfo("create")); err != nil {
+				t.Errorf("failed to lock force unlocked state: %v", err)
+			}
+		})
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	for name, backend := range testBackends(t, 0) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := backend.Read(ctx); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound before state is written, got %v", err)
+			}
+
+			// acquiring the lock without inventory takes no snapshot
+			lockInfo, err := Acquire(ctx, backend, "create")
+			if err != nil {
+				t.Fatalf("failed to acquire state: %v", err)
+			}
+			if err := backend.Write(ctx, []byte(`{"vpcId":"vpc-1"}`)); err != nil {
+				t.Fatalf("failed to write state: %v", err)
+			}
+			if err := backend.Unlock(ctx, lockInfo.Id); err != nil {
+				t.Fatalf("failed to unlock state: %v", err)
+			}
+			snapshots, err := backend.History(ctx)
+			if err != nil {
+				t.Fatalf("failed to get history: %v", err)
+			}
+			if len(snapshots) != 0 {
+				t.Fatalf("expected no snapshots, got %v", snapshots)
+			}
+
+			// acquiring the lock with inventory snapshots it
+			if _, err := Acquire(ctx, backend, "create"); err != nil {
+				t.Fatalf("failed to acquire state: %v", err)
+			}
+			if err := backend.Write(ctx, []byte(`{"vpcId":"vpc-2"}`)); err != nil {
+				t.Fatalf("failed to write state: %v", err)
+			}
+			snapshots, err = backend.History(ctx)
+			if err != nil {
+				t.Fatalf("failed to get history: %v", err)
+			}
+			if len(snapshots) != 1 {
+				t.Fatalf("expected 1 snapshot, got %v", snapshots)
+			}
+
+			if err := Restore(ctx, backend, snapshots[0].Id); err != nil {
+				t.Fatalf("failed to restore snapshot: %v", err)
+			}
+			data, err := backend.Read(ctx)
+			if err != nil {
+				t.Fatalf("failed to read state: %v", err)
+			}
+			if string(data) != `{"vpcId":"vpc-1"}` {
+				t.Errorf("expected restored inventory, got %s", data)
+			}
+
+			// the restore can be undone
+			snapshots, err = backend.History(ctx)
+			if err != nil {
+				t.Fatalf("failed to get history: %v", err)
+			}
+			if len(snapshots) != 2 {
+				t.Fatalf("expected 2 snapshots, got %v", snapshots)
+			}
+			data, err = backend.ReadSnapshot(ctx, snapshots[0].Id)
+			if err != nil {
+				t.Fatalf("failed to read snapshot: %v", err)
+			}
+			if string(data) != `{"vpcId":"vpc-2"}` {
+				t.Errorf("expected replaced inventory in newest snapshot, got %s", data)
+			}
+		})
+	}
+}
+
+func TestSnapshotHistoryLimit(t *testing.T) {
+	ctx := context.Background()
+	for name, backend := range testBackends(t, 2) {
+		t.Run(name, func(t *testing.T) {
+			if err := backend.Write(ctx, []byte(`{}`)); err != nil {
+				t.Fatalf("failed to write state: %v", err)
+			}
+			for range 3 {
+				if err := backend.Snapshot(ctx); err != nil {
+					t.Fatalf("failed to snapshot state: %v", err)
+				}
+			}
+			snapshots, err := backend.History(ctx)
+			if err != nil {
+				t.Fatalf("failed to get history: %v", err)
+			}
+			if len(snapshots) != 2 {
+				t.Errorf("expected history limited to 2 snapshots, got %v", snapshots)
+			}
+		})
+	}
+}
+
+func TestRestoreMissingSnapshot(t *testing.T) {
+	ctx := context.Background()
+	for name, backend := range testBackends(t, 0) {
+		t.Run(name, func(t *testing.T) {
+			if err := Restore(ctx, backend, "20240101T000000.000000000Z"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound for missing snapshot, got %v", err)
+			}
+			if err := Restore(ctx, backend, "latest"); err == nil {
+				t.Error("expected error for invalid snapshot ID")
+			}
+		})
+	}
+}
+
+type testInventory struct {
+	VpcId string `json:"vpcId"`
+}
+
+func (i *testInventory) WriteState(ctx context.Context, backend Backend) error {
+	inventoryBytes, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	return backend.Write(ctx, inventoryBytes)
+}
+
+func TestStartWriter(t *testing.T) {
+	for name, backend := range testBackends(t, 0) {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			lockInfo, err := Acquire(ctx, backend, "create")
+			if err != nil {
+				t.Fatalf("failed to acquire state: %v", err)
+			}
+
+			var wait sync.WaitGroup
+			inventoryChan := make(chan testInventory)
+			StartWriter(ctx, backend, lockInfo, inventoryChan, &wait)
+			inventoryChan <- testInventory{VpcId: "vpc-1"}
+
+			// inventory sent after cancellation is still written
+			cancel()
+			inventoryChan <- testInventory{VpcId: "vpc-2"}
+			close(inventoryChan)
+			wait.Wait()
+
+			data, err := backend.Read(context.Background())
+			if err != nil {
+				t.Fatalf("failed to read state: %v", err)
+			}
+			if string(data) != `{"vpcId":"vpc-2"}` {
+				t.Errorf("expected last inventory to be written, got %s", data)
+			}
+			if err := backend.Lock(context.Background(), NewLockInfo("delete")); err != nil {
+				t.Errorf("expected state to be unlocked once the channel is closed, got %v", err)
+			}
+		})
+	}
+}
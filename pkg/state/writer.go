@@ -0,0 +1,52 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// InventoryWriter is implemented by inventories that can be written to state,
+// e.g. *eks.EksInventory.
+type InventoryWriter interface {
+	WriteState(ctx context.Context, backend Backend) error
+}
+
+// StartWriter starts a goroutine that writes each inventory received on the
+// inventory channel to state and releases the lock when the channel is
+// closed.  Writes are not cancelled with the context so the latest inventory
+// is saved if the operation is interrupted.  Failures are written to stderr so
+// they don't mix with progress output on stdout.
+func StartWriter[I any, P interface {
+	*I
+	InventoryWriter
+}](
+	ctx context.Context,
+	backend Backend,
+	lockInfo LockInfo,
+	inventoryChan <-chan I,
+	wait *sync.WaitGroup,
+) {
+	ctx = context.WithoutCancel(ctx)
+
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		for inventory := range inventoryChan {
+			if err := P(&inventory).WriteState(ctx, backend); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to write inventory: %s\n", err)
+			}
+		}
+		Release(ctx, backend, lockInfo.Id)
+	}()
+}
+
+// Release unlocks the state with the lock ID and writes any failure to
+// stderr.  It is not cancelled with the context so it can be deferred once
+// the state is locked.
+func Release(ctx context.Context, backend Backend, lockId string) {
+	if err := backend.Unlock(context.WithoutCancel(ctx), lockId); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to unlock state: %s\n", err)
+	}
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// SleepContext pauses for the provided duration or until the context is done,
+// whichever comes first.  It returns the context's error if the context is
+// done before the duration elapses.
+func SleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -1,5 +1,25 @@
 package util
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+)
 
 var ErrResourceNotFound = errors.New("resource not found")
+
+// HasErrorCode returns true if the error is an AWS API error with one of the
+// provided error codes.
+func HasErrorCode(err error, codes ...string) bool {
+	var ae smithy.APIError
+	if !errors.As(err, &ae) {
+		return false
+	}
+	for _, code := range codes {
+		if ae.ErrorCode() == code {
+			return true
+		}
+	}
+
+	return false
+}
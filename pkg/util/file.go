@@ -0,0 +1,42 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to a file so that the file contains either its
+// previous contents or the new data, even if the process is interrupted.  The
+// data is written to a temporary file in the same directory which is then
+// renamed over the file.
+func WriteFileAtomic(name string, data []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", name, err)
+	}
+	tempName := tempFile.Name()
+
+	// remove the temporary file if it was not renamed
+	defer os.Remove(tempName)
+
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write temporary file for %s: %w", name, err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to sync temporary file for %s: %w", name, err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file for %s: %w", name, err)
+	}
+	if err := os.Chmod(tempName, perm); err != nil {
+		return fmt.Errorf("failed to set permissions on temporary file for %s: %w", name, err)
+	}
+	if err := os.Rename(tempName, name); err != nil {
+		return fmt.Errorf("failed to rename temporary file to %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package validation collects the problems found in a resource stack config
+// so they can all be reported at once.
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Problem is an invalid value in a config.
+type Problem struct {
+	// The path to the field using config file names, e.g.
+	// "availabilityZones[0].privateSubnetCidr".
+	Field string `json:"field"`
+
+	// A description of the problem.
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Field, p.Message)
+}
+
+// Errors contains all the problems found in a config.
+type Errors []Problem
+
+// Add adds a problem for a field.
+func (e *Errors) Add(field, format string, args ...any) {
+	*e = append(*e, Problem{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Err returns the errors if any problems were found, otherwise nil.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e Errors) Error() string {
+	problems := make([]string, len(e))
+	for i, problem := range e {
+		problems[i] = problem.String()
+	}
+
+	return fmt.Sprintf("%d problem(s) found: %s", len(e), strings.Join(problems, "; "))
+}
+
+// Index returns the path to an element of a list field, e.g.
+// "availabilityZones[0]".
+func Index(field string, index int) string {
+	return fmt.Sprintf("%s[%d]", field, index)
+}
+
+// Key returns the path to a value in a map field, e.g. "waiters.cluster".
+func Key(field string, key string) string {
+	return fmt.Sprintf("%s.%s", field, key)
+}
+
+// ParseCidr parses an IPv4 CIDR block, adding a problem for the field if it is
+// invalid.  It returns false if the CIDR is invalid.
+func (e *Errors) ParseCidr(field, cidr string) (netip.Prefix, bool) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil || !prefix.Addr().Is4() {
+		e.Add(field, "%q is not a valid IPv4 CIDR block", cidr)
+		return netip.Prefix{}, false
+	}
+	if prefix.Masked() != prefix {
+		e.Add(field, "%q has host bits set, use %s", cidr, prefix.Masked())
+		return netip.Prefix{}, false
+	}
+
+	return prefix, true
+}
+
+// Contains returns true if the inner CIDR block is entirely within the outer
+// CIDR block.
+func Contains(outer, inner netip.Prefix) bool {
+	return outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+// Tags adds a problem for each tag that AWS would reject.
+func (e *Errors) Tags(field string, tags map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		tagField := Key(field, key)
+		switch {
+		case strings.HasPrefix(strings.ToLower(key), "aws:"):
+			e.Add(tagField, "tag keys beginning with \"aws:\" are reserved")
+		case utf8.RuneCountInString(key) > 128:
+			e.Add(tagField, "tag key must be 128 characters or less")
+		}
+		if utf8.RuneCountInString(tags[key]) > 256 {
+			e.Add(tagField, "tag value must be 256 characters or less")
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package waiter polls AWS resources until they reach a desired condition with
+// a configurable timeout, poll interval and exponential backoff.
+package waiter
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nukleros/aws-builder/pkg/schema"
+	"github.com/nukleros/aws-builder/pkg/util"
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+// DefaultMaxInterval is the longest time between checks when backoff is used
+// and no maximum interval is configured.
+const DefaultMaxInterval = time.Minute
+
+// sleepContext waits between checks.  Tests replace it to record intervals
+// without waiting.
+var sleepContext = util.SleepContext
+
+// Config contains the settings used to wait for a resource.  Zero values are
+// replaced with the defaults for the resource.
+type Config struct {
+	// The total time to wait before giving up, e.g. "15m".
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+
+	// The time to wait between the first and second checks, e.g. "15s".
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// The longest time to wait between checks when using backoff.
+	MaxInterval time.Duration `yaml:"maxInterval" json:"maxInterval"`
+
+	// The factor the interval is multiplied by after each check.  A value of
+	// 1 checks at a fixed interval.
+	Backoff float64 `yaml:"backoff" json:"backoff"`
+}
+
+// SchemaFields describes the config fields in generated schemas.
+func (Config) SchemaFields() map[string]schema.Field {
+	return map[string]schema.Field{
+		"timeout":     {Description: "The total time to wait before giving up, e.g. \"15m\"."},
+		"interval":    {Description: "The time to wait between the first and second checks, e.g. \"15s\"."},
+		"maxInterval": {Description: "The longest time to wait between checks when using backoff."},
+		"backoff":     {Description: "The factor the interval is multiplied by after each check.  A value of 1 checks at a fixed interval."},
+	}
+}
+
+// WithDefaults returns the config with any zero values replaced by the values
+// in defaults.
+func (c Config) WithDefaults(defaults Config) Config {
+	if c.Timeout == 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.Interval == 0 {
+		c.Interval = defaults.Interval
+	}
+	if c.MaxInterval == 0 {
+		c.MaxInterval = defaults.MaxInterval
+	}
+	if c.Backoff == 0 {
+		c.Backoff = defaults.Backoff
+	}
+
+	return c
+}
+
+// Configs contains waiter configs by the name of the resource being waited
+// on, e.g. "cluster" or "rds-instance".
+type Configs map[string]Config
+
+// Get returns the config for the named resource with zero values replaced by
+// the values in defaults.
+func (c Configs) Get(name string, defaults Config) Config {
+	return c[name].WithDefaults(defaults)
+}
+
+// Merge returns configs containing the values in c with any zero values
+// replaced by the values in other for the same resource.
+func (c Configs) Merge(other Configs) Configs {
+	merged := Configs{}
+	for name, config := range other {
+		merged[name] = config
+	}
+	for name, config := range c {
+		merged[name] = config.WithDefaults(other[name])
+	}
+
+	return merged
+}
+
+// Validate adds a problem to errs for each config that is for a resource not
+// in names or has a negative duration or a backoff less than 1.
+func (c Configs) Validate(errs *validation.Errors, field string, names ...string) {
+	for _, name := range slices.Sorted(maps.Keys(c)) {
+		config := c[name]
+		configField := validation.Key(field, name)
+		switch {
+		case len(names) == 0:
+			errs.Add(configField, "no resources in this resource stack can be waited on")
+			continue
+		case !slices.Contains(names, name):
+			errs.Add(configField, "unknown resource, must be one of %s", strings.Join(names, ", "))
+			continue
+		}
+		if config.Timeout < 0 {
+			errs.Add(validation.Key(configField, "timeout"), "must not be negative")
+		}
+		if config.Interval < 0 {
+			errs.Add(validation.Key(configField, "interval"), "must not be negative")
+		}
+		if config.MaxInterval < 0 {
+			errs.Add(validation.Key(configField, "maxInterval"), "must not be negative")
+		}
+		if config.Backoff != 0 && config.Backoff < 1 {
+			errs.Add(validation.Key(configField, "backoff"), "must be 1 or greater")
+		}
+	}
+}
+
+// TimeoutError is returned when a resource does not reach the desired
+// condition before the timeout.
+type TimeoutError struct {
+	// A description of the resource being waited on.
+	Resource string
+
+	// The configured timeout.
+	Timeout time.Duration
+
+	// The status of the resource at the last check.
+	LastStatus string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf(
+		"timed out after %s waiting for %s, last status: %s",
+		e.Timeout,
+		e.Resource,
+		e.LastStatus,
+	)
+}
+
+// CheckFunc checks a resource's condition.  It returns true when the desired
+// condition is met, otherwise it returns the resource's current status.  An
+// error stops waiting and is returned to the caller.
+type CheckFunc func() (done bool, status string, err error)
+
+// Wait calls check until it reports the desired condition is met, returns an
+// error, the timeout elapses or the context is done.  The resource description
+// is used in errors, e.g. "cluster my-cluster".
+func Wait(ctx context.Context, config Config, resource string, check CheckFunc) error {
+	deadline := time.Now().Add(config.Timeout)
+	interval := config.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	maxInterval := config.MaxInterval
+	if maxInterval == 0 {
+		maxInterval = DefaultMaxInterval
+	}
+
+	for {
+		done, status, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return &TimeoutError{
+				Resource:   resource,
+				Timeout:    config.Timeout,
+				LastStatus: status,
+			}
+		}
+
+		if err := sleepContext(ctx, min(interval, remaining)); err != nil {
+			return fmt.Errorf("interrupted while waiting for %s: %w", resource, err)
+		}
+
+		if config.Backoff > 1 {
+			interval = min(time.Duration(float64(interval)*config.Backoff), max(maxInterval, config.Interval))
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nukleros/aws-builder/pkg/util"
+	"github.com/nukleros/aws-builder/pkg/validation"
+)
+
+// recordSleeps replaces sleepContext for the rest of the test with one that
+// records the intervals in the returned slice without waiting.
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+
+	var intervals []time.Duration
+	sleepContext = func(ctx context.Context, duration time.Duration) error {
+		intervals = append(intervals, duration)
+		return ctx.Err()
+	}
+	t.Cleanup(func() {
+		sleepContext = util.SleepContext
+	})
+
+	return &intervals
+}
+
+// doneAfter returns a check that reports the desired condition on the nth
+// call.
+func doneAfter(n int) CheckFunc {
+	calls := 0
+	return func() (bool, string, error) {
+		calls++
+		return calls == n, fmt.Sprintf("check %d", calls), nil
+	}
+}
+
+func TestWaitBackoff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   Config
+		expected []time.Duration
+	}{
+		{
+			name:     "fixed interval",
+			config:   Config{Timeout: time.Hour, Interval: 15 * time.Second, Backoff: 1},
+			expected: []time.Duration{15 * time.Second, 15 * time.Second, 15 * time.Second, 15 * time.Second},
+		},
+		{
+			name:     "capped at max interval",
+			config:   Config{Timeout: time.Hour, Interval: time.Second, MaxInterval: 5 * time.Second, Backoff: 2},
+			expected: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		},
+		{
+			name:     "capped at default max interval",
+			config:   Config{Timeout: time.Hour, Interval: 40 * time.Second, Backoff: 2},
+			expected: []time.Duration{40 * time.Second, DefaultMaxInterval, DefaultMaxInterval, DefaultMaxInterval},
+		},
+		{
+			name:     "interval longer than max interval",
+			config:   Config{Timeout: time.Hour, Interval: 10 * time.Second, MaxInterval: 5 * time.Second, Backoff: 2},
+			expected: []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			intervals := recordSleeps(t)
+
+			if err := Wait(context.Background(), testCase.config, "test resource", doneAfter(5)); err != nil {
+				t.Fatalf("failed to wait: %v", err)
+			}
+			if !reflect.DeepEqual(*intervals, testCase.expected) {
+				t.Errorf("expected intervals %v, got %v", testCase.expected, *intervals)
+			}
+		})
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	config := Config{Timeout: 20 * time.Millisecond, Interval: 5 * time.Millisecond, Backoff: 1}
+	calls := 0
+	check := func() (bool, string, error) {
+		calls++
+		return false, fmt.Sprintf("check %d", calls), nil
+	}
+
+	err := Wait(context.Background(), config, "cluster test-0", check)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	expected := TimeoutError{Resource: "cluster test-0", Timeout: config.Timeout, LastStatus: fmt.Sprintf("check %d", calls)}
+	if *timeoutErr != expected {
+		t.Errorf("expected %+v, got %+v", expected, *timeoutErr)
+	}
+	if calls < 2 {
+		t.Errorf("expected the resource to be checked until the timeout, checked %d times", calls)
+	}
+}
+
+func TestWaitStops(t *testing.T) {
+	recordSleeps(t)
+	config := Config{Timeout: time.Hour, Interval: time.Second, Backoff: 1}
+
+	// an error from the check is returned as is
+	checkErr := errors.New("cluster failed")
+	err := Wait(context.Background(), config, "cluster test-0", func() (bool, string, error) {
+		return false, "", checkErr
+	})
+	if err != checkErr {
+		t.Errorf("expected check error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Wait(ctx, config, "cluster test-0", doneAfter(5))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled error, got %v", err)
+	}
+}
+
+func TestConfigsMerge(t *testing.T) {
+	configs := Configs{
+		"cluster":      {Timeout: 30 * time.Minute},
+		"rds-instance": {Interval: time.Minute, Backoff: 1.5},
+	}
+	defaults := Configs{
+		"cluster":     {Timeout: 15 * time.Minute, Interval: 15 * time.Second, Backoff: 1},
+		"nat-gateway": {Timeout: 5 * time.Minute, Interval: 15 * time.Second, Backoff: 1},
+	}
+
+	merged := configs.Merge(defaults)
+	expected := Configs{
+		"cluster":      {Timeout: 30 * time.Minute, Interval: 15 * time.Second, Backoff: 1},
+		"nat-gateway":  {Timeout: 5 * time.Minute, Interval: 15 * time.Second, Backoff: 1},
+		"rds-instance": {Interval: time.Minute, Backoff: 1.5},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected merged configs %v, got %v", expected, merged)
+	}
+	if configs["cluster"].Interval != 0 || len(defaults) != 2 || defaults["cluster"].Timeout != 15*time.Minute {
+		t.Errorf("expected merge not to change its inputs, got %v and %v", configs, defaults)
+	}
+}
+
+func TestConfigsValidate(t *testing.T) {
+	configs := Configs{
+		"cluster":     {Timeout: -time.Minute, Backoff: 0.5},
+		"node-group":  {Interval: time.Second},
+		"nat-gateway": {MaxInterval: -time.Second},
+	}
+
+	var errs validation.Errors
+	configs.Validate(&errs, "waiters", "cluster", "nat-gateway")
+	expected := validation.Errors{
+		{Field: "waiters.cluster.timeout", Message: "must not be negative"},
+		{Field: "waiters.cluster.backoff", Message: "must be 1 or greater"},
+		{Field: "waiters.nat-gateway.maxInterval", Message: "must not be negative"},
+		{Field: "waiters.node-group", Message: "unknown resource, must be one of cluster, nat-gateway"},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected problems %v, got %v", expected, errs)
+	}
+}